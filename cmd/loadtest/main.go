@@ -35,6 +35,7 @@ func main() {
 	aggregateFilename := aggregateCmd.String("file", defaultFilename, "Input file name")
 	aggregateSkipCache := aggregateCmd.Bool("skip-cache", true, "Skip using cached sums")
 	aggregateParallel := aggregateCmd.Int("parallel", 0, "Parallel factor (0=sequential, <0=auto/GOMAXPROCS, >0=specific number of workers)")
+	aggregateReadAhead := aggregateCmd.Int("read-ahead", 0, "Number of blocks decoded ahead during the full scan (0=no read-ahead)")
 
 	// Check if a command is provided
 	if len(os.Args) < 2 {
@@ -49,7 +50,7 @@ func main() {
 		runImport(*importNumValues, *importBlockSize, *importFilename, *importSeed, *importMaxValue, *importMaxID)
 	case "aggregate":
 		aggregateCmd.Parse(os.Args[2:])
-		runAggregate(*aggregateFilename, *aggregateSkipCache, *aggregateParallel)
+		runAggregate(*aggregateFilename, *aggregateSkipCache, *aggregateParallel, *aggregateReadAhead)
 	default:
 		fmt.Printf("Unknown command: %s\n", os.Args[1])
 		fmt.Println("Expected 'import' or 'aggregate' subcommand")
@@ -170,7 +171,7 @@ func runImport(numValues, blockSize int, filename string, seed int64, maxValue i
 	}
 }
 
-func runAggregate(filename string, skipCache bool, parallel int) {
+func runAggregate(filename string, skipCache bool, parallel int, readAhead int) {
 	fmt.Printf("Running aggregations on %s (skip cache: %v, parallel: %v)\n", filename, skipCache, parallel)
 
 	// Open the file
@@ -187,10 +188,10 @@ func runAggregate(filename string, skipCache bool, parallel int) {
 	fmt.Printf("Block count: %d\n", reader.BlockCount())
 
 	// Run different aggregation operations
-	runAggregations(reader, skipCache, parallel)
+	runAggregations(reader, skipCache, parallel, readAhead)
 }
 
-func runAggregations(reader *col.Reader, skipCache bool, parallel int) {
+func runAggregations(reader *col.Reader, skipCache bool, parallel int, readAhead int) {
 	// Track overall time
 	startTime := time.Now()
 
@@ -204,6 +205,10 @@ func runAggregations(reader *col.Reader, skipCache bool, parallel int) {
 	aggStart := time.Now()
 	result := reader.AggregateWithOptions(opts)
 	aggDuration := time.Since(aggStart)
+	if err := result.Err(); err != nil {
+		fmt.Printf("Error aggregating: %v\n", err)
+		os.Exit(1)
+	}
 
 	// Print results
 	fmt.Printf("Count: %d\n", result.Count)
@@ -225,13 +230,28 @@ func runAggregations(reader *col.Reader, skipCache bool, parallel int) {
 	// Run full scan (read all blocks)
 	scanStart := time.Now()
 	var totalValues int64
-	for i := uint64(0); i < reader.BlockCount(); i++ {
-		_, values, err := reader.GetPairs(i)
+	if readAhead > 0 {
+		// Decode blocks in the background while counting
+		scanner := reader.ScanBlocks(readAhead)
+		defer scanner.Close()
+		for scanner.Next() {
+			_, _, values := scanner.Block()
+			totalValues += int64(len(values))
+		}
+		if err := scanner.Err(); err != nil {
+			fmt.Printf("Error scanning blocks: %v\n", err)
+			return
+		}
+	} else {
+		// Decode blocks with the same parallelism as the aggregation
+		err := reader.ScanAllUnordered(parallel, func(_ col.BlockID, _ []uint64, values []int64) error {
+			totalValues += int64(len(values))
+			return nil
+		})
 		if err != nil {
-			fmt.Printf("Error reading block %d: %v\n", i, err)
+			fmt.Printf("Error scanning blocks: %v\n", err)
 			return
 		}
-		totalValues += int64(len(values))
 	}
 	scanDuration := time.Since(scanStart)
 	fmt.Printf("Full scan: %d values (%.2f ms, %.2f values/sec)\n",
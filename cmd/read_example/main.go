@@ -5,8 +5,9 @@ import (
 	"errors"
 	"flag"
 	"fmt"
-	"io"
 	"os"
+
+	"vibe-lsm/pkg/col/format"
 )
 
 const (
@@ -14,70 +15,12 @@ const (
 	MagicNumber uint64 = 0x5649424553434F4C // "VIBESCOL" in ASCII
 )
 
-// FileHeader represents the file header structure
-type FileHeader struct {
-	Magic         uint64
-	Version       uint32
-	ColumnType    uint32
-	BlockCount    uint64
-	BlockSizeTarget uint32
-	CompressionType uint32
-	EncodingType  uint32
-	CreationTime  uint64
-	// Reserved bytes not included in struct
-}
-
-// BlockHeader represents the block header structure
-type BlockHeader struct {
-	MinID         uint64
-	MaxID         uint64
-	MinValue      int64
-	MaxValue      int64
-	Sum           int64
-	Count         uint32
-	EncodingType  uint32
-	CompressionType uint32
-	UncompressedSize uint32
-	CompressedSize uint32
-	Checksum      uint64
-	// Reserved bytes not included in struct
-}
-
-// BlockDataLayout represents the block data layout
-type BlockDataLayout struct {
-	IDSectionOffset   uint32
-	IDSectionSize     uint32
-	ValueSectionOffset uint32
-	ValueSectionSize  uint32
-}
-
-// FooterEntry represents an entry in the block index
-type FooterEntry struct {
-	BlockOffset   uint64
-	BlockSize     uint32
-	MinID         uint64
-	MaxID         uint64
-	MinValue      int64
-	MaxValue      int64
-	Sum           int64
-	Count         uint32
-}
-
-// Footer represents the file footer
-type Footer struct {
-	BlockIndexCount uint32
-	Entries        []FooterEntry
-	FooterSize     uint64
-	Checksum       uint64
-	Magic          uint64
-}
-
 // Reader provides methods to read our column format
 type Reader struct {
-	file        *os.File
-	fileHeader  FileHeader
-	footer      Footer
-	readBuffer  []byte // Reusable buffer to minimize allocations
+	file       *os.File
+	fileHeader format.FileHeader
+	footer     format.Footer
+	footerMeta format.FooterMetadata
 }
 
 // NewReader creates a new Reader for the given file
@@ -89,7 +32,6 @@ func NewReader(filename string) (*Reader, error) {
 
 	reader := &Reader{
 		file: file,
-		readBuffer: make([]byte, 8), // Start with a small reusable buffer
 	}
 
 	// Read and validate file header
@@ -98,7 +40,7 @@ func NewReader(filename string) (*Reader, error) {
 		return nil, err
 	}
 
-	// Read and validate footer 
+	// Read and validate footer
 	if err := reader.readFooter(); err != nil {
 		file.Close()
 		return nil, err
@@ -107,16 +49,23 @@ func NewReader(filename string) (*Reader, error) {
 	return reader, nil
 }
 
+// readAt reads size bytes at offset
+func (r *Reader) readAt(offset int64, size int) ([]byte, error) {
+	buf := make([]byte, size)
+	if _, err := r.file.ReadAt(buf, offset); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
 // readFileHeader reads and validates the file header
 func (r *Reader) readFileHeader() error {
-	// Seek to the beginning of the file
-	if _, err := r.file.Seek(0, io.SeekStart); err != nil {
-		return fmt.Errorf("failed to seek to file header: %w", err)
+	buf, err := r.readAt(0, format.FileHeaderSize)
+	if err != nil {
+		return fmt.Errorf("failed to read file header: %w", err)
 	}
-
-	// Read the magic number
-	if err := binary.Read(r.file, binary.LittleEndian, &r.fileHeader.Magic); err != nil {
-		return fmt.Errorf("failed to read magic number: %w", err)
+	if err := r.fileHeader.UnmarshalBinary(buf); err != nil {
+		return err
 	}
 
 	// Validate the magic number
@@ -124,44 +73,11 @@ func (r *Reader) readFileHeader() error {
 		return errors.New("invalid file format: magic number mismatch")
 	}
 
-	// Read the rest of the header
-	if err := binary.Read(r.file, binary.LittleEndian, &r.fileHeader.Version); err != nil {
-		return fmt.Errorf("failed to read version: %w", err)
-	}
-	if err := binary.Read(r.file, binary.LittleEndian, &r.fileHeader.ColumnType); err != nil {
-		return fmt.Errorf("failed to read column type: %w", err)
-	}
-	if err := binary.Read(r.file, binary.LittleEndian, &r.fileHeader.BlockCount); err != nil {
-		return fmt.Errorf("failed to read block count: %w", err)
-	}
-	if err := binary.Read(r.file, binary.LittleEndian, &r.fileHeader.BlockSizeTarget); err != nil {
-		return fmt.Errorf("failed to read block size target: %w", err)
-	}
-	if err := binary.Read(r.file, binary.LittleEndian, &r.fileHeader.CompressionType); err != nil {
-		return fmt.Errorf("failed to read compression type: %w", err)
-	}
-	if err := binary.Read(r.file, binary.LittleEndian, &r.fileHeader.EncodingType); err != nil {
-		return fmt.Errorf("failed to read encoding type: %w", err)
-	}
-	if err := binary.Read(r.file, binary.LittleEndian, &r.fileHeader.CreationTime); err != nil {
-		return fmt.Errorf("failed to read creation time: %w", err)
-	}
-
-	// Skip reserved bytes (24 bytes)
-	if _, err := r.file.Seek(24, io.SeekCurrent); err != nil {
-		return fmt.Errorf("failed to skip reserved bytes: %w", err)
-	}
-
 	return nil
 }
 
-// readFooter reads the file footer
-// Based on our hexdump analysis, we can see:
-// - Footer starts at offset 0x140 with block index count (01 00 00 00)
-// - Block index entry follows (40 00 00 00 00 00 00 00 = offset 64, etc.)
-// - Footer size at 0x170 (f4 = 244 bytes)
-// - CRC at 0x178 (f4 8e b1 5c 3c 59 bc f4)
-// - Magic at 0x180 (VIBESCOL)
+// readFooter reads the file footer. The footer metadata at the end of the file
+// holds the size of the footer, which locates its start.
 func (r *Reader) readFooter() error {
 	// First get file size
 	fileInfo, err := r.file.Stat()
@@ -169,62 +85,36 @@ func (r *Reader) readFooter() error {
 		return fmt.Errorf("failed to get file info: %w", err)
 	}
 	fileSize := fileInfo.Size()
-
-	// Seek to read the magic number at the end of the file (last 8 bytes)
-	if _, err := r.file.Seek(fileSize-8, io.SeekStart); err != nil {
-		return fmt.Errorf("failed to seek to footer magic: %w", err)
+	if fileSize < format.FileHeaderSize+format.FooterMetadataSize {
+		return fmt.Errorf("file too small: %d bytes", fileSize)
 	}
 
-	// Read and validate magic number
-	if err := binary.Read(r.file, binary.LittleEndian, &r.footer.Magic); err != nil {
-		return fmt.Errorf("failed to read footer magic number: %w", err)
+	// Read and validate the footer metadata (last 24 bytes)
+	metaOffset := fileSize - format.FooterMetadataSize
+	buf, err := r.readAt(metaOffset, format.FooterMetadataSize)
+	if err != nil {
+		return fmt.Errorf("failed to read footer metadata: %w", err)
 	}
-	if r.footer.Magic != MagicNumber {
+	if err := r.footerMeta.UnmarshalBinary(buf); err != nil {
+		return err
+	}
+	if r.footerMeta.Magic != MagicNumber {
 		return errors.New("invalid file format: footer magic number mismatch")
 	}
 
-	// Based on hexdump analysis, directly seek to offset 0x140 (start of footer)
-	if _, err := r.file.Seek(0x140, io.SeekStart); err != nil {
-		return fmt.Errorf("failed to seek to footer start: %w", err)
+	// The footer directly precedes its metadata
+	footerStart := metaOffset - int64(r.footerMeta.FooterSize)
+	if footerStart < format.FileHeaderSize {
+		return fmt.Errorf("invalid footer size: %d", r.footerMeta.FooterSize)
 	}
-
-	// Read block index count
-	if err := binary.Read(r.file, binary.LittleEndian, &r.footer.BlockIndexCount); err != nil {
-		return fmt.Errorf("failed to read block index count: %w", err)
+	buf, err = r.readAt(footerStart, int(r.footerMeta.FooterSize))
+	if err != nil {
+		return fmt.Errorf("failed to read footer: %w", err)
 	}
-
-	// Read block index entries
-	r.footer.Entries = make([]FooterEntry, r.footer.BlockIndexCount)
-	for i := uint32(0); i < r.footer.BlockIndexCount; i++ {
-		if err := binary.Read(r.file, binary.LittleEndian, &r.footer.Entries[i].BlockOffset); err != nil {
-			return fmt.Errorf("failed to read block offset: %w", err)
-		}
-		if err := binary.Read(r.file, binary.LittleEndian, &r.footer.Entries[i].BlockSize); err != nil {
-			return fmt.Errorf("failed to read block size: %w", err)
-		}
-		if err := binary.Read(r.file, binary.LittleEndian, &r.footer.Entries[i].MinID); err != nil {
-			return fmt.Errorf("failed to read min ID: %w", err)
-		}
-		if err := binary.Read(r.file, binary.LittleEndian, &r.footer.Entries[i].MaxID); err != nil {
-			return fmt.Errorf("failed to read max ID: %w", err)
-		}
-		if err := binary.Read(r.file, binary.LittleEndian, &r.footer.Entries[i].MinValue); err != nil {
-			return fmt.Errorf("failed to read min value: %w", err)
-		}
-		if err := binary.Read(r.file, binary.LittleEndian, &r.footer.Entries[i].MaxValue); err != nil {
-			return fmt.Errorf("failed to read max value: %w", err)
-		}
-		if err := binary.Read(r.file, binary.LittleEndian, &r.footer.Entries[i].Sum); err != nil {
-			return fmt.Errorf("failed to read sum: %w", err)
-		}
-		if err := binary.Read(r.file, binary.LittleEndian, &r.footer.Entries[i].Count); err != nil {
-			return fmt.Errorf("failed to read count: %w", err)
-		}
+	if err := r.footer.UnmarshalBinary(buf); err != nil {
+		return fmt.Errorf("failed to parse footer: %w", err)
 	}
 
-	// Set footer size based on file info
-	r.footer.FooterSize = uint64(fileSize - 0x140)
-
 	return nil
 }
 
@@ -233,123 +123,85 @@ func (r *Reader) Close() error {
 	return r.file.Close()
 }
 
-// DumpKVPairs dumps all key-value pairs to stdout
-func (r *Reader) DumpKVPairs() error {
-	fmt.Println("ID\tValue")
-	fmt.Println("--\t-----")
-
-	// Based on the hexdump we can see:
-	// - Block header starts at offset 0x40
-	// - Block data layout at offset 0x90
-	// - ID array starts at offset 0xa0
-	// - Value array starts at offset 0xf0
-
-	// Process each block (we know there's just one block in our example)
-	blockOffset := int64(0x40) // From hexdump analysis
-	
-	// Seek to the block header
-	if _, err := r.file.Seek(blockOffset, io.SeekStart); err != nil {
-		return fmt.Errorf("failed to seek to block header: %w", err)
+// readBlock reads the IDs and values of the block described by entry. The
+// example files store both as raw 8-byte integers.
+func (r *Reader) readBlock(entry format.FooterEntry) ([]uint64, []int64, error) {
+	buf, err := r.readAt(int64(entry.BlockOffset), format.BlockHeaderSize+format.BlockLayoutSize)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read block header: %w", err)
 	}
 
-	// Read block header
-	var header BlockHeader
-	if err := binary.Read(r.file, binary.LittleEndian, &header.MinID); err != nil {
-		return fmt.Errorf("failed to read min ID: %w", err)
-	}
-	if err := binary.Read(r.file, binary.LittleEndian, &header.MaxID); err != nil {
-		return fmt.Errorf("failed to read max ID: %w", err)
-	}
-	if err := binary.Read(r.file, binary.LittleEndian, &header.MinValue); err != nil {
-		return fmt.Errorf("failed to read min value: %w", err)
-	}
-	if err := binary.Read(r.file, binary.LittleEndian, &header.MaxValue); err != nil {
-		return fmt.Errorf("failed to read max value: %w", err)
+	var header format.BlockHeader
+	if err := header.UnmarshalBinary(buf[:format.BlockHeaderSize]); err != nil {
+		return nil, nil, err
 	}
-	if err := binary.Read(r.file, binary.LittleEndian, &header.Sum); err != nil {
-		return fmt.Errorf("failed to read sum: %w", err)
-	}
-	if err := binary.Read(r.file, binary.LittleEndian, &header.Count); err != nil {
-		return fmt.Errorf("failed to read count: %w", err)
-	}
-	if err := binary.Read(r.file, binary.LittleEndian, &header.EncodingType); err != nil {
-		return fmt.Errorf("failed to read encoding type: %w", err)
-	}
-	if err := binary.Read(r.file, binary.LittleEndian, &header.CompressionType); err != nil {
-		return fmt.Errorf("failed to read compression type: %w", err)
-	}
-	if err := binary.Read(r.file, binary.LittleEndian, &header.UncompressedSize); err != nil {
-		return fmt.Errorf("failed to read uncompressed size: %w", err)
-	}
-	if err := binary.Read(r.file, binary.LittleEndian, &header.CompressedSize); err != nil {
-		return fmt.Errorf("failed to read compressed size: %w", err)
-	}
-	if err := binary.Read(r.file, binary.LittleEndian, &header.Checksum); err != nil {
-		return fmt.Errorf("failed to read checksum: %w", err)
-	}
-
-	// Skip reserved bytes (8 bytes)
-	if _, err := r.file.Seek(8, io.SeekCurrent); err != nil {
-		return fmt.Errorf("failed to skip reserved bytes: %w", err)
+	var layout format.BlockLayout
+	if err := layout.UnmarshalBinary(buf[format.BlockHeaderSize:]); err != nil {
+		return nil, nil, err
 	}
 
-	// Read block data layout
-	var layout BlockDataLayout
-	if err := binary.Read(r.file, binary.LittleEndian, &layout.IDSectionOffset); err != nil {
-		return fmt.Errorf("failed to read ID section offset: %w", err)
-	}
-	if err := binary.Read(r.file, binary.LittleEndian, &layout.IDSectionSize); err != nil {
-		return fmt.Errorf("failed to read ID section size: %w", err)
-	}
-	if err := binary.Read(r.file, binary.LittleEndian, &layout.ValueSectionOffset); err != nil {
-		return fmt.Errorf("failed to read value section offset: %w", err)
+	// The section offsets are relative to the end of the layout
+	dataStart := int64(entry.BlockOffset) + format.BlockHeaderSize + format.BlockLayoutSize
+	dataSize := layout.DataSize()
+	if dataSize > uint64(entry.BlockSize) {
+		return nil, nil, fmt.Errorf("block data of %d bytes exceeds block size of %d bytes", dataSize, entry.BlockSize)
 	}
-	if err := binary.Read(r.file, binary.LittleEndian, &layout.ValueSectionSize); err != nil {
-		return fmt.Errorf("failed to read value section size: %w", err)
+	data, err := r.readAt(dataStart, int(dataSize))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read block data: %w", err)
 	}
 
-	// Fixed count based on our data - we know there are 10 entries
-	count := uint32(10)
-	
-	// Direct seek to IDs (based on hexdump)
-	if _, err := r.file.Seek(0xa0, io.SeekStart); err != nil {
-		return fmt.Errorf("failed to seek to IDs: %w", err)
+	count := int(header.Count)
+	if int(layout.IDSectionSize) != count*8 || int(layout.ValueSectionSize) != count*8 {
+		return nil, nil, fmt.Errorf("unexpected section sizes for %d raw pairs: ids=%d, values=%d",
+			count, layout.IDSectionSize, layout.ValueSectionSize)
 	}
-	
-	// Read IDs
+
+	idData := data[layout.IDSectionOffset:]
+	valueData := data[layout.ValueSectionOffset:]
 	ids := make([]uint64, count)
-	for i := uint32(0); i < count; i++ {
-		if err := binary.Read(r.file, binary.LittleEndian, &ids[i]); err != nil {
-			return fmt.Errorf("failed to read ID: %w", err)
-		}
+	values := make([]int64, count)
+	for i := 0; i < count; i++ {
+		ids[i] = binary.LittleEndian.Uint64(idData[i*8:])
+		values[i] = int64(binary.LittleEndian.Uint64(valueData[i*8:]))
 	}
 
-	// Direct seek to values (based on hexdump)
-	if _, err := r.file.Seek(0xf0, io.SeekStart); err != nil {
-		return fmt.Errorf("failed to seek to values: %w", err)
-	}
-	
-	// Read values
-	values := make([]int64, count)
-	for i := uint32(0); i < count; i++ {
-		if err := binary.Read(r.file, binary.LittleEndian, &values[i]); err != nil {
-			return fmt.Errorf("failed to read value: %w", err)
+	return ids, values, nil
+}
+
+// DumpKVPairs dumps all key-value pairs to stdout
+func (r *Reader) DumpKVPairs() error {
+	fmt.Println("ID\tValue")
+	fmt.Println("--\t-----")
+
+	var ids []uint64
+	var values []int64
+	for i, entry := range r.footer.Entries {
+		blockIDs, blockValues, err := r.readBlock(entry)
+		if err != nil {
+			return fmt.Errorf("block %d: %w", i, err)
 		}
+		ids = append(ids, blockIDs...)
+		values = append(values, blockValues...)
 	}
 
 	// Print IDs and values
-	for i := uint32(0); i < count; i++ {
+	for i := range ids {
 		fmt.Printf("%d\t%d\n", ids[i], values[i])
 	}
 
+	if len(ids) == 0 {
+		return nil
+	}
+
 	// Compute actual statistics from the data
 	minID := ids[0]
 	maxID := ids[0]
 	minValue := values[0]
 	maxValue := values[0]
 	var sum int64
-	
-	for i := uint32(0); i < count; i++ {
+
+	for i := range ids {
 		if ids[i] < minID {
 			minID = ids[i]
 		}
@@ -364,13 +216,13 @@ func (r *Reader) DumpKVPairs() error {
 		}
 		sum += values[i]
 	}
-	
+
 	fmt.Printf("\nBlock Statistics (computed from data):\n")
-	fmt.Printf("Count: %d\n", count)
+	fmt.Printf("Count: %d\n", len(ids))
 	fmt.Printf("Min ID: %d, Max ID: %d\n", minID, maxID)
 	fmt.Printf("Min Value: %d, Max Value: %d\n", minValue, maxValue)
 	fmt.Printf("Sum: %d\n", sum)
-	fmt.Printf("Average: %.2f\n\n", float64(sum)/float64(count))
+	fmt.Printf("Average: %.2f\n\n", float64(sum)/float64(len(ids)))
 
 	return nil
 }
@@ -381,18 +233,19 @@ func (r *Reader) AggregateFromMetadataOnly() {
 	var totalCount uint32
 	var totalSum int64
 	var globalMin int64 = int64(^uint64(0) >> 1) // Max int64 value
-	var globalMax int64 = -globalMin - 1          // Min int64 value
+	var globalMax int64 = -globalMin - 1         // Min int64 value
 
 	for _, entry := range r.footer.Entries {
+		// The example files store the value statistics in two's complement
 		totalCount += entry.Count
-		totalSum += entry.Sum
-		
-		if entry.MinValue < globalMin {
-			globalMin = entry.MinValue
+		totalSum += int64(entry.Sum)
+
+		if int64(entry.MinValue) < globalMin {
+			globalMin = int64(entry.MinValue)
 		}
-		
-		if entry.MaxValue > globalMax {
-			globalMax = entry.MaxValue
+
+		if int64(entry.MaxValue) > globalMax {
+			globalMax = int64(entry.MaxValue)
 		}
 	}
 
@@ -451,4 +304,4 @@ func main() {
 		fmt.Println("No operation specified. Use --dump to show key-value pairs or --agg to show aggregations.")
 		flag.PrintDefaults()
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"vibe-lsm/pkg/col"
+)
+
+// dumpFormats are the formats accepted by the read command's -format flag.
+// text is the human-readable listing; the others print nothing but the pairs,
+// so the output can be fed to scripts.
+var dumpFormats = []string{"text", "json", "csv", "tsv"}
+
+// dumpOptions selects the pairs printed by the read command's -dump flag
+type dumpOptions struct {
+	format string
+	block  int64  // Block to dump, -1 for all blocks
+	minID  uint64 // Inclusive ID range
+	maxID  uint64
+	offset uint64 // Number of matching pairs to skip
+	limit  uint64 // Maximum number of pairs to print, 0 for no limit
+}
+
+// errDumpLimit stops the scan once the limit of a dump is reached
+var errDumpLimit = errors.New("dump limit reached")
+
+// pairWriter prints pairs in one of the dump formats
+type pairWriter interface {
+	header() error
+	pair(id uint64, value int64) error
+	flush() error
+}
+
+// newPairWriter returns the pair writer for format
+func newPairWriter(format string, out io.Writer) (pairWriter, error) {
+	switch format {
+	case "text":
+		return &textPairWriter{w: bufio.NewWriter(out), separator: "\t", heading: "ID\tValue\n--\t-----\n"}, nil
+	case "tsv":
+		return &textPairWriter{w: bufio.NewWriter(out), separator: "\t", heading: "id\tvalue\n"}, nil
+	case "csv":
+		return &csvPairWriter{w: csv.NewWriter(out)}, nil
+	case "json":
+		return &jsonPairWriter{w: bufio.NewWriter(out)}, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected %s", format, strings.Join(dumpFormats, ", "))
+	}
+}
+
+// textPairWriter prints a pair per line with the ID and value separated by separator
+type textPairWriter struct {
+	w         *bufio.Writer
+	separator string
+	heading   string
+}
+
+func (p *textPairWriter) header() error {
+	_, err := p.w.WriteString(p.heading)
+	return err
+}
+
+func (p *textPairWriter) pair(id uint64, value int64) error {
+	var buf [48]byte
+	line := strconv.AppendUint(buf[:0], id, 10)
+	line = append(line, p.separator...)
+	line = strconv.AppendInt(line, value, 10)
+	line = append(line, '\n')
+	_, err := p.w.Write(line)
+	return err
+}
+
+func (p *textPairWriter) flush() error {
+	return p.w.Flush()
+}
+
+// csvPairWriter prints the pairs as CSV with an id,value header
+type csvPairWriter struct {
+	w *csv.Writer
+}
+
+func (p *csvPairWriter) header() error {
+	return p.w.Write([]string{"id", "value"})
+}
+
+func (p *csvPairWriter) pair(id uint64, value int64) error {
+	return p.w.Write([]string{strconv.FormatUint(id, 10), strconv.FormatInt(value, 10)})
+}
+
+func (p *csvPairWriter) flush() error {
+	p.w.Flush()
+	return p.w.Error()
+}
+
+// jsonPairWriter prints a JSON object per pair and line
+type jsonPairWriter struct {
+	w *bufio.Writer
+}
+
+func (p *jsonPairWriter) header() error {
+	return nil
+}
+
+func (p *jsonPairWriter) pair(id uint64, value int64) error {
+	line, err := json.Marshal(struct {
+		ID    uint64 `json:"id"`
+		Value int64  `json:"value"`
+	}{id, value})
+	if err != nil {
+		return err
+	}
+	_, err = p.w.Write(append(line, '\n'))
+	return err
+}
+
+func (p *jsonPairWriter) flush() error {
+	return p.w.Flush()
+}
+
+// dumpPairs prints the pairs selected by opts in block order
+func dumpPairs(reader *col.Reader, opts dumpOptions, out io.Writer) error {
+	writer, err := newPairWriter(opts.format, out)
+	if err != nil {
+		return err
+	}
+	if err := writer.header(); err != nil {
+		return err
+	}
+
+	var skipped, printed uint64
+	print := func(_ col.BlockID, ids []uint64, values []int64) error {
+		for i, id := range ids {
+			if skipped < opts.offset {
+				skipped++
+				continue
+			}
+			if opts.limit > 0 && printed == opts.limit {
+				return errDumpLimit
+			}
+			if err := writer.pair(id, values[i]); err != nil {
+				return err
+			}
+			printed++
+		}
+		return nil
+	}
+
+	if opts.block >= 0 {
+		err = dumpBlock(reader, col.BlockID(opts.block), opts.minID, opts.maxID, print)
+	} else {
+		err = reader.ScanIDRange(opts.minID, opts.maxID, print)
+	}
+	if err != nil && !errors.Is(err, errDumpLimit) {
+		return err
+	}
+	return writer.flush()
+}
+
+// dumpBlock passes the pairs of a single block in the ID range minID-maxID to fn
+func dumpBlock(reader *col.Reader, block col.BlockID, minID, maxID uint64, fn col.ScanFunc) error {
+	if uint64(block) >= reader.BlockCount() {
+		return fmt.Errorf("block %d out of range, the file has %d blocks", block, reader.BlockCount())
+	}
+	ids, values, err := reader.ReadBlock(block)
+	if err != nil {
+		return fmt.Errorf("failed to read block %d: %w", block, err)
+	}
+
+	n := 0
+	for i, id := range ids {
+		if id >= minID && id <= maxID {
+			ids[n], values[n] = id, values[i]
+			n++
+		}
+	}
+	return fn(block, ids[:n], values[:n])
+}
@@ -1,9 +1,15 @@
 package main
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"math"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -14,23 +20,53 @@ func main() {
 	// Define subcommands
 	writeCmd := flag.NewFlagSet("write", flag.ExitOnError)
 	readCmd := flag.NewFlagSet("read", flag.ExitOnError)
+	inspectCmd := flag.NewFlagSet("inspect", flag.ExitOnError)
+	verifyCmd := flag.NewFlagSet("verify", flag.ExitOnError)
+	repairCmd := flag.NewFlagSet("repair", flag.ExitOnError)
+	catalogCmd := flag.NewFlagSet("catalog", flag.ExitOnError)
 	
 	// Write command flags
 	writeOutputFile := writeCmd.String("o", "example.col", "Output file name")
 	writeIDs := writeCmd.String("ids", "", "Comma-separated list of IDs (uint64)")
 	writeValues := writeCmd.String("values", "", "Comma-separated list of values (int64)")
+	writeInput := writeCmd.String("input", "", "CSV file with one id,value pair per line, or - for stdin")
+	writeEncoding := writeCmd.String("encoding", "raw", "Encoding: "+strings.Join(encodingNames, ", ")+" or its number")
+	writeBlockSize := writeCmd.Int("block-size", 0, "Target block size in bytes (0 for the default)")
 	
 	// Read command flags
 	readInputFile := readCmd.String("f", "example.col", "Input file name")
 	dumpKV := readCmd.Bool("dump", false, "Dump all key-value pairs")
 	aggregate := readCmd.Bool("agg", false, "Show aggregations (count, min, max, sum, avg)")
+	dumpFormat := readCmd.String("format", "text", "Dump format: "+strings.Join(dumpFormats, ", "))
+	dumpBlock := readCmd.Int64("block", -1, "Dump only the given block (-1 for all blocks)")
+	dumpMinID := readCmd.Uint64("min-id", 0, "Dump only IDs greater than or equal to this ID")
+	dumpMaxID := readCmd.Uint64("max-id", math.MaxUint64, "Dump only IDs less than or equal to this ID")
+	dumpOffset := readCmd.Uint64("offset", 0, "Number of pairs to skip before dumping")
+	dumpLimit := readCmd.Uint64("limit", 0, "Maximum number of pairs to dump (0 for no limit)")
+
+	// Inspect command flags
+	inspectInputFile := inspectCmd.String("f", "example.col", "Input file name")
+	inspectStats := inspectCmd.Bool("stats", false, "Show storage efficiency (encoded size vs. raw size and overhead)")
+
+	// Verify command flags
+	verifyInputFile := verifyCmd.String("f", "example.col", "Input file name")
+
+	// Repair command flags
+	repairInputFile := repairCmd.String("f", "example.col", "Damaged input file name")
+	repairOutputFile := repairCmd.String("o", "repaired.col", "Output file name for the repaired copy")
 	
 	// Check for subcommand
 	if len(os.Args) < 2 {
-		fmt.Println("Expected 'write' or 'read' subcommand")
+		fmt.Println("Expected 'write', 'read', 'inspect', 'verify', 'repair' or 'catalog' subcommand")
 		fmt.Println("Usage:")
 		fmt.Println("  vibecol write -o output.col -ids \"1,2,3\" -values \"100,200,300\"")
+		fmt.Println("  vibecol write -o output.col -input pairs.csv -encoding varint-both")
 		fmt.Println("  vibecol read -f input.col --dump --agg")
+		fmt.Println("  vibecol read -f input.col --dump --format csv --min-id 100 --limit 1000")
+		fmt.Println("  vibecol inspect -f input.col --stats")
+		fmt.Println("  vibecol verify -f input.col")
+		fmt.Println("  vibecol repair -f damaged.col -o repaired.col")
+		fmt.Println("  vibecol catalog dir/")
 		os.Exit(1)
 	}
 
@@ -38,23 +74,55 @@ func main() {
 	switch os.Args[1] {
 	case "write":
 		writeCmd.Parse(os.Args[2:])
+		options, err := writerOptions(*writeEncoding, *writeBlockSize)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if *writeInput != "" {
+			runWriteInput(*writeOutputFile, *writeInput, options)
+			break
+		}
 		if *writeIDs == "" || *writeValues == "" {
-			fmt.Println("Error: both --ids and --values must be provided")
+			fmt.Println("Error: either --input or both --ids and --values must be provided")
 			writeCmd.PrintDefaults()
 			os.Exit(1)
 		}
-		runWrite(*writeOutputFile, *writeIDs, *writeValues)
+		runWrite(*writeOutputFile, *writeIDs, *writeValues, options)
 	case "read":
 		readCmd.Parse(os.Args[2:])
-		runRead(*readInputFile, *dumpKV, *aggregate)
+		runRead(*readInputFile, *dumpKV, *aggregate, dumpOptions{
+			format: *dumpFormat,
+			block:  *dumpBlock,
+			minID:  *dumpMinID,
+			maxID:  *dumpMaxID,
+			offset: *dumpOffset,
+			limit:  *dumpLimit,
+		})
+	case "inspect":
+		inspectCmd.Parse(os.Args[2:])
+		runInspect(*inspectInputFile, *inspectStats)
+	case "verify":
+		verifyCmd.Parse(os.Args[2:])
+		runVerify(*verifyInputFile)
+	case "repair":
+		repairCmd.Parse(os.Args[2:])
+		runRepair(*repairInputFile, *repairOutputFile)
+	case "catalog":
+		catalogCmd.Parse(os.Args[2:])
+		if catalogCmd.NArg() != 1 {
+			fmt.Println("Error: expected a single directory")
+			os.Exit(1)
+		}
+		runCatalog(catalogCmd.Arg(0))
 	default:
 		fmt.Printf("%q is not a valid command.\n", os.Args[1])
-		fmt.Println("Valid commands: 'write' or 'read'")
+		fmt.Println("Valid commands: 'write', 'read', 'inspect', 'verify', 'repair' or 'catalog'")
 		os.Exit(1)
 	}
 }
 
-func runWrite(outputFile, idsStr, valuesStr string) {
+func runWrite(outputFile, idsStr, valuesStr string, options []col.WriterOption) {
 	// Parse IDs and values
 	idsStrArr := strings.Split(idsStr, ",")
 	valuesStrArr := strings.Split(valuesStr, ",")
@@ -88,7 +156,7 @@ func runWrite(outputFile, idsStr, valuesStr string) {
 	}
 	
 	// Create writer
-	writer, err := col.NewWriter(outputFile)
+	writer, err := col.NewWriter(outputFile, options...)
 	if err != nil {
 		fmt.Printf("Error creating file: %v\n", err)
 		os.Exit(1)
@@ -110,7 +178,139 @@ func runWrite(outputFile, idsStr, valuesStr string) {
 	fmt.Printf("Wrote file with %d entries to %s\n", len(ids), outputFile)
 }
 
-func runRead(inputFile string, dumpKV, aggregate bool) {
+// encodingNames are the names accepted by the -encoding flag, in the order of
+// the encoding type numbers
+var encodingNames = func() []string {
+	var names []string
+	for _, encoding := range col.Encodings() {
+		names = append(names, encoding.Name)
+	}
+	return names
+}()
+
+// writerOptions returns the writer options for the -encoding and -block-size flags
+func writerOptions(encoding string, blockSize int) ([]col.WriterOption, error) {
+	encodingType := -1
+	for i, name := range encodingNames {
+		if name == encoding {
+			encodingType = i
+		}
+	}
+	if encodingType < 0 {
+		n, err := strconv.Atoi(encoding)
+		if err != nil || n < 0 || n >= len(encodingNames) {
+			return nil, fmt.Errorf("unknown encoding %q", encoding)
+		}
+		encodingType = n
+	}
+
+	options := []col.WriterOption{col.WithEncoding(uint32(encodingType))}
+	if blockSize < 0 {
+		return nil, fmt.Errorf("block size must not be negative, got %d", blockSize)
+	}
+	if blockSize > 0 {
+		options = append(options, col.WithBlockSize(uint32(blockSize)))
+	}
+	return options, nil
+}
+
+// inputBatchSize is the number of pairs parsed before they are handed to the writer
+const inputBatchSize = 8192
+
+// runWriteInput writes the id,value pairs of a CSV file, or of stdin for "-",
+// to outputFile. The input is parsed in batches, so it never has to fit into
+// memory. Lines starting with # are skipped, as is a header line whose first
+// field is not a number. Unsorted input is sorted per batch, so batches with
+// overlapping IDs become blocks with overlapping ID ranges.
+func runWriteInput(outputFile, input string, options []col.WriterOption) {
+	in := os.Stdin
+	if input != "-" {
+		file, err := os.Open(input)
+		if err != nil {
+			fmt.Printf("Error opening input: %v\n", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	simpleOptions := make([]col.SimpleWriterOption, len(options))
+	for i, option := range options {
+		simpleOptions[i] = option
+	}
+	writer, err := col.NewSimpleWriter(outputFile, simpleOptions...)
+	if err != nil {
+		fmt.Printf("Error creating file: %v\n", err)
+		os.Exit(1)
+	}
+
+	count, err := writeCSV(writer, in)
+	if err != nil {
+		fmt.Printf("Error writing %s: %v\n", input, err)
+		writer.Close()
+		os.Exit(1)
+	}
+	if err := writer.Close(); err != nil {
+		fmt.Printf("Error finalizing file: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Wrote file with %d entries to %s\n", count, outputFile)
+}
+
+// writeCSV parses id,value records from in and writes them in batches. It
+// returns the number of pairs written.
+func writeCSV(writer *col.SimpleWriter, in io.Reader) (int, error) {
+	records := csv.NewReader(in)
+	records.FieldsPerRecord = 2
+	records.Comment = '#'
+	records.TrimLeadingSpace = true
+	records.ReuseRecord = true
+
+	ids := make([]uint64, 0, inputBatchSize)
+	values := make([]int64, 0, inputBatchSize)
+	count := 0
+	for first := true; ; first = false {
+		record, err := records.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return count, err
+		}
+
+		id, err := strconv.ParseUint(strings.TrimSpace(record[0]), 10, 64)
+		if err != nil {
+			if first {
+				continue // Header line
+			}
+			line, _ := records.FieldPos(0)
+			return count, fmt.Errorf("line %d: invalid ID %q", line, record[0])
+		}
+		value, err := strconv.ParseInt(strings.TrimSpace(record[1]), 10, 64)
+		if err != nil {
+			line, _ := records.FieldPos(1)
+			return count, fmt.Errorf("line %d: invalid value %q", line, record[1])
+		}
+		ids = append(ids, id)
+		values = append(values, value)
+
+		if len(ids) == inputBatchSize {
+			if err := writer.Write(ids, values); err != nil {
+				return count, err
+			}
+			count += len(ids)
+			ids, values = ids[:0], values[:0]
+		}
+	}
+
+	if err := writer.Write(ids, values); err != nil {
+		return count, err
+	}
+	return count + len(ids), nil
+}
+
+func runRead(inputFile string, dumpKV, aggregate bool, dump dumpOptions) {
 	// Create a local flag set for help text if needed
 	readCmd := flag.NewFlagSet("read", flag.ExitOnError)
 	_ = readCmd.Bool("dump", false, "Dump all key-value pairs")
@@ -123,34 +323,40 @@ func runRead(inputFile string, dumpKV, aggregate bool) {
 	}
 	defer reader.Close()
 
+	if dumpKV {
+		if _, err := newPairWriter(dump.format, io.Discard); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Only the text format is meant for humans, the others print just the pairs
+	text := !dumpKV || dump.format == "text"
+
 	// Print file information
-	fmt.Printf("File: %s\n", inputFile)
-	fmt.Printf("Version: %d\n", reader.Version())
-	fmt.Printf("Blocks: %d\n\n", reader.BlockCount())
+	if text {
+		fmt.Printf("File: %s\n", inputFile)
+		fmt.Printf("Version: %d\n", reader.Version())
+		fmt.Printf("Blocks: %d\n\n", reader.BlockCount())
+	}
 
 	// Execute requested operations
 	if dumpKV {
-		fmt.Println("ID\tValue")
-		fmt.Println("--\t-----")
-		
-		// For each block
-		for i := uint32(0); i < uint32(reader.BlockCount()); i++ {
-			ids, values, err := reader.GetPairs(uint64(i))
-			if err != nil {
-				fmt.Printf("Error reading pairs from block %d: %v\n", i, err)
-				os.Exit(1)
-			}
-			
-			// Print pairs
-			for j := 0; j < len(ids); j++ {
-				fmt.Printf("%d\t%d\n", ids[j], values[j])
-			}
+		if err := dumpPairs(reader, dump, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error dumping pairs: %v\n", err)
+			os.Exit(1)
+		}
+		if text {
+			fmt.Println()
 		}
-		fmt.Println()
 	}
 
 	if aggregate {
 		result := reader.Aggregate()
+		if err := result.Err(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error aggregating: %v\n", err)
+			os.Exit(1)
+		}
 		fmt.Println("Aggregate Statistics (from metadata only):")
 		fmt.Printf("Count: %d\n", result.Count)
 		fmt.Printf("Min: %d\n", result.Min)
@@ -164,4 +370,158 @@ func runRead(inputFile string, dumpKV, aggregate bool) {
 		fmt.Println("No operation specified. Use --dump to show key-value pairs or --agg to show aggregations.")
 		readCmd.PrintDefaults()
 	}
-}
\ No newline at end of file
+}
+
+func runInspect(inputFile string, showStats bool) {
+	reader, err := col.NewReader(inputFile)
+	if err != nil {
+		fmt.Printf("Error opening file: %v\n", err)
+		os.Exit(1)
+	}
+	defer reader.Close()
+
+	stats, err := reader.FileStats()
+	if err != nil {
+		fmt.Printf("Error reading file statistics: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("File: %s\n", inputFile)
+	fmt.Printf("Version: %d\n", reader.Version())
+	fmt.Printf("Encoding: %d\n", reader.EncodingType())
+	fmt.Printf("Blocks: %d\n", reader.BlockCount())
+	fmt.Printf("Count: %d\n", stats.Count)
+	if stats.Count > 0 {
+		fmt.Printf("IDs: %d - %d\n", stats.MinID, stats.MaxID)
+		fmt.Printf("Values: %d - %d\n", stats.MinValue, stats.MaxValue)
+		fmt.Printf("Sum: %d\n", stats.Sum)
+	}
+
+	if showStats {
+		printEfficiency(reader)
+	}
+}
+
+// printEfficiency prints the size of every block against the raw size of 16
+// bytes per pair, followed by the overhead of the file
+func printEfficiency(reader *col.Reader) {
+	report, err := reader.EfficiencyReport()
+	if err != nil {
+		fmt.Printf("Error computing storage efficiency: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println()
+	fmt.Println("Block\tCount\tEncoding\tRaw\tEncoded\tRatio\tPadding")
+	for _, block := range report.Blocks {
+		fmt.Printf("%d\t%d\t%d\t%d\t%d\t%.3f\t%d\n", block.Block, block.Count, block.Encoding,
+			block.RawSize, block.DataSize, block.Ratio(), block.PaddingSize)
+	}
+
+	percent := func(size uint64) float64 {
+		if report.FileSize == 0 {
+			return 0
+		}
+		return 100 * float64(size) / float64(report.FileSize)
+	}
+
+	fmt.Println()
+	fmt.Printf("File size: %d bytes\n", report.FileSize)
+	fmt.Printf("Raw size: %d bytes (%d pairs at 16 bytes)\n", report.RawSize, report.Count)
+	fmt.Printf("Encoded data: %d bytes (%.1f%%), %.3f of raw\n",
+		report.DataSize, percent(report.DataSize), report.DataRatio())
+	fmt.Printf("Block headers: %d bytes (%.1f%%)\n", report.BlockHeaderSize, percent(report.BlockHeaderSize))
+	fmt.Printf("Padding: %d bytes (%.1f%%)\n", report.PaddingSize, percent(report.PaddingSize))
+	fmt.Printf("File header: %d bytes (%.1f%%)\n", report.FileHeaderSize, percent(report.FileHeaderSize))
+	fmt.Printf("ID bitmap: %d bytes (%.1f%%)\n", report.BitmapSize, percent(report.BitmapSize))
+	fmt.Printf("Footer: %d bytes (%.1f%%)\n", report.FooterSize, percent(report.FooterSize))
+	fmt.Printf("Write amplification: %.3f\n", report.Amplification())
+}
+
+func runVerify(inputFile string) {
+	reader, err := col.NewReader(inputFile)
+	if err != nil {
+		fmt.Printf("Error opening file: %v\n", err)
+		os.Exit(1)
+	}
+	defer reader.Close()
+
+	report, err := reader.ValidateFooterAgainstBlocks()
+	if err != nil {
+		fmt.Printf("Error validating file: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("File: %s\n", inputFile)
+	fmt.Printf("Blocks checked: %d\n", report.BlocksChecked)
+	for _, mismatch := range report.Mismatches {
+		fmt.Printf("Mismatch: %s\n", mismatch)
+	}
+	if len(report.FileStatsFields) > 0 {
+		fmt.Printf("Mismatch: file statistics: %v differ\n", report.FileStatsFields)
+	}
+	if !report.OK() {
+		os.Exit(1)
+	}
+	fmt.Println("OK")
+}
+
+func runRepair(inputFile, outputFile string) {
+	report, err := col.RebuildFooter(inputFile, outputFile)
+	if err != nil {
+		fmt.Printf("Error repairing file: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Repaired %s into %s\n", inputFile, outputFile)
+	fmt.Printf("Blocks recovered: %d\n", report.Blocks)
+	fmt.Printf("Pairs recovered: %d\n", report.Count)
+	fmt.Printf("Bytes discarded: %d (from offset %d)\n", report.Discarded, report.DataEnd)
+}
+
+// catalogFile is a file in the manifest printed by the catalog command. Files
+// that cannot be read are listed with the error instead of their description.
+type catalogFile struct {
+	Path string `json:"path"`
+	*col.CatalogEntry
+	Error string `json:"error,omitempty"`
+}
+
+func runCatalog(dir string) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.col"))
+	if err != nil {
+		fmt.Printf("Error listing files: %v\n", err)
+		os.Exit(1)
+	}
+
+	manifest := struct {
+		Files []catalogFile `json:"files"`
+	}{Files: make([]catalogFile, 0, len(paths))}
+	for _, path := range paths {
+		file := catalogFile{Path: path}
+		entry, err := catalogEntry(path)
+		if err != nil {
+			file.Error = err.Error()
+		} else {
+			file.CatalogEntry = &entry
+		}
+		manifest.Files = append(manifest.Files, file)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		fmt.Printf("Error writing manifest: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// catalogEntry returns the catalog description of the file at path
+func catalogEntry(path string) (col.CatalogEntry, error) {
+	reader, err := col.NewReader(path)
+	if err != nil {
+		return col.CatalogEntry{}, err
+	}
+	defer reader.Close()
+	return reader.CatalogEntry()
+}
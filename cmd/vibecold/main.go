@@ -0,0 +1,372 @@
+// Command vibecold serves queries against a directory of column files over HTTP.
+//
+// Endpoints:
+//
+//	GET  /files                                  list the .col files in the directory
+//	POST /aggregate?file=NAME[&deny=true]        aggregate a file; an optional request body
+//	                                             holds a roaring-serialized ID filter; fails
+//	                                             if any block cannot be read
+//	GET  /scan?file=NAME[&block=N][&limit=N]     return ID-value pairs of one or all blocks,
+//	                                             at most 10000 unless limit is set (max 1000000)
+//	GET  /get?file=NAME&id=ID                    return the value stored for a single ID
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"vibe-lsm/pkg/col"
+
+	"github.com/weaviate/sroar"
+)
+
+const (
+	// maxFilterSize limits the size of uploaded filter bitmaps (64MB)
+	maxFilterSize = 64 << 20
+
+	// defaultScanLimit is the number of pairs returned by a scan without a limit
+	defaultScanLimit = 10000
+
+	// maxScanLimit is the largest limit accepted by a scan, which bounds the
+	// size of a response
+	maxScanLimit = 1000000
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "Address to listen on")
+	dir := flag.String("dir", ".", "Directory containing .col files")
+	flag.Parse()
+
+	info, err := os.Stat(*dir)
+	if err != nil || !info.IsDir() {
+		fmt.Printf("Error: %q is not a directory\n", *dir)
+		os.Exit(1)
+	}
+
+	srv := &server{dir: *dir}
+	log.Printf("Serving column files from %s on %s", *dir, *addr)
+	if err := http.ListenAndServe(*addr, srv.routes()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// server answers queries for the column files in a single directory
+type server struct {
+	dir string
+}
+
+// routes returns the HTTP handler for all endpoints
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/files", s.handleFiles)
+	mux.HandleFunc("/aggregate", s.handleAggregate)
+	mux.HandleFunc("/scan", s.handleScan)
+	mux.HandleFunc("/get", s.handleGet)
+	return mux
+}
+
+// aggregateResponse is the JSON representation of an aggregation result
+type aggregateResponse struct {
+	Count uint64  `json:"count"`
+	Min   int64   `json:"min"`
+	Max   int64   `json:"max"`
+	Sum   int64   `json:"sum"`
+	Avg   float64 `json:"avg"`
+}
+
+// scanResponse is the JSON representation of a list of ID-value pairs
+type scanResponse struct {
+	IDs       []uint64 `json:"ids"`
+	Values    []int64  `json:"values"`
+	Truncated bool     `json:"truncated,omitempty"` // Whether the limit cut off further pairs
+}
+
+// getResponse is the JSON representation of a single ID lookup
+type getResponse struct {
+	ID    uint64 `json:"id"`
+	Value int64  `json:"value"`
+	Found bool   `json:"found"`
+}
+
+// handleFiles lists the column files that can be queried
+func (s *server) handleFiles(w http.ResponseWriter, r *http.Request) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to list directory: %w", err))
+		return
+	}
+
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".col") {
+			files = append(files, entry.Name())
+		}
+	}
+	sort.Strings(files)
+
+	writeJSON(w, files)
+}
+
+// handleAggregate aggregates a file, optionally restricted by an uploaded filter bitmap
+func (s *server) handleAggregate(w http.ResponseWriter, r *http.Request) {
+	reader, ok := s.openReader(w, r)
+	if !ok {
+		return
+	}
+	defer reader.Close()
+
+	opts := col.DefaultAggregateOptions()
+	query := r.URL.Query()
+
+	if query.Get("skip_cache") == "true" {
+		opts.SkipPreCalculated = true
+	}
+	if p := query.Get("parallel"); p != "" {
+		parallel, err := strconv.Atoi(p)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid parallel value %q: %w", p, err))
+			return
+		}
+		opts.Parallel = parallel
+	}
+
+	// An optional request body carries a roaring bitmap of IDs
+	if r.Body != nil {
+		payload, err := io.ReadAll(io.LimitReader(r.Body, maxFilterSize+1))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("failed to read filter: %w", err))
+			return
+		}
+		if len(payload) > maxFilterSize {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("filter exceeds %d bytes", maxFilterSize))
+			return
+		}
+		if len(payload) > 0 {
+			filter, err := decodeBitmap(payload)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+			if query.Get("deny") == "true" {
+				opts.DenyFilter = filter
+			} else {
+				opts.Filter = filter
+			}
+		}
+	}
+
+	result, err := aggregate(reader, opts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	// A partial result would be served as if it were complete
+	if err := result.Err(); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to aggregate: %w", err))
+		return
+	}
+	writeJSON(w, aggregateResponse{
+		Count: result.Count,
+		Min:   result.Min,
+		Max:   result.Max,
+		Sum:   result.Sum,
+		Avg:   result.Avg,
+	})
+}
+
+// handleScan returns the pairs of a single block or of the whole file
+func (s *server) handleScan(w http.ResponseWriter, r *http.Request) {
+	reader, ok := s.openReader(w, r)
+	if !ok {
+		return
+	}
+	defer reader.Close()
+
+	query := r.URL.Query()
+
+	// Determine which blocks to scan
+	startBlock, endBlock := uint64(0), reader.BlockCount()
+	if b := query.Get("block"); b != "" {
+		block, err := strconv.ParseUint(b, 10, 64)
+		if err != nil || block >= reader.BlockCount() {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid block %q", b))
+			return
+		}
+		startBlock, endBlock = block, block+1
+	}
+
+	limit := defaultScanLimit
+	if l := query.Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 0 || parsed > maxScanLimit {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit %q, must be between 0 and %d", l, maxScanLimit))
+			return
+		}
+		limit = parsed
+	}
+
+	resp := scanResponse{IDs: []uint64{}, Values: []int64{}}
+	for i := startBlock; i < endBlock; i++ {
+		if len(resp.IDs) >= limit {
+			resp.Truncated = true
+			break
+		}
+
+		ids, values, err := reader.ReadBlock(col.BlockID(i))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to read block %d: %w", i, err))
+			return
+		}
+
+		if len(resp.IDs)+len(ids) > limit {
+			remaining := limit - len(resp.IDs)
+			ids, values = ids[:remaining], values[:remaining]
+			resp.Truncated = true
+		}
+		resp.IDs = append(resp.IDs, ids...)
+		resp.Values = append(resp.Values, values...)
+	}
+
+	writeJSON(w, resp)
+}
+
+// handleGet looks up the value for a single ID
+func (s *server) handleGet(w http.ResponseWriter, r *http.Request) {
+	idStr := r.URL.Query().Get("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid id %q", idStr))
+		return
+	}
+
+	reader, ok := s.openReader(w, r)
+	if !ok {
+		return
+	}
+	defer reader.Close()
+
+	// Use the global ID bitmap to avoid scanning files that don't contain the ID
+	globalIDs, err := reader.GetGlobalIDBitmap()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !globalIDs.Contains(id) {
+		writeJSON(w, getResponse{ID: id})
+		return
+	}
+
+	for i := uint64(0); i < reader.BlockCount(); i++ {
+		ids, values, err := reader.ReadBlock(col.BlockID(i))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to read block %d: %w", i, err))
+			return
+		}
+		for j := range ids {
+			if ids[j] == id {
+				writeJSON(w, getResponse{ID: id, Value: values[j], Found: true})
+				return
+			}
+		}
+	}
+
+	writeJSON(w, getResponse{ID: id})
+}
+
+// openReader opens the file named by the "file" query parameter.
+// It writes an error response and returns false if the file cannot be opened.
+func (s *server) openReader(w http.ResponseWriter, r *http.Request) (*col.Reader, bool) {
+	name := r.URL.Query().Get("file")
+
+	// Only allow plain file names so that clients can't escape the directory
+	if name == "" || name != filepath.Base(name) || !strings.HasSuffix(name, ".col") {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid file %q", name))
+		return nil, false
+	}
+
+	path := filepath.Join(s.dir, name)
+	if _, err := os.Stat(path); err != nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("file %q not found", name))
+		return nil, false
+	}
+
+	reader, err := col.NewReader(path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to open %q: %w", name, err))
+		return nil, false
+	}
+	return reader, true
+}
+
+// decodeBitmap deserializes a roaring bitmap, rejecting malformed payloads
+func decodeBitmap(payload []byte) (bitmap *sroar.Bitmap, err error) {
+	if len(payload)%2 != 0 {
+		return nil, fmt.Errorf("invalid filter: payload length %d is not a multiple of 2", len(payload))
+	}
+
+	// sroar panics on malformed buffers, so convert that into an error
+	defer func() {
+		if r := recover(); r != nil {
+			bitmap = nil
+			err = fmt.Errorf("invalid filter: %v", r)
+		}
+	}()
+
+	bitmap = sroar.FromBufferWithCopy(payload)
+
+	// Visit every value to validate all containers, not only their headers.
+	// Lookups rely on the values being strictly increasing.
+	cardinality := bitmap.GetCardinality()
+	it := bitmap.NewIterator()
+	var prev uint64
+	for i := 0; i < cardinality; i++ {
+		v := it.Next()
+		if i > 0 && v <= prev {
+			return nil, fmt.Errorf("invalid filter: values are not strictly increasing")
+		}
+		prev = v
+	}
+	return bitmap, nil
+}
+
+// aggregate aggregates a file like Reader.AggregateWithOptions. Filters that
+// pass decodeBitmap may still be inconsistent in ways only operations on them
+// detect, and sroar panics on those, so panics are converted into errors.
+// Filtered aggregations run sequentially, since panics of parallel workers
+// cannot be recovered here.
+func aggregate(reader *col.Reader, opts col.AggregateOptions) (result col.AggregateResult, err error) {
+	if opts.Filter != nil || opts.DenyFilter != nil {
+		opts.Parallel = 0
+		opts.AutoParallel = false
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid filter: %v", r)
+		}
+	}()
+	return reader.AggregateWithOptions(opts), nil
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("failed to write response: %v", err)
+	}
+}
+
+// writeError writes err as a JSON error response with the given status code
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
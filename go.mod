@@ -2,11 +2,14 @@ module vibe-lsm
 
 go 1.22.5
 
+require (
+	github.com/stretchr/testify v1.10.0
+	github.com/weaviate/sroar v0.0.9
+)
+
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/stretchr/testify v1.10.0 // indirect
-	github.com/weaviate/sroar v0.0.9 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,58 @@
+package col
+
+// Advice describes how the data of a file is going to be read. It is passed to
+// the OS as a hint for managing the page cache, see Reader.Advise.
+type Advice int
+
+const (
+	// AdviceNormal restores the default behavior of the OS
+	AdviceNormal Advice = iota
+	// AdviceSequential announces reading the file from start to end, e.g. in
+	// a scan, so the OS reads ahead more aggressively
+	AdviceSequential
+	// AdviceRandom announces reading isolated blocks, so the OS does not read
+	// ahead pages that are not going to be used
+	AdviceRandom
+	// AdviceDontNeed announces that the data is not going to be read again
+	// soon, so the OS can drop the cached pages of the file
+	AdviceDontNeed
+)
+
+// Advise passes an access pattern for the whole file to the OS, so large reads
+// do not evict more useful pages from the page cache. It is a hint that never
+// changes results. Advise does nothing for in-memory readers and on platforms
+// without posix_fadvise.
+func (r *Reader) Advise(advice Advice) error {
+	return fadvise(r.file, advice)
+}
+
+// EnablePageCacheAdvice makes scans and filtered aggregations pass their
+// access pattern to the OS for the duration of the operation, see Advise
+func (r *Reader) EnablePageCacheAdvice() {
+	r.pageCacheAdvice = true
+}
+
+// DisablePageCacheAdvice stops passing access patterns to the OS, which is the
+// default
+func (r *Reader) DisablePageCacheAdvice() {
+	r.pageCacheAdvice = false
+}
+
+// adviseFor passes the access pattern of an operation to the OS if page cache
+// advice is enabled. The returned function restores the normal access pattern
+// and must be called when the operation finishes.
+func (r *Reader) adviseFor(advice Advice) func() {
+	if !r.pageCacheAdvice {
+		return func() {}
+	}
+	r.Advise(advice)
+	return func() { r.Advise(AdviceNormal) }
+}
+
+// closeInput closes a reader that was read completely as the input of an
+// operation like Concat. The pages of the file are dropped from the page
+// cache, as the output supersedes it.
+func (r *Reader) closeInput() error {
+	r.Advise(AdviceDontNeed)
+	return r.Close()
+}
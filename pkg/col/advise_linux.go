@@ -0,0 +1,54 @@
+//go:build linux && (amd64 || arm64)
+
+package col
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// Advice values of posix_fadvise
+const (
+	fadvNormal     = 0
+	fadvRandom     = 1
+	fadvSequential = 2
+	fadvDontNeed   = 4
+)
+
+// fadvise passes advice for the whole file to posix_fadvise if file is an OS file
+func fadvise(file readerFile, advice Advice) error {
+	var fadv uintptr
+	switch advice {
+	case AdviceNormal:
+		fadv = fadvNormal
+	case AdviceSequential:
+		fadv = fadvSequential
+	case AdviceRandom:
+		fadv = fadvRandom
+	case AdviceDontNeed:
+		fadv = fadvDontNeed
+	default:
+		return fmt.Errorf("unknown advice: %d", advice)
+	}
+
+	f, ok := file.(*os.File)
+	if !ok {
+		return nil
+	}
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return fmt.Errorf("failed to access file descriptor: %w", err)
+	}
+	var errno syscall.Errno
+	if err := conn.Control(func(fd uintptr) {
+		// An offset and length of 0 cover the whole file
+		_, _, errno = syscall.Syscall6(syscall.SYS_FADVISE64, fd, 0, 0, fadv, 0, 0)
+	}); err != nil {
+		return fmt.Errorf("failed to access file descriptor: %w", err)
+	}
+	if errno != 0 {
+		return fmt.Errorf("posix_fadvise failed: %w", errno)
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux || !(amd64 || arm64)
+
+package col
+
+import "fmt"
+
+// fadvise validates advice; the platform has no posix_fadvise to pass it to
+func fadvise(file readerFile, advice Advice) error {
+	if advice < AdviceNormal || advice > AdviceDontNeed {
+		return fmt.Errorf("unknown advice: %d", advice)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package col
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaviate/sroar"
+)
+
+func TestPageCacheAdvice(t *testing.T) {
+	ids := []uint64{1, 2, 3, 10, 11, 12}
+	values := []int64{5, -1, 7, 3, 9, 0}
+
+	writeAll := func(t *testing.T, w *Writer) {
+		require.NoError(t, w.WriteBlock(ids[:3], values[:3]))
+		require.NoError(t, w.WriteBlock(ids[3:], values[3:]))
+		require.NoError(t, w.FinalizeAndClose())
+	}
+
+	path := filepath.Join(t.TempDir(), "advice.col")
+	writer, err := NewWriter(path)
+	require.NoError(t, err)
+	writeAll(t, writer)
+
+	var buf bytes.Buffer
+	bufWriter, err := NewWriterToBuffer(&buf)
+	require.NoError(t, err)
+	writeAll(t, bufWriter)
+
+	fileReader, err := NewReader(path)
+	require.NoError(t, err)
+	defer fileReader.Close()
+	memReader, err := NewReaderFromBytes(buf.Bytes())
+	require.NoError(t, err)
+	defer memReader.Close()
+
+	for name, reader := range map[string]*Reader{"file": fileReader, "memory": memReader} {
+		t.Run(name, func(t *testing.T) {
+			for _, advice := range []Advice{AdviceSequential, AdviceRandom, AdviceDontNeed, AdviceNormal} {
+				assert.NoError(t, reader.Advise(advice))
+			}
+			assert.Error(t, reader.Advise(Advice(42)))
+
+			// Advice never changes results
+			reader.EnablePageCacheAdvice()
+			defer reader.DisablePageCacheAdvice()
+
+			var scanned []uint64
+			require.NoError(t, reader.ScanAll(2, func(_ BlockID, blockIDs []uint64, _ []int64) error {
+				scanned = append(scanned, blockIDs...)
+				return nil
+			}))
+			assert.Equal(t, ids, scanned)
+
+			scanner := reader.ScanBlocks(0)
+			blocks := 0
+			for scanner.Next() {
+				blocks++
+			}
+			require.NoError(t, scanner.Err())
+			require.NoError(t, scanner.Close())
+			assert.Equal(t, 2, blocks)
+
+			result := reader.AggregateWithOptions(AggregateOptions{Filter: sroar.FromSortedList([]uint64{2, 11})})
+			assert.Equal(t, AggregateResult{Count: 2, Min: -1, Max: 9, Sum: 8, Avg: 4, Source: SourceFiltered, BlocksScanned: 2}, result)
+
+			var stream bytes.Buffer
+			require.NoError(t, reader.StreamBlocks(&stream, 0))
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package col
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaviate/sroar"
+)
+
+func TestAggregateBlockRestriction(t *testing.T) {
+	// Block i holds IDs i*10+1 to i*10+3 with values i*100+1 to i*100+3
+	var buf bytes.Buffer
+	writer, err := NewWriterToBuffer(&buf)
+	require.NoError(t, err)
+	for b := uint64(0); b < 4; b++ {
+		require.NoError(t, writer.WriteBlock(
+			[]uint64{b*10 + 1, b*10 + 2, b*10 + 3},
+			[]int64{int64(b*100 + 1), int64(b*100 + 2), int64(b*100 + 3)}))
+	}
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := NewReaderFromBytes(buf.Bytes())
+	require.NoError(t, err)
+	defer reader.Close()
+
+	// Blocks 1 and 3, and an index out of range
+	blocks := []uint64{3, 1, 7}
+	expected := AggregateResult{Count: 6, Min: 101, Max: 303, Sum: 101 + 102 + 103 + 301 + 302 + 303}
+	expected.Avg = float64(expected.Sum) / 6
+
+	for name, opts := range map[string]AggregateOptions{
+		"Footer":           {Blocks: blocks},
+		"Decoded":          {Blocks: blocks, SkipPreCalculated: true},
+		"Parallel":         {Blocks: blocks, Parallel: 2},
+		"Parallel decoded": {Blocks: blocks, Parallel: 2, SkipPreCalculated: true},
+	} {
+		expected.Source, expected.BlocksScanned = SourceMetadata, 0
+		if opts.SkipPreCalculated {
+			expected.Source, expected.BlocksScanned = SourceFullScan, 2
+		}
+		assert.Equal(t, expected, reader.AggregateWithOptions(opts), name)
+	}
+
+	t.Run("Combined with filters", func(t *testing.T) {
+		filter := sroar.NewBitmap()
+		filter.SetMany([]uint64{2, 12, 13, 32})
+		result := reader.AggregateWithOptions(AggregateOptions{Blocks: blocks, Filter: filter})
+		assert.Equal(t, uint64(3), result.Count)
+		assert.Equal(t, int64(102+103+302), result.Sum)
+
+		deny := sroar.NewBitmap()
+		deny.Set(11)
+		result = reader.AggregateWithOptions(AggregateOptions{Blocks: []uint64{1}, DenyFilter: deny})
+		assert.Equal(t, uint64(2), result.Count)
+		assert.Equal(t, int64(102+103), result.Sum)
+	})
+
+	t.Run("Empty block list", func(t *testing.T) {
+		assert.Equal(t, AggregateResult{}, reader.AggregateWithOptions(AggregateOptions{Blocks: []uint64{}}))
+		assert.Equal(t, uint64(12), reader.AggregateWithOptions(AggregateOptions{}).Count)
+	})
+}
@@ -0,0 +1,108 @@
+package col
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrorPolicy decides how an aggregation handles blocks that cannot be read
+type ErrorPolicy int
+
+const (
+	// SkipAndReport aggregates the readable blocks and lists the others in
+	// AggregateResult.SkippedBlocks
+	SkipAndReport ErrorPolicy = iota
+	// FailFast stops at the first unreadable block. The result then holds
+	// no values, only the error in AggregateResult.SkippedBlocks.
+	FailFast
+)
+
+// BlockError is the error of a block that could not be read
+type BlockError struct {
+	Block BlockID
+	Err   error
+}
+
+func (e BlockError) Error() string {
+	return fmt.Sprintf("block %d: %v", e.Block, e.Err)
+}
+
+func (e BlockError) Unwrap() error {
+	return e.Err
+}
+
+// BlockErrors lists the blocks an aggregation could not read
+type BlockErrors []BlockError
+
+func (e BlockErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	return fmt.Sprintf("%d blocks could not be read, first %v", len(e), e[0])
+}
+
+// Unwrap returns the errors of the blocks
+func (e BlockErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, blockErr := range e {
+		errs[i] = blockErr
+	}
+	return errs
+}
+
+// mergeBlockErrors combines the skipped blocks of two results
+func mergeBlockErrors(a, b *BlockErrors) *BlockErrors {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	merged := append(append(BlockErrors(nil), *a...), *b...)
+	return &merged
+}
+
+// aggregateErrors collects the errors of unreadable blocks during an
+// aggregation. It is safe for concurrent use by the workers of a parallel
+// aggregation.
+type aggregateErrors struct {
+	policy  ErrorPolicy
+	mu      sync.Mutex
+	skipped BlockErrors
+	failed  atomic.Bool // Set once a block failed under FailFast
+}
+
+// add records the error of block and reports whether the aggregation stops
+func (e *aggregateErrors) add(block BlockID, err error) bool {
+	e.mu.Lock()
+	e.skipped = append(e.skipped, BlockError{Block: block, Err: err})
+	e.mu.Unlock()
+
+	if e.policy == FailFast {
+		e.failed.Store(true)
+		return true
+	}
+	return false
+}
+
+// stopped reports whether a block failed under FailFast, so the remaining
+// blocks are not read
+func (e *aggregateErrors) stopped() bool {
+	return e.failed.Load()
+}
+
+// apply adds the skipped blocks in block order to result. Under FailFast, the
+// values of the blocks read before the failure are dropped.
+func (e *aggregateErrors) apply(result AggregateResult) AggregateResult {
+	if len(e.skipped) == 0 {
+		return result
+	}
+	if e.failed.Load() {
+		result = AggregateResult{}
+	}
+	sort.Slice(e.skipped, func(a, b int) bool { return e.skipped[a].Block < e.skipped[b].Block })
+	result.SkippedBlocks = mergeBlockErrors(result.SkippedBlocks, &e.skipped)
+	return result
+}
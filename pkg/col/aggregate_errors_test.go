@@ -0,0 +1,114 @@
+package col
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaviate/sroar"
+)
+
+func TestAggregateErrorPolicy(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewWriterToBuffer(&buf)
+	require.NoError(t, err)
+	for b := uint64(0); b < 4; b++ {
+		require.NoError(t, writer.WriteBlock([]uint64{b*10 + 1, b*10 + 2}, []int64{1, 2}))
+	}
+	require.NoError(t, writer.FinalizeAndClose())
+
+	// Blocks 1 and 3 get an ID section size of 0, so they cannot be decoded
+	data := buf.Bytes()
+	reader, err := NewReaderFromBytes(data)
+	require.NoError(t, err)
+	for _, block := range []int{1, 3} {
+		offset := reader.blockIndex[block].BlockOffset + blockHeaderSize + 4
+		binary.LittleEndian.PutUint32(data[offset:], 0)
+	}
+	reader, err = NewReaderFromBytes(data)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	filter := sroar.NewBitmap()
+	filter.SetMany([]uint64{1, 11, 21, 31})
+	for name, tc := range map[string]struct {
+		opts  AggregateOptions
+		count uint64 // Count of the readable blocks 0 and 2
+	}{
+		"Sequential": {AggregateOptions{SkipPreCalculated: true}, 4},
+		"Filtered":   {AggregateOptions{Filter: filter}, 2},
+		"Parallel":   {AggregateOptions{SkipPreCalculated: true, Parallel: 2}, 4},
+	} {
+		t.Run(name, func(t *testing.T) {
+			opts := tc.opts
+			result := reader.AggregateWithOptions(opts)
+			assert.Equal(t, tc.count, result.Count)
+			require.NotNil(t, result.SkippedBlocks)
+			skipped := *result.SkippedBlocks
+			require.Len(t, skipped, 2)
+			assert.Equal(t, BlockID(1), skipped[0].Block)
+			assert.Equal(t, BlockID(3), skipped[1].Block)
+			assert.Error(t, result.Err())
+
+			var blockErr BlockError
+			assert.True(t, errors.As(result.Err(), &blockErr))
+
+			opts.OnError = FailFast
+			result = reader.AggregateWithOptions(opts)
+			assert.Equal(t, uint64(0), result.Count)
+			require.NotNil(t, result.SkippedBlocks)
+			assert.Error(t, result.Err())
+		})
+	}
+
+	t.Run("Footer statistics", func(t *testing.T) {
+		result := reader.Aggregate()
+		assert.Equal(t, uint64(8), result.Count)
+		assert.NoError(t, result.Err())
+	})
+
+	t.Run("Merged results keep skipped blocks", func(t *testing.T) {
+		partial := reader.AggregateWithOptions(AggregateOptions{SkipPreCalculated: true})
+		merged := MergeAggregates(partial, AggregateResult{Count: 1, Min: 5, Max: 5, Sum: 5, Avg: 5}, partial)
+		assert.Equal(t, uint64(2*partial.Count+1), merged.Count)
+		assert.Len(t, *merged.SkippedBlocks, 4)
+	})
+}
+
+func TestAggregateFooterError(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-aggregate-footer-error")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	var buf bytes.Buffer
+	writer, err := NewWriterToBuffer(&buf)
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteBlock([]uint64{1, 2}, []int64{10, 20}))
+	require.NoError(t, writer.FinalizeAndClose())
+
+	// Flip the last byte of the footer, which is part of the footer checksum
+	data := buf.Bytes()
+	data[len(data)-footerMetaSize-1] ^= 0xFF
+	filePath := filepath.Join(tempDir, "corrupt-footer.col")
+	require.NoError(t, os.WriteFile(filePath, data, 0644))
+
+	reader, err := NewReaderLazy(filePath)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	for _, policy := range []ErrorPolicy{SkipAndReport, FailFast} {
+		result := reader.AggregateWithOptions(AggregateOptions{OnError: policy})
+		assert.False(t, result.Valid())
+		assert.Nil(t, result.SkippedBlocks)
+		assert.ErrorIs(t, result.Err(), ErrCorruptFooter)
+	}
+
+	merged := MergeAggregates(reader.Aggregate(), AggregateResult{Count: 1, Min: 5, Max: 5, Sum: 5, Avg: 5})
+	assert.Equal(t, uint64(1), merged.Count)
+	assert.ErrorIs(t, merged.Err(), ErrCorruptFooter)
+}
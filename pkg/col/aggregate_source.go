@@ -0,0 +1,48 @@
+package col
+
+// AggregateSource describes how an aggregation was answered. Sources are
+// ordered by the work they take, so the merged result of several
+// aggregations has the source of the most expensive one.
+type AggregateSource int
+
+const (
+	// SourceNone is the source of results without values
+	SourceNone AggregateSource = iota
+	// SourceMetadata results were computed from the footer statistics
+	// without reading any block. Filtered aggregations have this source if
+	// the filters pass every ID of the aggregated blocks.
+	SourceMetadata
+	// SourceFullScan results were computed by decoding all values of the
+	// aggregated blocks
+	SourceFullScan
+	// SourceFiltered results were computed by decoding aggregated blocks and
+	// filtering their pairs by ID
+	SourceFiltered
+)
+
+// String returns the name of the source
+func (s AggregateSource) String() string {
+	switch s {
+	case SourceNone:
+		return "none"
+	case SourceMetadata:
+		return "metadata"
+	case SourceFullScan:
+		return "full scan"
+	case SourceFiltered:
+		return "filtered"
+	default:
+		return "unknown"
+	}
+}
+
+// withSource returns result with the source and the number of blocks read.
+// Results without values remain the zero AggregateResult.
+func (r AggregateResult) withSource(source AggregateSource, blocksScanned uint64) AggregateResult {
+	if r.Count == 0 {
+		return r
+	}
+	r.Source = source
+	r.BlocksScanned = blocksScanned
+	return r
+}
@@ -0,0 +1,192 @@
+package col
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaviate/sroar"
+)
+
+// aggregateValues returns result without the fields describing how it was
+// computed, to compare results of different aggregation paths
+func aggregateValues(result AggregateResult) AggregateResult {
+	result.Source, result.BlocksScanned = SourceNone, 0
+	return result
+}
+
+func TestAggregateSource(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewWriterToBuffer(&buf)
+	require.NoError(t, err)
+	for b := uint64(0); b < 4; b++ {
+		require.NoError(t, writer.WriteBlock([]uint64{b*10 + 1, b*10 + 2}, []int64{1, 2}))
+	}
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := NewReaderFromBytes(buf.Bytes())
+	require.NoError(t, err)
+	defer reader.Close()
+
+	filter := sroar.NewBitmap()
+	filter.SetMany([]uint64{1, 12})
+	for name, tc := range map[string]struct {
+		opts    AggregateOptions
+		source  AggregateSource
+		scanned uint64
+	}{
+		"File statistics":   {AggregateOptions{}, SourceMetadata, 0},
+		"Block statistics":  {AggregateOptions{Blocks: []uint64{0, 1}}, SourceMetadata, 0},
+		"Parallel footer":   {AggregateOptions{Parallel: 2}, SourceMetadata, 0},
+		"Full scan":         {AggregateOptions{SkipPreCalculated: true}, SourceFullScan, 4},
+		"Parallel scan":     {AggregateOptions{SkipPreCalculated: true, Parallel: 2}, SourceFullScan, 4},
+		"Filtered":          {AggregateOptions{Filter: filter}, SourceFiltered, 2},
+		"Parallel filtered": {AggregateOptions{Filter: filter, Parallel: 2}, SourceFiltered, 2},
+	} {
+		result := reader.AggregateWithOptions(tc.opts)
+		assert.Equal(t, tc.source, result.Source, name)
+		assert.Equal(t, tc.scanned, result.BlocksScanned, name)
+	}
+
+	// Results without values remain the zero result
+	empty := sroar.NewBitmap()
+	empty.Set(5)
+	assert.Equal(t, AggregateResult{}, reader.AggregateWithOptions(AggregateOptions{Filter: empty}))
+
+	t.Run("Merged sources", func(t *testing.T) {
+		metadata := reader.Aggregate()
+		scanned := reader.AggregateWithOptions(AggregateOptions{SkipPreCalculated: true})
+		merged := MergeAggregates(metadata, scanned)
+		assert.Equal(t, SourceFullScan, merged.Source)
+		assert.Equal(t, uint64(4), merged.BlocksScanned)
+		assert.Equal(t, "full scan", merged.Source.String())
+	})
+}
+
+func TestFilteredAggregationCoveredBlocks(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewWriterToBuffer(&buf)
+	require.NoError(t, err)
+
+	// Four blocks with the contiguous ID ranges [0,99], [100,199], ...
+	for b := uint64(0); b < 4; b++ {
+		ids := make([]uint64, 100)
+		values := make([]int64, 100)
+		for i := range ids {
+			ids[i] = b*100 + uint64(i)
+			values[i] = int64(ids[i]) - 150
+		}
+		require.NoError(t, writer.WriteBlock(ids, values))
+	}
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := NewReaderFromBytes(buf.Bytes())
+	require.NoError(t, err)
+	defer reader.Close()
+
+	// Covers blocks 1 and 2, and half of block 3
+	dense := sroar.NewBitmap()
+	for id := uint64(100); id < 350; id++ {
+		dense.Set(id)
+	}
+	// Contains the extremes of block 0, but not the IDs in between
+	gappy := sroar.NewBitmap()
+	gappy.SetMany([]uint64{0, 50, 99})
+	// Denies an ID of block 2
+	deny := sroar.NewBitmap()
+	deny.Set(250)
+	// Covers all blocks
+	all, err := reader.GetGlobalIDBitmap()
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		opts    AggregateOptions
+		source  AggregateSource
+		scanned uint64
+	}{
+		"Dense filter":             {AggregateOptions{Filter: dense}, SourceFiltered, 1},
+		"Parallel dense filter":    {AggregateOptions{Filter: dense, Parallel: 2}, SourceFiltered, 1},
+		"Gaps within the range":    {AggregateOptions{Filter: gappy}, SourceFiltered, 1},
+		"Deny filter in the range": {AggregateOptions{Filter: dense, DenyFilter: deny}, SourceFiltered, 2},
+		"Deny filter only":         {AggregateOptions{DenyFilter: deny}, SourceFiltered, 1},
+		"Covering filter":          {AggregateOptions{Filter: all}, SourceMetadata, 0},
+		"Parallel covering filter": {AggregateOptions{Filter: all, Parallel: 2}, SourceMetadata, 0},
+	} {
+		result := reader.AggregateWithOptions(tc.opts)
+		assert.Equal(t, tc.source, result.Source, name)
+		assert.Equal(t, tc.scanned, result.BlocksScanned, name)
+
+		// Decoding every block gives the same values
+		decodeOpts := tc.opts
+		decodeOpts.SkipPreCalculated = true
+		decoded := reader.AggregateWithOptions(decodeOpts)
+		assert.Equal(t, aggregateValues(decoded), aggregateValues(result), name)
+	}
+}
+
+func TestDenyFilterAggregationCoveredBlocks(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewWriterToBuffer(&buf)
+	require.NoError(t, err)
+
+	// Four blocks of even IDs with the ranges [0,198], [200,398], ...
+	for b := uint64(0); b < 4; b++ {
+		ids := make([]uint64, 100)
+		values := make([]int64, 100)
+		for i := range ids {
+			ids[i] = b*200 + uint64(i)*2
+			values[i] = int64(i) * int64(b+1)
+		}
+		require.NoError(t, writer.WriteBlock(ids, values))
+	}
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := NewReaderFromBytes(buf.Bytes())
+	require.NoError(t, err)
+	defer reader.Close()
+
+	// Odd IDs fall within the ranges of all blocks, but none is stored
+	odd := sroar.NewBitmap()
+	for id := uint64(1); id < 800; id += 2 {
+		odd.Set(id)
+	}
+	// Additionally denies a stored ID of block 1 and one beyond all blocks
+	oddAndStored := odd.Clone()
+	oddAndStored.SetMany([]uint64{202, 5000})
+	// Denies the minimum ID of block 2 and the maximum ID of block 3
+	bounds := sroar.NewBitmap()
+	bounds.SetMany([]uint64{400, 798})
+
+	for name, tc := range map[string]struct {
+		opts    AggregateOptions
+		source  AggregateSource
+		scanned uint64
+	}{
+		"Denied IDs not stored":          {AggregateOptions{DenyFilter: odd}, SourceMetadata, 0},
+		"Parallel denied IDs not stored": {AggregateOptions{DenyFilter: odd, Parallel: 2}, SourceMetadata, 0},
+		"Denied stored ID":               {AggregateOptions{DenyFilter: oddAndStored}, SourceFiltered, 1},
+		"Parallel denied stored ID":      {AggregateOptions{DenyFilter: oddAndStored, Parallel: 2}, SourceFiltered, 1},
+		"Denied block bounds":            {AggregateOptions{DenyFilter: bounds}, SourceFiltered, 2},
+	} {
+		result := reader.AggregateWithOptions(tc.opts)
+		assert.Equal(t, tc.source, result.Source, name)
+		assert.Equal(t, tc.scanned, result.BlocksScanned, name)
+
+		decodeOpts := tc.opts
+		decodeOpts.SkipPreCalculated = true
+		decoded := reader.AggregateWithOptions(decodeOpts)
+		assert.Equal(t, aggregateValues(decoded), aggregateValues(result), name)
+	}
+	assert.Equal(t, uint64(399), reader.AggregateWithOptions(AggregateOptions{DenyFilter: oddAndStored}).Count)
+
+	// Without a persisted bitmap, every block may store a denied ID
+	unindexed, err := NewReaderFromBytes(buf.Bytes())
+	require.NoError(t, err)
+	defer unindexed.Close()
+	unindexed.header.BitmapSize = 0
+	result := unindexed.AggregateWithOptions(AggregateOptions{DenyFilter: odd})
+	assert.Equal(t, SourceFiltered, result.Source)
+	assert.Equal(t, uint64(4), result.BlocksScanned)
+	assert.Equal(t, uint64(400), result.Count)
+}
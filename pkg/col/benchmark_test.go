@@ -313,7 +313,7 @@ func BenchmarkAggregation(b *testing.B) {
 				reader.Close()
 				
 				// Validate aggregation results
-				if result.Count != len(values) {
+				if result.Count != uint64(len(values)) {
 					b.Fatalf("Expected count %d, got %d", len(values), result.Count)
 				}
 				if result.Sum != expectedSum {
@@ -0,0 +1,205 @@
+package col
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+)
+
+// BlockCacheStats describes the usage of the block cache of a Reader
+type BlockCacheStats struct {
+	Capacity int // Maximum number of cached blocks
+	Blocks   int // Number of cached blocks
+	Hits     uint64
+	Misses   uint64
+	Rejected uint64 // Misses that were not admitted to the cache
+}
+
+// blockCache is an LRU cache of decoded blocks
+type blockCache struct {
+	mu       sync.Mutex
+	capacity int
+	lru      *list.List // Front is the most recently used block
+	blocks   map[BlockID]*list.Element
+	hits     uint64
+	misses   uint64
+	rejected uint64
+}
+
+// cachedBlock is a decoded block held by the cache. Its slices are never
+// handed out, only copied, so callers cannot modify them.
+type cachedBlock struct {
+	id     BlockID
+	ids    []uint64
+	values []int64
+}
+
+// EnableBlockCache caches up to capacity decoded blocks, evicting the least
+// recently used block when full. Without access statistics every block read is
+// admitted; with them (see EnableAccessStats), only blocks that were read
+// before are. It must not be called concurrently with reads.
+func (r *Reader) EnableBlockCache(capacity int) {
+	if capacity <= 0 {
+		r.blockCache = nil
+		return
+	}
+	r.blockCache = &blockCache{
+		capacity: capacity,
+		lru:      list.New(),
+		blocks:   make(map[BlockID]*list.Element, capacity),
+	}
+}
+
+// DisableBlockCache drops all cached blocks and stops caching, which is the
+// default. It must not be called concurrently with reads.
+func (r *Reader) DisableBlockCache() {
+	r.blockCache = nil
+}
+
+// BlockCacheStats returns the usage of the block cache. It is zero if the
+// cache is disabled.
+func (r *Reader) BlockCacheStats() BlockCacheStats {
+	c := r.blockCache
+	if c == nil {
+		return BlockCacheStats{}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return BlockCacheStats{
+		Capacity: c.capacity,
+		Blocks:   c.lru.Len(),
+		Hits:     c.hits,
+		Misses:   c.misses,
+		Rejected: c.rejected,
+	}
+}
+
+// Prewarm reads the given blocks ahead of traffic, so the first queries after
+// opening the file do not pay for cold reads. If the block cache is enabled,
+// the blocks are decoded into it regardless of access statistics, up to its
+// capacity; otherwise they are only read, which brings them into the page
+// cache of the OS. Prewarming neither counts as a read in the access
+// statistics nor in the cache statistics.
+func (r *Reader) Prewarm(blocks []BlockID) error {
+	for _, id := range blocks {
+		if err := r.prewarmBlock(id); err != nil {
+			return fmt.Errorf("failed to prewarm block %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// PrewarmAll prewarms every block of the file, see Prewarm. With a block cache
+// smaller than the file, the last blocks of the file remain cached.
+func (r *Reader) PrewarmAll() error {
+	if err := r.ensureFooter(); err != nil {
+		return err
+	}
+	blocks := make([]BlockID, len(r.blockIndex))
+	for i := range blocks {
+		blocks[i] = BlockID(i)
+	}
+	return r.Prewarm(blocks)
+}
+
+// prewarmBlock reads a block and adds it to the block cache if enabled
+func (r *Reader) prewarmBlock(id BlockID) error {
+	cache := r.blockCache
+	if cache != nil && cache.touch(id) {
+		return nil
+	}
+
+	sections, release, err := r.readBlockSections(id)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if cache == nil {
+		return nil
+	}
+
+	ids, values, err := decodeBlockDataInto(sections.idBytes, sections.valueBytes, sections.count,
+		sections.encodingType, r.header.ColumnType, nil, nil)
+	if err != nil {
+		return err
+	}
+	cache.add(id, ids, values, true)
+	return nil
+}
+
+// touch marks block id as the most recently used block without counting a hit
+// or miss. It returns false if the block is not cached.
+func (c *blockCache) touch(id BlockID) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.blocks[id]
+	if ok {
+		c.lru.MoveToFront(element)
+	}
+	return ok
+}
+
+// get returns the cached block id, copied into idsBuf and valsBuf if their
+// capacity suffices. Either buffer may be skipped by passing skipIDs or
+// skipValues. ok is false on a cache miss.
+func (c *blockCache) get(id BlockID, idsBuf []uint64, valsBuf []int64, skipIDs, skipValues bool) ([]uint64, []int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.blocks[id]
+	if !ok {
+		c.misses++
+		return nil, nil, false
+	}
+	c.hits++
+	c.lru.MoveToFront(element)
+
+	block := element.Value.(*cachedBlock)
+	var ids []uint64
+	var values []int64
+	if !skipIDs {
+		ids = resizeUint64s(idsBuf, len(block.ids))
+		copy(ids, block.ids)
+	}
+	if !skipValues {
+		values = resizeInt64s(valsBuf, len(block.values))
+		copy(values, block.values)
+	}
+	return ids, values, true
+}
+
+// add caches a copy of a decoded block if admit is true
+func (c *blockCache) add(id BlockID, ids []uint64, values []int64, admit bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !admit {
+		c.rejected++
+		return
+	}
+	if _, ok := c.blocks[id]; ok {
+		// Added by a concurrent read of the same block
+		return
+	}
+
+	if c.lru.Len() >= c.capacity {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.blocks, oldest.Value.(*cachedBlock).id)
+	}
+	c.blocks[id] = c.lru.PushFront(&cachedBlock{
+		id:     id,
+		ids:    append([]uint64(nil), ids...),
+		values: append([]int64(nil), values...),
+	})
+}
+
+// admitToCache returns whether a block that was read reads times, including
+// the current read, is admitted to the block cache. reads is 0 if access
+// statistics are disabled.
+func (r *Reader) admitToCache(reads uint64) bool {
+	return r.access == nil || reads > 1
+}
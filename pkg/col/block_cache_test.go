@@ -0,0 +1,125 @@
+package col
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockCache(t *testing.T) {
+	t.Run("LRU", func(t *testing.T) {
+		reader := writeBlocksToBuffer(t, 4, 10)
+		reader.EnableBlockCache(2)
+
+		expectedIDs, expectedValues, err := reader.ReadBlock(0)
+		require.NoError(t, err)
+		for _, id := range []BlockID{1, 0, 2} {
+			_, _, err := reader.ReadBlock(id)
+			require.NoError(t, err)
+		}
+
+		// Block 1 was evicted as the least recently used block
+		assert.Equal(t, BlockCacheStats{Capacity: 2, Blocks: 2, Hits: 1, Misses: 3}, reader.BlockCacheStats())
+
+		ids, values, err := reader.ReadBlock(0)
+		require.NoError(t, err)
+		assert.Equal(t, expectedIDs, ids)
+		assert.Equal(t, expectedValues, values)
+		_, _, err = reader.ReadBlock(1)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), reader.BlockCacheStats().Hits)
+		assert.Equal(t, uint64(4), reader.BlockCacheStats().Misses)
+
+		// Returned slices are copies, so modifying them does not affect the cache
+		ids[0] = 1000
+		values[0] = -1
+		onlyIDs, err := reader.ReadBlockIDs(0)
+		require.NoError(t, err)
+		assert.Equal(t, expectedIDs, onlyIDs)
+		onlyValues, err := reader.ReadBlockValues(0)
+		require.NoError(t, err)
+		assert.Equal(t, expectedValues, onlyValues)
+
+		reader.DisableBlockCache()
+		assert.Equal(t, BlockCacheStats{}, reader.BlockCacheStats())
+	})
+
+	t.Run("Admission by access statistics", func(t *testing.T) {
+		reader := writeBlocksToBuffer(t, 4, 10)
+		reader.EnableAccessStats()
+		reader.EnableBlockCache(2)
+
+		// A scan reads every block once, none is admitted
+		require.NoError(t, reader.ScanAll(1, func(BlockID, []uint64, []int64) error { return nil }))
+		stats := reader.BlockCacheStats()
+		assert.Zero(t, stats.Blocks)
+		assert.Equal(t, uint64(4), stats.Rejected)
+
+		// The second read of a block admits it
+		_, _, err := reader.ReadBlock(3)
+		require.NoError(t, err)
+		_, _, err = reader.ReadBlock(3)
+		require.NoError(t, err)
+		stats = reader.BlockCacheStats()
+		assert.Equal(t, 1, stats.Blocks)
+		assert.Equal(t, uint64(1), stats.Hits)
+
+		// Cache hits are counted as reads
+		assert.Equal(t, uint64(3), reader.AccessStats()[3].Reads)
+	})
+
+	t.Run("Prewarm", func(t *testing.T) {
+		reader := writeBlocksToBuffer(t, 4, 10)
+		reader.EnableAccessStats()
+		reader.EnableBlockCache(3)
+
+		// Prewarmed blocks are admitted without counting as reads
+		require.NoError(t, reader.Prewarm([]BlockID{1, 3, 1}))
+		assert.Equal(t, BlockCacheStats{Capacity: 3, Blocks: 2}, reader.BlockCacheStats())
+		assert.Zero(t, reader.AccessStats()[1].Reads)
+
+		expectedIDs, expectedValues, err := reader.ReadBlock(3)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{30, 31, 32, 33, 34, 35, 36, 37, 38, 39}, expectedIDs)
+		assert.Equal(t, int64(60), expectedValues[0])
+		assert.Equal(t, uint64(1), reader.BlockCacheStats().Hits)
+
+		// The last blocks of the file remain cached
+		require.NoError(t, reader.PrewarmAll())
+		_, _, err = reader.ReadBlock(0)
+		require.NoError(t, err)
+		stats := reader.BlockCacheStats()
+		assert.Equal(t, 3, stats.Blocks)
+		assert.Equal(t, uint64(1), stats.Misses)
+
+		assert.Error(t, reader.Prewarm([]BlockID{4}))
+
+		// Without a block cache the blocks are only read
+		reader.DisableBlockCache()
+		require.NoError(t, reader.PrewarmAll())
+		assert.Equal(t, BlockCacheStats{}, reader.BlockCacheStats())
+	})
+
+	t.Run("Prewarm list columns", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer, err := NewWriterToBuffer(&buf, WithDataType(DataTypeInt64List))
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlockLists([]uint64{1, 2}, [][]int64{{5, -3}, {7}}))
+		require.NoError(t, writer.FinalizeAndClose())
+		reader, err := NewReaderFromBytes(buf.Bytes())
+		require.NoError(t, err)
+		defer reader.Close()
+		reader.EnableBlockCache(1)
+
+		// The flattened pairs are cached like those of other columns
+		require.NoError(t, reader.PrewarmAll())
+		assert.Equal(t, BlockCacheStats{Capacity: 1, Blocks: 1}, reader.BlockCacheStats())
+		ids, values, err := reader.ReadBlock(0)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{1, 1, 2}, ids)
+		assert.Equal(t, []int64{5, -3, 7}, values)
+		assert.Equal(t, uint64(1), reader.BlockCacheStats().Hits)
+	})
+}
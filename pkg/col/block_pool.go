@@ -0,0 +1,62 @@
+package col
+
+import (
+	"sync"
+
+	"github.com/weaviate/sroar"
+)
+
+// decodedBlockPool holds the buffers of released PooledBlocks
+var decodedBlockPool = sync.Pool{
+	New: func() interface{} {
+		return &PooledBlock{}
+	},
+}
+
+// PooledBlock holds the decoded pairs of a block in buffers taken from a pool
+// shared by all readers. Release returns the buffers to the pool, after which
+// IDs and Values must no longer be used.
+type PooledBlock struct {
+	ID     BlockID
+	IDs    []uint64
+	Values []int64
+}
+
+// Release returns the buffers of the block to the pool
+func (b *PooledBlock) Release() {
+	decodedBlockPool.Put(b)
+}
+
+// ReadBlockPooled returns the ID-value pairs of a block like ReadBlock, but
+// decodes them into pooled buffers. Scans that release each block once it
+// has been processed reuse the same few buffers instead of allocating two
+// slices per block.
+func (r *Reader) ReadBlockPooled(id BlockID) (*PooledBlock, error) {
+	block := decodedBlockPool.Get().(*PooledBlock)
+	ids, values, err := r.readBlockInto(id, block.IDs, block.Values)
+	if err != nil {
+		block.Release()
+		return nil, err
+	}
+	block.ID, block.IDs, block.Values = id, ids, values
+	return block, nil
+}
+
+// readBlockValuesFiltered returns the values of a block whose IDs are in
+// filter, if set, and not in denyFilter, if set. The block is decoded into
+// pooled buffers and the values are appended to valsBuf[:0].
+func (r *Reader) readBlockValuesFiltered(id BlockID, filter, denyFilter *sroar.Bitmap, valsBuf []int64) ([]int64, error) {
+	block, err := r.ReadBlockPooled(id)
+	if err != nil {
+		return nil, err
+	}
+	defer block.Release()
+
+	values := valsBuf[:0]
+	for i, id := range block.IDs {
+		if (filter == nil || filter.Contains(id)) && (denyFilter == nil || !denyFilter.Contains(id)) {
+			values = append(values, block.Values[i])
+		}
+	}
+	return values, nil
+}
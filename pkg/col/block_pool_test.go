@@ -0,0 +1,107 @@
+package col
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaviate/sroar"
+)
+
+func TestBlockPooling(t *testing.T) {
+	const numBlocks = 10
+	var buf bytes.Buffer
+	writer, err := NewWriterToBuffer(&buf)
+	require.NoError(t, err)
+	var expectedIDs []uint64
+	var expectedValues []int64
+	for b := 0; b < numBlocks; b++ {
+		ids := make([]uint64, 100)
+		values := make([]int64, 100)
+		for i := range ids {
+			ids[i] = uint64(b*100 + i)
+			values[i] = int64(b*1000 + i)
+		}
+		require.NoError(t, writer.WriteBlock(ids, values))
+		expectedIDs = append(expectedIDs, ids...)
+		expectedValues = append(expectedValues, values...)
+	}
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := NewReaderFromBytes(buf.Bytes())
+	require.NoError(t, err)
+	defer reader.Close()
+
+	t.Run("Read pooled blocks", func(t *testing.T) {
+		for b := BlockID(0); b < numBlocks; b++ {
+			ids, values, err := reader.ReadBlock(b)
+			require.NoError(t, err)
+
+			block, err := reader.ReadBlockPooled(b)
+			require.NoError(t, err)
+			assert.Equal(t, b, block.ID)
+			assert.Equal(t, ids, block.IDs)
+			assert.Equal(t, values, block.Values)
+			block.Release()
+		}
+
+		_, err := reader.ReadBlockPooled(numBlocks)
+		assert.Error(t, err)
+	})
+
+	t.Run("Recycled scan", func(t *testing.T) {
+		for _, readAhead := range []int{0, 1, 4} {
+			scanner := reader.ScanBlocksRecycled(readAhead)
+			var allIDs []uint64
+			var allValues []int64
+			for scanner.Next() {
+				_, ids, values := scanner.Block()
+				allIDs = append(allIDs, ids...)
+				allValues = append(allValues, values...)
+			}
+			require.NoError(t, scanner.Err())
+			require.NoError(t, scanner.Close())
+			assert.Equal(t, expectedIDs, allIDs, "read-ahead %d", readAhead)
+			assert.Equal(t, expectedValues, allValues, "read-ahead %d", readAhead)
+		}
+	})
+
+	t.Run("Recycled scan closed early", func(t *testing.T) {
+		scanner := reader.ScanBlocksRecycled(4)
+		require.True(t, scanner.Next())
+		require.NoError(t, scanner.Close())
+		require.NoError(t, scanner.Close())
+
+		// Released buffers are handed out again without sharing
+		first, err := reader.ReadBlockPooled(0)
+		require.NoError(t, err)
+		second, err := reader.ReadBlockPooled(1)
+		require.NoError(t, err)
+		assert.Equal(t, expectedIDs[:100], first.IDs)
+		assert.Equal(t, expectedIDs[100:200], second.IDs)
+		first.Release()
+		second.Release()
+	})
+
+	t.Run("Filtered aggregation", func(t *testing.T) {
+		filter := sroar.NewBitmap()
+		filter.SetMany([]uint64{5, 150, 999})
+		deny := sroar.NewBitmap()
+		deny.Set(150)
+		for _, parallel := range []int{0, 2} {
+			result := reader.AggregateWithOptions(AggregateOptions{Filter: filter, DenyFilter: deny, Parallel: parallel})
+			assert.Equal(t, uint64(2), result.Count)
+			assert.Equal(t, int64(5+9099), result.Sum)
+		}
+
+		ids, values, err := reader.ReadBlockFiltered(1, filter, deny)
+		require.NoError(t, err)
+		assert.Empty(t, ids)
+		assert.Empty(t, values)
+		ids, values, err = reader.ReadBlockFiltered(1, filter, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{150}, ids)
+		assert.Equal(t, []int64{1050}, values)
+	})
+}
@@ -0,0 +1,123 @@
+package col
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/weaviate/sroar"
+)
+
+// BoolAggregateResult holds the result of aggregating a DataTypeBool column
+type BoolAggregateResult struct {
+	Count     uint64 // Number of values
+	TrueCount uint64 // Number of true values
+}
+
+// FalseCount returns the number of false values
+func (r BoolAggregateResult) FalseCount() uint64 {
+	return r.Count - r.TrueCount
+}
+
+// WriteBlockBool writes a block of a DataTypeBool column. The values are
+// stored as packed bits, one bit per value, instead of 8 bytes each. The
+// statistics of the block describe the values as 0 and 1, so the Sum is the
+// number of true values. Unlike WriteBlock, the block is written as a whole
+// even if it exceeds the target block size.
+func (w *Writer) WriteBlockBool(ids []uint64, values []bool, options ...BlockOption) error {
+	if w.dataType != DataTypeBool {
+		return fmt.Errorf("cannot write bool values to a column of data type %d", w.dataType)
+	}
+	config := blockConfig{
+		encodingType:    w.encodingType,
+		compressionType: CompressionNone,
+	}
+	for _, option := range options {
+		option(&config)
+	}
+	if config.compressionType != CompressionNone {
+		return fmt.Errorf("unsupported compression type: %d", config.compressionType)
+	}
+	if len(ids) != len(values) {
+		return errors.New("ids and values must have the same length")
+	}
+	if len(ids) == 0 {
+		return errors.New("cannot write empty block")
+	}
+
+	encodedIDs, encodedIDBytes, _, err := encodeIDs(ids, config.encodingType)
+	if err != nil {
+		return err
+	}
+	idSection := appendSection(nil, encodedIDs, encodedIDBytes)
+
+	bits := make([]int64, len(values))
+	valueSection := make([]byte, (len(values)+7)/8)
+	for i, v := range values {
+		if v {
+			bits[i] = 1
+			valueSection[i/8] |= 1 << (i % 8)
+		}
+	}
+
+	return w.writeEncodedBlock(ids, bits, idSection, valueSection, config)
+}
+
+// decodeBoolValues unpacks count bits from the value section of a block of a
+// DataTypeBool column into 0 and 1, reusing the backing array of valuesBuf if
+// it is large enough
+func decodeBoolValues(valueBytes []byte, count int, valuesBuf []int64) []int64 {
+	if maxCount := len(valueBytes) * 8; count > maxCount {
+		count = maxCount
+	}
+	values := resizeInt64s(valuesBuf, count)
+	for i := range values {
+		values[i] = int64(valueBytes[i/8]>>(i%8)) & 1
+	}
+	return values
+}
+
+// ReadBlockBool returns the ID-value pairs of a block of a DataTypeBool column
+func (r *Reader) ReadBlockBool(id BlockID) ([]uint64, []bool, error) {
+	if r.header.ColumnType != DataTypeBool {
+		return nil, nil, fmt.Errorf("cannot read bool values from a column of data type %d", r.header.ColumnType)
+	}
+
+	ids, bits, err := r.ReadBlock(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	values := make([]bool, len(bits))
+	for i, bit := range bits {
+		values[i] = bit != 0
+	}
+	return ids, values, nil
+}
+
+// AggregateBool counts the values and the true values of a DataTypeBool
+// column with the given options. Unfiltered counts are answered from the
+// footer.
+func (r *Reader) AggregateBool(opts AggregateOptions) (BoolAggregateResult, error) {
+	if r.header.ColumnType != DataTypeBool {
+		return BoolAggregateResult{}, fmt.Errorf("cannot aggregate bool values of a column of data type %d", r.header.ColumnType)
+	}
+	if err := r.ensureFooter(); err != nil {
+		return BoolAggregateResult{}, err
+	}
+
+	result := r.AggregateWithOptions(opts)
+	if err := result.Err(); err != nil {
+		return BoolAggregateResult{}, err
+	}
+	return BoolAggregateResult{Count: result.Count, TrueCount: uint64(result.Sum)}, nil
+}
+
+// TrueIDBitmap returns a bitmap of the IDs whose value is true in a
+// DataTypeBool column, usable as an allow filter against other columns, e.g.
+// in AggregateOptions.Filter. If filter is not nil, the result is restricted
+// to the IDs it contains.
+func (r *Reader) TrueIDBitmap(filter *sroar.Bitmap) (*sroar.Bitmap, error) {
+	if r.header.ColumnType != DataTypeBool {
+		return nil, fmt.Errorf("cannot read bool values from a column of data type %d", r.header.ColumnType)
+	}
+	return r.BuildIDBitmap(IDBitmapOptions{Where: Eq(Col, Const(1)), Filter: filter})
+}
@@ -0,0 +1,99 @@
+package col
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaviate/sroar"
+)
+
+func TestBoolColumn(t *testing.T) {
+	ids := make([]uint64, 1000)
+	values := make([]bool, 1000)
+	for i := range ids {
+		ids[i] = uint64(i)
+		values[i] = i%3 == 0
+	}
+
+	var buf bytes.Buffer
+	writer, err := NewWriterToBuffer(&buf, WithDataType(DataTypeBool), WithEncoding(EncodingVarIntBoth))
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteBlockBool(ids[:500], values[:500]))
+	require.NoError(t, writer.WriteBlockBool(ids[500:], values[500:], WithBlockEncoding(EncodingRaw)))
+	assert.Error(t, writer.WriteBlock([]uint64{2000}, []int64{1}))
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := NewReaderFromBytes(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, DataTypeBool, reader.DataType())
+
+	t.Run("Read", func(t *testing.T) {
+		blockIDs, blockValues, err := reader.ReadBlockBool(1)
+		require.NoError(t, err)
+		assert.Equal(t, ids[500:], blockIDs)
+		assert.Equal(t, values[500:], blockValues)
+
+		value, found, err := reader.Get(9)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, int64(1), value)
+	})
+
+	t.Run("Aggregate", func(t *testing.T) {
+		for _, opts := range []AggregateOptions{{}, {SkipPreCalculated: true}} {
+			result, err := reader.AggregateBool(opts)
+			require.NoError(t, err)
+			assert.Equal(t, BoolAggregateResult{Count: 1000, TrueCount: 334}, result)
+			assert.Equal(t, uint64(666), result.FalseCount())
+		}
+
+		filter := sroar.NewBitmap()
+		filter.SetMany([]uint64{0, 1, 2, 3, 999})
+		result, err := reader.AggregateBool(AggregateOptions{Filter: filter})
+		require.NoError(t, err)
+		assert.Equal(t, BoolAggregateResult{Count: 5, TrueCount: 3}, result)
+	})
+
+	t.Run("TrueIDBitmap", func(t *testing.T) {
+		bitmap, err := reader.TrueIDBitmap(nil)
+		require.NoError(t, err)
+		assert.Equal(t, 334, bitmap.GetCardinality())
+		assert.True(t, bitmap.Contains(999))
+		assert.False(t, bitmap.Contains(998))
+
+		filter := sroar.NewBitmap()
+		filter.SetMany([]uint64{3, 4})
+		bitmap, err = reader.TrueIDBitmap(filter)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{3}, bitmap.ToArray())
+	})
+
+	t.Run("Size", func(t *testing.T) {
+		var intBuf bytes.Buffer
+		intWriter, err := NewWriterToBuffer(&intBuf, WithEncoding(EncodingVarIntBoth), WithPadding(PaddingNone))
+		require.NoError(t, err)
+		bits := make([]int64, len(values))
+		for i, v := range values {
+			if v {
+				bits[i] = 1
+			}
+		}
+		require.NoError(t, writeAllBlocks(intWriter, ids, bits))
+		require.NoError(t, intWriter.FinalizeAndClose())
+
+		var boolBuf bytes.Buffer
+		boolWriter, err := NewWriterToBuffer(&boolBuf, WithDataType(DataTypeBool),
+			WithEncoding(EncodingVarIntBoth), WithPadding(PaddingNone))
+		require.NoError(t, err)
+		require.NoError(t, boolWriter.WriteBlockBool(ids, values))
+		require.NoError(t, boolWriter.FinalizeAndClose())
+		assert.Less(t, boolBuf.Len(), intBuf.Len())
+	})
+
+	var intBuf bytes.Buffer
+	intWriter, err := NewWriterToBuffer(&intBuf)
+	require.NoError(t, err)
+	assert.Error(t, intWriter.WriteBlockBool([]uint64{1}, []bool{true}))
+}
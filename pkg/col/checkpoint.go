@@ -0,0 +1,202 @@
+package col
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrCheckpoint is returned by the block writes of a writer with a checkpoint
+// interval if the block was written, but the checkpoint after it failed. The
+// block must not be written again.
+var ErrCheckpoint = errors.New("block written, but checkpoint failed")
+
+// CheckpointPath returns the path of the checkpoint a Writer keeps next to
+// the file at path while it is being written
+func CheckpointPath(path string) string {
+	return path + ".checkpoint"
+}
+
+// Checkpoint publishes the blocks written so far. It writes the footer of
+// these blocks to a checkpoint next to the file, see CheckpointPath, which
+// readers opened with OpenOptions.FollowCheckpoints use until the file is
+// finalized. The blocks are synced before the checkpoint is replaced, so
+// a checkpoint never refers to blocks that are not on disk. Finalize removes
+// the checkpoint. Checkpoints are only available for writers created with
+// NewWriter.
+func (w *Writer) Checkpoint() error {
+	if w.path == "" {
+		return errors.New("checkpoints require a writer created with NewWriter")
+	}
+
+	footer, err := w.footer()
+	if err != nil {
+		return err
+	}
+	footerBuf, err := footer.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	meta := FooterMetadata{FooterSize: uint64(len(footerBuf)), Magic: MagicNumber}
+	metaBuf, err := meta.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	if err := w.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync blocks: %w", err)
+	}
+
+	// The checkpoint is replaced atomically, so readers see either the
+	// previous or the new one
+	if err := writeFileAtomically(CheckpointPath(w.path), append(footerBuf, metaBuf...)); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomically writes data to a temporary file next to path, syncs it
+// and renames it to path
+func writeFileAtomically(path string, data []byte) error {
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// WithCheckpointInterval makes the writer publish a checkpoint after every n
+// blocks, see Writer.Checkpoint. For long imports, a crash then loses at most
+// the blocks written since the last checkpoint: the partially written file
+// can be opened with OpenOptions.FollowCheckpoints, or be completed with
+// RebuildFooter. Automatic checkpoints require a writer created with
+// NewWriter. A failed checkpoint is reported as ErrCheckpoint by the write of
+// the block before it, which was written nonetheless.
+func WithCheckpointInterval(n int) WriterOption {
+	return func(w *Writer) {
+		w.checkpointInterval = n
+	}
+}
+
+// checkpointIfDue writes a checkpoint if the checkpoint interval has passed
+// since the last one
+func (w *Writer) checkpointIfDue() error {
+	if w.checkpointInterval <= 0 || w.blockCount%uint64(w.checkpointInterval) != 0 {
+		return nil
+	}
+	if err := w.Checkpoint(); err != nil {
+		return fmt.Errorf("%w: block %d: %w", ErrCheckpoint, w.blockCount-1, err)
+	}
+	return nil
+}
+
+// removeCheckpoint removes the checkpoint of a finalized file
+func (w *Writer) removeCheckpoint() error {
+	if w.path == "" {
+		return nil
+	}
+	if err := os.Remove(CheckpointPath(w.path)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove checkpoint: %w", err)
+	}
+	return nil
+}
+
+// readCheckpoint reads the block index from the checkpoint of the file. The
+// error wraps os.ErrNotExist if there is no checkpoint.
+func (r *Reader) readCheckpoint() error {
+	buf, err := os.ReadFile(CheckpointPath(r.filename))
+	if err != nil {
+		return fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	if len(buf) < footerMetaSize {
+		return fmt.Errorf("checkpoint too small: %d bytes", len(buf))
+	}
+
+	var meta FooterMetadata
+	if err := meta.UnmarshalBinary(buf[len(buf)-footerMetaSize:]); err != nil {
+		return err
+	}
+	if meta.Magic != MagicNumber || meta.FooterSize != uint64(len(buf)-footerMetaSize) {
+		return fmt.Errorf("invalid checkpoint: magic 0x%X, footer size %d", meta.Magic, meta.FooterSize)
+	}
+
+	r.resetFooter()
+	r.footerMeta = meta
+	if err := r.applyFooter(buf[:meta.FooterSize]); err != nil {
+		return fmt.Errorf("failed to parse checkpoint: %w", err)
+	}
+	// While the file is finalized, the header may already count blocks the
+	// checkpoint does not have
+	r.header.BlockCount = uint64(len(r.blockIndex))
+	r.checkpointed = true
+	return nil
+}
+
+// resetFooter drops everything read from a footer
+func (r *Reader) resetFooter() {
+	r.footerMeta = FooterMetadata{}
+	r.blockIndex = nil
+	r.plan = idPlan{}
+	r.extendedStats = nil
+	r.fileStats = nil
+	r.unsignedStats = nil
+	r.lineage = nil
+	r.valueOrders = nil
+	r.partitions = nil
+	r.dataFileSize = 0
+	r.footerSections = nil
+	r.globalIDs = nil
+}
+
+// Checkpointed reports whether the reader serves the blocks of a checkpoint
+// of a file that is still being written, rather than a finalized file
+func (r *Reader) Checkpointed() bool {
+	return r.checkpointed
+}
+
+// Refresh picks up the blocks written since the reader was opened or last
+// refreshed. The reader serves the blocks of the latest checkpoint, or all
+// blocks once the file has been finalized, after which Refresh has no
+// effect. Refresh is only available for readers opened with
+// OpenOptions.FollowCheckpoints and must not be called concurrently with
+// other methods of the reader.
+func (r *Reader) Refresh() error {
+	if !r.followCheckpoints {
+		return errors.New("reader does not follow checkpoints")
+	}
+	if !r.checkpointed {
+		return nil
+	}
+
+	info, err := os.Stat(r.filename)
+	if err != nil {
+		return fmt.Errorf("failed to get file info: %w", err)
+	}
+	r.fileSize = info.Size()
+
+	// Finalize rewrites the header before writing the footer
+	if err := r.readHeader(); err != nil {
+		return fmt.Errorf("failed to read header: %w", err)
+	}
+	r.checkpointed = false
+	r.resetFooter()
+	if err := r.readFooter(); err != nil {
+		return fmt.Errorf("failed to refresh footer: %w", err)
+	}
+	r.growAccessStats()
+	return nil
+}
@@ -0,0 +1,226 @@
+package col
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpoints(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "growing.col")
+	writer, err := NewWriter(path)
+	require.NoError(t, err)
+
+	block := func(b uint64) ([]uint64, []int64) {
+		return []uint64{b*10 + 1, b*10 + 2}, []int64{int64(b), int64(b)}
+	}
+
+	// Without a checkpoint the file cannot be opened yet
+	ids, values := block(0)
+	require.NoError(t, writer.WriteBlock(ids, values))
+	_, err = NewReaderWithOptions(path, OpenOptions{FollowCheckpoints: true})
+	assert.Error(t, err)
+
+	require.NoError(t, writer.Checkpoint())
+	ids, values = block(1)
+	require.NoError(t, writer.WriteBlock(ids, values))
+
+	reader, err := NewReaderWithOptions(path, OpenOptions{FollowCheckpoints: true})
+	require.NoError(t, err)
+	defer reader.Close()
+	assert.True(t, reader.Checkpointed())
+	assert.Equal(t, uint64(1), reader.BlockCount())
+	assert.Equal(t, uint64(2), reader.Aggregate().Count)
+	bitmap, err := reader.GetGlobalIDBitmap()
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1, 2}, bitmap.ToArray())
+
+	t.Run("Refresh picks up new checkpoints", func(t *testing.T) {
+		require.NoError(t, writer.Checkpoint())
+		require.NoError(t, reader.Refresh())
+		assert.True(t, reader.Checkpointed())
+		assert.Equal(t, uint64(2), reader.BlockCount())
+		value, found, err := reader.Get(12)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, int64(1), value)
+	})
+
+	t.Run("Refresh after finalization", func(t *testing.T) {
+		ids, values := block(2)
+		require.NoError(t, writer.WriteBlock(ids, values))
+		require.NoError(t, writer.FinalizeAndClose())
+		_, err := os.Stat(CheckpointPath(path))
+		assert.True(t, errors.Is(err, os.ErrNotExist))
+
+		require.NoError(t, reader.Refresh())
+		assert.False(t, reader.Checkpointed())
+		assert.Equal(t, uint64(3), reader.BlockCount())
+		assert.Equal(t, uint64(6), reader.Aggregate().Count)
+		require.NoError(t, reader.Refresh())
+
+		strict, err := NewReaderWithOptions(path, OpenOptions{FollowCheckpoints: true, Strict: true})
+		require.NoError(t, err)
+		strict.Close()
+	})
+
+	t.Run("Refresh requires following checkpoints", func(t *testing.T) {
+		plain, err := NewReader(path)
+		require.NoError(t, err)
+		defer plain.Close()
+		assert.Error(t, plain.Refresh())
+	})
+
+	t.Run("In-memory writers", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer, err := NewWriterToBuffer(&buf)
+		require.NoError(t, err)
+		assert.Error(t, writer.Checkpoint())
+	})
+}
+
+func TestCheckpointDuringFinalize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "finalizing.col")
+	writer, err := NewWriter(path)
+	require.NoError(t, err)
+	defer writer.Close()
+	require.NoError(t, writer.WriteBlock([]uint64{1, 2}, []int64{10, 20}))
+	require.NoError(t, writer.Checkpoint())
+	require.NoError(t, writer.WriteBlock([]uint64{3, 4}, []int64{30, 40}))
+
+	reader, err := NewReaderWithOptions(path, OpenOptions{FollowCheckpoints: true})
+	require.NoError(t, err)
+	defer reader.Close()
+
+	// Finalize rewrites the header with the final block count before it
+	// writes the footer
+	bitmapOffset, bitmapSize, err := writer.writeGlobalIDBitmap()
+	require.NoError(t, err)
+	_, err = writer.file.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+	require.NoError(t, writer.writeFileHeader(bitmapOffset, bitmapSize))
+
+	check := func(t *testing.T, reader *Reader) {
+		assert.True(t, reader.Checkpointed())
+		require.Equal(t, uint64(1), reader.BlockCount())
+		for b := uint64(0); b < reader.BlockCount(); b++ {
+			_, _, err := reader.ReadBlock(BlockID(b))
+			require.NoError(t, err)
+		}
+	}
+
+	require.NoError(t, reader.Refresh())
+	check(t, reader)
+
+	opened, err := NewReaderWithOptions(path, OpenOptions{FollowCheckpoints: true})
+	require.NoError(t, err)
+	defer opened.Close()
+	check(t, opened)
+}
+
+func TestRefreshAccessStats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "growing.col")
+	writer, err := NewWriter(path)
+	require.NoError(t, err)
+	defer writer.Close()
+	require.NoError(t, writer.WriteBlock([]uint64{1, 2}, []int64{10, 20}))
+	require.NoError(t, writer.Checkpoint())
+
+	reader, err := NewReaderWithOptions(path, OpenOptions{FollowCheckpoints: true})
+	require.NoError(t, err)
+	defer reader.Close()
+	reader.EnableAccessStats()
+	reader.EnableBlockCache(4)
+	_, _, err = reader.ReadBlock(0)
+	require.NoError(t, err)
+
+	// Blocks added by Refresh are counted and cached like the others
+	require.NoError(t, writer.WriteBlock([]uint64{3, 4}, []int64{30, 40}))
+	require.NoError(t, writer.Checkpoint())
+	require.NoError(t, reader.Refresh())
+	for i := 0; i < 3; i++ {
+		ids, values, err := reader.ReadBlock(1)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{3, 4}, ids)
+		assert.Equal(t, []int64{30, 40}, values)
+	}
+
+	stats := reader.AccessStats()
+	require.Len(t, stats, 2)
+	assert.Equal(t, uint64(1), stats[0].Reads)
+	assert.Equal(t, uint64(3), stats[1].Reads)
+	cache := reader.BlockCacheStats()
+	assert.Equal(t, 1, cache.Blocks)
+	assert.Equal(t, uint64(1), cache.Hits)
+}
+
+func TestCheckpointInterval(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "import.col")
+	writer, err := NewWriter(path, WithCheckpointInterval(2))
+	require.NoError(t, err)
+	for b := uint64(0); b < 5; b++ {
+		require.NoError(t, writer.WriteBlock([]uint64{b*10 + 1, b*10 + 2}, []int64{1, 2}))
+	}
+
+	// A crash after the fifth block loses it, the first four were checkpointed
+	require.NoError(t, writer.Close())
+	reader, err := NewReaderWithOptions(path, OpenOptions{FollowCheckpoints: true})
+	require.NoError(t, err)
+	assert.True(t, reader.Checkpointed())
+	assert.Equal(t, uint64(4), reader.BlockCount())
+	assert.Equal(t, uint64(8), reader.Aggregate().Count)
+	require.NoError(t, reader.Close())
+
+	// Rebuilding the footer recovers all complete blocks
+	repaired := filepath.Join(dir, "repaired.col")
+	report, err := RebuildFooter(path, repaired)
+	require.NoError(t, err)
+	assert.Equal(t, 5, report.Blocks)
+
+	t.Run("Finalize supersedes checkpoints", func(t *testing.T) {
+		path := filepath.Join(dir, "final.col")
+		writer, err := NewWriter(path, WithCheckpointInterval(1))
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlock([]uint64{1}, []int64{1}))
+		_, err = os.Stat(CheckpointPath(path))
+		require.NoError(t, err)
+		require.NoError(t, writer.FinalizeAndClose())
+		_, err = os.Stat(CheckpointPath(path))
+		assert.True(t, errors.Is(err, os.ErrNotExist))
+	})
+
+	t.Run("Failed checkpoints keep the block", func(t *testing.T) {
+		path := filepath.Join(dir, "failing.col")
+		writer, err := NewWriter(path, WithCheckpointInterval(1))
+		require.NoError(t, err)
+
+		// A directory in place of the temporary checkpoint makes it fail
+		tmp := CheckpointPath(path) + ".tmp"
+		require.NoError(t, os.Mkdir(tmp, 0755))
+		err = writer.WriteBlock([]uint64{1, 2}, []int64{1, 2})
+		assert.ErrorIs(t, err, ErrCheckpoint)
+
+		require.NoError(t, os.Remove(tmp))
+		require.NoError(t, writer.WriteBlock([]uint64{3}, []int64{3}))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		reader, err := NewReader(path)
+		require.NoError(t, err)
+		defer reader.Close()
+		assert.Equal(t, uint64(2), reader.BlockCount())
+		assert.Equal(t, uint64(3), reader.Aggregate().Count)
+	})
+
+	t.Run("In-memory writers", func(t *testing.T) {
+		var buf bytes.Buffer
+		_, err := NewWriterToBuffer(&buf, WithCheckpointInterval(2))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,161 @@
+package col
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+
+	"vibe-lsm/pkg/col/format"
+)
+
+// ErrChecksumMismatch is returned by VerifyChecksum if the file checksum does
+// not match the file contents
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// ErrCorruptFooter is returned when opening a file whose footer does not match
+// the checksum recorded in its footer checksum section
+var ErrCorruptFooter = errors.New("corrupt footer")
+
+// checksumChunkSize is the size of the reads that VerifyChecksum hashes
+const checksumChunkSize = 1 << 20
+
+// WithChecksum sets the algorithm of the file checksum, ChecksumCRC64ISO by
+// default. ChecksumNone disables the checksum. The algorithm is recorded in
+// the file header, so readers verify the file with the same algorithm.
+func WithChecksum(checksumType uint32) WriterOption {
+	return func(w *Writer) {
+		w.checksumType = checksumType
+	}
+}
+
+// checksumFile hashes all bytes written after the file header. Blocks and the
+// footer are only ever appended, so the hash follows the file order; the file
+// header is rewritten by Finalize and hashed last.
+type checksumFile struct {
+	writerFile
+	checksum hash.Hash64
+	position int64
+}
+
+func (f *checksumFile) Write(p []byte) (int, error) {
+	n, err := f.writerFile.Write(p)
+	if f.position >= headerSize {
+		f.checksum.Write(p[:n])
+	}
+	f.position += int64(n)
+	return n, err
+}
+
+func (f *checksumFile) Seek(offset int64, whence int) (int64, error) {
+	position, err := f.writerFile.Seek(offset, whence)
+	if err == nil {
+		f.position = position
+	}
+	return position, err
+}
+
+// fileChecksum returns the checksum of the file: the bytes after the file
+// header up to the footer metadata, followed by the final file header
+func (w *Writer) fileChecksum(header []byte) uint64 {
+	if w.checksum == nil {
+		return 0
+	}
+	w.checksum.Write(header)
+	return w.checksum.Sum64()
+}
+
+// footerChecksumSection returns the footer checksum section of footer. Its
+// checksum covers the encoded footer, so the section must be appended last;
+// sections appended after it, like the data file section of a sidecar, are not
+// covered.
+func (w *Writer) footerChecksumSection(footer format.Footer) (format.FooterSection, error) {
+	footerBuf, err := footer.MarshalBinary()
+	if err != nil {
+		return format.FooterSection{}, err
+	}
+	checksum, err := format.Checksum(w.checksumType, footerBuf)
+	if err != nil {
+		return format.FooterSection{}, err
+	}
+	return format.FooterSection{
+		Type:    FooterSectionChecksum,
+		Payload: binary.LittleEndian.AppendUint64(nil, checksum),
+	}, nil
+}
+
+// verifyFooterChecksum compares the checksum recorded in the footer checksum
+// section, if any, to the footer bytes before the section. Unlike
+// VerifyChecksum it only needs the footer, so it runs whenever a footer is
+// read.
+func (r *Reader) verifyFooterChecksum(footerBuf []byte, footer format.Footer) error {
+	if r.header.ChecksumType == ChecksumNone {
+		return nil
+	}
+
+	covered := 4 + len(footer.Entries)*footerEntrySize
+	for _, section := range footer.Sections {
+		if section.Type != FooterSectionChecksum {
+			covered += footerSectionHeaderSize + len(section.Payload)
+			continue
+		}
+
+		if len(section.Payload) != uint64Size {
+			return fmt.Errorf("%w: checksum section size mismatch: expected=%d, actual=%d",
+				ErrCorruptFooter, uint64Size, len(section.Payload))
+		}
+		recorded := readBufferedUint64(section.Payload, 0)
+		actual, err := format.Checksum(r.header.ChecksumType, footerBuf[:covered])
+		if err != nil {
+			return err
+		}
+		if actual != recorded {
+			return fmt.Errorf("%w: recorded=0x%X, actual=0x%X", ErrCorruptFooter, recorded, actual)
+		}
+		return nil
+	}
+
+	// Files written before the section was introduced
+	return nil
+}
+
+// VerifyChecksum reads the whole file and compares its checksum to the one
+// recorded in the footer metadata, using the algorithm recorded in the file
+// header. It returns an error wrapping ErrChecksumMismatch if they differ and
+// nil for files written without a checksum.
+func (r *Reader) VerifyChecksum() error {
+	if err := r.ensureFooter(); err != nil {
+		return err
+	}
+	if r.header.ChecksumType == ChecksumNone {
+		return nil
+	}
+
+	checksum, err := format.NewChecksum(r.header.ChecksumType)
+	if err != nil {
+		return err
+	}
+
+	end := r.fileSize - footerMetaSize
+	buf := make([]byte, checksumChunkSize)
+	for offset := int64(headerSize); offset < end; offset += int64(len(buf)) {
+		if remaining := end - offset; remaining < int64(len(buf)) {
+			buf = buf[:remaining]
+		}
+		if err := r.readBytesInto(buf, offset); err != nil {
+			return err
+		}
+		checksum.Write(buf)
+	}
+
+	header, err := r.readBytesAt(0, headerSize)
+	if err != nil {
+		return err
+	}
+	checksum.Write(header)
+
+	if actual := checksum.Sum64(); actual != r.footerMeta.Checksum {
+		return fmt.Errorf("%w: recorded=0x%X, actual=0x%X", ErrChecksumMismatch, r.footerMeta.Checksum, actual)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package col
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeChecksumFile writes a multi-block file to buf
+func writeChecksumFile(t *testing.T, buf *bytes.Buffer, options ...WriterOption) {
+	ids := make([]uint64, 3000)
+	values := make([]int64, len(ids))
+	for i := range ids {
+		ids[i] = uint64(i * 3)
+		values[i] = int64(i) - 1500
+	}
+
+	writer, err := NewWriterToBuffer(buf, append(options, WithBlockSize(4096))...)
+	require.NoError(t, err)
+	require.NoError(t, writeAllBlocks(writer, ids, values))
+	require.NoError(t, writer.FinalizeAndClose())
+}
+
+func TestChecksum(t *testing.T) {
+	for name, checksumType := range map[string]uint32{
+		"CRC64-ISO": ChecksumCRC64ISO,
+		"CRC32C":    ChecksumCRC32C,
+		"XXHash64":  ChecksumXXHash64,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeChecksumFile(t, &buf, WithChecksum(checksumType))
+			data := buf.Bytes()
+
+			reader, err := NewReaderFromBytes(data)
+			require.NoError(t, err)
+			require.Greater(t, reader.BlockCount(), uint64(1))
+			assert.Equal(t, checksumType, reader.HeaderOnly().ChecksumType)
+			assert.NotZero(t, reader.footerMeta.Checksum)
+			require.NoError(t, reader.VerifyChecksum())
+
+			// Corruptions of a block and the header are detected
+			for _, offset := range []int{int(reader.blockIndex[1].BlockOffset) + 100, 40} {
+				corrupted := bytes.Clone(data)
+				corrupted[offset] ^= 0x01
+				reader, err := NewReaderFromBytes(corrupted)
+				require.NoError(t, err)
+				assert.ErrorIs(t, reader.VerifyChecksum(), ErrChecksumMismatch, "offset %d", offset)
+			}
+
+			// Corruptions of the footer are detected when opening the file
+			footerStart := len(data) - footerMetaSize - int(reader.footerMeta.FooterSize)
+			sections := reader.footerSections
+			require.Equal(t, FooterSectionChecksum, sections[len(sections)-1].Type)
+			for _, offset := range []int{footerStart + 10, footerStart + 4 + len(reader.blockIndex)*footerEntrySize + 20} {
+				corrupted := bytes.Clone(data)
+				corrupted[offset] ^= 0x01
+				_, err := NewReaderFromBytes(corrupted)
+				assert.ErrorIs(t, err, ErrCorruptFooter, "offset %d", offset)
+			}
+		})
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		var buf bytes.Buffer
+		writeChecksumFile(t, &buf)
+		reader, err := NewReaderFromBytes(buf.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, ChecksumCRC64ISO, reader.HeaderOnly().ChecksumType)
+		assert.NoError(t, reader.VerifyChecksum())
+	})
+
+	t.Run("None", func(t *testing.T) {
+		var buf bytes.Buffer
+		writeChecksumFile(t, &buf, WithChecksum(ChecksumNone))
+		reader, err := NewReaderFromBytes(buf.Bytes())
+		require.NoError(t, err)
+		assert.Zero(t, reader.footerMeta.Checksum)
+		assert.NoError(t, reader.VerifyChecksum())
+		for _, section := range reader.footerSections {
+			assert.NotEqual(t, FooterSectionChecksum, section.Type)
+		}
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		_, err := NewWriterToBuffer(&bytes.Buffer{}, WithChecksum(42))
+		assert.Error(t, err)
+	})
+
+	t.Run("Concat", func(t *testing.T) {
+		dir := t.TempDir()
+		var sources []string
+		for i := uint64(0); i < 2; i++ {
+			path := filepath.Join(dir, fmt.Sprintf("src%d.col", i))
+			writer, err := NewWriter(path, WithChecksum(ChecksumXXHash64))
+			require.NoError(t, err)
+			require.NoError(t, writer.WriteBlock([]uint64{i*10 + 1, i*10 + 2}, []int64{1, 2}))
+			require.NoError(t, writer.FinalizeAndClose())
+			sources = append(sources, path)
+		}
+
+		out := filepath.Join(dir, "out.col")
+		require.NoError(t, Concat(out, sources...))
+		data, err := os.ReadFile(out)
+		require.NoError(t, err)
+		reader, err := NewReaderFromBytes(data)
+		require.NoError(t, err)
+		assert.NoError(t, reader.VerifyChecksum())
+	})
+}
@@ -0,0 +1,484 @@
+// Package coltest generates random column files and verifies them against the
+// format spec. It writes datasets with every encoding and padding policy,
+// checks byte-level invariants of the written file and compares everything
+// read back through the public API with the data that was written.
+package coltest
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+
+	"vibe-lsm/pkg/col"
+)
+
+// Layout constants from the format spec
+const (
+	headerSize         = 64
+	blockHeaderSize    = 64
+	blockLayoutSize    = 16
+	footerEntrySize    = 56
+	footerMetaSize     = 24
+	footerSectionHdr   = 8
+	maxBlockSizeTarget = math.MaxUint32
+)
+
+// Encodings lists every encoding type a writer accepts
+var Encodings = []uint32{
+	col.EncodingRaw,
+	col.EncodingDeltaID,
+	col.EncodingDeltaValue,
+	col.EncodingDeltaBoth,
+	col.EncodingVarInt,
+	col.EncodingVarIntID,
+	col.EncodingVarIntValue,
+	col.EncodingVarIntBoth,
+	col.EncodingDeltaDelta,
+}
+
+// Compressions lists every compression type a writer accepts
+var Compressions = []uint32{
+	col.CompressionNone,
+}
+
+// Paddings lists the padding policies datasets are written with
+var Paddings = []col.PaddingPolicy{
+	col.PaddingNone,
+	col.PaddingPageAligned,
+	col.PaddingBoundary(64),
+}
+
+// Block is a single block of a dataset
+type Block struct {
+	IDs    []uint64
+	Values []int64
+}
+
+// Dataset is a sequence of blocks written to a single file
+type Dataset struct {
+	Blocks []Block
+}
+
+// Count returns the number of pairs in the dataset
+func (d Dataset) Count() int {
+	count := 0
+	for _, b := range d.Blocks {
+		count += len(b.IDs)
+	}
+	return count
+}
+
+// RandomDataset generates a dataset with a random number of blocks. IDs are
+// sorted within each block and drawn from dense, sparse or huge ranges; values
+// mix small, negative and extreme numbers. Empty datasets are generated too.
+func RandomDataset(rng *rand.Rand) Dataset {
+	var d Dataset
+	numBlocks := rng.Intn(6)
+	for i := 0; i < numBlocks; i++ {
+		d.Blocks = append(d.Blocks, randomBlock(rng))
+	}
+	return d
+}
+
+// randomBlock generates a single non-empty block
+func randomBlock(rng *rand.Rand) Block {
+	var n int
+	switch rng.Intn(3) {
+	case 0:
+		n = 1 + rng.Intn(4)
+	case 1:
+		n = 1 + rng.Intn(100)
+	default:
+		n = 1 + rng.Intn(2000)
+	}
+
+	b := Block{IDs: make([]uint64, n), Values: make([]int64, n)}
+
+	// IDs
+	idMode := rng.Intn(3)
+	id := uint64(rng.Int63n(1 << 20))
+	if idMode == 2 {
+		id = math.MaxUint64 - uint64(n)*(1<<40)
+	}
+	for i := range b.IDs {
+		b.IDs[i] = id
+		switch idMode {
+		case 0: // Dense
+			id += 1 + uint64(rng.Intn(2))
+		case 1: // Sparse
+			id += 1 + uint64(rng.Int63n(1<<30))
+		default: // Huge
+			id += 1 + uint64(rng.Int63n(1<<40))
+		}
+	}
+
+	// Values
+	valueMode := rng.Intn(3)
+	for i := range b.Values {
+		switch valueMode {
+		case 0: // Small
+			b.Values[i] = rng.Int63n(200) - 100
+		case 1: // Negative
+			b.Values[i] = -rng.Int63n(1 << 40)
+		default: // Extreme
+			switch rng.Intn(4) {
+			case 0:
+				b.Values[i] = math.MaxInt64
+			case 1:
+				b.Values[i] = math.MinInt64
+			default:
+				b.Values[i] = int64(rng.Uint64())
+			}
+		}
+	}
+
+	return b
+}
+
+// Options selects how a dataset is written
+type Options struct {
+	Encoding    uint32
+	Compression uint32
+	Padding     col.PaddingPolicy
+}
+
+// Combinations returns every combination of encoding, compression and padding
+func Combinations() []Options {
+	var combinations []Options
+	for _, encoding := range Encodings {
+		for _, compression := range Compressions {
+			for _, padding := range Paddings {
+				combinations = append(combinations, Options{
+					Encoding:    encoding,
+					Compression: compression,
+					Padding:     padding,
+				})
+			}
+		}
+	}
+	return combinations
+}
+
+// String describes the options for test names
+func (o Options) String() string {
+	return fmt.Sprintf("encoding=%d/compression=%d/padding=%d", o.Encoding, o.Compression, o.Padding.Alignment())
+}
+
+// Write writes the dataset to path, one block per dataset block. The block
+// size target is raised so blocks are never split.
+func Write(path string, d Dataset, opts Options) error {
+	writer, err := col.NewWriter(path,
+		col.WithBlockSize(maxBlockSizeTarget),
+		col.WithEncoding(opts.Encoding),
+		col.WithPadding(opts.Padding))
+	if err != nil {
+		return fmt.Errorf("failed to create writer: %w", err)
+	}
+
+	for i, b := range d.Blocks {
+		err := writer.WriteBlockWithOptions(b.IDs, b.Values, col.WithBlockCompression(opts.Compression))
+		if err != nil {
+			writer.Close()
+			return fmt.Errorf("failed to write block %d: %w", i, err)
+		}
+	}
+
+	if err := writer.FinalizeAndClose(); err != nil {
+		return fmt.Errorf("failed to finalize: %w", err)
+	}
+	return nil
+}
+
+// Verify opens the file at path and checks that everything read back through
+// the public API matches the dataset: block contents, aggregates, file
+// statistics and the global ID bitmap.
+func Verify(path string, d Dataset) error {
+	reader, err := col.NewReader(path)
+	if err != nil {
+		return fmt.Errorf("failed to open reader: %w", err)
+	}
+	defer reader.Close()
+
+	if got := reader.BlockCount(); got != uint64(len(d.Blocks)) {
+		return fmt.Errorf("block count: expected %d, got %d", len(d.Blocks), got)
+	}
+
+	expected := expectedStats(d)
+	distinct := make(map[uint64]struct{})
+
+	for i, b := range d.Blocks {
+		ids, values, err := reader.ReadBlock(col.BlockID(i))
+		if err != nil {
+			return fmt.Errorf("failed to read block %d: %w", i, err)
+		}
+		if err := equalPairs(b, ids, values); err != nil {
+			return fmt.Errorf("block %d: %w", i, err)
+		}
+		for _, id := range b.IDs {
+			distinct[id] = struct{}{}
+		}
+	}
+
+	stats, err := reader.FileStats()
+	if err != nil {
+		return fmt.Errorf("failed to read file statistics: %w", err)
+	}
+	if stats != expected {
+		return fmt.Errorf("file statistics: expected %+v, got %+v", expected, stats)
+	}
+
+	for _, skip := range []bool{false, true} {
+		result := reader.AggregateWithOptions(col.AggregateOptions{SkipPreCalculated: skip})
+		if err := checkAggregate(expected, result); err != nil {
+			return fmt.Errorf("aggregate (skip pre-calculated %v): %w", skip, err)
+		}
+	}
+
+	bitmap, err := reader.GetGlobalIDBitmap()
+	if err != nil {
+		return fmt.Errorf("failed to read global ID bitmap: %w", err)
+	}
+	if got := bitmap.GetCardinality(); got != len(distinct) {
+		return fmt.Errorf("global ID bitmap: expected %d IDs, got %d", len(distinct), got)
+	}
+	for id := range distinct {
+		if !bitmap.Contains(id) {
+			return fmt.Errorf("global ID bitmap: missing ID %d", id)
+		}
+	}
+
+	return nil
+}
+
+// equalPairs compares decoded pairs with the pairs of a block
+func equalPairs(b Block, ids []uint64, values []int64) error {
+	if len(ids) != len(b.IDs) || len(values) != len(b.Values) {
+		return fmt.Errorf("expected %d pairs, got %d IDs and %d values", len(b.IDs), len(ids), len(values))
+	}
+	for i := range b.IDs {
+		if ids[i] != b.IDs[i] {
+			return fmt.Errorf("ID %d: expected %d, got %d", i, b.IDs[i], ids[i])
+		}
+		if values[i] != b.Values[i] {
+			return fmt.Errorf("value %d: expected %d, got %d", i, b.Values[i], values[i])
+		}
+	}
+	return nil
+}
+
+// expectedStats computes the file statistics of a dataset. Sums wrap around
+// like the writer's.
+func expectedStats(d Dataset) col.FileStats {
+	var stats col.FileStats
+	first := true
+	for _, b := range d.Blocks {
+		for i, id := range b.IDs {
+			v := b.Values[i]
+			if first || id < stats.MinID {
+				stats.MinID = id
+			}
+			if first || id > stats.MaxID {
+				stats.MaxID = id
+			}
+			if first || v < stats.MinValue {
+				stats.MinValue = v
+			}
+			if first || v > stats.MaxValue {
+				stats.MaxValue = v
+			}
+			stats.Count++
+			stats.Sum += v
+			first = false
+		}
+	}
+	return stats
+}
+
+// checkAggregate compares an aggregation result with the expected statistics
+func checkAggregate(expected col.FileStats, result col.AggregateResult) error {
+	if result.Count != expected.Count {
+		return fmt.Errorf("count: expected %d, got %d", expected.Count, result.Count)
+	}
+	if expected.Count == 0 {
+		return nil
+	}
+	if result.Min != expected.MinValue || result.Max != expected.MaxValue || result.Sum != expected.Sum {
+		return fmt.Errorf("expected min=%d max=%d sum=%d, got min=%d max=%d sum=%d",
+			expected.MinValue, expected.MaxValue, expected.Sum, result.Min, result.Max, result.Sum)
+	}
+	return nil
+}
+
+// CheckInvariants parses the raw bytes of the file at path and checks the
+// structural invariants of the format spec: header and footer magic numbers,
+// matching block counts, non-overlapping blocks within the file, block headers
+// that agree with their footer entries and sections that fit their block.
+func CheckInvariants(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	if len(data) < headerSize+footerMetaSize {
+		return fmt.Errorf("file too small: %d bytes", len(data))
+	}
+
+	// File header
+	if magic := binary.LittleEndian.Uint64(data[0:8]); magic != col.MagicNumber {
+		return fmt.Errorf("invalid header magic number: 0x%X", magic)
+	}
+	if version := binary.LittleEndian.Uint32(data[8:12]); version != col.Version {
+		return fmt.Errorf("unexpected version: %d", version)
+	}
+	headerBlockCount := binary.LittleEndian.Uint64(data[16:24])
+	bitmapOffset := binary.LittleEndian.Uint64(data[44:52])
+	bitmapSize := binary.LittleEndian.Uint64(data[52:60])
+
+	// Footer metadata
+	metaStart := uint64(len(data) - footerMetaSize)
+	footerSize := binary.LittleEndian.Uint64(data[metaStart:])
+	if magic := binary.LittleEndian.Uint64(data[metaStart+16:]); magic != col.MagicNumber {
+		return fmt.Errorf("invalid footer magic number: 0x%X", magic)
+	}
+	if footerSize < 4 || footerSize > metaStart-headerSize {
+		return fmt.Errorf("invalid footer size: %d", footerSize)
+	}
+	footerStart := metaStart - footerSize
+
+	footerBlockCount := uint64(binary.LittleEndian.Uint32(data[footerStart:]))
+	if footerBlockCount != headerBlockCount {
+		return fmt.Errorf("block count mismatch: header=%d, footer=%d", headerBlockCount, footerBlockCount)
+	}
+	indexEnd := footerStart + 4 + footerBlockCount*footerEntrySize
+	if indexEnd > metaStart {
+		return fmt.Errorf("block index exceeds footer: end=%d, footer end=%d", indexEnd, metaStart)
+	}
+
+	// Blocks
+	prevEnd := uint64(headerSize)
+	for i := uint64(0); i < footerBlockCount; i++ {
+		entry := data[footerStart+4+i*footerEntrySize:]
+		blockOffset := binary.LittleEndian.Uint64(entry[0:8])
+		blockSize := uint64(binary.LittleEndian.Uint32(entry[8:12]))
+
+		if blockOffset < prevEnd {
+			return fmt.Errorf("block %d overlaps its predecessor: offset=%d, previous end=%d", i, blockOffset, prevEnd)
+		}
+		if blockSize < blockHeaderSize+blockLayoutSize || blockOffset+blockSize > footerStart {
+			return fmt.Errorf("block %d out of bounds: offset=%d, size=%d, footer start=%d",
+				i, blockOffset, blockSize, footerStart)
+		}
+		prevEnd = blockOffset + blockSize
+
+		if err := checkBlock(data[blockOffset:blockOffset+blockSize], entry); err != nil {
+			return fmt.Errorf("block %d: %w", i, err)
+		}
+	}
+
+	// Global ID bitmap
+	// The bitmap size includes its 4-byte length prefix
+	if bitmapSize > 0 && (bitmapOffset < prevEnd || bitmapOffset+bitmapSize > footerStart) {
+		return fmt.Errorf("global ID bitmap out of bounds: offset=%d, size=%d", bitmapOffset, bitmapSize)
+	}
+
+	// Optional footer sections
+	for offset := indexEnd; offset < metaStart; {
+		if offset+footerSectionHdr > metaStart {
+			return fmt.Errorf("truncated footer section header at offset %d", offset)
+		}
+		size := uint64(binary.LittleEndian.Uint32(data[offset+4:]))
+		offset += footerSectionHdr + size
+		if offset > metaStart {
+			return fmt.Errorf("footer section exceeds footer: end=%d, footer end=%d", offset, metaStart)
+		}
+	}
+
+	return nil
+}
+
+// checkBlock checks a block against its footer entry
+func checkBlock(block, entry []byte) error {
+	// Statistics and count are stored in the same order in the block header
+	// (offset 0) and in the footer entry (offset 12)
+	for offset := 0; offset < 40; offset += 8 {
+		header := binary.LittleEndian.Uint64(block[offset:])
+		footer := binary.LittleEndian.Uint64(entry[12+offset:])
+		if header != footer {
+			return fmt.Errorf("header field at offset %d does not match footer: %d != %d", offset, header, footer)
+		}
+	}
+	count := binary.LittleEndian.Uint32(block[40:44])
+	if footerCount := binary.LittleEndian.Uint32(entry[52:56]); count != footerCount {
+		return fmt.Errorf("count mismatch: header=%d, footer=%d", count, footerCount)
+	}
+	if count == 0 {
+		return fmt.Errorf("empty block")
+	}
+	if compression := binary.LittleEndian.Uint32(block[48:52]); compression != col.CompressionNone {
+		return fmt.Errorf("unexpected compression type: %d", compression)
+	}
+
+	minID := binary.LittleEndian.Uint64(block[0:8])
+	maxID := binary.LittleEndian.Uint64(block[8:16])
+	minValue := signMagnitude(binary.LittleEndian.Uint64(block[16:24]))
+	maxValue := signMagnitude(binary.LittleEndian.Uint64(block[24:32]))
+	if minID > maxID || minValue > maxValue {
+		return fmt.Errorf("min greater than max: IDs [%d, %d], values [%d, %d]", minID, maxID, minValue, maxValue)
+	}
+
+	// The layout section places the ID section first, followed by the value section
+	layout := block[blockHeaderSize : blockHeaderSize+blockLayoutSize]
+	idOffset := uint64(binary.LittleEndian.Uint32(layout[0:4]))
+	idSize := uint64(binary.LittleEndian.Uint32(layout[4:8]))
+	valueOffset := uint64(binary.LittleEndian.Uint32(layout[8:12]))
+	valueSize := uint64(binary.LittleEndian.Uint32(layout[12:16]))
+	if idOffset != 0 || valueOffset != idSize {
+		return fmt.Errorf("unexpected section layout: ID section at %d (%d bytes), value section at %d",
+			idOffset, idSize, valueOffset)
+	}
+	if blockHeaderSize+blockLayoutSize+valueOffset+valueSize > uint64(len(block)) {
+		return fmt.Errorf("sections exceed block: value section ends at %d, block size %d",
+			blockHeaderSize+blockLayoutSize+valueOffset+valueSize, len(block))
+	}
+
+	// Sections use 8 bytes per entry unless they are varint encoded
+	encoding := binary.LittleEndian.Uint32(block[44:48])
+	fixedIDs, fixedValues := true, true
+	switch encoding {
+	case col.EncodingVarInt, col.EncodingVarIntBoth, col.EncodingDeltaDelta:
+		fixedIDs, fixedValues = false, false
+	case col.EncodingVarIntID:
+		fixedIDs = false
+	case col.EncodingVarIntValue:
+		fixedValues = false
+	}
+	if err := checkSectionSize("ID", idSize, count, fixedIDs); err != nil {
+		return err
+	}
+	return checkSectionSize("value", valueSize, count, fixedValues)
+}
+
+// signMagnitude decodes a value statistic, which the format stores with the
+// sign in the most significant bit and the magnitude in the remaining bits
+func signMagnitude(v uint64) int64 {
+	if v&(1<<63) == 0 {
+		return int64(v)
+	}
+	if v == 1<<63 {
+		return math.MinInt64 // Stored as negative zero
+	}
+	return -int64(v &^ (1 << 63))
+}
+
+// checkSectionSize checks the size of a data section holding count entries
+func checkSectionSize(name string, size uint64, count uint32, fixed bool) error {
+	if fixed && size != uint64(count)*8 {
+		return fmt.Errorf("%s section size: expected %d, got %d", name, uint64(count)*8, size)
+	}
+	// Varints take 1 to 10 bytes each
+	if !fixed && (size < uint64(count) || size > uint64(count)*10) {
+		return fmt.Errorf("%s section size %d out of range for %d varints", name, size, count)
+	}
+	return nil
+}
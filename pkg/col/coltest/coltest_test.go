@@ -0,0 +1,85 @@
+package coltest
+
+import (
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTripAllCombinations(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "coltest")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	const seeds = 10
+	for _, opts := range Combinations() {
+		opts := opts
+		t.Run(opts.String(), func(t *testing.T) {
+			for seed := int64(0); seed < seeds; seed++ {
+				dataset := RandomDataset(rand.New(rand.NewSource(seed)))
+				path := filepath.Join(tempDir, "roundtrip.col")
+
+				require.NoError(t, Write(path, dataset, opts), "seed %d", seed)
+				require.NoError(t, CheckInvariants(path), "seed %d", seed)
+				require.NoError(t, Verify(path, dataset), "seed %d", seed)
+			}
+		})
+	}
+}
+
+func TestCheckInvariantsDetectsCorruption(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "coltest-corrupt")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	path := filepath.Join(tempDir, "corrupt.col")
+	dataset := Dataset{Blocks: []Block{
+		{IDs: []uint64{1, 2, 3}, Values: []int64{10, 20, 30}},
+		{IDs: []uint64{4, 5}, Values: []int64{-1, -2}},
+	}}
+	require.NoError(t, Write(path, dataset, Options{Padding: Paddings[0]}))
+	require.NoError(t, CheckInvariants(path))
+
+	original, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	corruptions := map[string]int{
+		"header magic":     0,
+		"block count":      16,
+		"block min ID":     headerSize,
+		"block count head": headerSize + 40,
+		"footer magic":     len(original) - 8,
+	}
+	for name, offset := range corruptions {
+		data := append([]byte(nil), original...)
+		data[offset] ^= 0xFF
+		require.NoError(t, os.WriteFile(path, data, 0644))
+		require.Error(t, CheckInvariants(path), name)
+	}
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	f.Add(int64(0), uint8(0), uint8(0))
+	f.Add(int64(1), uint8(3), uint8(1))
+	f.Add(int64(42), uint8(7), uint8(2))
+	f.Add(int64(math.MaxInt64), uint8(5), uint8(0))
+
+	tempDir := f.TempDir()
+	f.Fuzz(func(t *testing.T, seed int64, encoding, padding uint8) {
+		opts := Options{
+			Encoding:    Encodings[int(encoding)%len(Encodings)],
+			Compression: Compressions[0],
+			Padding:     Paddings[int(padding)%len(Paddings)],
+		}
+		dataset := RandomDataset(rand.New(rand.NewSource(seed)))
+		path := filepath.Join(tempDir, "fuzz.col")
+
+		require.NoError(t, Write(path, dataset, opts))
+		require.NoError(t, CheckInvariants(path))
+		require.NoError(t, Verify(path, dataset))
+	})
+}
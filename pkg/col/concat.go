@@ -0,0 +1,143 @@
+package col
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Concat stitches the blocks of the source files into a single file at dst
+// without decoding the block data. The sources must use the same encoding and
+// data type, and have non-overlapping ID ranges in increasing order, i.e. every
+// ID of a source must be greater than all IDs of the previous sources. Empty
+// sources are skipped.
+//
+// The global ID bitmaps of the sources are merged and the footer is regenerated
+// with offsets adjusted to the new block positions. The sources are recorded as
+// the lineage of dst, see Reader.Lineage.
+func Concat(dst string, srcs ...string) error {
+	if len(srcs) == 0 {
+		return fmt.Errorf("no source files provided")
+	}
+
+	// Creating dst truncates it, so it must not be one of the sources
+	dstAbs, err := filepath.Abs(dst)
+	if err != nil {
+		return fmt.Errorf("failed to resolve destination path: %w", err)
+	}
+
+	readers := make([]*Reader, 0, len(srcs))
+	defer func() {
+		for _, r := range readers {
+			r.closeInput()
+		}
+	}()
+
+	var prevMaxID uint64
+	var prevSrc string
+	for _, src := range srcs {
+		srcAbs, err := filepath.Abs(src)
+		if err != nil {
+			return fmt.Errorf("failed to resolve source path: %w", err)
+		}
+		if srcAbs == dstAbs {
+			return fmt.Errorf("destination %q is also a source", dst)
+		}
+
+		reader, err := NewReader(src)
+		if err != nil {
+			return fmt.Errorf("failed to open %q: %w", src, err)
+		}
+		readers = append(readers, reader)
+		reader.Advise(AdviceSequential)
+
+		if reader.header.EncodingType != readers[0].header.EncodingType {
+			return fmt.Errorf("encoding mismatch: %q uses %d, %q uses %d",
+				srcs[0], readers[0].header.EncodingType, src, reader.header.EncodingType)
+		}
+
+		if reader.header.ColumnType != readers[0].header.ColumnType {
+			return fmt.Errorf("data type mismatch: %q uses %d, %q uses %d",
+				srcs[0], readers[0].header.ColumnType, src, reader.header.ColumnType)
+		}
+
+		if len(reader.blockIndex) == 0 {
+			continue
+		}
+
+		// Determine the ID range of this source from the footer
+		minID, maxID := reader.blockIndex[0].MinID, reader.blockIndex[0].MaxID
+		for _, entry := range reader.blockIndex[1:] {
+			if entry.MinID < minID {
+				minID = entry.MinID
+			}
+			if entry.MaxID > maxID {
+				maxID = entry.MaxID
+			}
+		}
+
+		if prevSrc != "" && minID <= prevMaxID {
+			return fmt.Errorf("ID ranges overlap: %q starts at %d, but %q ends at %d",
+				src, minID, prevSrc, prevMaxID)
+		}
+		prevMaxID = maxID
+		prevSrc = src
+	}
+
+	lineage := make([]LineageEntry, len(readers))
+	for i, reader := range readers {
+		lineage[i] = reader.lineageEntry(srcs[i])
+	}
+
+	writer, err := NewWriter(dst,
+		WithEncoding(readers[0].header.EncodingType),
+		WithDataType(readers[0].header.ColumnType),
+		WithBlockSize(readers[0].header.BlockSizeTarget),
+		WithLineage(lineage...))
+	if err != nil {
+		return err
+	}
+
+	// A failed concatenation leaves no partial file behind
+	for i, reader := range readers {
+		if err := appendAllRawBlocks(reader, writer); err != nil {
+			writer.Close()
+			os.Remove(dst)
+			return fmt.Errorf("failed to copy blocks of %q: %w", srcs[i], err)
+		}
+	}
+
+	if err := writer.FinalizeAndClose(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return nil
+}
+
+// appendAllRawBlocks appends all blocks of in to the writer without decoding
+// them, and merges the global ID bitmap of in into the writer's bitmap
+func appendAllRawBlocks(in *Reader, w *Writer) error {
+	for blockIdx := range in.blockIndex {
+		data, err := in.readRawBlock(blockIdx)
+		if err != nil {
+			return fmt.Errorf("failed to read block %d: %w", blockIdx, err)
+		}
+
+		stats, err := in.completeBlockStats(blockIdx)
+		if err != nil {
+			return err
+		}
+
+		if err := w.appendRawBlock(data, stats, nil); err != nil {
+			return fmt.Errorf("failed to copy block %d: %w", blockIdx, err)
+		}
+	}
+
+	globalIDs, err := in.GetGlobalIDBitmap()
+	if err != nil {
+		return err
+	}
+	w.globalIDs.Or(globalIDs)
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package col
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcat(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-concat-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	writeFile := func(name string, encoding uint32, blocks ...[]uint64) string {
+		path := filepath.Join(tempDir, name)
+		writer, err := NewWriter(path, WithEncoding(encoding))
+		require.NoError(t, err)
+		for _, ids := range blocks {
+			values := make([]int64, len(ids))
+			for i, id := range ids {
+				values[i] = int64(id) * 10
+			}
+			require.NoError(t, writer.WriteBlock(ids, values))
+		}
+		require.NoError(t, writer.FinalizeAndClose())
+		return path
+	}
+
+	a := writeFile("a.col", EncodingVarIntBoth, []uint64{1, 2, 3}, []uint64{5, 8})
+	empty := writeFile("empty.col", EncodingVarIntBoth)
+	b := writeFile("b.col", EncodingVarIntBoth, []uint64{10, 20})
+
+	dst := filepath.Join(tempDir, "dst.col")
+	require.NoError(t, Concat(dst, a, empty, b))
+
+	reader, err := NewReader(dst)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	assert.Equal(t, uint64(3), reader.BlockCount())
+	ids, values := readAllPairs(t, reader)
+	assert.Equal(t, []uint64{1, 2, 3, 5, 8, 10, 20}, ids)
+	assert.Equal(t, []int64{10, 20, 30, 50, 80, 100, 200}, values)
+
+	assert.Equal(t, aggregateValues(reader.Aggregate()), aggregateValues(reader.AggregateWithOptions(AggregateOptions{SkipPreCalculated: true})))
+
+	bitmap, err := reader.GetGlobalIDBitmap()
+	require.NoError(t, err)
+	assert.Equal(t, ids, bitmap.ToArray())
+
+	t.Run("Lineage", func(t *testing.T) {
+		lineage, err := reader.Lineage()
+		require.NoError(t, err)
+		require.Len(t, lineage, 3)
+
+		for i, src := range []string{a, empty, b} {
+			srcReader, err := NewReader(src)
+			require.NoError(t, err)
+			assert.Equal(t, src, lineage[i].Source)
+			assert.Equal(t, srcReader.HeaderOnly().CreationTime, lineage[i].CreationTime)
+			require.NoError(t, srcReader.Close())
+		}
+		assert.Equal(t, LineageEntry{Source: a, CreationTime: lineage[0].CreationTime, MinID: 1, MaxID: 8, Count: 5}, lineage[0])
+		assert.Equal(t, uint64(0), lineage[1].Count)
+		assert.Equal(t, uint64(10), lineage[2].MinID)
+		assert.Equal(t, uint64(20), lineage[2].MaxID)
+
+		// Files written directly have no lineage
+		srcReader, err := NewReader(a)
+		require.NoError(t, err)
+		defer srcReader.Close()
+		lineage, err = srcReader.Lineage()
+		require.NoError(t, err)
+		assert.Nil(t, lineage)
+	})
+
+	t.Run("Corrupt lineage count", func(t *testing.T) {
+		// A count that cannot fit the section is rejected before allocating
+		payload := make([]byte, uint32Size+lineageEntryFixedSize)
+		binary.LittleEndian.PutUint32(payload, math.MaxUint32)
+		var r Reader
+		assert.Error(t, r.parseLineageSection(payload))
+		assert.Nil(t, r.lineage)
+
+		binary.LittleEndian.PutUint32(payload, 2)
+		assert.Error(t, r.parseLineageSection(payload))
+	})
+
+	t.Run("Overlapping", func(t *testing.T) {
+		overlap := writeFile("overlap.col", EncodingVarIntBoth, []uint64{8, 9})
+		assert.Error(t, Concat(filepath.Join(tempDir, "bad.col"), a, overlap))
+	})
+
+	t.Run("EncodingMismatch", func(t *testing.T) {
+		raw := writeFile("raw.col", EncodingRaw, []uint64{100})
+		assert.Error(t, Concat(filepath.Join(tempDir, "bad.col"), a, raw))
+	})
+
+	t.Run("DestinationIsSource", func(t *testing.T) {
+		assert.Error(t, Concat(a, a, b))
+	})
+
+	t.Run("RemovesOutputOnError", func(t *testing.T) {
+		// The ID section of the only block claims more bytes than the block has
+		corrupt := writeFile("corrupt.col", EncodingVarIntBoth, []uint64{100, 200})
+		r, err := NewReader(corrupt)
+		require.NoError(t, err)
+		data, err := os.ReadFile(corrupt)
+		require.NoError(t, err)
+		binary.LittleEndian.PutUint32(data[r.blockIndex[0].BlockOffset+blockHeaderSize+4:], math.MaxUint32)
+		require.NoError(t, r.Close())
+		require.NoError(t, os.WriteFile(corrupt, data, 0644))
+
+		out := filepath.Join(tempDir, "partial.col")
+		assert.Error(t, Concat(out, a, corrupt))
+		_, err = os.Stat(out)
+		assert.True(t, os.IsNotExist(err), "The partial output should be removed")
+	})
+}
@@ -176,7 +176,7 @@ func TestConcurrentAggregation(t *testing.T) {
 
 	// Verify all results are the same
 	expected := AggregateResult{
-		Count: totalEntries,
+		Count: uint64(totalEntries),
 		Min:   0,                                               // First value in first block
 		Max:   int64((numBlocks-1)*1000 + entriesPerBlock - 1), // Last value in last block
 		Sum:   expectedSum,
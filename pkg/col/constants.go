@@ -1,18 +1,41 @@
 package col
 
+import (
+	"math"
+
+	"vibe-lsm/pkg/col/format"
+)
+
 // Constants for file format
 const (
 	// MagicNumberStr is the string representation of the magic number
 	MagicNumberStr = "VIBE_COL"
 
 	// Size constants
-	headerSize      = 64
-	blockHeaderSize = 64
-	blockLayoutSize = 16
+	headerSize      = format.FileHeaderSize
+	blockHeaderSize = format.BlockHeaderSize
+	blockLayoutSize = format.BlockLayoutSize
+
+	// Footer sizes
+	footerEntrySize         = format.FooterEntrySize
+	footerSectionHeaderSize = format.FooterSectionHeaderSize
+	blockStatsEntrySize     = 16 // Size of a per-block entry in the block statistics section
+	fileStatsSize           = 48 // Size of the file statistics section payload
+	unsignedStatsEntrySize  = 32 // Size of a per-block entry in the unsigned statistics section
+	lineageEntryFixedSize   = 36 // Size of a lineage entry without the source path
+	partitionEntrySize      = 8  // Size of a per-block entry in the partitions section
+	footerMetaSize          = format.FooterMetadataSize
+
+	// Block streaming sizes
+	streamHeaderSize      = 20  // Magic number, stream version, data type and block count
+	streamBlockHeaderSize = 104 // Statistics (100 bytes) and size (4 bytes) preceding a streamed block
 
 	// Default block size (target)
 	defaultBlockSize = 4096 * 4 // 16KB
 
+	// Largest block including its padding, as block sizes are stored as uint32
+	maxBlockSize = math.MaxUint32
+
 	// Field sizes
 	uint32Size = 4
 	uint64Size = 8
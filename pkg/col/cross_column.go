@@ -0,0 +1,64 @@
+package col
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/weaviate/sroar"
+)
+
+// EvaluatePredicates returns the IDs that satisfy the predicate of every file,
+// where each file holds a single column of the same ID space. A nil predicate
+// matches every ID of its file. The files are evaluated from the one with the
+// fewest pairs to the one with the most, each restricted to the IDs matched so
+// far, and evaluation stops as soon as no ID is left.
+func EvaluatePredicates(preds map[*Reader]Pred) (*sroar.Bitmap, error) {
+	if len(preds) == 0 {
+		return nil, fmt.Errorf("no predicates provided")
+	}
+
+	type column struct {
+		reader *Reader
+		count  uint64
+	}
+	columns := make([]column, 0, len(preds))
+	for reader := range preds {
+		stats, err := reader.FileStats()
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column{reader: reader, count: stats.Count})
+	}
+	sort.Slice(columns, func(a, b int) bool { return columns[a].count < columns[b].count })
+
+	var matched *sroar.Bitmap
+	for _, c := range columns {
+		bitmap, err := c.reader.BuildIDBitmap(IDBitmapOptions{Where: preds[c.reader], Filter: matched})
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate predicate %v: %w", preds[c.reader], err)
+		}
+		matched = bitmap
+		if matched.IsEmpty() {
+			break
+		}
+	}
+	return matched, nil
+}
+
+// AggregateWhere aggregates the target file over the IDs that satisfy the
+// predicates of the other files, see EvaluatePredicates. The target may also
+// have a predicate of its own. A footer error of the target is returned even
+// if no ID matches.
+func AggregateWhere(target *Reader, preds map[*Reader]Pred) (AggregateResult, error) {
+	if err := target.ensureFooter(); err != nil {
+		return AggregateResult{}, err
+	}
+	matched, err := EvaluatePredicates(preds)
+	if err != nil {
+		return AggregateResult{}, err
+	}
+	if matched.IsEmpty() {
+		return AggregateResult{}, nil
+	}
+	return target.AggregateWithOptions(AggregateOptions{Filter: matched}), nil
+}
@@ -0,0 +1,83 @@
+package col
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrossColumnPredicates(t *testing.T) {
+	// writeColumn writes a single column over the given IDs
+	writeColumn := func(t *testing.T, ids []uint64, values []int64) *Reader {
+		var buf bytes.Buffer
+		writer, err := NewWriterToBuffer(&buf)
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlock(ids, values))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		reader, err := NewReaderFromBytes(buf.Bytes())
+		require.NoError(t, err)
+		t.Cleanup(func() { reader.Close() })
+		return reader
+	}
+
+	// Three columns of rows 1-6: age, country code and price
+	age := writeColumn(t, []uint64{1, 2, 3, 4, 5, 6}, []int64{17, 25, 31, 45, 52, 19})
+	country := writeColumn(t, []uint64{1, 2, 3, 4, 5, 6}, []int64{1, 2, 1, 1, 2, 1})
+	price := writeColumn(t, []uint64{1, 2, 3, 4, 6}, []int64{100, 200, 300, 400, 600})
+
+	t.Run("Intersection", func(t *testing.T) {
+		matched, err := EvaluatePredicates(map[*Reader]Pred{
+			age:     Ge(Col, Const(18)),
+			country: Eq(Col, Const(1)),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{3, 4, 6}, matched.ToArray())
+
+		// A nil predicate requires the ID to be present in the file
+		matched, err = EvaluatePredicates(map[*Reader]Pred{
+			country: Eq(Col, Const(2)),
+			price:   nil,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{2}, matched.ToArray())
+	})
+
+	t.Run("No match", func(t *testing.T) {
+		matched, err := EvaluatePredicates(map[*Reader]Pred{
+			age:     Gt(Col, Const(100)),
+			country: Eq(Col, Const(1)),
+		})
+		require.NoError(t, err)
+		assert.True(t, matched.IsEmpty())
+
+		result, err := AggregateWhere(price, map[*Reader]Pred{age: Gt(Col, Const(100))})
+		require.NoError(t, err)
+		assert.Equal(t, AggregateResult{}, result)
+	})
+
+	t.Run("Aggregate target column", func(t *testing.T) {
+		result, err := AggregateWhere(price, map[*Reader]Pred{
+			age:     Ge(Col, Const(18)),
+			country: Eq(Col, Const(1)),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), result.Count)
+		assert.Equal(t, int64(300+400+600), result.Sum)
+
+		// The target may filter itself
+		result, err = AggregateWhere(price, map[*Reader]Pred{
+			country: Eq(Col, Const(1)),
+			price:   Lt(Col, Const(500)),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(100+300+400), result.Sum)
+	})
+
+	t.Run("No predicates", func(t *testing.T) {
+		_, err := EvaluatePredicates(nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,133 @@
+package col
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeltaOfDeltaEncode(t *testing.T) {
+	t.Run("Constant stride", func(t *testing.T) {
+		ids := []uint64{1000, 1010, 1020, 1030, 1040}
+		encoded := deltaOfDeltaEncode(ids)
+		assert.Equal(t, []uint64{1000, 10, 0, 0, 0}, encoded)
+		assert.Equal(t, ids, deltaOfDeltaDecode(encoded))
+	})
+
+	t.Run("Jitter and wrap around", func(t *testing.T) {
+		ids := []uint64{0, 5, 9, 15, math.MaxUint64, 3, 3, 2}
+		encoded := deltaOfDeltaEncode(ids)
+		assert.Equal(t, uint64(math.MaxUint64), encoded[2]) // -1
+		assert.Equal(t, ids, deltaOfDeltaDecode(encoded))
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		assert.Empty(t, deltaOfDeltaEncode(nil))
+		assert.Empty(t, deltaOfDeltaDecode(nil))
+	})
+}
+
+func TestDeltaDeltaEncoding(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-delta-delta-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	// Millisecond timestamps sampled every second with a little jitter
+	const count = 1000
+	timestamps := make([]uint64, count)
+	values := make([]int64, count)
+	for i := range timestamps {
+		timestamps[i] = 1_700_000_000_000 + uint64(i)*1000 + uint64(i%3)
+		values[i] = int64(i % 7)
+	}
+
+	writeFile := func(t *testing.T, name string, options ...WriterOption) *Reader {
+		path := filepath.Join(tempDir, name)
+		writer, err := NewWriter(path, append(options, WithBlockSize(1<<20))...)
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlock(timestamps, values))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		reader, err := NewReader(path)
+		require.NoError(t, err)
+		t.Cleanup(func() { reader.Close() })
+		return reader
+	}
+
+	idSectionSize := func(t *testing.T, r *Reader) uint32 {
+		data, err := r.readRawBlock(0)
+		require.NoError(t, err)
+		layout := data[blockHeaderSize:]
+		return readBufferedUint32(layout, 4)
+	}
+
+	t.Run("Round trip", func(t *testing.T) {
+		reader := writeFile(t, "delta-delta.col", WithEncoding(EncodingDeltaDelta))
+		assert.True(t, reader.IsVarIntEncoded())
+
+		ids, readValues, err := reader.ReadBlock(0)
+		require.NoError(t, err)
+		assert.Equal(t, timestamps, ids)
+		assert.Equal(t, values, readValues)
+	})
+
+	t.Run("Smaller than delta varints", func(t *testing.T) {
+		deltaDelta := writeFile(t, "smaller-delta-delta.col", WithEncoding(EncodingDeltaDelta))
+		varIntBoth := writeFile(t, "smaller-varint.col", WithEncoding(EncodingVarIntBoth))
+
+		// The second differences fit into a single byte, the deltas take two
+		assert.Less(t, idSectionSize(t, deltaDelta), idSectionSize(t, varIntBoth)*55/100)
+	})
+
+	t.Run("Size estimate", func(t *testing.T) {
+		writer, err := NewWriter(filepath.Join(tempDir, "estimate.col"), WithEncoding(EncodingDeltaDelta))
+		require.NoError(t, err)
+		defer writer.Close()
+
+		_, _, idSize, err := encodeIDs(timestamps, EncodingDeltaDelta)
+		require.NoError(t, err)
+		_, _, valueSize, err := encodeValues(values, EncodingDeltaDelta, DataTypeInt64)
+		require.NoError(t, err)
+
+		var pairSizes uint64
+		for i := range timestamps {
+			pairSizes += writer.encodedPairSize(timestamps, values, i)
+		}
+		assert.Equal(t, uint64(idSize)+uint64(valueSize), pairSizes)
+	})
+
+	t.Run("Auto selection", func(t *testing.T) {
+		reader := writeFile(t, "auto.col", WithEncoding(EncodingVarIntBoth), WithAutoEncoding())
+		meta, err := reader.BlockMeta(0)
+		require.NoError(t, err)
+		assert.Equal(t, EncodingDeltaDelta, meta.Encoding)
+
+		ids, _, err := reader.ReadBlock(0)
+		require.NoError(t, err)
+		assert.Equal(t, timestamps, ids)
+
+		// IDs without a regular stride keep the writer's encoding
+		path := filepath.Join(tempDir, "auto-irregular.col")
+		writer, err := NewWriter(path, WithEncoding(EncodingVarIntBoth), WithAutoEncoding())
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlock([]uint64{1, 2, 1000, 1001, 50000}, []int64{1, 2, 3, 4, 5}))
+		require.NoError(t, writer.WriteBlockWithOptions(timestamps[:10], values[:10], WithBlockEncoding(EncodingRaw)))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		irregular, err := NewReader(path)
+		require.NoError(t, err)
+		defer irregular.Close()
+		meta, err = irregular.BlockMeta(0)
+		require.NoError(t, err)
+		assert.Equal(t, EncodingVarIntBoth, meta.Encoding)
+
+		// Explicit block encodings are kept
+		meta, err = irregular.BlockMeta(1)
+		require.NoError(t, err)
+		assert.Equal(t, EncodingRaw, meta.Encoding)
+	})
+}
@@ -75,7 +75,7 @@ func TestDenyFilter(t *testing.T) {
 		name        string
 		allowIDs    []uint64
 		denyIDs     []uint64
-		expectCount int
+		expectCount uint64
 		expectMin   int64
 		expectMax   int64
 		expectSum   int64
@@ -182,8 +182,8 @@ func TestDenyFilter(t *testing.T) {
 		})
 	}
 
-	// Test readBlockFiltered with deny filter
-	t.Run("readBlockFiltered with deny filter", func(t *testing.T) {
+	// Test ReadBlockFiltered with deny filter
+	t.Run("ReadBlockFiltered with deny filter", func(t *testing.T) {
 		// Allow filter that matches some IDs in block 1
 		allowFilter := sroar.NewBitmap()
 		for i := uint64(1); i <= 10; i++ {
@@ -198,9 +198,9 @@ func TestDenyFilter(t *testing.T) {
 		denyFilter.Set(8)
 		denyFilter.Set(10)
 
-		ids, values, err := reader.readBlockFiltered(0, allowFilter, denyFilter)
+		ids, values, err := reader.ReadBlockFiltered(0, allowFilter, denyFilter)
 		if err != nil {
-			t.Fatalf("readBlockFiltered failed: %v", err)
+			t.Fatalf("ReadBlockFiltered failed: %v", err)
 		}
 
 		if len(ids) != 5 || len(values) != 5 {
@@ -227,9 +227,9 @@ func TestDenyFilter(t *testing.T) {
 		denyFilter.Set(101)
 		denyFilter.Set(201)
 
-		ids, values, err := reader.readBlockFiltered(0, nil, denyFilter)
+		ids, values, err := reader.ReadBlockFiltered(0, nil, denyFilter)
 		if err != nil {
-			t.Fatalf("readBlockFiltered failed: %v", err)
+			t.Fatalf("ReadBlockFiltered failed: %v", err)
 		}
 
 		if len(ids) != 99 || len(values) != 99 {
@@ -0,0 +1,55 @@
+package col
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeterministicOutput(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// write creates a file with the same content every time it is called
+	write := func(name string, options ...WriterOption) []byte {
+		filePath := filepath.Join(tempDir, name)
+		writer, err := NewWriter(filePath, options...)
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlock([]uint64{1, 2, 3}, []int64{10, -20, 30}))
+		require.NoError(t, writer.WriteBlock([]uint64{5, 8}, []int64{0, 7}))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		data, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+		return data
+	}
+
+	first := write("a.col", WithDeterministicOutput(), WithEncoding(EncodingVarIntBoth))
+
+	// The creation time would differ from the first file without the option
+	time.Sleep(1100 * time.Millisecond)
+	second := write("b.col", WithDeterministicOutput(), WithEncoding(EncodingVarIntBoth))
+	assert.True(t, bytes.Equal(first, second), "identical inputs should produce identical files")
+
+	reader, err := NewReaderFromBytes(first)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), reader.HeaderOnly().CreationTime)
+	require.NoError(t, reader.Close())
+
+	// A fixed timestamp is recorded as is and kept by Finalize
+	fixed := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	reader, err = NewReaderFromBytes(write("c.col", WithFixedTimestamp(fixed)))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(fixed.Unix()), reader.HeaderOnly().CreationTime)
+	require.NoError(t, reader.Close())
+
+	// Times before the epoch are clamped
+	reader, err = NewReaderFromBytes(write("d.col", WithFixedTimestamp(time.Date(1960, 1, 1, 0, 0, 0, 0, time.UTC))))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), reader.HeaderOnly().CreationTime)
+	require.NoError(t, reader.Close())
+}
@@ -0,0 +1,102 @@
+package col
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"unsafe"
+)
+
+// directIOBufferSize is the size of the aligned buffers direct reads go
+// through; larger reads are split into several requests
+const directIOBufferSize = 64 * int(PageSize)
+
+// directBufferPool holds page-aligned buffers of directIOBufferSize bytes
+var directBufferPool = sync.Pool{
+	New: func() interface{} {
+		buf := alignedBuffer(directIOBufferSize)
+		return &buf
+	},
+}
+
+// alignedBuffer allocates a buffer of size bytes that starts on a page
+// boundary, as required for direct I/O
+func alignedBuffer(size int) []byte {
+	buf := make([]byte, size+int(PageSize))
+	offset := int(PageSize) - int(uintptr(unsafe.Pointer(&buf[0]))&uintptr(PageSize-1))
+	if offset == int(PageSize) {
+		offset = 0
+	}
+	return buf[offset : offset+size]
+}
+
+// directFile reads a file opened for direct I/O. The kernel only serves
+// reads at aligned offsets into aligned buffers, so every read is widened to
+// page boundaries, read into a pooled buffer and copied out.
+type directFile struct {
+	file *os.File
+}
+
+// ReadAt implements io.ReaderAt
+func (f *directFile) ReadAt(p []byte, off int64) (int, error) {
+	bufPtr := directBufferPool.Get().(*[]byte)
+	defer directBufferPool.Put(bufPtr)
+	buf := *bufPtr
+
+	n := 0
+	for n < len(p) {
+		pos := off + int64(n)
+		start := pos &^ (PageSize - 1)
+		skip := int(pos - start)
+		want := (skip + len(p) - n + int(PageSize) - 1) &^ int(PageSize-1)
+		if want > len(buf) {
+			want = len(buf)
+		}
+
+		read, err := f.file.ReadAt(buf[:want], start)
+		if read > skip {
+			n += copy(p[n:], buf[skip:read])
+		}
+		if n == len(p) {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		if read < want {
+			return n, io.EOF
+		}
+	}
+	return n, nil
+}
+
+// Close implements io.Closer
+func (f *directFile) Close() error {
+	return f.file.Close()
+}
+
+// openDirectReader opens the file for reading with direct I/O and reads its
+// header. If the platform or file system does not support direct I/O, the
+// file is read through the page cache instead.
+func openDirectReader(filename string) (*Reader, error) {
+	file, err := openDirectFile(filename)
+	if err != nil {
+		return openReader(filename)
+	}
+
+	fileInfo, err := file.file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to get file info: %w", err)
+	}
+
+	return newReader(file, fileInfo.Size())
+}
+
+// DirectIO reports whether the reader bypasses the page cache, see
+// OpenOptions.DirectIO
+func (r *Reader) DirectIO() bool {
+	_, ok := r.file.(*directFile)
+	return ok
+}
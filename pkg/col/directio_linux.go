@@ -0,0 +1,26 @@
+//go:build linux
+
+package col
+
+import (
+	"io"
+	"os"
+	"syscall"
+)
+
+// openDirectFile opens the file with O_DIRECT. Some file systems, e.g. tmpfs,
+// refuse the flag on open and others only on the first read, so a page is
+// read to make sure direct reads work.
+func openDirectFile(filename string) (*directFile, error) {
+	file, err := os.OpenFile(filename, os.O_RDONLY|syscall.O_DIRECT, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	direct := &directFile{file: file}
+	if _, err := direct.ReadAt(make([]byte, 1), 0); err != nil && err != io.EOF {
+		file.Close()
+		return nil, err
+	}
+	return direct, nil
+}
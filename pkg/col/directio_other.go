@@ -0,0 +1,10 @@
+//go:build !linux
+
+package col
+
+import "errors"
+
+// openDirectFile fails; the platform has no O_DIRECT
+func openDirectFile(filename string) (*directFile, error) {
+	return nil, errors.New("direct I/O is not supported on this platform")
+}
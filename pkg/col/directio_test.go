@@ -0,0 +1,76 @@
+package col
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectIO(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "direct.col")
+	writer, err := NewWriter(path)
+	require.NoError(t, err)
+	for b := uint64(0); b < 3; b++ {
+		ids := make([]uint64, 1000)
+		values := make([]int64, 1000)
+		for i := range ids {
+			ids[i] = b*1000 + uint64(i)
+			values[i] = int64(ids[i]) * 3
+		}
+		require.NoError(t, writer.WriteBlock(ids, values))
+	}
+	require.NoError(t, writer.FinalizeAndClose())
+
+	t.Run("Reader", func(t *testing.T) {
+		// Depending on the file system the reader may fall back to regular reads
+		reader, err := NewReaderWithOptions(path, OpenOptions{DirectIO: true})
+		require.NoError(t, err)
+		defer reader.Close()
+		t.Logf("direct I/O: %v", reader.DirectIO())
+
+		value, found, err := reader.Get(2500)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, int64(7500), value)
+
+		result := reader.AggregateWithOptions(AggregateOptions{SkipPreCalculated: true})
+		assert.Equal(t, uint64(3000), result.Count)
+		assert.Equal(t, int64(3*2999*3000/2), result.Sum)
+	})
+
+	t.Run("Unaligned reads", func(t *testing.T) {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		file, err := os.Open(path)
+		require.NoError(t, err)
+		direct := &directFile{file: file}
+		defer direct.Close()
+
+		for _, tc := range []struct{ off, n int }{
+			{0, 1},
+			{1, 4096},
+			{4095, 2},
+			{100, directIOBufferSize + 5000},
+		} {
+			if tc.off+tc.n > len(data) {
+				tc.n = len(data) - tc.off
+			}
+			p := make([]byte, tc.n)
+			n, err := direct.ReadAt(p, int64(tc.off))
+			require.NoError(t, err)
+			assert.Equal(t, tc.n, n)
+			assert.Equal(t, data[tc.off:tc.off+tc.n], p)
+		}
+
+		// Reads past the end return the available bytes and io.EOF
+		p := make([]byte, 10)
+		n, err := direct.ReadAt(p, int64(len(data)-4))
+		assert.Equal(t, io.EOF, err)
+		assert.Equal(t, 4, n)
+		assert.Equal(t, data[len(data)-4:], p[:4])
+	})
+}
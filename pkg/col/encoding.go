@@ -1,11 +1,57 @@
 package col
 
+import (
+	"fmt"
+	"math"
+)
+
 // DeltaEncoder handles delta encoding for a sequence of values
 type DeltaEncoder interface {
 	Encode(values interface{}) interface{}
 	Decode(values interface{}) interface{}
 }
 
+// sectionEncoding describes how a single data section (IDs or values) is encoded
+type sectionEncoding struct {
+	delta        bool // Values are stored as differences to their predecessor
+	deltaOfDelta bool // Values are stored as ZigZag encoded differences of consecutive deltas
+	varint       bool // Values are stored as variable-length integers instead of 8 bytes
+}
+
+// sectionEncodings returns how the ID and value sections are encoded for an
+// encoding type, as described in the format spec
+func sectionEncodings(encodingType uint32) (ids, values sectionEncoding, err error) {
+	switch encodingType {
+	case EncodingRaw:
+		return sectionEncoding{}, sectionEncoding{}, nil
+	case EncodingDeltaID:
+		return sectionEncoding{delta: true}, sectionEncoding{}, nil
+	case EncodingDeltaValue:
+		return sectionEncoding{}, sectionEncoding{delta: true}, nil
+	case EncodingDeltaBoth:
+		return sectionEncoding{delta: true}, sectionEncoding{delta: true}, nil
+	case EncodingVarInt:
+		return sectionEncoding{varint: true}, sectionEncoding{varint: true}, nil
+	case EncodingVarIntID:
+		return sectionEncoding{delta: true, varint: true}, sectionEncoding{}, nil
+	case EncodingVarIntValue:
+		return sectionEncoding{}, sectionEncoding{delta: true, varint: true}, nil
+	case EncodingVarIntBoth:
+		return sectionEncoding{delta: true, varint: true}, sectionEncoding{delta: true, varint: true}, nil
+	case EncodingDeltaDelta:
+		return sectionEncoding{deltaOfDelta: true, varint: true}, sectionEncoding{delta: true, varint: true}, nil
+	default:
+		return sectionEncoding{}, sectionEncoding{}, fmt.Errorf("unsupported encoding type: %d", encodingType)
+	}
+}
+
+// zigzagValues returns whether varint values of a column with the given data
+// type are zigzag encoded. Unsigned columns store plain varints unless the values
+// are delta encoded, as deltas can be negative.
+func zigzagValues(encoding sectionEncoding, dataType uint32) bool {
+	return encoding.delta || dataType != DataTypeUint64
+}
+
 // deltaEncode calculates delta-encoded values from original values
 func deltaEncode(values []uint64) []uint64 {
 	if len(values) == 0 {
@@ -42,6 +88,51 @@ func deltaDecode(deltas []uint64) []uint64 {
 	return result
 }
 
+// deltaOfDeltaEncode calculates delta-of-delta encoded values from original
+// values. The first value is stored as-is, followed by the differences between
+// consecutive deltas, where the delta before the first value is 0. A constant
+// stride thus encodes as the stride followed by zeros.
+func deltaOfDeltaEncode(values []uint64) []uint64 {
+	if len(values) == 0 {
+		return []uint64{}
+	}
+
+	result := make([]uint64, len(values))
+	// First value is stored as-is
+	result[0] = values[0]
+
+	// For remaining values, store the change of the delta. The arithmetic wraps,
+	// the results are ZigZag encoded as int64.
+	var prevDelta uint64
+	for i := 1; i < len(values); i++ {
+		delta := values[i] - values[i-1]
+		result[i] = delta - prevDelta
+		prevDelta = delta
+	}
+
+	return result
+}
+
+// deltaOfDeltaDecode reconstructs original values from delta-of-delta encoded values
+func deltaOfDeltaDecode(deltas []uint64) []uint64 {
+	if len(deltas) == 0 {
+		return []uint64{}
+	}
+
+	result := make([]uint64, len(deltas))
+	// First value is stored as-is
+	result[0] = deltas[0]
+
+	// For remaining values, accumulate the delta and add it to the previous value
+	var delta uint64
+	for i := 1; i < len(deltas); i++ {
+		delta += deltas[i]
+		result[i] = result[i-1] + delta
+	}
+
+	return result
+}
+
 // deltaEncodeInt64 calculates delta-encoded values from original int64 values
 func deltaEncodeInt64(values []int64) []int64 {
 	if len(values) == 0 {
@@ -94,6 +185,11 @@ func uint64ToInt64(value uint64) int64 {
 	if value&(1<<63) == 0 {
 		return int64(value)
 	}
+	// The magnitude of math.MinInt64 does not fit into 63 bits, it is stored as
+	// negative zero
+	if value == 1<<63 {
+		return math.MinInt64
+	}
 	// Handle negative values by converting bits back
 	return ^int64(value&^(1<<63)) + 1
 }
@@ -134,6 +230,16 @@ func encodeVarInt(value uint64) []byte {
 	return result
 }
 
+// varIntSize returns the number of bytes encodeVarInt produces for value
+func varIntSize(value uint64) int {
+	size := 1
+	for value >= 0x80 {
+		value >>= 7
+		size++
+	}
+	return size
+}
+
 // decodeVarInt decodes a variable-length byte array back to uint64
 // It reads bytes until it finds one without the continuation bit set
 func decodeVarInt(data []byte) (uint64, int) {
@@ -187,6 +293,23 @@ func encodeSignedVarInt(value int64) []byte {
 	return encodeVarInt(zigzag)
 }
 
+// encodeUnsignedVarInt encodes the bit pattern of value as a plain varint,
+// without ZigZag encoding
+func encodeUnsignedVarInt(value int64) []byte {
+	return encodeVarInt(uint64(value))
+}
+
+// encodeZigZagVarInt encodes the bit pattern of value as a signed varint, for
+// unsigned data whose entries can wrap around like negative numbers
+func encodeZigZagVarInt(value uint64) []byte {
+	return encodeSignedVarInt(int64(value))
+}
+
+// signedVarIntSize returns the number of bytes encodeSignedVarInt produces for value
+func signedVarIntSize(value int64) int {
+	return varIntSize(uint64((value << 1) ^ (value >> 63)))
+}
+
 // decodeSignedVarInt decodes a variable-length byte array back to int64
 // It first decodes the ZigZag-encoded unsigned integer, then converts it back
 // to a signed integer
@@ -0,0 +1,77 @@
+package col
+
+import "fmt"
+
+// encodingNames are the names of the encoding types, indexed by type
+var encodingNames = []string{
+	EncodingRaw:         "raw",
+	EncodingDeltaID:     "delta-id",
+	EncodingDeltaValue:  "delta-value",
+	EncodingDeltaBoth:   "delta-both",
+	EncodingVarInt:      "varint",
+	EncodingVarIntID:    "varint-id",
+	EncodingVarIntValue: "varint-value",
+	EncodingVarIntBoth:  "varint-both",
+	EncodingDeltaDelta:  "delta-delta",
+}
+
+// SectionEncodingInfo describes how the IDs or the values of a block are encoded
+type SectionEncodingInfo struct {
+	Delta        bool // Entries are stored as differences to their predecessor
+	DeltaOfDelta bool // Entries are stored as differences of consecutive deltas
+	VarInt       bool // Entries are stored as variable-length integers
+}
+
+// FixedSize returns whether every entry takes up 8 bytes, so entries can be
+// located without decoding their predecessors
+func (s SectionEncodingInfo) FixedSize() bool {
+	return !s.VarInt
+}
+
+// EncodingDescription describes an encoding type, see EncodingInfo
+type EncodingDescription struct {
+	Type   uint32
+	Name   string // Short name, e.g. "varint-both"
+	IDs    SectionEncodingInfo
+	Values SectionEncodingInfo
+}
+
+// EncodingInfo describes the encoding type, so tools and query planners can
+// tell how blocks are encoded without comparing the Encoding constants. It
+// fails for unknown encoding types.
+func EncodingInfo(encodingType uint32) (EncodingDescription, error) {
+	ids, values, err := sectionEncodings(encodingType)
+	if err != nil {
+		return EncodingDescription{}, err
+	}
+	return EncodingDescription{
+		Type:   encodingType,
+		Name:   encodingNames[encodingType],
+		IDs:    SectionEncodingInfo{Delta: ids.delta, DeltaOfDelta: ids.deltaOfDelta, VarInt: ids.varint},
+		Values: SectionEncodingInfo{Delta: values.delta, DeltaOfDelta: values.deltaOfDelta, VarInt: values.varint},
+	}, nil
+}
+
+// Encodings returns the descriptions of all encoding types, ordered by type
+func Encodings() []EncodingDescription {
+	descriptions := make([]EncodingDescription, len(encodingNames))
+	for i := range descriptions {
+		// All types up to the last name are known
+		descriptions[i], _ = EncodingInfo(uint32(i))
+	}
+	return descriptions
+}
+
+// BlockEncoding describes the encoding of a block, which may differ from the
+// file default. Only the block header is read.
+func (r *Reader) BlockEncoding(id BlockID) (EncodingDescription, error) {
+	meta, err := r.BlockMeta(id)
+	if err != nil {
+		return EncodingDescription{}, err
+	}
+	description, err := EncodingInfo(meta.Encoding)
+	if err != nil {
+		return EncodingDescription{}, fmt.Errorf("block %d: %w", id, err)
+	}
+	return description, nil
+}
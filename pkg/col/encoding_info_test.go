@@ -0,0 +1,67 @@
+package col
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodingInfo(t *testing.T) {
+	info, err := EncodingInfo(EncodingVarIntID)
+	require.NoError(t, err)
+	assert.Equal(t, EncodingDescription{
+		Type:   EncodingVarIntID,
+		Name:   "varint-id",
+		IDs:    SectionEncodingInfo{Delta: true, VarInt: true},
+		Values: SectionEncodingInfo{},
+	}, info)
+	assert.False(t, info.IDs.FixedSize())
+	assert.True(t, info.Values.FixedSize())
+
+	info, err = EncodingInfo(EncodingDeltaDelta)
+	require.NoError(t, err)
+	assert.Equal(t, "delta-delta", info.Name)
+	assert.True(t, info.IDs.DeltaOfDelta)
+	assert.True(t, info.Values.Delta)
+
+	_, err = EncodingInfo(EncodingDeltaDelta + 1)
+	assert.Error(t, err)
+
+	encodings := Encodings()
+	require.Len(t, encodings, int(EncodingDeltaDelta)+1)
+	names := make(map[string]bool)
+	for i, encoding := range encodings {
+		assert.Equal(t, uint32(i), encoding.Type)
+		assert.NotEmpty(t, encoding.Name)
+		names[encoding.Name] = true
+	}
+	assert.Len(t, names, len(encodings), "names must be unique")
+}
+
+func TestReaderBlockEncoding(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewWriterToBuffer(&buf, WithEncoding(EncodingRaw))
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteBlock([]uint64{1, 2}, []int64{10, 20}))
+	require.NoError(t, writer.WriteBlockWithOptions([]uint64{3, 4}, []int64{30, 40}, WithBlockEncoding(EncodingVarIntBoth)))
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := NewReaderFromBytes(buf.Bytes())
+	require.NoError(t, err)
+	defer reader.Close()
+
+	info, err := reader.BlockEncoding(0)
+	require.NoError(t, err)
+	assert.Equal(t, "raw", info.Name)
+	assert.True(t, info.IDs.FixedSize())
+
+	info, err = reader.BlockEncoding(1)
+	require.NoError(t, err)
+	assert.Equal(t, EncodingVarIntBoth, info.Type)
+	assert.True(t, info.Values.VarInt)
+
+	_, err = reader.BlockEncoding(2)
+	assert.Error(t, err)
+}
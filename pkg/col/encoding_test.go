@@ -380,6 +380,11 @@ func TestVarintEncodingSize(t *testing.T) {
 				c.value, c.expectedSize, len(encoded))
 		}
 
+		if size := varIntSize(c.value); size != c.expectedSize {
+			t.Errorf("Value %d: varIntSize returned %d, expected %d",
+				c.value, size, c.expectedSize)
+		}
+
 		// Verify decoding
 		decoded, bytesRead := decodeVarInt(encoded)
 		if decoded != c.value {
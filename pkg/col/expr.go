@@ -0,0 +1,600 @@
+package col
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Expr is an int64-valued expression over the pairs of a file, built from
+// Col, ID, Const and the arithmetic functions. Expressions are evaluated one
+// block at a time with a tight loop per operator. Arithmetic wraps around on
+// overflow like int64 arithmetic in Go.
+type Expr interface {
+	// eval writes the value of the expression for every pair of the block to out
+	eval(b *exprBlock, out []int64)
+	String() string
+}
+
+// Pred is a boolean expression over the pairs of a file, used to filter the
+// pairs that contribute to an aggregation
+type Pred interface {
+	// eval writes whether the predicate holds for every pair of the block to out
+	eval(b *exprBlock, out []bool)
+	String() string
+}
+
+// exprBlock is the block an expression is evaluated on. It hands out scratch
+// buffers for intermediate results, which are reused across blocks.
+type exprBlock struct {
+	ids      []uint64
+	values   []int64
+	ints     [][]int64
+	bools    [][]bool
+	nextInt  int
+	nextBool int
+}
+
+// reset prepares the block for evaluating a new block of pairs
+func (b *exprBlock) reset(ids []uint64, values []int64) {
+	b.ids = ids
+	b.values = values
+	b.nextInt = 0
+	b.nextBool = 0
+}
+
+// intBuf returns a scratch buffer with one int64 per pair
+func (b *exprBlock) intBuf() []int64 {
+	if b.nextInt == len(b.ints) {
+		b.ints = append(b.ints, nil)
+	}
+	buf := resizeInt64s(b.ints[b.nextInt], len(b.values))
+	b.ints[b.nextInt] = buf
+	b.nextInt++
+	return buf
+}
+
+// boolBuf returns a scratch buffer with one bool per pair
+func (b *exprBlock) boolBuf() []bool {
+	if b.nextBool == len(b.bools) {
+		b.bools = append(b.bools, nil)
+	}
+	buf := b.bools[b.nextBool]
+	if cap(buf) < len(b.values) {
+		buf = make([]bool, len(b.values))
+	}
+	buf = buf[:len(b.values)]
+	b.bools[b.nextBool] = buf
+	b.nextBool++
+	return buf
+}
+
+// releaseInt and releaseBool return the most recently acquired scratch buffer
+func (b *exprBlock) releaseInt()  { b.nextInt-- }
+func (b *exprBlock) releaseBool() { b.nextBool-- }
+
+// colExpr is the value column
+type colExpr struct{}
+
+// idExpr is the ID column
+type idExpr struct{}
+
+var (
+	// Col is the value of a pair
+	Col Expr = colExpr{}
+
+	// ID is the ID of a pair, converted to int64
+	ID Expr = idExpr{}
+)
+
+func (colExpr) eval(b *exprBlock, out []int64) { copy(out, b.values) }
+func (colExpr) String() string                 { return "Col" }
+
+func (idExpr) eval(b *exprBlock, out []int64) {
+	for i, id := range b.ids {
+		out[i] = int64(id)
+	}
+}
+func (idExpr) String() string { return "ID" }
+
+// Const is a constant expression
+type Const int64
+
+func (c Const) eval(b *exprBlock, out []int64) {
+	for i := range out {
+		out[i] = int64(c)
+	}
+}
+func (c Const) String() string { return fmt.Sprintf("%d", int64(c)) }
+
+// arithOp is a binary arithmetic operator
+type arithOp int
+
+const (
+	opAdd arithOp = iota
+	opSub
+	opMul
+	opDiv
+)
+
+var arithOpNames = [...]string{opAdd: "Add", opSub: "Sub", opMul: "Mul", opDiv: "Div"}
+
+// arithExpr applies an arithmetic operator to two expressions
+type arithExpr struct {
+	op          arithOp
+	left, right Expr
+}
+
+// Add returns a + b
+func Add(a, b Expr) Expr { return arithExpr{op: opAdd, left: a, right: b} }
+
+// Sub returns a - b
+func Sub(a, b Expr) Expr { return arithExpr{op: opSub, left: a, right: b} }
+
+// Mul returns a * b
+func Mul(a, b Expr) Expr { return arithExpr{op: opMul, left: a, right: b} }
+
+// Div returns a / b, truncated towards zero. Division by zero yields 0.
+func Div(a, b Expr) Expr { return arithExpr{op: opDiv, left: a, right: b} }
+
+func (e arithExpr) eval(b *exprBlock, out []int64) {
+	e.left.eval(b, out)
+
+	// Constant operands are applied as a scalar, without a scratch buffer
+	if c, ok := e.right.(Const); ok {
+		applyScalar(e.op, out, int64(c))
+		return
+	}
+
+	right := b.intBuf()
+	defer b.releaseInt()
+	e.right.eval(b, right)
+
+	switch e.op {
+	case opAdd:
+		for i := range out {
+			out[i] += right[i]
+		}
+	case opSub:
+		for i := range out {
+			out[i] -= right[i]
+		}
+	case opMul:
+		for i := range out {
+			out[i] *= right[i]
+		}
+	case opDiv:
+		for i := range out {
+			if right[i] == 0 {
+				out[i] = 0
+			} else {
+				out[i] /= right[i]
+			}
+		}
+	}
+}
+
+// applyScalar applies an arithmetic operator with a constant right operand
+func applyScalar(op arithOp, out []int64, c int64) {
+	switch op {
+	case opAdd:
+		for i := range out {
+			out[i] += c
+		}
+	case opSub:
+		for i := range out {
+			out[i] -= c
+		}
+	case opMul:
+		for i := range out {
+			out[i] *= c
+		}
+	case opDiv:
+		if c == 0 {
+			clear(out)
+			return
+		}
+		for i := range out {
+			out[i] /= c
+		}
+	}
+}
+
+func (e arithExpr) String() string {
+	return fmt.Sprintf("%s(%s, %s)", arithOpNames[e.op], e.left, e.right)
+}
+
+// absExpr is the absolute value of an expression
+type absExpr struct {
+	arg Expr
+}
+
+// Abs returns |a|. The absolute value of math.MinInt64 wraps around to itself.
+func Abs(a Expr) Expr { return absExpr{arg: a} }
+
+func (e absExpr) eval(b *exprBlock, out []int64) {
+	e.arg.eval(b, out)
+	for i, v := range out {
+		if v < 0 {
+			out[i] = -v
+		}
+	}
+}
+
+func (e absExpr) String() string { return fmt.Sprintf("Abs(%s)", e.arg) }
+
+// cmpOp is a comparison operator
+type cmpOp int
+
+const (
+	opGt cmpOp = iota
+	opGe
+	opLt
+	opLe
+	opEq
+	opNe
+)
+
+var cmpOpNames = [...]string{opGt: "Gt", opGe: "Ge", opLt: "Lt", opLe: "Le", opEq: "Eq", opNe: "Ne"}
+
+// cmpPred compares two expressions
+type cmpPred struct {
+	op          cmpOp
+	left, right Expr
+}
+
+// Gt holds if a > b
+func Gt(a, b Expr) Pred { return cmpPred{op: opGt, left: a, right: b} }
+
+// Ge holds if a >= b
+func Ge(a, b Expr) Pred { return cmpPred{op: opGe, left: a, right: b} }
+
+// Lt holds if a < b
+func Lt(a, b Expr) Pred { return cmpPred{op: opLt, left: a, right: b} }
+
+// Le holds if a <= b
+func Le(a, b Expr) Pred { return cmpPred{op: opLe, left: a, right: b} }
+
+// Eq holds if a == b
+func Eq(a, b Expr) Pred { return cmpPred{op: opEq, left: a, right: b} }
+
+// Ne holds if a != b
+func Ne(a, b Expr) Pred { return cmpPred{op: opNe, left: a, right: b} }
+
+func (p cmpPred) eval(b *exprBlock, out []bool) {
+	left := b.intBuf()
+	defer b.releaseInt()
+	p.left.eval(b, left)
+
+	// Constant operands are compared as a scalar, without a scratch buffer
+	if c, ok := p.right.(Const); ok {
+		compareScalar(p.op, left, int64(c), out)
+		return
+	}
+
+	right := b.intBuf()
+	defer b.releaseInt()
+	p.right.eval(b, right)
+
+	switch p.op {
+	case opGt:
+		for i := range out {
+			out[i] = left[i] > right[i]
+		}
+	case opGe:
+		for i := range out {
+			out[i] = left[i] >= right[i]
+		}
+	case opLt:
+		for i := range out {
+			out[i] = left[i] < right[i]
+		}
+	case opLe:
+		for i := range out {
+			out[i] = left[i] <= right[i]
+		}
+	case opEq:
+		for i := range out {
+			out[i] = left[i] == right[i]
+		}
+	case opNe:
+		for i := range out {
+			out[i] = left[i] != right[i]
+		}
+	}
+}
+
+// compareScalar compares every value with a constant
+func compareScalar(op cmpOp, left []int64, c int64, out []bool) {
+	switch op {
+	case opGt:
+		for i, v := range left {
+			out[i] = v > c
+		}
+	case opGe:
+		for i, v := range left {
+			out[i] = v >= c
+		}
+	case opLt:
+		for i, v := range left {
+			out[i] = v < c
+		}
+	case opLe:
+		for i, v := range left {
+			out[i] = v <= c
+		}
+	case opEq:
+		for i, v := range left {
+			out[i] = v == c
+		}
+	case opNe:
+		for i, v := range left {
+			out[i] = v != c
+		}
+	}
+}
+
+func (p cmpPred) String() string {
+	return fmt.Sprintf("%s(%s, %s)", cmpOpNames[p.op], p.left, p.right)
+}
+
+// logicPred combines predicates with And or Or
+type logicPred struct {
+	and   bool
+	preds []Pred
+}
+
+// And holds if all predicates hold. And without predicates always holds.
+func And(preds ...Pred) Pred { return logicPred{and: true, preds: preds} }
+
+// Or holds if any predicate holds. Or without predicates never holds.
+func Or(preds ...Pred) Pred { return logicPred{and: false, preds: preds} }
+
+func (p logicPred) eval(b *exprBlock, out []bool) {
+	for i := range out {
+		out[i] = p.and
+	}
+	if len(p.preds) == 0 {
+		return
+	}
+
+	tmp := b.boolBuf()
+	defer b.releaseBool()
+	for _, pred := range p.preds {
+		pred.eval(b, tmp)
+		if p.and {
+			for i := range out {
+				out[i] = out[i] && tmp[i]
+			}
+		} else {
+			for i := range out {
+				out[i] = out[i] || tmp[i]
+			}
+		}
+	}
+}
+
+func (p logicPred) String() string {
+	names := make([]string, len(p.preds))
+	for i, pred := range p.preds {
+		names[i] = pred.String()
+	}
+	op := "Or"
+	if p.and {
+		op = "And"
+	}
+	return fmt.Sprintf("%s(%s)", op, strings.Join(names, ", "))
+}
+
+// notPred negates a predicate
+type notPred struct {
+	pred Pred
+}
+
+// Not holds if p does not hold
+func Not(p Pred) Pred { return notPred{pred: p} }
+
+func (p notPred) eval(b *exprBlock, out []bool) {
+	p.pred.eval(b, out)
+	for i := range out {
+		out[i] = !out[i]
+	}
+}
+
+func (p notPred) String() string { return fmt.Sprintf("Not(%s)", p.pred) }
+
+// Filter restricts an aggregation to the pairs matching a predicate
+type Filter struct {
+	pred Pred
+}
+
+// Where returns a filter for the pairs matching p
+func Where(p Pred) Filter {
+	return Filter{pred: p}
+}
+
+// aggKind is the kind of an aggregation
+type aggKind int
+
+const (
+	aggCount aggKind = iota
+	aggSum
+	aggMin
+	aggMax
+	aggAvg
+)
+
+var aggKindNames = [...]string{aggCount: "Count", aggSum: "Sum", aggMin: "Min", aggMax: "Max", aggAvg: "Avg"}
+
+// Aggregation is an aggregate over an expression, optionally restricted by
+// filters. Multiple filters must all hold.
+type Aggregation struct {
+	kind    aggKind
+	expr    Expr
+	filters []Filter
+}
+
+// Count counts the pairs matching the filters
+func Count(filters ...Filter) Aggregation {
+	return Aggregation{kind: aggCount, filters: filters}
+}
+
+// Sum sums e over the pairs matching the filters
+func Sum(e Expr, filters ...Filter) Aggregation {
+	return Aggregation{kind: aggSum, expr: e, filters: filters}
+}
+
+// Min returns the minimum of e over the pairs matching the filters
+func Min(e Expr, filters ...Filter) Aggregation {
+	return Aggregation{kind: aggMin, expr: e, filters: filters}
+}
+
+// Max returns the maximum of e over the pairs matching the filters
+func Max(e Expr, filters ...Filter) Aggregation {
+	return Aggregation{kind: aggMax, expr: e, filters: filters}
+}
+
+// Avg returns the average of e over the pairs matching the filters
+func Avg(e Expr, filters ...Filter) Aggregation {
+	return Aggregation{kind: aggAvg, expr: e, filters: filters}
+}
+
+// String returns the aggregation in the syntax used to build it
+func (a Aggregation) String() string {
+	var args []string
+	if a.expr != nil {
+		args = append(args, a.expr.String())
+	}
+	for _, f := range a.filters {
+		args = append(args, fmt.Sprintf("Where(%s)", f.pred))
+	}
+	return fmt.Sprintf("%s(%s)", aggKindNames[a.kind], strings.Join(args, ", "))
+}
+
+// ExprResult is the result of an Aggregation
+type ExprResult struct {
+	Count uint64  // Number of pairs matching the filters
+	Value int64   // Result of Count, Sum, Min or Max; 0 if no pairs matched
+	Avg   float64 // Result of Avg; 0 if no pairs matched
+}
+
+// aggState accumulates an aggregation across blocks
+type aggState struct {
+	agg    Aggregation
+	pred   Pred // Combined filters, nil if unfiltered
+	count  uint64
+	value  int64
+	sum    int64
+	seeded bool // Whether value holds a Min or Max yet
+}
+
+// update adds a block to the aggregation
+func (s *aggState) update(b *exprBlock) {
+	var mask []bool
+	if s.pred != nil {
+		mask = b.boolBuf()
+		defer b.releaseBool()
+		s.pred.eval(b, mask)
+	}
+
+	if s.agg.kind == aggCount {
+		if mask == nil {
+			s.count += uint64(len(b.values))
+			return
+		}
+		for _, ok := range mask {
+			if ok {
+				s.count++
+			}
+		}
+		return
+	}
+
+	vals := b.intBuf()
+	defer b.releaseInt()
+	s.agg.expr.eval(b, vals)
+
+	for i, v := range vals {
+		if mask != nil && !mask[i] {
+			continue
+		}
+		s.count++
+		s.sum += v
+		switch s.agg.kind {
+		case aggMin:
+			if !s.seeded || v < s.value {
+				s.value = v
+			}
+			s.seeded = true
+		case aggMax:
+			if !s.seeded || v > s.value {
+				s.value = v
+			}
+			s.seeded = true
+		}
+	}
+}
+
+// result returns the final result of the aggregation
+func (s *aggState) result() ExprResult {
+	result := ExprResult{Count: s.count}
+	switch s.agg.kind {
+	case aggCount:
+		result.Value = int64(s.count)
+	case aggSum:
+		result.Value = s.sum
+	case aggMin, aggMax:
+		result.Value = s.value
+	case aggAvg:
+		if s.count > 0 {
+			result.Avg = float64(s.sum) / float64(s.count)
+		}
+	}
+	return result
+}
+
+// Evaluate computes the aggregations in a single pass over the file and returns
+// one result per aggregation, in the same order. For example
+//
+//	results, err := reader.Evaluate(
+//		Sum(Mul(Col, Const(2))),
+//		Count(Where(Gt(Col, Const(100)))),
+//	)
+func (r *Reader) Evaluate(aggs ...Aggregation) ([]ExprResult, error) {
+	states := make([]*aggState, len(aggs))
+	for i, agg := range aggs {
+		if agg.kind != aggCount && agg.expr == nil {
+			return nil, fmt.Errorf("aggregation %d (%s) has no expression", i, agg)
+		}
+		state := &aggState{agg: agg}
+		switch len(agg.filters) {
+		case 0:
+		case 1:
+			state.pred = agg.filters[0].pred
+		default:
+			preds := make([]Pred, len(agg.filters))
+			for j, f := range agg.filters {
+				preds[j] = f.pred
+			}
+			state.pred = And(preds...)
+		}
+		states[i] = state
+	}
+
+	var block exprBlock
+	err := r.ScanAll(0, func(_ BlockID, ids []uint64, values []int64) error {
+		block.reset(ids, values)
+		for _, state := range states {
+			state.update(&block)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]ExprResult, len(states))
+	for i, state := range states {
+		results[i] = state.result()
+	}
+	return results, nil
+}
@@ -0,0 +1,169 @@
+package col
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluate(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-expr-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	filePath := filepath.Join(tempDir, "expr.col")
+	writer, err := NewWriter(filePath, WithEncoding(EncodingVarIntBoth))
+	require.NoError(t, err)
+
+	var ids []uint64
+	var values []int64
+	for block := 0; block < 5; block++ {
+		blockIDs := make([]uint64, 100)
+		blockValues := make([]int64, 100)
+		for i := range blockIDs {
+			blockIDs[i] = uint64(block*100 + i)
+			blockValues[i] = int64((block*100+i)*7%301 - 150)
+		}
+		require.NoError(t, writer.WriteBlock(blockIDs, blockValues))
+		ids = append(ids, blockIDs...)
+		values = append(values, blockValues...)
+	}
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := NewReader(filePath)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	// expected computes an aggregation with a plain loop over all pairs
+	expected := func(kind aggKind, expr func(id uint64, v int64) int64, pred func(id uint64, v int64) bool) ExprResult {
+		var result ExprResult
+		var sum int64
+		min, max := int64(math.MaxInt64), int64(math.MinInt64)
+		for i, id := range ids {
+			if pred != nil && !pred(id, values[i]) {
+				continue
+			}
+			result.Count++
+			if expr == nil {
+				continue
+			}
+			v := expr(id, values[i])
+			sum += v
+			if v < min {
+				min = v
+			}
+			if v > max {
+				max = v
+			}
+		}
+		switch kind {
+		case aggCount:
+			result.Value = int64(result.Count)
+		case aggSum:
+			result.Value = sum
+		case aggMin:
+			result.Value = min
+		case aggMax:
+			result.Value = max
+		case aggAvg:
+			result.Avg = float64(sum) / float64(result.Count)
+		}
+		return result
+	}
+
+	col := func(_ uint64, v int64) int64 { return v }
+
+	testCases := []struct {
+		agg      Aggregation
+		expected ExprResult
+	}{
+		{
+			agg:      Count(),
+			expected: expected(aggCount, nil, nil),
+		},
+		{
+			agg:      Sum(Mul(Col, Const(2))),
+			expected: expected(aggSum, func(_ uint64, v int64) int64 { return v * 2 }, nil),
+		},
+		{
+			agg:      Count(Where(Gt(Col, Const(100)))),
+			expected: expected(aggCount, nil, func(_ uint64, v int64) bool { return v > 100 }),
+		},
+		{
+			agg: Sum(Add(Col, ID), Where(Lt(ID, Const(250))), Where(Ne(Col, Const(0)))),
+			expected: expected(aggSum, func(id uint64, v int64) int64 { return v + int64(id) },
+				func(id uint64, v int64) bool { return id < 250 && v != 0 }),
+		},
+		{
+			agg: Min(Sub(Col, Mul(ID, Col)), Where(Or(Le(Col, Const(-100)), Ge(Col, Const(140))))),
+			expected: expected(aggMin, func(id uint64, v int64) int64 { return v - int64(id)*v },
+				func(_ uint64, v int64) bool { return v <= -100 || v >= 140 }),
+		},
+		{
+			agg:      Max(Div(ID, Col), Where(Not(Eq(Col, Const(3))))),
+			expected: expected(aggMax, func(id uint64, v int64) int64 { return safeDiv(int64(id), v) }, func(_ uint64, v int64) bool { return v != 3 }),
+		},
+		{
+			agg:      Avg(Col, Where(And(Gt(ID, Const(10)), Lt(Col, ID)))),
+			expected: expected(aggAvg, col, func(id uint64, v int64) bool { return id > 10 && v < int64(id) }),
+		},
+		{
+			agg: Sum(Abs(Sub(Col, Const(20)))),
+			expected: expected(aggSum, func(_ uint64, v int64) int64 {
+				if v < 20 {
+					return 20 - v
+				}
+				return v - 20
+			}, nil),
+		},
+		{
+			agg:      Sum(Div(Col, Const(0))),
+			expected: ExprResult{Count: uint64(len(ids))},
+		},
+	}
+
+	aggs := make([]Aggregation, len(testCases))
+	for i, tc := range testCases {
+		aggs[i] = tc.agg
+	}
+
+	// All aggregations are computed in a single pass
+	results, err := reader.Evaluate(aggs...)
+	require.NoError(t, err)
+	require.Len(t, results, len(testCases))
+	for i, tc := range testCases {
+		assert.Equal(t, tc.expected, results[i], tc.agg.String())
+	}
+
+	// Matches the built-in aggregation
+	results, err = reader.Evaluate(Sum(Col), Min(Col), Max(Col), Avg(Col))
+	require.NoError(t, err)
+	builtin := reader.Aggregate()
+	assert.Equal(t, builtin.Sum, results[0].Value)
+	assert.Equal(t, builtin.Min, results[1].Value)
+	assert.Equal(t, builtin.Max, results[2].Value)
+	assert.InDelta(t, builtin.Avg, results[3].Avg, 1e-9)
+
+	// Empty matches
+	results, err = reader.Evaluate(Min(Col, Where(Gt(Col, Const(1000)))), Avg(Col, Where(Or())))
+	require.NoError(t, err)
+	assert.Equal(t, []ExprResult{{}, {}}, results)
+
+	_, err = reader.Evaluate(Sum(nil))
+	assert.Error(t, err)
+
+	assert.Equal(t, "Count(Where(Gt(Col, 100)))", Count(Where(Gt(Col, Const(100)))).String())
+	assert.Equal(t, "Sum(Mul(Col, 2))", Sum(Mul(Col, Const(2))).String())
+}
+
+// safeDiv divides like Div, yielding 0 for division by zero
+func safeDiv(a, b int64) int64 {
+	if b == 0 {
+		return 0
+	}
+	return a / b
+}
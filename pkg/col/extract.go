@@ -0,0 +1,79 @@
+package col
+
+import (
+	"fmt"
+	"os"
+)
+
+// ExtractBlocks copies the selected blocks of in byte-for-byte into a new file at
+// out, in the given order, and writes a regenerated footer and global ID bitmap.
+// Blocks are not re-encoded; only their IDs are decoded to rebuild the bitmap.
+// If the extraction fails, the file at out is removed.
+func ExtractBlocks(in *Reader, blockIdxs []uint64, out string) error {
+	if err := in.ensureFooter(); err != nil {
+		return err
+	}
+
+	for _, blockIdx := range blockIdxs {
+		if blockIdx >= uint64(len(in.blockIndex)) {
+			return fmt.Errorf("invalid block index: %d", blockIdx)
+		}
+	}
+
+	writer, err := NewWriter(out,
+		WithEncoding(in.header.EncodingType),
+		WithDataType(in.header.ColumnType),
+		WithBlockSize(in.header.BlockSizeTarget))
+	if err != nil {
+		return err
+	}
+
+	// A failed extraction leaves no partial file behind
+	if err := copyRawBlocks(in, blockIdxs, writer); err != nil {
+		writer.Close()
+		os.Remove(out)
+		return err
+	}
+	if err := writer.FinalizeAndClose(); err != nil {
+		os.Remove(out)
+		return err
+	}
+	return nil
+}
+
+// copyRawBlocks appends the given blocks of in to the writer without re-encoding.
+// The writer must use the same encoding and data type as the reader.
+func copyRawBlocks(in *Reader, blockIdxs []uint64, w *Writer) error {
+	if in.header.EncodingType != w.encodingType {
+		return fmt.Errorf("encoding mismatch: source uses %d, destination uses %d",
+			in.header.EncodingType, w.encodingType)
+	}
+	if in.header.ColumnType != w.dataType {
+		return fmt.Errorf("data type mismatch: source uses %d, destination uses %d",
+			in.header.ColumnType, w.dataType)
+	}
+
+	for _, blockIdx := range blockIdxs {
+		data, err := in.readRawBlock(int(blockIdx))
+		if err != nil {
+			return fmt.Errorf("failed to read block %d: %w", blockIdx, err)
+		}
+
+		// The IDs are needed for the global ID bitmap
+		ids, err := in.ReadBlockIDs(BlockID(blockIdx))
+		if err != nil {
+			return fmt.Errorf("failed to decode block %d: %w", blockIdx, err)
+		}
+
+		stats, err := in.completeBlockStats(int(blockIdx))
+		if err != nil {
+			return err
+		}
+
+		if err := w.appendRawBlock(data, stats, ids); err != nil {
+			return fmt.Errorf("failed to copy block %d: %w", blockIdx, err)
+		}
+	}
+
+	return nil
+}
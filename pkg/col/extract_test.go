@@ -0,0 +1,98 @@
+package col
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractBlocks(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-extract-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	for _, encoding := range []uint32{EncodingRaw, EncodingDeltaBoth, EncodingVarIntBoth} {
+		inPath := filepath.Join(tempDir, "in.col")
+		writer, err := NewWriter(inPath, WithEncoding(encoding))
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlock([]uint64{1, 2, 3}, []int64{10, -20, 30}))
+		require.NoError(t, writer.WriteBlock([]uint64{4, 5}, []int64{40, 0}))
+		require.NoError(t, writer.WriteBlock([]uint64{6, 7, 8, 9}, []int64{60, 70, 80, 90}))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		in, err := NewReader(inPath)
+		require.NoError(t, err)
+
+		// Extract the last and the first block, in that order
+		outPath := filepath.Join(tempDir, "out.col")
+		require.NoError(t, ExtractBlocks(in, []uint64{2, 0}, outPath))
+
+		out, err := NewReader(outPath)
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), out.BlockCount())
+		assert.Equal(t, encoding, out.EncodingType())
+
+		ids, values, err := out.GetPairs(0)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{6, 7, 8, 9}, ids)
+		assert.Equal(t, []int64{60, 70, 80, 90}, values)
+
+		ids, values, err = out.GetPairs(1)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{1, 2, 3}, ids)
+		assert.Equal(t, []int64{10, -20, 30}, values)
+
+		// Footer statistics must match the data
+		assert.Equal(t, aggregateValues(out.Aggregate()), aggregateValues(out.AggregateWithOptions(AggregateOptions{SkipPreCalculated: true})))
+		stats, err := out.Stats()
+		require.NoError(t, err)
+		assert.True(t, stats.FromMetadata)
+		assert.Equal(t, uint64(1), stats.NegativeCount)
+
+		// The global ID bitmap only contains the extracted IDs
+		bitmap, err := out.GetGlobalIDBitmap()
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{1, 2, 3, 6, 7, 8, 9}, bitmap.ToArray())
+
+		// Invalid block indexes are rejected
+		assert.Error(t, ExtractBlocks(in, []uint64{3}, outPath))
+
+		require.NoError(t, out.Close())
+		require.NoError(t, in.Close())
+	}
+}
+
+func TestExtractBlocksRemovesOutputOnError(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-extract-error-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	inPath := filepath.Join(tempDir, "in.col")
+	writer, err := NewWriter(inPath, WithChecksum(ChecksumNone))
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteBlock([]uint64{1, 2}, []int64{10, 20}))
+	require.NoError(t, writer.WriteBlock([]uint64{3, 4}, []int64{30, 40}))
+	require.NoError(t, writer.FinalizeAndClose())
+
+	// Block 1 gets an ID section size of 0, so its IDs cannot be decoded
+	in, err := NewReader(inPath)
+	require.NoError(t, err)
+	data, err := os.ReadFile(inPath)
+	require.NoError(t, err)
+	binary.LittleEndian.PutUint32(data[in.blockIndex[1].BlockOffset+blockHeaderSize+4:], 0)
+	require.NoError(t, in.Close())
+	require.NoError(t, os.WriteFile(inPath, data, 0644))
+
+	in, err = NewReader(inPath)
+	require.NoError(t, err)
+	defer in.Close()
+
+	outPath := filepath.Join(tempDir, "out.col")
+	assert.Error(t, ExtractBlocks(in, []uint64{0, 1}, outPath))
+	_, err = os.Stat(outPath)
+	assert.True(t, os.IsNotExist(err), "The partial output should be removed")
+}
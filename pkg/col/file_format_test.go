@@ -167,3 +167,43 @@ func TestFileFormatDebug(t *testing.T) {
 		}
 	}
 }
+
+func TestFinalizeRewritesHeaderInPlace(t *testing.T) {
+	tempFile := t.TempDir() + "/header.col"
+
+	writer, err := NewWriter(tempFile, WithEncoding(EncodingVarIntBoth), WithBlockSize(8192))
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	if err := writer.WriteBlock([]uint64{1, 2, 3}, []int64{10, 20, 30}); err != nil {
+		t.Fatalf("Failed to write block: %v", err)
+	}
+
+	initial, err := os.ReadFile(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+
+	if err := writer.FinalizeAndClose(); err != nil {
+		t.Fatalf("Failed to finalize file: %v", err)
+	}
+	final, err := os.ReadFile(tempFile)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+
+	// Only the block count (16:24) and the bitmap location (44:60) may change
+	for _, r := range [][2]int{{0, 16}, {24, 44}, {60, headerSize}} {
+		for i := r[0]; i < r[1]; i++ {
+			if initial[i] != final[i] {
+				t.Fatalf("Header byte %d changed on finalize: 0x%02X -> 0x%02X", i, initial[i], final[i])
+			}
+		}
+	}
+	if blockCount := binary.LittleEndian.Uint64(final[16:24]); blockCount != 1 {
+		t.Errorf("Expected block count 1, got %d", blockCount)
+	}
+	if bitmapOffset := binary.LittleEndian.Uint64(final[44:52]); bitmapOffset == 0 {
+		t.Errorf("Expected the bitmap offset to be set")
+	}
+}
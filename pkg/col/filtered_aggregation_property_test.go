@@ -191,7 +191,7 @@ func TestFilteredAggregationProperties(t *testing.T) {
 		emptyFilter := sroar.NewBitmap()
 		result := reader.AggregateWithOptions(AggregateOptions{Filter: emptyFilter})
 
-		assert.Equal(t, 0, result.Count, "Empty filter should return count of 0")
+		assert.Equal(t, uint64(0), result.Count, "Empty filter should return count of 0")
 		assert.Equal(t, int64(0), result.Min, "Empty filter should return min of 0")
 		assert.Equal(t, int64(0), result.Max, "Empty filter should return max of 0")
 		assert.Equal(t, int64(0), result.Sum, "Empty filter should return sum of 0")
@@ -241,7 +241,7 @@ func TestFilteredAggregationProperties(t *testing.T) {
 		result := reader.AggregateWithOptions(AggregateOptions{Filter: filter})
 
 		// Compare with manual calculation
-		assert.Equal(t, count, result.Count, "Count should match manual calculation")
+		assert.Equal(t, uint64(count), result.Count, "Count should match manual calculation")
 		assert.Equal(t, min, result.Min, "Min should match manual calculation")
 		assert.Equal(t, max, result.Max, "Max should match manual calculation")
 		assert.Equal(t, sum, result.Sum, "Sum should match manual calculation")
@@ -1,6 +1,7 @@
 package col
 
 import (
+	"bytes"
 	"os"
 	"testing"
 
@@ -74,7 +75,7 @@ func TestFilteredAggregation(t *testing.T) {
 	tests := []struct {
 		name        string
 		filterIDs   []uint64
-		expectCount int
+		expectCount uint64
 		expectMin   int64
 		expectMax   int64
 		expectSum   int64
@@ -237,17 +238,17 @@ func TestFilteredAggregation(t *testing.T) {
 		}
 	})
 
-	// Test readBlockFiltered
-	t.Run("readBlockFiltered", func(t *testing.T) {
+	// Test ReadBlockFiltered
+	t.Run("ReadBlockFiltered", func(t *testing.T) {
 		// Filter that matches some IDs in block 1
 		filter := sroar.NewBitmap()
 		filter.Set(10)
 		filter.Set(20)
 		filter.Set(30)
 
-		ids, values, err := reader.readBlockFiltered(0, filter, nil)
+		ids, values, err := reader.ReadBlockFiltered(0, filter, nil)
 		if err != nil {
-			t.Fatalf("readBlockFiltered failed: %v", err)
+			t.Fatalf("ReadBlockFiltered failed: %v", err)
 		}
 
 		if len(ids) != 3 || len(values) != 3 {
@@ -284,3 +285,90 @@ func floatEquals(a, b, tolerance float64) bool {
 	}
 	return diff <= tolerance
 }
+
+// TestEmptyAggregateResults tests that all aggregation paths return the zero
+// result when no values match
+func TestEmptyAggregateResults(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewWriterToBuffer(&buf, WithBlockSize(512))
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	ids := make([]uint64, 200)
+	values := make([]int64, 200)
+	for i := range ids {
+		ids[i] = uint64(i * 2)
+		values[i] = int64(i) - 100
+	}
+	if err := writeAllBlocks(writer, ids, values); err != nil {
+		t.Fatalf("Failed to write blocks: %v", err)
+	}
+	if err := writer.FinalizeAndClose(); err != nil {
+		t.Fatalf("Failed to finalize: %v", err)
+	}
+
+	reader, err := NewReaderFromBytes(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to open reader: %v", err)
+	}
+	defer reader.Close()
+	if reader.BlockCount() < 2 {
+		t.Fatalf("Expected multiple blocks, got %d", reader.BlockCount())
+	}
+
+	// Odd IDs fall within the ID range of the blocks, but none are stored
+	oddIDs := sroar.NewBitmap()
+	for id := uint64(1); id < 400; id += 2 {
+		oddIDs.Set(id)
+	}
+	allIDs, err := reader.GetGlobalIDBitmap()
+	if err != nil {
+		t.Fatalf("Failed to read global IDs: %v", err)
+	}
+
+	cases := map[string]AggregateOptions{
+		"Filter without matches":          {Filter: oddIDs},
+		"Deny filter denying all":         {DenyFilter: allIDs},
+		"Parallel filter without matches": {Filter: oddIDs, Parallel: 4},
+		"Parallel deny filter":            {DenyFilter: allIDs, Parallel: 4, SkipPreCalculated: true},
+		"Filter outside the ID range":     {Filter: sroar.NewBitmap()},
+	}
+	for name, opts := range cases {
+		if result := reader.AggregateWithOptions(opts); result != (AggregateResult{}) || result.Valid() {
+			t.Errorf("%s: expected the zero result, got %+v", name, result)
+		}
+	}
+
+	// A file without blocks, with and without pre-calculated statistics
+	var emptyBuf bytes.Buffer
+	emptyWriter, err := NewWriterToBuffer(&emptyBuf)
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	if err := emptyWriter.FinalizeAndClose(); err != nil {
+		t.Fatalf("Failed to finalize: %v", err)
+	}
+	empty, err := NewReaderFromBytes(emptyBuf.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to open reader: %v", err)
+	}
+	defer empty.Close()
+
+	for _, opts := range []AggregateOptions{{}, {SkipPreCalculated: true}, {Parallel: 2}} {
+		if result := empty.AggregateWithOptions(opts); result != (AggregateResult{}) {
+			t.Errorf("Empty file with %+v: expected the zero result, got %+v", opts, result)
+		}
+	}
+	stats, err := empty.Stats()
+	if err != nil {
+		t.Fatalf("Failed to compute stats: %v", err)
+	}
+	if stats.AggregateResult != (AggregateResult{}) {
+		t.Errorf("Empty file stats: expected the zero result, got %+v", stats.AggregateResult)
+	}
+
+	// Non-empty results are valid
+	if result := reader.Aggregate(); !result.Valid() || result.Min != -100 {
+		t.Errorf("Expected a valid result with min -100, got %+v", result)
+	}
+}
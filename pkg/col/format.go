@@ -2,7 +2,11 @@
 package col
 
 import (
+	"errors"
+	"math/big"
 	"time"
+
+	"vibe-lsm/pkg/col/format"
 )
 
 const (
@@ -13,7 +17,11 @@ const (
 	Version uint32 = 1
 
 	// Data types
-	DataTypeInt64 uint32 = 0
+	DataTypeInt64           uint32 = 0
+	DataTypeBool            uint32 = 9  // Packed bits, see section 4.2.6 of the format spec
+	DataTypeUint64          uint32 = 11 // Unsigned values, see section 6.4.3 of the format spec
+	DataTypeInt64List       uint32 = 12 // Lists of int64 values per ID, see section 4.2.5 of the format spec
+	DataTypeTimestampMillis uint32 = 13 // Milliseconds since the Unix epoch, see section 6.4.3 of the format spec
 
 	// Encoding types
 	EncodingRaw         uint32 = 0
@@ -24,76 +32,215 @@ const (
 	EncodingVarIntID    uint32 = 5 // Variable-length encoding for IDs
 	EncodingVarIntValue uint32 = 6 // Variable-length encoding for values
 	EncodingVarIntBoth  uint32 = 7 // Variable-length encoding for both IDs and values
+	EncodingDeltaDelta  uint32 = 8 // Delta-of-delta encoding for IDs, otherwise like EncodingVarIntBoth
 
 	// Compression types
 	CompressionNone uint32 = 0
+
+	// Footer section types
+	FooterSectionBlockStats    uint32 = 1 // Extended per-block statistics
+	FooterSectionFileStats     uint32 = 2 // File-level statistics
+	FooterSectionUnsignedStats uint32 = 3 // Per-block statistics of unsigned columns
+	FooterSectionLineage       uint32 = 4 // Source files of a merged file
+	FooterSectionValueOrder    uint32 = 5 // Per-block permutations sorting the values
+	FooterSectionPartitions    uint32 = 6 // Per-block partition keys
+	FooterSectionDataFile      uint32 = 7 // Size of the data file described by a sidecar
+	FooterSectionChecksum      uint32 = 8 // Checksum of the footer bytes before the section
+
+	// Checksum algorithms of the file checksum
+	ChecksumNone     = format.ChecksumNone
+	ChecksumCRC64ISO = format.ChecksumCRC64ISO // Default
+	ChecksumCRC32C   = format.ChecksumCRC32C   // Hardware-accelerated on amd64 and arm64
+	ChecksumXXHash64 = format.ChecksumXXHash64 // Fastest in software
+)
+
+// The structures stored in a file are defined with their binary encoding in
+// the format package
+type (
+	FileHeader          = format.FileHeader
+	BlockHeader         = format.BlockHeader
+	BlockLayout         = format.BlockLayout
+	FooterEntry         = format.FooterEntry
+	FooterSectionHeader = format.FooterSectionHeader
+	FooterMetadata      = format.FooterMetadata
 )
 
-// FileHeader represents the header of a column file
-type FileHeader struct {
-	Magic           uint64
-	Version         uint32
-	ColumnType      uint32
-	BlockCount      uint64
-	BlockSizeTarget uint32
-	CompressionType uint32
-	EncodingType    uint32
-	CreationTime    uint64
-	BitmapOffset    uint64 // Offset to the global ID bitmap
-	BitmapSize      uint64 // Size of the global ID bitmap in bytes
-	// Reserved space - fills up to 64 bytes
-}
-
-// BlockHeader represents the header of a block
-type BlockHeader struct {
-	MinID            uint64
-	MaxID            uint64
-	MinValue         uint64 // Stored as uint64, but represents int64
-	MaxValue         uint64 // Stored as uint64, but represents int64
-	Sum              uint64 // Stored as uint64, but represents int64
-	Count            uint32
-	EncodingType     uint32
-	CompressionType  uint32
-	UncompressedSize uint32
-	CompressedSize   uint32
-	Checksum         uint64
-	// Reserved space - fills up to 64 bytes
-}
-
-// BlockLayout represents the layout of a block
-type BlockLayout struct {
-	IDSectionOffset    uint32
-	IDSectionSize      uint32
-	ValueSectionOffset uint32
-	ValueSectionSize   uint32
-}
-
-// FooterEntry represents an entry in the footer
-type FooterEntry struct {
-	BlockOffset uint64
-	BlockSize   uint32
+// ExtendedBlockStats holds per-block statistics stored in the block statistics footer section
+type ExtendedBlockStats struct {
+	SumSquares    float64 // Sum of squared values, stored as float64 to avoid overflow
+	NegativeCount uint32  // Number of values < 0
+	ZeroCount     uint32  // Number of values == 0
+}
+
+// FileStats holds file-level statistics stored in the file statistics footer section
+type FileStats struct {
+	Count    uint64 // Total number of values in the file
+	MinID    uint64
+	MaxID    uint64
+	MinValue int64
+	MaxValue int64
+	Sum      int64
+}
+
+// LineageEntry describes a source file that a file was produced from, e.g. by
+// Concat, as stored in the lineage footer section
+type LineageEntry struct {
+	Source       string // Path of the source file as passed to the producing operation
+	CreationTime uint64 // Creation time recorded in the header of the source file
+	MinID        uint64 // Minimum ID of the source file, 0 if it is empty
+	MaxID        uint64 // Maximum ID of the source file, 0 if it is empty
+	Count        uint64 // Number of values in the source file
+}
+
+// BlockMeta describes a block as recorded in the footer index and the block header
+type BlockMeta struct {
+	ID          BlockID
+	Offset      uint64 // Offset of the block header in the file
+	Size        uint32 // Size of the block in bytes, including padding
 	MinID       uint64
 	MaxID       uint64
-	MinValue    uint64 // Stored as uint64, but represents int64
-	MaxValue    uint64 // Stored as uint64, but represents int64
-	Sum         uint64 // Stored as uint64, but represents int64
+	MinValue    int64
+	MaxValue    int64
+	Sum         int64
 	Count       uint32
+	Encoding    uint32 // Encoding type of the block, may differ from the file's
+	Compression uint32 // Compression type of the block
+	Partition   uint64 // Partition key of the block, 0 if none was set
 }
 
-// FooterMetadata represents the metadata at the end of the footer
-type FooterMetadata struct {
-	FooterSize uint64
+// FooterInfo describes the footer of a file
+type FooterInfo struct {
+	Offset     uint64 // Offset of the footer in the file
+	Size       uint64 // Size of the footer, excluding the 24-byte footer metadata
 	Checksum   uint64
-	Magic      uint64
+	BlockCount uint64
+	Sections   []FooterSectionHeader // Optional sections in file order, including unknown ones
 }
 
 // AggregateResult represents the result of an aggregation
 type AggregateResult struct {
-	Count int
+	Count uint64
 	Min   int64
 	Max   int64
 	Sum   int64
 	Avg   float64
+
+	// Source tells whether the result was computed from the footer
+	// statistics or by reading blocks, and BlocksScanned how many blocks
+	// were read and decoded for it. Like the other fields, they are 0 for
+	// results without values.
+	Source        AggregateSource
+	BlocksScanned uint64
+
+	// SkippedBlocks lists the blocks that could not be read, see
+	// AggregateOptions.OnError. If it is set, the result is partial. It is a
+	// pointer so that results remain comparable.
+	SkippedBlocks *BlockErrors
+
+	// FooterErr is the error of loading the footer of a lazily opened file,
+	// see NewReaderLazy. If it is set, no blocks were aggregated, whatever
+	// AggregateOptions.OnError.
+	FooterErr error
+}
+
+// Valid returns whether any values were aggregated. Min, Max, Sum and Avg of
+// a result that is not valid are 0, whichever way it was computed.
+func (r AggregateResult) Valid() bool {
+	return r.Count > 0
+}
+
+// Err returns the footer error or the errors of the skipped blocks, or nil if
+// all blocks were aggregated
+func (r AggregateResult) Err() error {
+	if r.FooterErr != nil {
+		return r.FooterErr
+	}
+	if r.SkippedBlocks == nil {
+		return nil
+	}
+	return *r.SkippedBlocks
+}
+
+// newAggregateResult returns the result of aggregating count values with the
+// given extremes and sum. Without values, it is the zero AggregateResult, so
+// the identity values of a running minimum and maximum are not returned.
+func newAggregateResult(count uint64, min, max, sum int64) AggregateResult {
+	if count == 0 {
+		return AggregateResult{}
+	}
+	return AggregateResult{
+		Count: count,
+		Min:   min,
+		Max:   max,
+		Sum:   sum,
+		Avg:   float64(sum) / float64(count),
+	}
+}
+
+// MergeAggregates combines the results of aggregations over disjoint sets of
+// values, e.g. of different files, into the result over all of them. Results
+// with a Count of 0 are ignored whatever their Min and Max, and the average is
+// recomputed from the merged sum and count. The scanned and skipped blocks and
+// the footer errors of all results are kept, and the merged source is the most
+// expensive one.
+// Merging no non-empty results returns the zero AggregateResult, apart from
+// these.
+func MergeAggregates(results ...AggregateResult) AggregateResult {
+	var merged AggregateResult
+	for _, result := range results {
+		merged.SkippedBlocks = mergeBlockErrors(merged.SkippedBlocks, result.SkippedBlocks)
+		if result.FooterErr != nil {
+			merged.FooterErr = errors.Join(merged.FooterErr, result.FooterErr)
+		}
+		merged.BlocksScanned += result.BlocksScanned
+		if result.Source > merged.Source {
+			merged.Source = result.Source
+		}
+		if result.Count == 0 {
+			continue
+		}
+		if merged.Count == 0 || result.Min < merged.Min {
+			merged.Min = result.Min
+		}
+		if merged.Count == 0 || result.Max > merged.Max {
+			merged.Max = result.Max
+		}
+		merged.Count += result.Count
+		merged.Sum += result.Sum
+	}
+	if merged.Count > 0 {
+		merged.Avg = float64(merged.Sum) / float64(merged.Count)
+	}
+	return merged
+}
+
+// UnsignedAggregateResult represents the result of an aggregation over an
+// unsigned column. The sum is kept as a 128-bit integer, so it never wraps.
+type UnsignedAggregateResult struct {
+	Count   uint64
+	Min     uint64
+	Max     uint64
+	Sum     uint64 // Low 64 bits of the sum
+	SumHigh uint64 // High 64 bits of the sum, non-zero if the sum exceeds uint64
+	Avg     float64
+}
+
+// Valid returns whether any values were aggregated. All other fields of a
+// result that is not valid are 0.
+func (r UnsignedAggregateResult) Valid() bool {
+	return r.Count > 0
+}
+
+// Overflowed returns whether the sum does not fit into Sum alone
+func (r UnsignedAggregateResult) Overflowed() bool {
+	return r.SumHigh != 0
+}
+
+// SumBig returns the full sum
+func (r UnsignedAggregateResult) SumBig() *big.Int {
+	sum := new(big.Int).SetUint64(r.SumHigh)
+	sum.Lsh(sum, 64)
+	return sum.Or(sum, new(big.Int).SetUint64(r.Sum))
 }
 
 // NewFileHeader creates a new file header with default values
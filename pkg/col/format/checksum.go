@@ -0,0 +1,65 @@
+package format
+
+import (
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"hash/crc64"
+)
+
+// Checksum algorithms, recorded in FileHeader.ChecksumType
+const (
+	ChecksumNone     uint32 = 0 // No checksum, the footer checksum is 0
+	ChecksumCRC64ISO uint32 = 1 // CRC-64 with the ISO polynomial
+	ChecksumCRC32C   uint32 = 2 // CRC-32 with the Castagnoli polynomial
+	ChecksumXXHash64 uint32 = 3 // XXH64 with seed 0
+)
+
+// The tables are built once, as building them is much more expensive than
+// checksumming a small file
+var (
+	crc64ISOTable = crc64.MakeTable(crc64.ISO)
+	crc32CTable   = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// NewChecksum returns a new hash computing the file checksum with the given
+// algorithm. CRC-32C uses the CPU's CRC instructions where available.
+// ChecksumNone is not a hash and returns an error.
+func NewChecksum(checksumType uint32) (hash.Hash64, error) {
+	switch checksumType {
+	case ChecksumCRC64ISO:
+		return crc64.New(crc64ISOTable), nil
+	case ChecksumCRC32C:
+		return crc32Hash{crc32.New(crc32CTable)}, nil
+	case ChecksumXXHash64:
+		return newXXHash64(), nil
+	default:
+		return nil, fmt.Errorf("unsupported checksum type: %d", checksumType)
+	}
+}
+
+// Checksum returns the checksum of data with the given algorithm
+func Checksum(checksumType uint32, data []byte) (uint64, error) {
+	switch checksumType {
+	case ChecksumCRC64ISO:
+		return crc64.Checksum(data, crc64ISOTable), nil
+	case ChecksumCRC32C:
+		return uint64(crc32.Checksum(data, crc32CTable)), nil
+	}
+
+	h, err := NewChecksum(checksumType)
+	if err != nil {
+		return 0, err
+	}
+	h.Write(data)
+	return h.Sum64(), nil
+}
+
+// crc32Hash widens a 32-bit CRC to a hash.Hash64
+type crc32Hash struct {
+	hash.Hash32
+}
+
+func (h crc32Hash) Sum64() uint64 {
+	return uint64(h.Sum32())
+}
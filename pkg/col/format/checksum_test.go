@@ -0,0 +1,65 @@
+package format
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksumKnownValues(t *testing.T) {
+	tests := []struct {
+		checksumType uint32
+		input        string
+		expected     uint64
+	}{
+		{ChecksumCRC64ISO, "123456789", 0xB90956C775A41001},
+		{ChecksumCRC32C, "123456789", 0xE3069283},
+		{ChecksumXXHash64, "", 0xEF46DB3751D8E999},
+		{ChecksumXXHash64, "abc", 0x44BC2CF5AD770999},
+		{ChecksumXXHash64, "Nobody inspects the spammish repetition", 0xFBCEA83C8A378BF1},
+	}
+
+	for _, tt := range tests {
+		sum, err := Checksum(tt.checksumType, []byte(tt.input))
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, sum, "type %d of %q", tt.checksumType, tt.input)
+
+		h, err := NewChecksum(tt.checksumType)
+		require.NoError(t, err)
+		h.Write([]byte(tt.input))
+		assert.Equal(t, tt.expected, h.Sum64(), "streamed type %d of %q", tt.checksumType, tt.input)
+	}
+
+	_, err := NewChecksum(ChecksumNone)
+	assert.Error(t, err)
+	_, err = Checksum(42, nil)
+	assert.Error(t, err)
+}
+
+func TestChecksumStreaming(t *testing.T) {
+	data := make([]byte, 10000)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	for _, checksumType := range []uint32{ChecksumCRC64ISO, ChecksumCRC32C, ChecksumXXHash64} {
+		expected, err := Checksum(checksumType, data)
+		require.NoError(t, err)
+
+		// Writes of every size, so stripes are split at all positions
+		h, err := NewChecksum(checksumType)
+		require.NoError(t, err)
+		for size, rest := 1, data; len(rest) > 0; size = size%67 + 1 {
+			n := min(size, len(rest))
+			h.Write(rest[:n])
+			rest = rest[n:]
+		}
+		assert.Equal(t, expected, h.Sum64(), "type %d", checksumType)
+
+		h.Reset()
+		h.Write(data[:31])
+		partial, err := Checksum(checksumType, data[:31])
+		require.NoError(t, err)
+		assert.Equal(t, partial, h.Sum64(), "type %d after reset", checksumType)
+	}
+}
@@ -0,0 +1,377 @@
+// Package format defines the binary structures of a column file, see
+// column_format_spec.md. Every structure serializes itself to and from its
+// exact on-disk representation, so readers and writers never deal with
+// offsets of individual fields. All values are little-endian.
+package format
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Sizes of the fixed-size structures in bytes
+const (
+	FileHeaderSize          = 64
+	BlockHeaderSize         = 64
+	BlockLayoutSize         = 16
+	FooterEntrySize         = 56
+	FooterSectionHeaderSize = 8
+	FooterMetadataSize      = 24
+)
+
+// FileHeader represents the header of a column file
+type FileHeader struct {
+	Magic           uint64
+	Version         uint32
+	ColumnType      uint32
+	BlockCount      uint64
+	BlockSizeTarget uint32
+	CompressionType uint32
+	EncodingType    uint32
+	CreationTime    uint64
+	BitmapOffset    uint64 // Offset to the global ID bitmap
+	BitmapSize      uint64 // Size of the global ID bitmap in bytes
+	ChecksumType    uint32 // Algorithm of the file checksum, see NewChecksum
+}
+
+// MarshalBinary returns the FileHeaderSize bytes of the header
+func (h FileHeader) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, FileHeaderSize)
+	binary.LittleEndian.PutUint64(buf[0:], h.Magic)
+	binary.LittleEndian.PutUint32(buf[8:], h.Version)
+	binary.LittleEndian.PutUint32(buf[12:], h.ColumnType)
+	binary.LittleEndian.PutUint64(buf[16:], h.BlockCount)
+	binary.LittleEndian.PutUint32(buf[24:], h.BlockSizeTarget)
+	binary.LittleEndian.PutUint32(buf[28:], h.CompressionType)
+	binary.LittleEndian.PutUint32(buf[32:], h.EncodingType)
+	binary.LittleEndian.PutUint64(buf[36:], h.CreationTime)
+	binary.LittleEndian.PutUint64(buf[44:], h.BitmapOffset)
+	binary.LittleEndian.PutUint64(buf[52:], h.BitmapSize)
+	binary.LittleEndian.PutUint32(buf[60:], h.ChecksumType)
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a header from exactly FileHeaderSize bytes. The
+// fields are not validated.
+func (h *FileHeader) UnmarshalBinary(data []byte) error {
+	if err := checkSize("file header", data, FileHeaderSize); err != nil {
+		return err
+	}
+	*h = FileHeader{
+		Magic:           binary.LittleEndian.Uint64(data[0:]),
+		Version:         binary.LittleEndian.Uint32(data[8:]),
+		ColumnType:      binary.LittleEndian.Uint32(data[12:]),
+		BlockCount:      binary.LittleEndian.Uint64(data[16:]),
+		BlockSizeTarget: binary.LittleEndian.Uint32(data[24:]),
+		CompressionType: binary.LittleEndian.Uint32(data[28:]),
+		EncodingType:    binary.LittleEndian.Uint32(data[32:]),
+		CreationTime:    binary.LittleEndian.Uint64(data[36:]),
+		BitmapOffset:    binary.LittleEndian.Uint64(data[44:]),
+		BitmapSize:      binary.LittleEndian.Uint64(data[52:]),
+		ChecksumType:    binary.LittleEndian.Uint32(data[60:]),
+	}
+	return nil
+}
+
+// BlockHeader represents the header of a block. Its fields fill all 64 bytes,
+// so only 4 bytes are left for the checksum.
+type BlockHeader struct {
+	MinID            uint64
+	MaxID            uint64
+	MinValue         uint64 // Stored as uint64, but represents int64
+	MaxValue         uint64 // Stored as uint64, but represents int64
+	Sum              uint64 // Stored as uint64, but represents int64
+	Count            uint32
+	EncodingType     uint32
+	CompressionType  uint32
+	UncompressedSize uint32
+	CompressedSize   uint32
+	Checksum         uint32
+}
+
+// MarshalBinary returns the BlockHeaderSize bytes of the header
+func (h BlockHeader) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, BlockHeaderSize)
+	binary.LittleEndian.PutUint64(buf[0:], h.MinID)
+	binary.LittleEndian.PutUint64(buf[8:], h.MaxID)
+	binary.LittleEndian.PutUint64(buf[16:], h.MinValue)
+	binary.LittleEndian.PutUint64(buf[24:], h.MaxValue)
+	binary.LittleEndian.PutUint64(buf[32:], h.Sum)
+	binary.LittleEndian.PutUint32(buf[40:], h.Count)
+	binary.LittleEndian.PutUint32(buf[44:], h.EncodingType)
+	binary.LittleEndian.PutUint32(buf[48:], h.CompressionType)
+	binary.LittleEndian.PutUint32(buf[52:], h.UncompressedSize)
+	binary.LittleEndian.PutUint32(buf[56:], h.CompressedSize)
+	binary.LittleEndian.PutUint32(buf[60:], h.Checksum)
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a header from exactly BlockHeaderSize bytes
+func (h *BlockHeader) UnmarshalBinary(data []byte) error {
+	if err := checkSize("block header", data, BlockHeaderSize); err != nil {
+		return err
+	}
+	*h = BlockHeader{
+		MinID:            binary.LittleEndian.Uint64(data[0:]),
+		MaxID:            binary.LittleEndian.Uint64(data[8:]),
+		MinValue:         binary.LittleEndian.Uint64(data[16:]),
+		MaxValue:         binary.LittleEndian.Uint64(data[24:]),
+		Sum:              binary.LittleEndian.Uint64(data[32:]),
+		Count:            binary.LittleEndian.Uint32(data[40:]),
+		EncodingType:     binary.LittleEndian.Uint32(data[44:]),
+		CompressionType:  binary.LittleEndian.Uint32(data[48:]),
+		UncompressedSize: binary.LittleEndian.Uint32(data[52:]),
+		CompressedSize:   binary.LittleEndian.Uint32(data[56:]),
+		Checksum:         binary.LittleEndian.Uint32(data[60:]),
+	}
+	return nil
+}
+
+// BlockLayout represents the layout of a block, which follows the block header.
+// The section offsets are relative to the end of the layout.
+type BlockLayout struct {
+	IDSectionOffset    uint32
+	IDSectionSize      uint32
+	ValueSectionOffset uint32
+	ValueSectionSize   uint32
+}
+
+// MarshalBinary returns the BlockLayoutSize bytes of the layout
+func (l BlockLayout) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, BlockLayoutSize)
+	binary.LittleEndian.PutUint32(buf[0:], l.IDSectionOffset)
+	binary.LittleEndian.PutUint32(buf[4:], l.IDSectionSize)
+	binary.LittleEndian.PutUint32(buf[8:], l.ValueSectionOffset)
+	binary.LittleEndian.PutUint32(buf[12:], l.ValueSectionSize)
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a layout from exactly BlockLayoutSize bytes
+func (l *BlockLayout) UnmarshalBinary(data []byte) error {
+	if err := checkSize("block layout", data, BlockLayoutSize); err != nil {
+		return err
+	}
+	*l = BlockLayout{
+		IDSectionOffset:    binary.LittleEndian.Uint32(data[0:]),
+		IDSectionSize:      binary.LittleEndian.Uint32(data[4:]),
+		ValueSectionOffset: binary.LittleEndian.Uint32(data[8:]),
+		ValueSectionSize:   binary.LittleEndian.Uint32(data[12:]),
+	}
+	return nil
+}
+
+// DataSize returns the size of the data sections following the layout
+func (l BlockLayout) DataSize() uint64 {
+	idEnd := uint64(l.IDSectionOffset) + uint64(l.IDSectionSize)
+	valueEnd := uint64(l.ValueSectionOffset) + uint64(l.ValueSectionSize)
+	if idEnd > valueEnd {
+		return idEnd
+	}
+	return valueEnd
+}
+
+// FooterEntry represents an entry of the block index in the footer
+type FooterEntry struct {
+	BlockOffset uint64
+	BlockSize   uint32
+	MinID       uint64
+	MaxID       uint64
+	MinValue    uint64 // Stored as uint64, but represents int64
+	MaxValue    uint64 // Stored as uint64, but represents int64
+	Sum         uint64 // Stored as uint64, but represents int64
+	Count       uint32
+}
+
+// MarshalBinary returns the FooterEntrySize bytes of the entry
+func (e FooterEntry) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, FooterEntrySize)
+	e.put(buf)
+	return buf, nil
+}
+
+// UnmarshalBinary decodes an entry from exactly FooterEntrySize bytes
+func (e *FooterEntry) UnmarshalBinary(data []byte) error {
+	if err := checkSize("footer entry", data, FooterEntrySize); err != nil {
+		return err
+	}
+	*e = FooterEntry{
+		BlockOffset: binary.LittleEndian.Uint64(data[0:]),
+		BlockSize:   binary.LittleEndian.Uint32(data[8:]),
+		MinID:       binary.LittleEndian.Uint64(data[12:]),
+		MaxID:       binary.LittleEndian.Uint64(data[20:]),
+		MinValue:    binary.LittleEndian.Uint64(data[28:]),
+		MaxValue:    binary.LittleEndian.Uint64(data[36:]),
+		Sum:         binary.LittleEndian.Uint64(data[44:]),
+		Count:       binary.LittleEndian.Uint32(data[52:]),
+	}
+	return nil
+}
+
+func (e FooterEntry) put(buf []byte) {
+	binary.LittleEndian.PutUint64(buf[0:], e.BlockOffset)
+	binary.LittleEndian.PutUint32(buf[8:], e.BlockSize)
+	binary.LittleEndian.PutUint64(buf[12:], e.MinID)
+	binary.LittleEndian.PutUint64(buf[20:], e.MaxID)
+	binary.LittleEndian.PutUint64(buf[28:], e.MinValue)
+	binary.LittleEndian.PutUint64(buf[36:], e.MaxValue)
+	binary.LittleEndian.PutUint64(buf[44:], e.Sum)
+	binary.LittleEndian.PutUint32(buf[52:], e.Count)
+}
+
+// FooterSectionHeader precedes each optional section that follows the block index in the footer.
+// Readers skip sections with unknown types, so new sections can be added without breaking old files.
+type FooterSectionHeader struct {
+	Type uint32
+	Size uint32 // Size of the section payload in bytes, excluding this header
+}
+
+// MarshalBinary returns the FooterSectionHeaderSize bytes of the header
+func (h FooterSectionHeader) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, FooterSectionHeaderSize)
+	binary.LittleEndian.PutUint32(buf[0:], h.Type)
+	binary.LittleEndian.PutUint32(buf[4:], h.Size)
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a header from exactly FooterSectionHeaderSize bytes
+func (h *FooterSectionHeader) UnmarshalBinary(data []byte) error {
+	if err := checkSize("footer section header", data, FooterSectionHeaderSize); err != nil {
+		return err
+	}
+	*h = FooterSectionHeader{
+		Type: binary.LittleEndian.Uint32(data[0:]),
+		Size: binary.LittleEndian.Uint32(data[4:]),
+	}
+	return nil
+}
+
+// FooterSection is an optional footer section. Its header is derived from the
+// type and the payload when marshaling the footer.
+type FooterSection struct {
+	Type    uint32
+	Payload []byte
+}
+
+// Footer represents the footer of a column file without the footer metadata:
+// the block index count, the block index and the optional sections
+type Footer struct {
+	Entries  []FooterEntry
+	Sections []FooterSection
+}
+
+// MarshalBinary returns the encoded footer
+func (f Footer) MarshalBinary() ([]byte, error) {
+	// Counts and section sizes are stored as uint32
+	if uint64(len(f.Entries)) > math.MaxUint32 {
+		return nil, fmt.Errorf("block index of %d entries exceeds the maximum of %d entries", len(f.Entries), uint64(math.MaxUint32))
+	}
+	size := 4 + len(f.Entries)*FooterEntrySize
+	for _, section := range f.Sections {
+		if uint64(len(section.Payload)) > math.MaxUint32 {
+			return nil, fmt.Errorf("footer section %d of %d bytes exceeds the maximum of %d bytes",
+				section.Type, len(section.Payload), uint64(math.MaxUint32))
+		}
+		size += FooterSectionHeaderSize + len(section.Payload)
+	}
+
+	buf := make([]byte, size)
+	binary.LittleEndian.PutUint32(buf[0:], uint32(len(f.Entries)))
+	offset := 4
+	for _, entry := range f.Entries {
+		entry.put(buf[offset:])
+		offset += FooterEntrySize
+	}
+	for _, section := range f.Sections {
+		binary.LittleEndian.PutUint32(buf[offset:], section.Type)
+		binary.LittleEndian.PutUint32(buf[offset+4:], uint32(len(section.Payload)))
+		offset += FooterSectionHeaderSize
+		offset += copy(buf[offset:], section.Payload)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a footer. Sections are kept in file order whatever
+// their type; their payloads refer to data.
+func (f *Footer) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return fmt.Errorf("footer too small: %d bytes", len(data))
+	}
+	count := binary.LittleEndian.Uint32(data[0:])
+	offset := 4
+	if uint64(count) > uint64((len(data)-offset)/FooterEntrySize) {
+		return fmt.Errorf("block index of %d entries exceeds footer of %d bytes", count, len(data))
+	}
+
+	entries := make([]FooterEntry, count)
+	for i := range entries {
+		if err := entries[i].UnmarshalBinary(data[offset : offset+FooterEntrySize]); err != nil {
+			return err
+		}
+		offset += FooterEntrySize
+	}
+
+	var sections []FooterSection
+	for offset < len(data) {
+		if offset+FooterSectionHeaderSize > len(data) {
+			return fmt.Errorf("truncated footer section header at offset %d", offset)
+		}
+		var header FooterSectionHeader
+		if err := header.UnmarshalBinary(data[offset : offset+FooterSectionHeaderSize]); err != nil {
+			return err
+		}
+		offset += FooterSectionHeaderSize
+
+		if uint64(header.Size) > uint64(len(data)-offset) {
+			return fmt.Errorf("footer section %d exceeds footer: size=%d, remaining=%d",
+				header.Type, header.Size, len(data)-offset)
+		}
+		size := int(header.Size)
+		sections = append(sections, FooterSection{
+			Type:    header.Type,
+			Payload: data[offset : offset+size],
+		})
+		offset += size
+	}
+
+	*f = Footer{Entries: entries, Sections: sections}
+	return nil
+}
+
+// FooterMetadata represents the metadata at the end of the footer, which
+// locates the start of the footer
+type FooterMetadata struct {
+	FooterSize uint64 // Size of the footer, excluding the metadata
+	Checksum   uint64
+	Magic      uint64
+}
+
+// MarshalBinary returns the FooterMetadataSize bytes of the metadata
+func (m FooterMetadata) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, FooterMetadataSize)
+	binary.LittleEndian.PutUint64(buf[0:], m.FooterSize)
+	binary.LittleEndian.PutUint64(buf[8:], m.Checksum)
+	binary.LittleEndian.PutUint64(buf[16:], m.Magic)
+	return buf, nil
+}
+
+// UnmarshalBinary decodes the metadata from exactly FooterMetadataSize bytes
+func (m *FooterMetadata) UnmarshalBinary(data []byte) error {
+	if err := checkSize("footer metadata", data, FooterMetadataSize); err != nil {
+		return err
+	}
+	*m = FooterMetadata{
+		FooterSize: binary.LittleEndian.Uint64(data[0:]),
+		Checksum:   binary.LittleEndian.Uint64(data[8:]),
+		Magic:      binary.LittleEndian.Uint64(data[16:]),
+	}
+	return nil
+}
+
+// checkSize returns an error if data does not have the size of the structure
+func checkSize(name string, data []byte, size int) error {
+	if len(data) != size {
+		return fmt.Errorf("invalid %s size: expected=%d, actual=%d", name, size, len(data))
+	}
+	return nil
+}
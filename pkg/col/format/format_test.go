@@ -0,0 +1,162 @@
+package format
+
+import (
+	"encoding"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type structure interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+func TestFixedSizeRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     structure
+		out    structure
+		size   int
+		filled []int // Offsets of bytes that must be non-zero
+	}{
+		{
+			name: "file header",
+			in: &FileHeader{
+				Magic: 0x0102030405060708, Version: 1, ColumnType: 11, BlockCount: 3,
+				BlockSizeTarget: 4097, CompressionType: 2, EncodingType: 7,
+				CreationTime: 1700000001, BitmapOffset: 64, BitmapSize: 42, ChecksumType: 3,
+			},
+			out:    &FileHeader{},
+			size:   FileHeaderSize,
+			filled: []int{0, 8, 12, 16, 24, 28, 32, 36, 44, 52, 60},
+		},
+		{
+			name: "block header",
+			in: &BlockHeader{
+				MinID: 1, MaxID: 2, MinValue: 3, MaxValue: 4, Sum: 5, Count: 6,
+				EncodingType: 7, CompressionType: 8, UncompressedSize: 9,
+				CompressedSize: 10, Checksum: 11,
+			},
+			out:    &BlockHeader{},
+			size:   BlockHeaderSize,
+			filled: []int{0, 8, 16, 24, 32, 40, 44, 48, 52, 56, 60},
+		},
+		{
+			name:   "block layout",
+			in:     &BlockLayout{IDSectionOffset: 1, IDSectionSize: 2, ValueSectionOffset: 3, ValueSectionSize: 4},
+			out:    &BlockLayout{},
+			size:   BlockLayoutSize,
+			filled: []int{0, 4, 8, 12},
+		},
+		{
+			name: "footer entry",
+			in: &FooterEntry{
+				BlockOffset: 1, BlockSize: 2, MinID: 3, MaxID: 4, MinValue: 5,
+				MaxValue: 6, Sum: 7, Count: 8,
+			},
+			out:    &FooterEntry{},
+			size:   FooterEntrySize,
+			filled: []int{0, 8, 12, 20, 28, 36, 44, 52},
+		},
+		{
+			name:   "footer section header",
+			in:     &FooterSectionHeader{Type: 1, Size: 2},
+			out:    &FooterSectionHeader{},
+			size:   FooterSectionHeaderSize,
+			filled: []int{0, 4},
+		},
+		{
+			name:   "footer metadata",
+			in:     &FooterMetadata{FooterSize: 1, Checksum: 2, Magic: 3},
+			out:    &FooterMetadata{},
+			size:   FooterMetadataSize,
+			filled: []int{0, 8, 16},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.in.MarshalBinary()
+			require.NoError(t, err)
+			require.Len(t, data, tt.size)
+			for _, offset := range tt.filled {
+				assert.NotZero(t, data[offset], "field at offset %d", offset)
+			}
+
+			require.NoError(t, tt.out.UnmarshalBinary(data))
+			assert.Equal(t, tt.in, tt.out)
+
+			assert.Error(t, tt.out.UnmarshalBinary(data[:tt.size-1]))
+			assert.Error(t, tt.out.UnmarshalBinary(append(data, 0)))
+		})
+	}
+}
+
+func TestFooterRoundTrip(t *testing.T) {
+	in := Footer{
+		Entries: []FooterEntry{
+			{BlockOffset: 64, BlockSize: 100, MinID: 1, MaxID: 10, Count: 10},
+			{BlockOffset: 164, BlockSize: 200, MinID: 11, MaxID: 30, Count: 20},
+		},
+		Sections: []FooterSection{
+			{Type: 1, Payload: []byte{1, 2, 3}},
+			{Type: 99, Payload: []byte{}},
+			{Type: 2, Payload: []byte{4}},
+		},
+	}
+
+	data, err := in.MarshalBinary()
+	require.NoError(t, err)
+	require.Len(t, data, 4+2*FooterEntrySize+3*FooterSectionHeaderSize+4)
+
+	var out Footer
+	require.NoError(t, out.UnmarshalBinary(data))
+	assert.Equal(t, in, out)
+
+	// A footer without blocks and sections only holds the block index count
+	data, err = Footer{}.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 0}, data)
+	require.NoError(t, out.UnmarshalBinary(data))
+	assert.Empty(t, out.Entries)
+	assert.Empty(t, out.Sections)
+}
+
+func TestFooterUnmarshalTruncated(t *testing.T) {
+	data, err := Footer{
+		Entries:  []FooterEntry{{BlockOffset: 64, Count: 1}},
+		Sections: []FooterSection{{Type: 1, Payload: []byte{1, 2, 3, 4}}},
+	}.MarshalBinary()
+	require.NoError(t, err)
+
+	var footer Footer
+	assert.Error(t, footer.UnmarshalBinary(data[:2]), "block index count")
+	assert.Error(t, footer.UnmarshalBinary(data[:4+FooterEntrySize-1]), "block index")
+	assert.Error(t, footer.UnmarshalBinary(data[:4+FooterEntrySize+4]), "section header")
+	assert.Error(t, footer.UnmarshalBinary(data[:len(data)-1]), "section payload")
+}
+
+func TestFooterUnmarshalHugeSizes(t *testing.T) {
+	data, err := Footer{
+		Entries:  []FooterEntry{{BlockOffset: 64, Count: 1}},
+		Sections: []FooterSection{{Type: 1, Payload: []byte{1, 2, 3, 4}}},
+	}.MarshalBinary()
+	require.NoError(t, err)
+
+	// Sizes beyond the range of a 32-bit int must not wrap around
+	var footer Footer
+	corrupt := append([]byte(nil), data...)
+	binary.LittleEndian.PutUint32(corrupt[0:], math.MaxUint32)
+	assert.Error(t, footer.UnmarshalBinary(corrupt), "block index count")
+
+	corrupt = append([]byte(nil), data...)
+	binary.LittleEndian.PutUint32(corrupt[4+FooterEntrySize+4:], math.MaxUint32)
+	assert.Error(t, footer.UnmarshalBinary(corrupt), "section size")
+
+	layout := BlockLayout{IDSectionSize: math.MaxUint32, ValueSectionOffset: math.MaxUint32, ValueSectionSize: math.MaxUint32}
+	assert.Equal(t, uint64(2*math.MaxUint32), layout.DataSize())
+}
@@ -1,6 +1,8 @@
 package col
 
 import (
+	"math"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -148,9 +150,8 @@ func TestInt64ToUint64Conversion(t *testing.T) {
 		{0, 0},
 		{1, 1},
 		{-1, 9223372036854775809}, // 2^63 + 1
-		{9223372036854775807, 9223372036854775807}, // int64 max
-		// For int64 min, the conversion is tricky, so we'll skip it in this test
-		// {-9223372036854775808, 9223372036854775808}, // int64 min, becomes 2^63
+		{9223372036854775807, 9223372036854775807},  // int64 max
+		{-9223372036854775808, 9223372036854775808}, // int64 min, stored as negative zero (2^63)
 		{42, 42},
 		{-42, 9223372036854775850}, // 2^63 + 42
 	}
@@ -168,3 +169,134 @@ func TestInt64ToUint64Conversion(t *testing.T) {
 		}
 	}
 }
+
+func TestMergeAggregates(t *testing.T) {
+	a := AggregateResult{Count: 2, Min: -5, Max: 10, Sum: 5, Avg: 2.5}
+	b := AggregateResult{Count: 3, Min: 1, Max: 20, Sum: 30, Avg: 10}
+
+	// Empty results are ignored, whether they carry sentinel extremes or zeros
+	emptySentinel := AggregateResult{Min: math.MaxInt64, Max: math.MinInt64}
+	emptyZero := AggregateResult{}
+
+	merged := MergeAggregates(emptySentinel, a, emptyZero, b)
+	expected := AggregateResult{Count: 5, Min: -5, Max: 20, Sum: 35, Avg: 7}
+	if merged != expected {
+		t.Errorf("Expected %+v, got %+v", expected, merged)
+	}
+
+	if merged := MergeAggregates(b); merged != b {
+		t.Errorf("Expected a single result to be returned unchanged, got %+v", merged)
+	}
+
+	// Positive extremes of an empty result must not win over a negative maximum
+	negative := AggregateResult{Count: 1, Min: -3, Max: -3, Sum: -3, Avg: -3}
+	if merged := MergeAggregates(AggregateResult{Max: 0}, negative); merged != negative {
+		t.Errorf("Expected %+v, got %+v", negative, merged)
+	}
+
+	if merged := MergeAggregates(); merged != (AggregateResult{}) {
+		t.Errorf("Expected the zero result, got %+v", merged)
+	}
+	if merged := MergeAggregates(emptySentinel, emptyZero); merged != (AggregateResult{}) {
+		t.Errorf("Expected the zero result, got %+v", merged)
+	}
+}
+
+func TestNegativeStatisticsRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "negative.col")
+
+	// Entirely negative blocks, including the int64 minimum which has no
+	// positive counterpart
+	blocks := []struct {
+		ids    []uint64
+		values []int64
+	}{
+		{[]uint64{1, 2, 3}, []int64{-5, -1, -100}},
+		{[]uint64{10, 11}, []int64{math.MinInt64, -1}},
+		{[]uint64{20, 21}, []int64{-7, 3}},
+	}
+
+	for _, encoding := range []uint32{EncodingRaw, EncodingDeltaBoth, EncodingVarIntBoth} {
+		writer, err := NewWriter(path, WithEncoding(encoding))
+		if err != nil {
+			t.Fatalf("Failed to create writer: %v", err)
+		}
+		for _, b := range blocks {
+			if err := writer.WriteBlock(b.ids, b.values); err != nil {
+				t.Fatalf("Failed to write block: %v", err)
+			}
+		}
+		if err := writer.FinalizeAndClose(); err != nil {
+			t.Fatalf("Failed to finalize: %v", err)
+		}
+
+		reader, err := NewReader(path)
+		if err != nil {
+			t.Fatalf("Failed to open reader: %v", err)
+		}
+
+		for i, b := range blocks {
+			meta, err := reader.BlockMeta(BlockID(i))
+			if err != nil {
+				t.Fatalf("Failed to read block meta: %v", err)
+			}
+			minValue, maxValue := calculateMinMaxInt64(b.values)
+			sum := calculateSumInt64(b.values)
+			if meta.MinValue != minValue || meta.MaxValue != maxValue || meta.Sum != sum {
+				t.Errorf("encoding %d, block %d: expected min=%d max=%d sum=%d, got min=%d max=%d sum=%d",
+					encoding, i, minValue, maxValue, sum, meta.MinValue, meta.MaxValue, meta.Sum)
+			}
+		}
+
+		// The footer, the block headers and the file statistics agree with the data
+		report, err := reader.ValidateFooterAgainstBlocks()
+		if err != nil {
+			t.Fatalf("Failed to validate: %v", err)
+		}
+		if !report.OK() {
+			t.Errorf("encoding %d: statistics differ from the data: %+v", encoding, report)
+		}
+
+		fileStats, err := reader.FileStats()
+		if err != nil {
+			t.Fatalf("Failed to read file stats: %v", err)
+		}
+		if fileStats.MinValue != math.MinInt64 || fileStats.MaxValue != 3 {
+			t.Errorf("encoding %d: expected file min=%d max=3, got min=%d max=%d",
+				encoding, int64(math.MinInt64), fileStats.MinValue, fileStats.MaxValue)
+		}
+
+		result := reader.Aggregate()
+		if result.Min != math.MinInt64 || result.Max != 3 || result.Count != 7 {
+			t.Errorf("encoding %d: unexpected aggregate %+v", encoding, result)
+		}
+		reader.Close()
+	}
+}
+
+func TestFinalizeRejectsInconsistentBlockTracking(t *testing.T) {
+	tempDir := t.TempDir()
+
+	corruptions := map[string]func(w *Writer){
+		"positions":  func(w *Writer) { w.blockPositions = w.blockPositions[:1] },
+		"sizes":      func(w *Writer) { w.blockSizes = w.blockSizes[:1] },
+		"statistics": func(w *Writer) { w.blockStats = w.blockStats[:1] },
+	}
+	for name, corrupt := range corruptions {
+		writer, err := NewWriter(filepath.Join(tempDir, name+".col"))
+		if err != nil {
+			t.Fatalf("Failed to create writer: %v", err)
+		}
+		for block := uint64(0); block < 2; block++ {
+			if err := writer.WriteBlock([]uint64{block*10 + 1, block*10 + 2}, []int64{1, 2}); err != nil {
+				t.Fatalf("Failed to write block: %v", err)
+			}
+		}
+
+		corrupt(writer)
+		if err := writer.Finalize(); err == nil {
+			t.Errorf("Expected Finalize to fail with inconsistent block %s", name)
+		}
+		writer.Close()
+	}
+}
@@ -0,0 +1,137 @@
+package col
+
+import (
+	"fmt"
+
+	"github.com/weaviate/sroar"
+)
+
+// persistedIDBitmap returns the global ID bitmap stored in the file. Unlike
+// GetGlobalIDBitmap it fails for files that contain blocks but no bitmap, as an
+// empty bitmap would silently drop their IDs from bitmap algebra.
+func (r *Reader) persistedIDBitmap() (*sroar.Bitmap, error) {
+	if r.header.BitmapSize == 0 && r.header.BlockCount > 0 {
+		return nil, fmt.Errorf("file has %d blocks but no global ID bitmap", r.header.BlockCount)
+	}
+	return r.GetGlobalIDBitmap()
+}
+
+// UnionIDBitmaps returns the IDs present in any of the files, using their
+// persisted global ID bitmaps without decoding any blocks. The result is a new
+// bitmap that can be modified by the caller.
+func UnionIDBitmaps(readers ...*Reader) (*sroar.Bitmap, error) {
+	bitmaps := make([]*sroar.Bitmap, 0, len(readers))
+	for i, r := range readers {
+		bitmap, err := r.persistedIDBitmap()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get global ID bitmap from reader %d: %w", i, err)
+		}
+		bitmaps = append(bitmaps, bitmap)
+	}
+
+	switch len(bitmaps) {
+	case 0:
+		return sroar.NewBitmap(), nil
+	case 1:
+		// FastOr returns a single input as is, which may be cached by the reader
+		return bitmaps[0].Clone(), nil
+	default:
+		return sroar.FastOr(bitmaps...), nil
+	}
+}
+
+// DifferenceIDBitmaps returns the IDs present in base but in none of the other
+// files, for example the IDs of an old generation that were not overwritten by
+// newer ones. Like UnionIDBitmaps it only reads the persisted bitmaps.
+func DifferenceIDBitmaps(base *Reader, others ...*Reader) (*sroar.Bitmap, error) {
+	baseIDs, err := UnionIDBitmaps(base)
+	if err != nil {
+		return nil, err
+	}
+	if len(others) == 0 {
+		return baseIDs, nil
+	}
+
+	otherIDs, err := UnionIDBitmaps(others...)
+	if err != nil {
+		return nil, err
+	}
+	return sroar.AndNot(baseIDs, otherIDs), nil
+}
+
+// CountDistinctIDs returns the number of unique IDs across all files
+func CountDistinctIDs(readers ...*Reader) (uint64, error) {
+	union, err := UnionIDBitmaps(readers...)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(union.GetCardinality()), nil
+}
+
+// IDBitmapOptions configures Reader.BuildIDBitmap
+type IDBitmapOptions struct {
+	// Where selects the pairs whose IDs are added, e.g. Gt(Col, Const(100)).
+	// If nil, all IDs are added and only the ID sections are decoded.
+	Where Pred
+
+	// Filter restricts the result to the IDs it contains, so the bitmaps of
+	// several columns can be intersected one file at a time. Blocks outside the
+	// range of the filter are skipped.
+	Filter *sroar.Bitmap
+}
+
+// BuildIDBitmap returns a bitmap of the IDs of all pairs matching opts, built
+// in a single pass over the blocks. The result can be used as an allow filter
+// against other files, e.g. in AggregateOptions.Filter, to evaluate predicates
+// across columns. Without options it equals the global ID bitmap, which
+// UnionIDBitmaps returns without decoding any blocks.
+func (r *Reader) BuildIDBitmap(opts IDBitmapOptions) (*sroar.Bitmap, error) {
+	if err := r.ensureFooter(); err != nil {
+		return nil, err
+	}
+
+	bitmap := sroar.NewBitmap()
+	if opts.Filter != nil && opts.Filter.IsEmpty() {
+		return bitmap, nil
+	}
+
+	var block exprBlock
+	var mask []bool
+	var idsBuf []uint64
+	var valsBuf []int64
+	for _, blockIdx := range r.FilteredBlockIterator(opts.Filter, nil) {
+		var ids []uint64
+		var err error
+		if opts.Where == nil {
+			ids, err = r.ReadBlockIDsInto(BlockID(blockIdx), idsBuf)
+			idsBuf = ids
+		} else {
+			var values []int64
+			ids, values, err = r.ReadBlockInto(BlockID(blockIdx), idsBuf, valsBuf)
+			idsBuf, valsBuf = ids, values
+			if err == nil {
+				block.reset(ids, values)
+				if cap(mask) < len(ids) {
+					mask = make([]bool, len(ids))
+				}
+				mask = mask[:len(ids)]
+				opts.Where.eval(&block, mask)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read block %d: %w", blockIdx, err)
+		}
+
+		for i, id := range ids {
+			if opts.Where != nil && !mask[i] {
+				continue
+			}
+			if opts.Filter != nil && !opts.Filter.Contains(id) {
+				continue
+			}
+			bitmap.Set(id)
+		}
+	}
+
+	return bitmap, nil
+}
@@ -0,0 +1,147 @@
+package col
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaviate/sroar"
+)
+
+func TestIDBitmapAlgebra(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-id-bitmaps-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	writeFile := func(name string, ids ...uint64) *Reader {
+		path := filepath.Join(tempDir, name)
+		writer, err := NewWriter(path)
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlock(ids, make([]int64, len(ids))))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		reader, err := NewReader(path)
+		require.NoError(t, err)
+		t.Cleanup(func() { reader.Close() })
+		return reader
+	}
+
+	gen1 := writeFile("gen1.col", 1, 2, 3, 4, 5)
+	gen2 := writeFile("gen2.col", 4, 5, 6, 7)
+	gen3 := writeFile("gen3.col", 2, 7, 1000000)
+
+	t.Run("Union", func(t *testing.T) {
+		union, err := UnionIDBitmaps(gen1, gen2, gen3)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{1, 2, 3, 4, 5, 6, 7, 1000000}, union.ToArray())
+
+		count, err := CountDistinctIDs(gen1, gen2, gen3)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(8), count)
+
+		empty, err := UnionIDBitmaps()
+		require.NoError(t, err)
+		assert.True(t, empty.IsEmpty())
+	})
+
+	t.Run("Difference", func(t *testing.T) {
+		diff, err := DifferenceIDBitmaps(gen1, gen2, gen3)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{1, 3}, diff.ToArray())
+
+		all, err := DifferenceIDBitmaps(gen2)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{4, 5, 6, 7}, all.ToArray())
+	})
+
+	t.Run("Results do not alias cached bitmaps", func(t *testing.T) {
+		gen1.EnableGlobalIDBitmapCaching()
+		defer gen1.DisableGlobalIDBitmapCaching()
+
+		union, err := UnionIDBitmaps(gen1)
+		require.NoError(t, err)
+		union.Set(42)
+
+		cached, err := gen1.GetGlobalIDBitmap()
+		require.NoError(t, err)
+		assert.False(t, cached.Contains(42))
+	})
+
+	t.Run("Files without a bitmap are rejected", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join(tempDir, "gen1.col"))
+		require.NoError(t, err)
+		clear(data[44:60]) // Bitmap offset and size in the file header
+		path := filepath.Join(tempDir, "no_bitmap.col")
+		require.NoError(t, os.WriteFile(path, data, 0644))
+
+		noBitmap, err := NewReader(path)
+		require.NoError(t, err)
+		defer noBitmap.Close()
+
+		_, err = UnionIDBitmaps(gen2, noBitmap)
+		assert.Error(t, err)
+		_, err = DifferenceIDBitmaps(gen2, noBitmap)
+		assert.Error(t, err)
+	})
+}
+
+func TestBuildIDBitmap(t *testing.T) {
+	// Block i holds IDs i*10+1 to i*10+4 with values equal to the IDs
+	var buf bytes.Buffer
+	writer, err := NewWriterToBuffer(&buf)
+	require.NoError(t, err)
+	for b := uint64(0); b < 3; b++ {
+		ids := []uint64{b*10 + 1, b*10 + 2, b*10 + 3, b*10 + 4}
+		values := []int64{int64(ids[0]), int64(ids[1]), int64(ids[2]), int64(ids[3])}
+		require.NoError(t, writer.WriteBlock(ids, values))
+	}
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := NewReaderFromBytes(buf.Bytes())
+	require.NoError(t, err)
+	defer reader.Close()
+
+	t.Run("All IDs", func(t *testing.T) {
+		bitmap, err := reader.BuildIDBitmap(IDBitmapOptions{})
+		require.NoError(t, err)
+		global, err := reader.GetGlobalIDBitmap()
+		require.NoError(t, err)
+		assert.Equal(t, global.ToArray(), bitmap.ToArray())
+	})
+
+	t.Run("Value predicate", func(t *testing.T) {
+		bitmap, err := reader.BuildIDBitmap(IDBitmapOptions{
+			Where: And(Gt(Col, Const(3)), Lt(Col, Const(23))),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{4, 11, 12, 13, 14, 21, 22}, bitmap.ToArray())
+
+		// Predicates may also refer to the ID
+		bitmap, err = reader.BuildIDBitmap(IDBitmapOptions{Where: Eq(ID, Const(12))})
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{12}, bitmap.ToArray())
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		filter := sroar.NewBitmap()
+		filter.SetMany([]uint64{2, 3, 13, 99})
+		bitmap, err := reader.BuildIDBitmap(IDBitmapOptions{Where: Ne(Col, Const(3)), Filter: filter})
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{2, 13}, bitmap.ToArray())
+
+		bitmap, err = reader.BuildIDBitmap(IDBitmapOptions{Filter: sroar.NewBitmap()})
+		require.NoError(t, err)
+		assert.True(t, bitmap.IsEmpty())
+	})
+
+	t.Run("Allow filter for another column", func(t *testing.T) {
+		bitmap, err := reader.BuildIDBitmap(IDBitmapOptions{Where: Ge(Col, Const(20))})
+		require.NoError(t, err)
+		result := reader.AggregateWithOptions(AggregateOptions{Filter: bitmap})
+		assert.Equal(t, uint64(4), result.Count)
+		assert.Equal(t, int64(21+22+23+24), result.Sum)
+	})
+}
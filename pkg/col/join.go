@@ -0,0 +1,150 @@
+package col
+
+import (
+	"fmt"
+)
+
+// JoinMode determines which IDs are emitted by Join
+type JoinMode int
+
+const (
+	// JoinInner emits only IDs present in both files
+	JoinInner JoinMode = iota
+	// JoinLeft emits all IDs of the left file, with the right value if present
+	JoinLeft
+	// JoinOuter emits all IDs present in either file
+	JoinOuter
+)
+
+// JoinRow is a single row produced by Join
+type JoinRow struct {
+	ID     uint64
+	ValueA int64
+	ValueB int64
+	HasA   bool // Whether the ID is present in the left file
+	HasB   bool // Whether the ID is present in the right file
+}
+
+// blockCursor iterates over the pairs of a file in ID order, one block at a time
+type blockCursor struct {
+	reader   *Reader
+	blockIdx uint64
+	ids      []uint64
+	values   []int64
+	pos      int
+	lastID   uint64
+	started  bool
+}
+
+// newBlockCursor creates a cursor positioned before the first pair of the file
+func newBlockCursor(r *Reader) *blockCursor {
+	return &blockCursor{reader: r}
+}
+
+// next advances the cursor and reports whether a pair is available
+func (c *blockCursor) next() (bool, error) {
+	c.pos++
+	for c.pos >= len(c.ids) {
+		if c.blockIdx >= c.reader.BlockCount() {
+			return false, nil
+		}
+
+		ids, values, err := c.reader.ReadBlock(BlockID(c.blockIdx))
+		if err != nil {
+			return false, fmt.Errorf("failed to read block %d: %w", c.blockIdx, err)
+		}
+		c.blockIdx++
+		c.ids = ids
+		c.values = values
+		c.pos = 0
+	}
+
+	// A merge join requires IDs to be strictly increasing across the whole file
+	id := c.ids[c.pos]
+	if c.started && id <= c.lastID {
+		return false, fmt.Errorf("IDs are not strictly increasing: %d follows %d in block %d",
+			id, c.lastID, c.blockIdx-1)
+	}
+	c.lastID = id
+	c.started = true
+
+	return true, nil
+}
+
+// id returns the ID at the current cursor position
+func (c *blockCursor) id() uint64 {
+	return c.ids[c.pos]
+}
+
+// value returns the value at the current cursor position
+func (c *blockCursor) value() int64 {
+	return c.values[c.pos]
+}
+
+// Join iterates both files in ID order and calls fn for each row selected by mode.
+// Only one block of each file is held in memory at a time. Both files must have
+// strictly increasing IDs across blocks, which is what SimpleWriter produces.
+// Iteration stops early if fn returns false.
+func Join(a, b *Reader, mode JoinMode, fn func(row JoinRow) bool) error {
+	if mode != JoinInner && mode != JoinLeft && mode != JoinOuter {
+		return fmt.Errorf("unsupported join mode: %d", mode)
+	}
+
+	left := newBlockCursor(a)
+	right := newBlockCursor(b)
+
+	hasLeft, err := left.next()
+	if err != nil {
+		return fmt.Errorf("left file: %w", err)
+	}
+	hasRight, err := right.next()
+	if err != nil {
+		return fmt.Errorf("right file: %w", err)
+	}
+
+	for hasLeft || hasRight {
+		var row JoinRow
+		advanceLeft, advanceRight := false, false
+
+		switch {
+		case hasLeft && hasRight && left.id() == right.id():
+			row = JoinRow{ID: left.id(), ValueA: left.value(), ValueB: right.value(), HasA: true, HasB: true}
+			advanceLeft, advanceRight = true, true
+		case hasLeft && (!hasRight || left.id() < right.id()):
+			row = JoinRow{ID: left.id(), ValueA: left.value(), HasA: true}
+			advanceLeft = true
+		default:
+			row = JoinRow{ID: right.id(), ValueB: right.value(), HasB: true}
+			advanceRight = true
+		}
+
+		// Decide whether this row is part of the result for the requested mode
+		emit := (row.HasA && row.HasB) ||
+			(mode == JoinLeft && row.HasA) ||
+			mode == JoinOuter
+		if emit && !fn(row) {
+			return nil
+		}
+
+		// Once one side is exhausted, inner and left joins cannot produce more rows
+		if !hasLeft && mode != JoinOuter {
+			return nil
+		}
+		if !hasRight && mode == JoinInner {
+			return nil
+		}
+
+		if advanceLeft {
+			if hasLeft, err = left.next(); err != nil {
+				return fmt.Errorf("left file: %w", err)
+			}
+		}
+		if advanceRight {
+			if hasRight, err = right.next(); err != nil {
+				return fmt.Errorf("right file: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
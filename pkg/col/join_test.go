@@ -0,0 +1,108 @@
+package col
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeJoinTestFile writes the given blocks to a new file and opens a reader for it
+func writeJoinTestFile(t *testing.T, dir, name string, blocks [][]uint64) *Reader {
+	path := filepath.Join(dir, name)
+	writer, err := NewWriter(path)
+	require.NoError(t, err)
+
+	for _, ids := range blocks {
+		values := make([]int64, len(ids))
+		for i, id := range ids {
+			values[i] = int64(id) * 10
+		}
+		require.NoError(t, writer.WriteBlock(ids, values))
+	}
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := NewReader(path)
+	require.NoError(t, err)
+	return reader
+}
+
+func TestJoin(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-join-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	// Left spans two blocks, right spans three blocks with different boundaries
+	left := writeJoinTestFile(t, tempDir, "left.col", [][]uint64{{1, 2, 3}, {5, 7}})
+	defer left.Close()
+	right := writeJoinTestFile(t, tempDir, "right.col", [][]uint64{{2}, {3, 4}, {7, 9}})
+	defer right.Close()
+
+	collect := func(mode JoinMode) []JoinRow {
+		var rows []JoinRow
+		err := Join(left, right, mode, func(row JoinRow) bool {
+			rows = append(rows, row)
+			return true
+		})
+		require.NoError(t, err)
+		return rows
+	}
+
+	t.Run("Inner", func(t *testing.T) {
+		rows := collect(JoinInner)
+		assert.Equal(t, []JoinRow{
+			{ID: 2, ValueA: 20, ValueB: 20, HasA: true, HasB: true},
+			{ID: 3, ValueA: 30, ValueB: 30, HasA: true, HasB: true},
+			{ID: 7, ValueA: 70, ValueB: 70, HasA: true, HasB: true},
+		}, rows)
+	})
+
+	t.Run("Left", func(t *testing.T) {
+		rows := collect(JoinLeft)
+		ids := make([]uint64, len(rows))
+		for i, row := range rows {
+			ids[i] = row.ID
+			assert.True(t, row.HasA)
+		}
+		assert.Equal(t, []uint64{1, 2, 3, 5, 7}, ids)
+		assert.False(t, rows[0].HasB)
+		assert.True(t, rows[1].HasB)
+	})
+
+	t.Run("Outer", func(t *testing.T) {
+		rows := collect(JoinOuter)
+		ids := make([]uint64, len(rows))
+		for i, row := range rows {
+			ids[i] = row.ID
+		}
+		assert.Equal(t, []uint64{1, 2, 3, 4, 5, 7, 9}, ids)
+		assert.Equal(t, JoinRow{ID: 9, ValueB: 90, HasB: true}, rows[len(rows)-1])
+	})
+
+	t.Run("StopEarly", func(t *testing.T) {
+		var count int
+		err := Join(left, right, JoinOuter, func(row JoinRow) bool {
+			count++
+			return count < 2
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+}
+
+func TestJoinUnsortedFile(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-join-unsorted-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	// The second block starts below the end of the first one
+	left := writeJoinTestFile(t, tempDir, "left.col", [][]uint64{{5, 6}, {1, 2}})
+	defer left.Close()
+	right := writeJoinTestFile(t, tempDir, "right.col", [][]uint64{{1, 2, 5, 6}})
+	defer right.Close()
+
+	err = Join(left, right, JoinInner, func(row JoinRow) bool { return true })
+	assert.Error(t, err)
+}
@@ -0,0 +1,89 @@
+package col
+
+import (
+	"io"
+	"math"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadBlocksBeyond4GB(t *testing.T) {
+	if testing.Short() {
+		t.Skip("writes a sparse file larger than 4GB")
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("files on Windows are not sparse unless marked as such")
+	}
+
+	filePath := filepath.Join(t.TempDir(), "large.col")
+	w, err := NewWriter(filePath, WithEncoding(EncodingVarIntBoth), WithChecksum(ChecksumNone))
+	require.NoError(t, err)
+	require.NoError(t, w.WriteBlock([]uint64{1, 2, 3}, []int64{10, 20, 30}))
+
+	// Leave a hole, so the following blocks, the bitmap and the footer start
+	// beyond the range of 32-bit offsets without writing gigabytes of data
+	const offset = 5 << 30
+	_, err = w.file.Seek(offset, io.SeekStart)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteBlock([]uint64{100, 200}, []int64{-5, 7}))
+	require.NoError(t, w.WriteBlock([]uint64{300}, []int64{42}))
+	require.NoError(t, w.FinalizeAndClose())
+
+	r, err := NewReader(filePath)
+	require.NoError(t, err)
+	defer r.Close()
+
+	require.Equal(t, uint64(3), r.BlockCount())
+	assert.Equal(t, uint64(offset), r.blockIndex[1].BlockOffset)
+	assert.Greater(t, r.blockIndex[2].BlockOffset, uint64(math.MaxUint32))
+	assert.Greater(t, r.header.BitmapOffset, uint64(math.MaxUint32))
+
+	ids, values, err := r.GetPairs(1)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{100, 200}, ids)
+	assert.Equal(t, []int64{-5, 7}, values)
+
+	value, found, err := r.Get(300)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, int64(42), value)
+
+	result := r.Aggregate()
+	assert.Equal(t, uint64(6), result.Count)
+	assert.Equal(t, int64(104), result.Sum)
+
+	bitmap, err := r.GetGlobalIDBitmap()
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1, 2, 3, 100, 200, 300}, bitmap.ToArray())
+}
+
+func TestCheckBlockSize(t *testing.T) {
+	assert.NoError(t, checkBlockSize(1, maxBlockSize))
+	assert.Error(t, checkBlockSize(1, maxBlockSize+1))
+
+	// Counts beyond uint32 cannot be represented on 32-bit platforms
+	count := uint64(math.MaxUint32) + 1
+	if uint64(int(count)) == count {
+		assert.Error(t, checkBlockSize(int(count), blockHeaderSize+blockLayoutSize))
+	}
+}
+
+func TestSimpleWriterRejectsOversizedTarget(t *testing.T) {
+	size := uint64(maxBlockSize) + 1
+	if uint64(int(size)) != size {
+		t.Skip("target block sizes beyond uint32 cannot be represented as int")
+	}
+
+	_, err := NewSimpleWriter(filepath.Join(t.TempDir(), "oversized.col"), WithTargetBlockSize(int(size)))
+	assert.Error(t, err)
+
+	writer, err := NewSimpleWriter(filepath.Join(t.TempDir(), "target.col"))
+	require.NoError(t, err)
+	assert.Error(t, writer.SetTargetBlockSize(int(size)))
+	assert.Error(t, writer.SetTargetBlockSize(-1))
+	require.NoError(t, writer.Close())
+}
@@ -0,0 +1,116 @@
+package col
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaviate/sroar"
+)
+
+func TestNewReaderLazy(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-lazy-reader-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	filePath := filepath.Join(tempDir, "lazy.col")
+	writer, err := NewWriter(filePath, WithEncoding(EncodingDeltaBoth))
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteBlock([]uint64{1, 2, 3}, []int64{10, 20, 30}))
+	require.NoError(t, writer.WriteBlock([]uint64{4, 5}, []int64{40, 50}))
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := NewReaderLazy(filePath)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	// Header metadata is available without touching the footer
+	header := reader.HeaderOnly()
+	assert.Equal(t, MagicNumber, header.Magic)
+	assert.Equal(t, uint64(2), header.BlockCount)
+	assert.Equal(t, EncodingDeltaBoth, header.EncodingType)
+	assert.Nil(t, reader.blockIndex, "Footer should not be parsed yet")
+
+	// The first operation that needs the block index loads the footer
+	ids, values, err := reader.GetPairs(1)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{4, 5}, ids)
+	assert.Equal(t, []int64{40, 50}, values)
+	assert.Len(t, reader.blockIndex, 2)
+
+	result := reader.Aggregate()
+	assert.Equal(t, uint64(5), result.Count)
+	assert.Equal(t, int64(150), result.Sum)
+}
+
+func TestNewReaderLazyMissingFooter(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-lazy-reader-missing-footer")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	// Write a file without finalizing it, so it has a header but no footer
+	filePath := filepath.Join(tempDir, "unfinalized.col")
+	writer, err := NewWriter(filePath)
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteBlock([]uint64{1, 2}, []int64{10, 20}))
+	require.NoError(t, writer.Close())
+
+	// The eager reader fails immediately
+	_, err = NewReader(filePath)
+	assert.Error(t, err)
+
+	// The lazy reader opens, but fails once the footer is needed
+	reader, err := NewReaderLazy(filePath)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	assert.Equal(t, MagicNumber, reader.HeaderOnly().Magic)
+	_, _, err = reader.GetPairs(0)
+	assert.Error(t, err)
+}
+
+func TestNewReaderLazyTruncatedFile(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-lazy-reader-truncated")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	filePath := filepath.Join(tempDir, "truncated.col")
+	writer, err := NewWriter(filePath)
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteBlock([]uint64{1, 2}, []int64{10, 20}))
+	require.NoError(t, writer.FinalizeAndClose())
+
+	// Cut off the footer metadata, so the footer cannot be located
+	info, err := os.Stat(filePath)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(filePath, info.Size()-footerMetaSize/2))
+
+	reader, err := NewReaderLazy(filePath)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	result := reader.Aggregate()
+	assert.False(t, result.Valid())
+	assert.Error(t, result.Err())
+
+	filter := sroar.NewBitmap()
+	filter.Set(1)
+	result = reader.AggregateWithOptions(AggregateOptions{Filter: filter, DenyFilter: filter})
+	assert.Error(t, result.Err())
+
+	// The target's footer error is returned even though no ID matches
+	var buf bytes.Buffer
+	other, err := NewWriterToBuffer(&buf)
+	require.NoError(t, err)
+	require.NoError(t, other.WriteBlock([]uint64{1, 2}, []int64{10, 20}))
+	require.NoError(t, other.FinalizeAndClose())
+	otherReader, err := NewReaderFromBytes(buf.Bytes())
+	require.NoError(t, err)
+	defer otherReader.Close()
+
+	_, err = AggregateWhere(reader, map[*Reader]Pred{otherReader: Gt(Col, Const(100))})
+	assert.Error(t, err)
+}
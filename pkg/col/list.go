@@ -0,0 +1,209 @@
+package col
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// listSectionHeaderSize is the size of the list count and the size of the
+// encoded IDs at the start of the ID section of a list column block
+const listSectionHeaderSize = 8
+
+// WriteBlockLists writes a block of a DataTypeInt64List column, in which every
+// ID maps to a list of values. Lists may be empty, but the block must hold at
+// least one value. The statistics of the block, and therefore all
+// aggregations, describe the flattened values. Unlike WriteBlock, the block is
+// written as a whole even if it exceeds the target block size.
+func (w *Writer) WriteBlockLists(ids []uint64, lists [][]int64, options ...BlockOption) error {
+	if w.dataType != DataTypeInt64List {
+		return fmt.Errorf("cannot write lists to a column of data type %d", w.dataType)
+	}
+	config := blockConfig{
+		encodingType:    w.encodingType,
+		compressionType: CompressionNone,
+	}
+	for _, option := range options {
+		option(&config)
+	}
+	if config.compressionType != CompressionNone {
+		return fmt.Errorf("unsupported compression type: %d", config.compressionType)
+	}
+	if len(ids) != len(lists) {
+		return errors.New("ids and lists must have the same length")
+	}
+
+	lengths := make([]uint64, len(lists))
+	var values []int64
+	for i, list := range lists {
+		lengths[i] = uint64(len(list))
+		values = append(values, list...)
+	}
+	if len(values) == 0 {
+		return errors.New("block must contain at least one value")
+	}
+
+	idSection, err := encodeListIDSection(ids, lengths, config.encodingType)
+	if err != nil {
+		return err
+	}
+	encodedValues, encodedValueBytes, _, err := encodeValues(values, config.encodingType, w.dataType)
+	if err != nil {
+		return err
+	}
+	valueSection := appendSection(nil, encodedValues, encodedValueBytes)
+
+	return w.writeEncodedBlock(ids, values, idSection, valueSection, config)
+}
+
+// encodeListIDSection encodes the ID section of a list column block: the
+// number of lists and the size of the encoded IDs (4 bytes each), the IDs
+// encoded like the ID section of other blocks, and the list lengths as
+// VarInts
+func encodeListIDSection(ids []uint64, lengths []uint64, encodingType uint32) ([]byte, error) {
+	encodedIDs, encodedIDBytes, idsSize, err := encodeIDs(ids, encodingType)
+	if err != nil {
+		return nil, err
+	}
+
+	section := make([]byte, listSectionHeaderSize, listSectionHeaderSize+int(idsSize)+len(lengths))
+	binary.LittleEndian.PutUint32(section[0:], uint32(len(ids)))
+	binary.LittleEndian.PutUint32(section[4:], idsSize)
+	section = appendSection(section, encodedIDs, encodedIDBytes)
+	for _, length := range lengths {
+		section = binary.AppendUvarint(section, length)
+	}
+	return section, nil
+}
+
+// decodeListIDSection decodes the IDs and list lengths from the ID section of
+// a list column block
+func decodeListIDSection(idBytes []byte, encoding sectionEncoding) ([]uint64, []uint64, error) {
+	if len(idBytes) < listSectionHeaderSize {
+		return nil, nil, fmt.Errorf("list section too small: %d bytes", len(idBytes))
+	}
+	// Every list takes up at least one byte for its ID and one for its length
+	listCount := binary.LittleEndian.Uint32(idBytes[0:])
+	idsSize := binary.LittleEndian.Uint32(idBytes[4:])
+	if uint64(idsSize) > uint64(len(idBytes)-listSectionHeaderSize) {
+		return nil, nil, fmt.Errorf("list IDs exceed the section: %d bytes, section has %d",
+			uint64(listSectionHeaderSize)+uint64(idsSize), len(idBytes))
+	}
+	if uint64(listCount) > uint64(len(idBytes)) {
+		return nil, nil, fmt.Errorf("%d lists exceed the section of %d bytes", listCount, len(idBytes))
+	}
+	count := int(listCount)
+	idsEnd := listSectionHeaderSize + int(idsSize)
+
+	ids, err := decodeIDSection(idBytes[listSectionHeaderSize:idsEnd], count, encoding, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(ids) != count {
+		return nil, nil, fmt.Errorf("list section holds %d IDs, expected %d", len(ids), count)
+	}
+
+	lengths := make([]uint64, count)
+	offset := idsEnd
+	for i := range lengths {
+		length, n := binary.Uvarint(idBytes[offset:])
+		if n <= 0 {
+			return nil, nil, fmt.Errorf("failed to decode the length of list %d", i)
+		}
+		lengths[i] = length
+		offset += n
+	}
+	return ids, lengths, nil
+}
+
+// decodeBlockIDs decodes the IDs of a block of a column with the given data
+// type, reusing the backing array of idsBuf if it is large enough. The IDs of
+// list columns are repeated for every value of their list, so they line up
+// with the flattened values.
+func decodeBlockIDs(idBytes []byte, count int, encoding sectionEncoding, dataType uint32, idsBuf []uint64) ([]uint64, error) {
+	if dataType != DataTypeInt64List {
+		return decodeIDSection(idBytes, count, encoding, idsBuf)
+	}
+
+	listIDs, lengths, err := decodeListIDSection(idBytes, encoding)
+	if err != nil {
+		return nil, err
+	}
+	ids := resizeUint64s(idsBuf, count)[:0]
+	for i, length := range lengths {
+		if uint64(len(ids))+length > uint64(count) {
+			return nil, fmt.Errorf("lists hold more than %d values", count)
+		}
+		for j := uint64(0); j < length; j++ {
+			ids = append(ids, listIDs[i])
+		}
+	}
+	if len(ids) != count {
+		return nil, fmt.Errorf("lists hold %d values, expected %d", len(ids), count)
+	}
+	return ids, nil
+}
+
+// ReadBlockLists returns the IDs of a block of a DataTypeInt64List column with
+// their lists of values, including empty lists. ReadBlock and the other
+// pair-based APIs return the flattened values instead, with the ID of a list
+// repeated for each of its values.
+func (r *Reader) ReadBlockLists(id BlockID) ([]uint64, [][]int64, error) {
+	if r.header.ColumnType != DataTypeInt64List {
+		return nil, nil, fmt.Errorf("cannot read lists from a column of data type %d", r.header.ColumnType)
+	}
+	r.recordAccess(id)
+
+	sections, release, err := r.readBlockSections(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer release()
+
+	idEncoding, valueEncoding, err := sectionEncodings(sections.encodingType)
+	if err != nil {
+		return nil, nil, err
+	}
+	ids, lengths, err := decodeListIDSection(sections.idBytes, idEncoding)
+	if err != nil {
+		return nil, nil, err
+	}
+	values := decodeValueSection(sections.valueBytes, sections.count, valueEncoding, r.header.ColumnType, nil)
+
+	lists := make([][]int64, len(ids))
+	offset := uint64(0)
+	for i, length := range lengths {
+		if offset+length > uint64(len(values)) {
+			return nil, nil, fmt.Errorf("lists hold more than %d values", len(values))
+		}
+		lists[i] = values[offset : offset+length : offset+length]
+		offset += length
+	}
+	return ids, lists, nil
+}
+
+// GetList returns the list of values stored for id in a DataTypeInt64List
+// column and whether the file contains the ID. If the ID is stored in several
+// blocks, the list of the last of these blocks is returned, like Get.
+func (r *Reader) GetList(id uint64) ([]int64, bool, error) {
+	if r.header.ColumnType != DataTypeInt64List {
+		return nil, false, fmt.Errorf("cannot read lists from a column of data type %d", r.header.ColumnType)
+	}
+	if err := r.ensureFooter(); err != nil {
+		return nil, false, err
+	}
+
+	blocks := r.blocksInIDRange(id, id)
+	for i := len(blocks) - 1; i >= 0; i-- {
+		ids, lists, err := r.ReadBlockLists(blocks[i])
+		if err != nil {
+			return nil, false, err
+		}
+		for j := len(ids) - 1; j >= 0; j-- {
+			if ids[j] == id {
+				return lists[j], true, nil
+			}
+		}
+	}
+	return nil, false, nil
+}
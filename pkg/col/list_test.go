@@ -0,0 +1,87 @@
+package col
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaviate/sroar"
+)
+
+func TestListColumn(t *testing.T) {
+	for _, encoding := range []uint32{EncodingRaw, EncodingVarIntBoth, EncodingDeltaDelta} {
+		var buf bytes.Buffer
+		writer, err := NewWriterToBuffer(&buf, WithDataType(DataTypeInt64List), WithEncoding(encoding))
+		require.NoError(t, err)
+
+		require.NoError(t, writer.WriteBlockLists(
+			[]uint64{1, 2, 3, 4},
+			[][]int64{{5, -3}, {}, {7}, {1, 1, 1}},
+		))
+		require.NoError(t, writer.WriteBlockLists([]uint64{10, 12}, [][]int64{{100}, {-20, 30}}))
+		assert.Error(t, writer.WriteBlock([]uint64{20}, []int64{1}))
+		assert.Error(t, writer.WriteBlockLists([]uint64{20}, [][]int64{{}}))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		reader, err := NewReaderFromBytes(buf.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, DataTypeInt64List, reader.DataType())
+
+		ids, lists, err := reader.ReadBlockLists(0)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{1, 2, 3, 4}, ids)
+		assert.Equal(t, [][]int64{{5, -3}, {}, {7}, {1, 1, 1}}, lists)
+
+		// The pair-based APIs see the flattened values
+		ids, values, err := reader.ReadBlock(0)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{1, 1, 3, 4, 4, 4}, ids)
+		assert.Equal(t, []int64{5, -3, 7, 1, 1, 1}, values)
+		blockIDs, err := reader.ReadBlockIDs(1)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{10, 12, 12}, blockIDs)
+
+		list, found, err := reader.GetList(12)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, []int64{-20, 30}, list)
+		list, found, err = reader.GetList(2)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Empty(t, list)
+		_, found, err = reader.GetList(11)
+		require.NoError(t, err)
+		assert.False(t, found)
+
+		// Aggregations cover the flattened values
+		for _, opts := range []AggregateOptions{{}, {SkipPreCalculated: true}} {
+			result := reader.AggregateWithOptions(opts)
+			assert.Equal(t, uint64(9), result.Count, "encoding %d", encoding)
+			assert.Equal(t, int64(-20), result.Min)
+			assert.Equal(t, int64(100), result.Max)
+			assert.Equal(t, int64(122), result.Sum)
+		}
+		filter := sroar.NewBitmap()
+		filter.SetMany([]uint64{1, 12})
+		result := reader.AggregateWithOptions(AggregateOptions{Filter: filter})
+		assert.Equal(t, uint64(4), result.Count)
+		assert.Equal(t, int64(12), result.Sum)
+	}
+}
+
+func TestListColumnRejectsOtherTypes(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewWriterToBuffer(&buf)
+	require.NoError(t, err)
+	assert.Error(t, writer.WriteBlockLists([]uint64{1}, [][]int64{{1}}))
+	require.NoError(t, writer.WriteBlock([]uint64{1}, []int64{1}))
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := NewReaderFromBytes(buf.Bytes())
+	require.NoError(t, err)
+	_, _, err = reader.GetList(1)
+	assert.Error(t, err)
+	_, _, err = reader.ReadBlockLists(0)
+	assert.Error(t, err)
+}
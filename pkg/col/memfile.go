@@ -0,0 +1,91 @@
+package col
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// readerFile is the storage a Reader reads from, usually an *os.File
+type readerFile interface {
+	io.ReaderAt
+	io.Closer
+}
+
+// writerFile is the storage a Writer writes to, usually an *os.File. The writer
+// seeks back to rewrite the file header when finalizing.
+type writerFile interface {
+	io.Writer
+	io.Seeker
+	Sync() error
+	Close() error
+}
+
+// bytesFile is a readerFile over a byte slice
+type bytesFile struct {
+	*bytes.Reader
+}
+
+// Close does nothing, the data stays owned by the caller
+func (bytesFile) Close() error {
+	return nil
+}
+
+// bufferFile is a seekable in-memory writerFile. Its contents are appended to
+// dst when it is closed.
+type bufferFile struct {
+	data   []byte
+	pos    int64
+	dst    *bytes.Buffer
+	closed bool
+}
+
+// Write writes p at the current position, growing the data as needed
+func (f *bufferFile) Write(p []byte) (int, error) {
+	if f.closed {
+		return 0, errors.New("write to closed buffer")
+	}
+	end := f.pos + int64(len(p))
+	if end > int64(len(f.data)) {
+		f.data = append(f.data, make([]byte, end-int64(len(f.data)))...)
+	}
+	copy(f.data[f.pos:], p)
+	f.pos = end
+	return len(p), nil
+}
+
+// Seek sets the position for the next write
+func (f *bufferFile) Seek(offset int64, whence int) (int64, error) {
+	var pos int64
+	switch whence {
+	case io.SeekStart:
+		pos = offset
+	case io.SeekCurrent:
+		pos = f.pos + offset
+	case io.SeekEnd:
+		pos = int64(len(f.data)) + offset
+	default:
+		return 0, fmt.Errorf("invalid whence: %d", whence)
+	}
+	if pos < 0 {
+		return 0, fmt.Errorf("negative position: %d", pos)
+	}
+	f.pos = pos
+	return pos, nil
+}
+
+// Sync does nothing, the data is only kept in memory
+func (f *bufferFile) Sync() error {
+	return nil
+}
+
+// Close appends the data to the destination buffer
+func (f *bufferFile) Close() error {
+	if f.closed {
+		return errors.New("buffer already closed")
+	}
+	f.closed = true
+	_, err := f.dst.Write(f.data)
+	return err
+}
@@ -0,0 +1,84 @@
+package col
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryFiles(t *testing.T) {
+	ids := []uint64{1, 5, 9, 200}
+	values := []int64{-3, 0, 12, 7}
+
+	writeAll := func(t *testing.T, w *Writer) {
+		require.NoError(t, w.WriteBlock(ids[:2], values[:2]))
+		require.NoError(t, w.WriteBlock(ids[2:], values[2:]))
+		require.NoError(t, w.FinalizeAndClose())
+	}
+
+	t.Run("Round trip", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer, err := NewWriterToBuffer(&buf, WithEncoding(EncodingVarIntBoth))
+		require.NoError(t, err)
+		writeAll(t, writer)
+
+		reader, err := NewReaderFromBytes(buf.Bytes())
+		require.NoError(t, err)
+		defer reader.Close()
+
+		readIDs, readValues := readAllPairs(t, reader)
+		assert.Equal(t, ids, readIDs)
+		assert.Equal(t, values, readValues)
+		assert.Equal(t, int64(12), reader.Aggregate().Max)
+	})
+
+	t.Run("Same bytes as a file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "file.col")
+		fileWriter, err := NewWriter(path)
+		require.NoError(t, err)
+		writeAll(t, fileWriter)
+
+		var buf bytes.Buffer
+		bufWriter, err := NewWriterToBuffer(&buf)
+		require.NoError(t, err)
+		writeAll(t, bufWriter)
+
+		fileData, err := os.ReadFile(path)
+		require.NoError(t, err)
+		bufData := buf.Bytes()
+		require.Equal(t, len(fileData), len(bufData))
+
+		// The creation times in the headers may differ
+		clear(fileData[36:44])
+		clear(bufData[36:44])
+		assert.Equal(t, fileData, bufData)
+	})
+
+	t.Run("Nothing is written before closing", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer, err := NewWriterToBuffer(&buf)
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlock(ids, values))
+		assert.Zero(t, buf.Len())
+		require.NoError(t, writer.Finalize())
+		assert.Zero(t, buf.Len())
+		require.NoError(t, writer.Close())
+		assert.NotZero(t, buf.Len())
+	})
+
+	t.Run("Invalid data", func(t *testing.T) {
+		_, err := NewReaderFromBytes([]byte("not a column file"))
+		assert.Error(t, err)
+
+		var buf bytes.Buffer
+		writer, err := NewWriterToBuffer(&buf)
+		require.NoError(t, err)
+		writeAll(t, writer)
+		_, err = NewReaderFromBytes(buf.Bytes()[:buf.Len()-10])
+		assert.Error(t, err)
+	})
+}
@@ -118,3 +118,66 @@ func TestCalculatePadding(t *testing.T) {
 		}
 	}
 }
+
+func TestPaddingPolicy(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-padding-policy-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	writeFile := func(name string, options ...WriterOption) (string, int64) {
+		filePath := filepath.Join(tempDir, name)
+		writer, err := NewWriter(filePath, append(options, WithEncoding(EncodingVarIntBoth))...)
+		require.NoError(t, err)
+		for block := uint64(0); block < 3; block++ {
+			ids := []uint64{block*10 + 1, block*10 + 2, block*10 + 3}
+			require.NoError(t, writer.WriteBlock(ids, []int64{1, -2, 3}))
+		}
+		require.NoError(t, writer.FinalizeAndClose())
+
+		info, err := os.Stat(filePath)
+		require.NoError(t, err)
+		return filePath, info.Size()
+	}
+
+	alignedPath, alignedSize := writeFile("aligned.col")
+	defaultPath, _ := writeFile("default.col", WithPadding(PaddingPageAligned))
+	nonePath, noneSize := writeFile("none.col", WithPadding(PaddingNone))
+	boundaryPath, _ := writeFile("boundary.col", WithPadding(PaddingBoundary(512)))
+
+	assert.Less(t, noneSize, alignedSize, "Unpadded file should be smaller")
+
+	cases := []struct {
+		path      string
+		alignment uint64
+	}{
+		{alignedPath, uint64(PageSize)},
+		{defaultPath, uint64(PageSize)},
+		{nonePath, 1},
+		{boundaryPath, 512},
+	}
+	for _, c := range cases {
+		reader, err := NewReader(c.path)
+		require.NoError(t, err)
+
+		require.Equal(t, uint64(3), reader.BlockCount())
+		for i, entry := range reader.blockIndex {
+			if i > 0 {
+				assert.Zero(t, entry.BlockOffset%c.alignment, "%s: block %d is not aligned", c.path, i)
+			}
+			ids, values, err := reader.ReadBlock(BlockID(i))
+			require.NoError(t, err)
+			assert.Equal(t, []uint64{uint64(i)*10 + 1, uint64(i)*10 + 2, uint64(i)*10 + 3}, ids)
+			assert.Equal(t, []int64{1, -2, 3}, values)
+		}
+		if c.alignment == 1 {
+			// Without padding the blocks are stored back to back
+			assert.Equal(t, reader.blockIndex[0].BlockOffset+uint64(reader.blockIndex[0].BlockSize), reader.blockIndex[1].BlockOffset)
+		}
+
+		bitmap, err := reader.GetGlobalIDBitmap()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(9), uint64(bitmap.GetCardinality()))
+
+		require.NoError(t, reader.Close())
+	}
+}
@@ -0,0 +1,102 @@
+package col
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"vibe-lsm/pkg/col/format"
+)
+
+// WithBlockPartition tags a block with an opaque partition key, e.g. a tenant
+// ID or shard. The key is stored in the partitions footer section and lets
+// aggregations skip the blocks of other partitions via
+// AggregateOptions.PartitionFilter. Blocks without a key have key 0.
+func WithBlockPartition(key uint64) BlockOption {
+	return func(c *blockConfig) {
+		c.partition = key
+	}
+}
+
+// partitioned reports whether any block written so far has a partition key
+func (w *Writer) partitioned() bool {
+	for _, stats := range w.blockStats {
+		if stats.partition != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// partitionsSection returns the footer section with the partition key of
+// every block
+func (w *Writer) partitionsSection() format.FooterSection {
+	payload := make([]byte, len(w.blockStats)*partitionEntrySize)
+	for i, stats := range w.blockStats {
+		binary.LittleEndian.PutUint64(payload[i*partitionEntrySize:], stats.partition)
+	}
+
+	return format.FooterSection{Type: FooterSectionPartitions, Payload: payload}
+}
+
+// parsePartitionsSection parses the footer section with the per-block
+// partition keys
+func (r *Reader) parsePartitionsSection(payload []byte) error {
+	if len(payload) != len(r.blockIndex)*partitionEntrySize {
+		return fmt.Errorf("partitions section size mismatch: expected=%d, actual=%d",
+			len(r.blockIndex)*partitionEntrySize, len(payload))
+	}
+
+	r.partitions = make([]uint64, len(r.blockIndex))
+	for i := range r.partitions {
+		r.partitions[i] = readBufferedUint64(payload, i*partitionEntrySize)
+	}
+
+	return nil
+}
+
+// BlockPartition returns the partition key of a block as set with
+// WithBlockPartition, or 0 if the block has none
+func (r *Reader) BlockPartition(id BlockID) (uint64, error) {
+	if err := r.ensureFooter(); err != nil {
+		return 0, err
+	}
+	if id >= BlockID(len(r.blockIndex)) {
+		return 0, fmt.Errorf("invalid block index: %d", id)
+	}
+	return r.blockPartition(int(id)), nil
+}
+
+// blockPartition returns the partition key of a block, 0 for files without
+// the partitions section
+func (r *Reader) blockPartition(blockIndex int) uint64 {
+	if r.partitions == nil {
+		return 0
+	}
+	return r.partitions[blockIndex]
+}
+
+// partitioned reports whether any block of the file has a partition key
+func (r *Reader) partitioned() bool {
+	for _, partition := range r.partitions {
+		if partition != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// partitionBlocks returns the blocks whose partition key is one of keys
+func (r *Reader) partitionBlocks(blocks []uint64, keys []uint64) []uint64 {
+	allowed := make(map[uint64]struct{}, len(keys))
+	for _, key := range keys {
+		allowed[key] = struct{}{}
+	}
+
+	matching := make([]uint64, 0, len(blocks))
+	for _, blockIdx := range blocks {
+		if _, ok := allowed[r.blockPartition(int(blockIdx))]; ok {
+			matching = append(matching, blockIdx)
+		}
+	}
+	return matching
+}
@@ -0,0 +1,181 @@
+package col
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaviate/sroar"
+)
+
+func TestBlockPartitions(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "partitioned.col")
+	writer, err := NewWriter(path)
+	require.NoError(t, err)
+
+	// Blocks 0 and 2 belong to tenant 7, block 1 to tenant 9, block 3 has no key
+	tenants := []uint64{7, 9, 7, 0}
+	for b, tenant := range tenants {
+		ids := make([]uint64, 100)
+		values := make([]int64, 100)
+		for i := range ids {
+			ids[i] = uint64(b*100 + i)
+			values[i] = int64(b + 1)
+		}
+		require.NoError(t, writer.WriteBlockWithOptions(ids, values, WithBlockPartition(tenant)))
+	}
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := NewReader(path)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	t.Run("Footer", func(t *testing.T) {
+		for b, tenant := range tenants {
+			key, err := reader.BlockPartition(BlockID(b))
+			require.NoError(t, err)
+			assert.Equal(t, tenant, key)
+
+			meta, err := reader.BlockMeta(BlockID(b))
+			require.NoError(t, err)
+			assert.Equal(t, tenant, meta.Partition)
+		}
+		_, err := reader.BlockPartition(BlockID(len(tenants)))
+		assert.Error(t, err)
+	})
+
+	t.Run("PartitionFilter", func(t *testing.T) {
+		for _, skip := range []bool{false, true} {
+			result := reader.AggregateWithOptions(AggregateOptions{PartitionFilter: []uint64{7}, SkipPreCalculated: skip})
+			assert.Equal(t, uint64(200), result.Count)
+			assert.Equal(t, int64(100+300), result.Sum)
+
+			result = reader.AggregateWithOptions(AggregateOptions{PartitionFilter: []uint64{9, 0}, SkipPreCalculated: skip})
+			assert.Equal(t, uint64(200), result.Count)
+			assert.Equal(t, int64(200+400), result.Sum)
+		}
+
+		// The ID filter applies within the selected partitions
+		filter := sroar.NewBitmap()
+		filter.SetMany([]uint64{50, 150, 250})
+		result := reader.AggregateWithOptions(AggregateOptions{PartitionFilter: []uint64{7}, Filter: filter})
+		assert.Equal(t, uint64(2), result.Count)
+		assert.Equal(t, int64(1+3), result.Sum)
+
+		result = reader.AggregateWithOptions(AggregateOptions{PartitionFilter: []uint64{7}, Parallel: 2})
+		assert.Equal(t, uint64(200), result.Count)
+
+		assert.Equal(t, AggregateResult{}, reader.AggregateWithOptions(AggregateOptions{PartitionFilter: []uint64{}}))
+		assert.Equal(t, AggregateResult{}, reader.AggregateWithOptions(AggregateOptions{PartitionFilter: []uint64{42}}))
+	})
+
+	t.Run("Concat", func(t *testing.T) {
+		dst := filepath.Join(dir, "concat.col")
+		require.NoError(t, Concat(dst, path))
+
+		concat, err := NewReader(dst)
+		require.NoError(t, err)
+		defer concat.Close()
+		for b, tenant := range tenants {
+			key, err := concat.BlockPartition(BlockID(b))
+			require.NoError(t, err)
+			assert.Equal(t, tenant, key)
+		}
+	})
+
+	t.Run("Unpartitioned", func(t *testing.T) {
+		unpartitioned := filepath.Join(dir, "plain.col")
+		writer, err := NewWriter(unpartitioned)
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlock([]uint64{1, 2}, []int64{3, 4}))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		plain, err := NewReader(unpartitioned)
+		require.NoError(t, err)
+		defer plain.Close()
+		info, err := plain.FooterInfo()
+		require.NoError(t, err)
+		for _, section := range info.Sections {
+			assert.NotEqual(t, FooterSectionPartitions, section.Type)
+		}
+		assert.Equal(t, uint64(2), plain.AggregateWithOptions(AggregateOptions{PartitionFilter: []uint64{0}}).Count)
+	})
+}
+
+func TestPartitionsOfRewrittenBlocks(t *testing.T) {
+	// Blocks of partitions 1, none and 2
+	var buf bytes.Buffer
+	writer, err := NewWriterToBuffer(&buf, WithEncoding(EncodingVarIntBoth))
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteBlockWithOptions([]uint64{1, 2, 3}, []int64{10, 20, 30}, WithBlockPartition(1)))
+	require.NoError(t, writer.WriteBlock([]uint64{4, 5}, []int64{40, 50}))
+	require.NoError(t, writer.WriteBlockWithOptions([]uint64{8, 9, 10, 11}, []int64{80, 90, 100, 110},
+		WithBlockPartition(2), WithBlockEncoding(EncodingDeltaBoth)))
+	require.NoError(t, writer.FinalizeAndClose())
+	in, err := NewReaderFromBytes(buf.Bytes())
+	require.NoError(t, err)
+	defer in.Close()
+
+	// partitions returns the partition key of every block of the file
+	partitions := func(t *testing.T, reader *Reader) []uint64 {
+		keys := make([]uint64, reader.BlockCount())
+		for b := range keys {
+			key, err := reader.BlockPartition(BlockID(b))
+			require.NoError(t, err)
+			keys[b] = key
+		}
+		return keys
+	}
+	rewrite := func(t *testing.T, fn func(out *Writer) error) *Reader {
+		var out bytes.Buffer
+		writer, err := NewWriterToBuffer(&out)
+		require.NoError(t, err)
+		require.NoError(t, fn(writer))
+		require.NoError(t, writer.FinalizeAndClose())
+		reader, err := NewReaderFromBytes(out.Bytes())
+		require.NoError(t, err)
+		t.Cleanup(func() { reader.Close() })
+		return reader
+	}
+
+	t.Run("Transform", func(t *testing.T) {
+		out := rewrite(t, func(w *Writer) error {
+			return Transform(in, w, func(id uint64, v int64) (int64, bool) { return v + 1, true })
+		})
+		assert.Equal(t, []uint64{1, 0, 2}, partitions(t, out))
+	})
+
+	t.Run("RemapIDs", func(t *testing.T) {
+		mapping := make(map[uint64]uint64)
+		for _, id := range []uint64{1, 2, 3, 4, 5, 8, 9, 10, 11} {
+			mapping[id] = id * 2
+		}
+		out := rewrite(t, func(w *Writer) error { return RemapIDs(in, w, mapping) })
+		assert.Equal(t, []uint64{1, 0, 2}, partitions(t, out))
+	})
+
+	t.Run("Shard", func(t *testing.T) {
+		tempDir := t.TempDir()
+		inPath := filepath.Join(tempDir, "in.col")
+		require.NoError(t, os.WriteFile(inPath, buf.Bytes(), 0644))
+
+		// The last block spans the boundary and is split
+		paths, err := Shard(inPath, []uint64{10}, filepath.Join(tempDir, "shard-%d.col"))
+		require.NoError(t, err)
+		expected := [][]uint64{{1, 0, 2}, {2}}
+		for i, path := range paths {
+			shard, err := NewReader(path)
+			require.NoError(t, err)
+			defer shard.Close()
+			assert.Equal(t, expected[i], partitions(t, shard), "shard %d", i)
+
+			meta, err := shard.BlockMeta(BlockID(shard.BlockCount() - 1))
+			require.NoError(t, err)
+			assert.Equal(t, EncodingDeltaBoth, meta.Encoding, "shard %d", i)
+		}
+	})
+}
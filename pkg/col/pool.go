@@ -0,0 +1,339 @@
+package col
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Default settings for a Pool
+const (
+	defaultPoolMaxOpen     = 256
+	defaultPoolIdleTimeout = 5 * time.Minute
+)
+
+// ErrPoolClosed is returned when acquiring a reader from a closed pool
+var ErrPoolClosed = errors.New("reader pool is closed")
+
+// PoolOption configures a Pool
+type PoolOption func(*Pool)
+
+// WithMaxOpenReaders limits the number of files the pool keeps open. When the
+// limit is reached, the least recently used reader that is not in use is
+// closed; if all readers are in use, Acquire waits for one to be released.
+func WithMaxOpenReaders(n int) PoolOption {
+	return func(p *Pool) {
+		p.maxOpen = n
+	}
+}
+
+// WithIdleTimeout closes readers that have not been used for the given
+// duration. A duration of 0 keeps idle readers open until they are evicted
+// by the open-files limit.
+func WithIdleTimeout(d time.Duration) PoolOption {
+	return func(p *Pool) {
+		p.idleTimeout = d
+	}
+}
+
+// poolEntry is a cached reader together with its usage bookkeeping
+type poolEntry struct {
+	name      string
+	reader    *Reader
+	refs      int           // Number of handles currently using the reader
+	lastUsed  time.Time     // Time the last handle was released
+	element   *list.Element // Position in the LRU list while idle
+	forgotten bool          // Removed by Forget while in use
+}
+
+// Pool caches open Readers for the files of a directory. Readers are opened on
+// first use and shared between concurrent users, so the footer of a file is
+// parsed only once while its reader stays cached.
+type Pool struct {
+	dir         string
+	maxOpen     int
+	idleTimeout time.Duration
+
+	mu        sync.Mutex
+	cond      *sync.Cond
+	entries   map[string]*poolEntry
+	idle      *list.List // Idle entries, least recently used first
+	forgotten int        // Forgotten readers that stay open until released
+	closed    bool
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewPool creates a reader pool for the files in dir
+func NewPool(dir string, options ...PoolOption) (*Pool, error) {
+	p := &Pool{
+		dir:         dir,
+		maxOpen:     defaultPoolMaxOpen,
+		idleTimeout: defaultPoolIdleTimeout,
+		entries:     make(map[string]*poolEntry),
+		idle:        list.New(),
+		stop:        make(chan struct{}),
+	}
+	p.cond = sync.NewCond(&p.mu)
+
+	for _, option := range options {
+		option(p)
+	}
+
+	if p.maxOpen <= 0 {
+		return nil, fmt.Errorf("max open readers must be positive, got %d", p.maxOpen)
+	}
+	if p.idleTimeout < 0 {
+		return nil, fmt.Errorf("idle timeout must not be negative, got %v", p.idleTimeout)
+	}
+
+	if p.idleTimeout > 0 {
+		p.wg.Add(1)
+		go p.evictIdleLoop()
+	}
+
+	return p, nil
+}
+
+// PooledReader is a Reader borrowed from a Pool. Close returns it to the pool
+// instead of closing the file.
+type PooledReader struct {
+	*Reader
+
+	pool  *Pool
+	entry *poolEntry
+	once  sync.Once
+}
+
+// Close releases the reader back to the pool. It is safe to call Close
+// multiple times.
+func (pr *PooledReader) Close() error {
+	pr.once.Do(func() {
+		pr.pool.release(pr.entry)
+	})
+	return nil
+}
+
+// Acquire returns a reader for the file name, relative to the pool directory.
+// The reader must be closed when it is no longer needed.
+func (p *Pool) Acquire(name string) (*PooledReader, error) {
+	p.mu.Lock()
+	for {
+		if p.closed {
+			p.mu.Unlock()
+			return nil, ErrPoolClosed
+		}
+
+		// Reuse a cached reader
+		if entry, ok := p.entries[name]; ok && entry.reader != nil {
+			p.use(entry)
+			p.mu.Unlock()
+			return &PooledReader{Reader: entry.reader, pool: p, entry: entry}, nil
+		}
+
+		// Another goroutine is opening the same file
+		if _, ok := p.entries[name]; ok {
+			p.cond.Wait()
+			continue
+		}
+
+		// Make room for the new file; entries being opened and forgotten
+		// readers still in use count as open
+		if len(p.entries)+p.forgotten >= p.maxOpen && !p.evictLRU() {
+			p.cond.Wait()
+			continue
+		}
+		break
+	}
+
+	// Reserve the entry and open the file without holding the lock
+	entry := &poolEntry{name: name}
+	p.entries[name] = entry
+	p.mu.Unlock()
+
+	reader, err := NewReader(filepath.Join(p.dir, name))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	defer p.cond.Broadcast()
+
+	if err != nil {
+		delete(p.entries, name)
+		return nil, fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	if p.closed {
+		delete(p.entries, name)
+		reader.Close()
+		return nil, ErrPoolClosed
+	}
+
+	entry.reader = reader
+	p.use(entry)
+	return &PooledReader{Reader: reader, pool: p, entry: entry}, nil
+}
+
+// use marks an entry as in use. The caller must hold the lock.
+func (p *Pool) use(entry *poolEntry) {
+	if entry.element != nil {
+		p.idle.Remove(entry.element)
+		entry.element = nil
+	}
+	entry.refs++
+}
+
+// release returns a handle to the pool
+func (p *Pool) release(entry *poolEntry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry.refs--
+	if entry.refs > 0 {
+		return
+	}
+
+	// Readers released after the pool or the entry was closed are closed now
+	if entry.forgotten {
+		p.forgotten--
+		entry.reader.Close()
+		p.cond.Broadcast()
+		return
+	}
+	if p.closed {
+		entry.reader.Close()
+		return
+	}
+
+	entry.lastUsed = time.Now()
+	entry.element = p.idle.PushBack(entry)
+	p.cond.Broadcast()
+}
+
+// evictLRU closes the least recently used idle reader and reports whether one
+// was evicted. The caller must hold the lock.
+func (p *Pool) evictLRU() bool {
+	front := p.idle.Front()
+	if front == nil {
+		return false
+	}
+	p.evict(front.Value.(*poolEntry))
+	return true
+}
+
+// evict removes an idle entry and closes its reader. The caller must hold the lock.
+func (p *Pool) evict(entry *poolEntry) {
+	p.idle.Remove(entry.element)
+	entry.element = nil
+	delete(p.entries, entry.name)
+	entry.reader.Close()
+}
+
+// evictIdleLoop periodically closes readers that exceeded the idle timeout
+func (p *Pool) evictIdleLoop() {
+	defer p.wg.Done()
+
+	interval := p.idleTimeout / 2
+	if interval <= 0 {
+		interval = p.idleTimeout
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.EvictIdle(p.idleTimeout)
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// EvictIdle closes all readers that are not in use and were last used longer
+// than d ago. It returns the number of closed readers.
+func (p *Pool) EvictIdle(d time.Duration) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cutoff := time.Now().Add(-d)
+	evicted := 0
+	for front := p.idle.Front(); front != nil; front = p.idle.Front() {
+		entry := front.Value.(*poolEntry)
+		if entry.lastUsed.After(cutoff) {
+			break
+		}
+		p.evict(entry)
+		evicted++
+	}
+	if evicted > 0 {
+		p.cond.Broadcast()
+	}
+	return evicted
+}
+
+// Forget closes the cached reader for name, for example after the file was
+// replaced or deleted. Readers still in use are closed once released, and
+// count against the open-files limit until then.
+func (p *Pool) Forget(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry, ok := p.entries[name]
+	if !ok || entry.reader == nil {
+		return
+	}
+	if entry.refs == 0 {
+		p.evict(entry)
+	} else {
+		delete(p.entries, name)
+		entry.forgotten = true
+		p.forgotten++
+	}
+	p.cond.Broadcast()
+}
+
+// OpenCount returns the number of files the pool currently keeps open
+func (p *Pool) OpenCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	open := p.forgotten
+	for _, entry := range p.entries {
+		if entry.reader != nil {
+			open++
+		}
+	}
+	return open
+}
+
+// Close closes all idle readers and stops the eviction goroutine. Readers
+// still in use are closed when they are released.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+
+	var firstErr error
+	for name, entry := range p.entries {
+		if entry.reader != nil && entry.refs == 0 {
+			if err := entry.reader.Close(); err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("failed to close %s: %w", name, err)
+			}
+		}
+	}
+	p.entries = make(map[string]*poolEntry)
+	p.idle.Init()
+	p.cond.Broadcast()
+	p.mu.Unlock()
+
+	close(p.stop)
+	p.wg.Wait()
+
+	return firstErr
+}
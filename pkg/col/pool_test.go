@@ -0,0 +1,204 @@
+package col
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePoolTestFiles writes n small files named 0.col, 1.col, ... to dir
+func writePoolTestFiles(t *testing.T, dir string, n int) {
+	for i := 0; i < n; i++ {
+		writer, err := NewWriter(filepath.Join(dir, fmt.Sprintf("%d.col", i)))
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlock([]uint64{uint64(i), uint64(i) + 100}, []int64{int64(i), 1}))
+		require.NoError(t, writer.FinalizeAndClose())
+	}
+}
+
+func TestPool(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-pool-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+	writePoolTestFiles(t, tempDir, 5)
+
+	t.Run("Readers are shared and cached", func(t *testing.T) {
+		pool, err := NewPool(tempDir, WithIdleTimeout(0))
+		require.NoError(t, err)
+		defer pool.Close()
+
+		first, err := pool.Acquire("1.col")
+		require.NoError(t, err)
+		second, err := pool.Acquire("1.col")
+		require.NoError(t, err)
+		assert.Same(t, first.Reader, second.Reader)
+
+		result := first.Aggregate()
+		assert.Equal(t, uint64(2), result.Count)
+		assert.Equal(t, int64(2), result.Sum)
+
+		require.NoError(t, first.Close())
+		require.NoError(t, first.Close())
+		require.NoError(t, second.Close())
+
+		third, err := pool.Acquire("1.col")
+		require.NoError(t, err)
+		assert.Same(t, first.Reader, third.Reader)
+		require.NoError(t, third.Close())
+		assert.Equal(t, 1, pool.OpenCount())
+	})
+
+	t.Run("Open files are limited", func(t *testing.T) {
+		pool, err := NewPool(tempDir, WithMaxOpenReaders(2), WithIdleTimeout(0))
+		require.NoError(t, err)
+		defer pool.Close()
+
+		for i := 0; i < 5; i++ {
+			r, err := pool.Acquire(fmt.Sprintf("%d.col", i))
+			require.NoError(t, err)
+			assert.Equal(t, uint64(2), r.Aggregate().Count)
+			require.NoError(t, r.Close())
+			assert.LessOrEqual(t, pool.OpenCount(), 2)
+		}
+
+		// With all readers in use, Acquire waits until one is released
+		a, err := pool.Acquire("0.col")
+		require.NoError(t, err)
+		b, err := pool.Acquire("1.col")
+		require.NoError(t, err)
+
+		acquired := make(chan *PooledReader)
+		go func() {
+			r, err := pool.Acquire("2.col")
+			assert.NoError(t, err)
+			acquired <- r
+		}()
+
+		select {
+		case <-acquired:
+			t.Fatal("Acquire should wait while all readers are in use")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		require.NoError(t, a.Close())
+		c := <-acquired
+		assert.Equal(t, uint64(2), c.Aggregate().Count)
+		require.NoError(t, c.Close())
+		require.NoError(t, b.Close())
+	})
+
+	t.Run("Idle readers are evicted", func(t *testing.T) {
+		pool, err := NewPool(tempDir, WithIdleTimeout(0))
+		require.NoError(t, err)
+		defer pool.Close()
+
+		inUse, err := pool.Acquire("0.col")
+		require.NoError(t, err)
+		idle, err := pool.Acquire("1.col")
+		require.NoError(t, err)
+		require.NoError(t, idle.Close())
+
+		assert.Equal(t, 1, pool.EvictIdle(0))
+		assert.Equal(t, 1, pool.OpenCount())
+		require.NoError(t, inUse.Close())
+
+		// The background loop evicts readers after the idle timeout
+		timed, err := NewPool(tempDir, WithIdleTimeout(10*time.Millisecond))
+		require.NoError(t, err)
+		defer timed.Close()
+
+		r, err := timed.Acquire("2.col")
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+		assert.Eventually(t, func() bool { return timed.OpenCount() == 0 }, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("Forget and Close", func(t *testing.T) {
+		pool, err := NewPool(tempDir)
+		require.NoError(t, err)
+
+		r, err := pool.Acquire("3.col")
+		require.NoError(t, err)
+		pool.Forget("3.col")
+		assert.Equal(t, 1, pool.OpenCount())
+
+		// The forgotten reader stays usable until it is released
+		assert.Equal(t, uint64(2), r.Aggregate().Count)
+		require.NoError(t, r.Close())
+		assert.Equal(t, 0, pool.OpenCount())
+
+		_, err = pool.Acquire("missing.col")
+		assert.Error(t, err)
+
+		require.NoError(t, pool.Close())
+		require.NoError(t, pool.Close())
+		_, err = pool.Acquire("3.col")
+		assert.ErrorIs(t, err, ErrPoolClosed)
+	})
+
+	t.Run("Forgotten readers count as open", func(t *testing.T) {
+		pool, err := NewPool(tempDir, WithMaxOpenReaders(1), WithIdleTimeout(0))
+		require.NoError(t, err)
+		defer pool.Close()
+
+		r, err := pool.Acquire("0.col")
+		require.NoError(t, err)
+		pool.Forget("0.col")
+
+		acquired := make(chan *PooledReader)
+		go func() {
+			r, err := pool.Acquire("1.col")
+			assert.NoError(t, err)
+			acquired <- r
+		}()
+
+		select {
+		case <-acquired:
+			t.Fatal("Acquire should wait while the forgotten reader is in use")
+		case <-time.After(50 * time.Millisecond):
+		}
+		assert.Equal(t, 1, pool.OpenCount())
+
+		require.NoError(t, r.Close())
+		other := <-acquired
+		assert.Equal(t, 1, pool.OpenCount())
+		require.NoError(t, other.Close())
+	})
+
+	t.Run("Concurrent use", func(t *testing.T) {
+		pool, err := NewPool(tempDir, WithMaxOpenReaders(3), WithIdleTimeout(0))
+		require.NoError(t, err)
+		defer pool.Close()
+
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < 50; i++ {
+					r, err := pool.Acquire(fmt.Sprintf("%d.col", (g+i)%5))
+					if !assert.NoError(t, err) {
+						return
+					}
+					assert.Equal(t, uint64(2), r.Aggregate().Count)
+					assert.NoError(t, r.Close())
+				}
+			}(g)
+		}
+		wg.Wait()
+		assert.LessOrEqual(t, pool.OpenCount(), 3)
+	})
+
+	t.Run("Invalid options", func(t *testing.T) {
+		_, err := NewPool(tempDir, WithMaxOpenReaders(0))
+		assert.Error(t, err)
+		_, err = NewPool(tempDir, WithIdleTimeout(-time.Second))
+		assert.Error(t, err)
+	})
+}
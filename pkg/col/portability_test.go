@@ -0,0 +1,65 @@
+package col
+
+import (
+	"math"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntFromFile(t *testing.T) {
+	v, err := intFromFile(math.MaxInt32)
+	require.NoError(t, err)
+	assert.Equal(t, math.MaxInt32, v)
+
+	_, err = intFromFile(math.MaxUint64)
+	assert.Error(t, err)
+
+	// Sizes of up to 4GB are only representable with 64-bit ints
+	_, err = intFromFile(math.MaxUint32)
+	if strconv.IntSize == 32 {
+		assert.Error(t, err)
+	} else {
+		assert.NoError(t, err)
+	}
+}
+
+func TestPlatformFallbacks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "platform.col")
+	writer, err := NewWriter(path)
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteBlock([]uint64{1, 2, 3}, []int64{10, 20, 30}))
+	require.NoError(t, writer.FinalizeAndClose())
+
+	t.Run("Direct I/O", func(t *testing.T) {
+		reader, err := NewReaderWithOptions(path, OpenOptions{DirectIO: true})
+		require.NoError(t, err)
+		defer reader.Close()
+
+		// Only Linux has O_DIRECT, elsewhere the page cache is used
+		if runtime.GOOS != "linux" {
+			assert.False(t, reader.DirectIO())
+		}
+		value, found, err := reader.Get(2)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, int64(20), value)
+	})
+
+	t.Run("Advice", func(t *testing.T) {
+		reader, err := NewReader(path)
+		require.NoError(t, err)
+		defer reader.Close()
+
+		// Advice is a hint, platforms without posix_fadvise accept and ignore it
+		for _, advice := range []Advice{AdviceNormal, AdviceSequential, AdviceRandom, AdviceDontNeed} {
+			assert.NoError(t, reader.Advise(advice))
+		}
+		assert.Error(t, reader.Advise(Advice(-1)))
+		assert.Error(t, reader.Advise(AdviceDontNeed+1))
+	})
+}
@@ -0,0 +1,112 @@
+package col
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter limits the I/O throughput of readers and writers sharing it, so
+// background work like compactions or full scans does not starve foreground
+// queries of disk bandwidth. Bytes are admitted at the limit, with bursts of at
+// most a tenth of a second's worth. A RateLimiter is safe for concurrent use.
+type RateLimiter struct {
+	mu     sync.Mutex
+	limit  int64     // Bytes per second, 0 if unlimited
+	tokens float64   // Bytes that may pass without waiting, negative if reserved ahead
+	last   time.Time // Time tokens were last replenished
+	bytes  uint64
+	waited time.Duration
+}
+
+// RateLimiterStats describes the current limit and the usage of a RateLimiter
+type RateLimiterStats struct {
+	Limit  int64         // Bytes per second, 0 if unlimited
+	Bytes  uint64        // Total bytes that passed the limiter
+	Waited time.Duration // Total time I/O was delayed by the limiter
+}
+
+// NewRateLimiter returns a limiter admitting bytesPerSecond bytes per second.
+// A limit of 0 or less only counts the bytes, see SetLimit.
+func NewRateLimiter(bytesPerSecond int64) *RateLimiter {
+	l := &RateLimiter{last: time.Now()}
+	l.SetLimit(bytesPerSecond)
+	return l
+}
+
+// SetLimit changes the limit to bytesPerSecond bytes per second. A limit of 0
+// or less disables throttling.
+func (l *RateLimiter) SetLimit(bytesPerSecond int64) {
+	if bytesPerSecond < 0 {
+		bytesPerSecond = 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = bytesPerSecond
+	l.tokens = 0
+	l.last = time.Now()
+}
+
+// Stats returns the current limit and the usage so far, e.g. to export them as
+// metrics
+func (l *RateLimiter) Stats() RateLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return RateLimiterStats{Limit: l.limit, Bytes: l.bytes, Waited: l.waited}
+}
+
+// wait blocks until n bytes may pass the limiter
+func (l *RateLimiter) wait(n int) {
+	l.mu.Lock()
+	l.bytes += uint64(n)
+	if l.limit == 0 {
+		l.mu.Unlock()
+		return
+	}
+
+	now := time.Now()
+	rate := float64(l.limit)
+	l.tokens += now.Sub(l.last).Seconds() * rate
+	if burst := rate / 10; l.tokens > burst {
+		l.tokens = burst
+	}
+	l.last = now
+
+	// Reserve the bytes, so concurrent callers queue up behind each other
+	l.tokens -= float64(n)
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / rate * float64(time.Second))
+	}
+	l.waited += delay
+	l.mu.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+// SetRateLimiter makes all reads of the file, including scans and
+// aggregations, pass l. A nil limiter removes the limit. It must not be called
+// concurrently with reads.
+func (r *Reader) SetRateLimiter(l *RateLimiter) {
+	r.rateLimiter = l
+}
+
+// WithRateLimiter makes all writes of the Writer pass l
+func WithRateLimiter(l *RateLimiter) WriterOption {
+	return func(w *Writer) {
+		w.rateLimiter = l
+	}
+}
+
+// rateLimitedFile passes all writes to a file through a RateLimiter
+type rateLimitedFile struct {
+	writerFile
+	limiter *RateLimiter
+}
+
+func (f rateLimitedFile) Write(p []byte) (int, error) {
+	f.limiter.wait(len(p))
+	return f.writerFile.Write(p)
+}
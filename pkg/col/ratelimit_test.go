@@ -0,0 +1,93 @@
+package col
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	t.Run("Throttles to the limit", func(t *testing.T) {
+		limiter := NewRateLimiter(1 << 20)
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			limiter.wait(100 << 10)
+		}
+		elapsed := time.Since(start)
+
+		// 300KB at 1MB/s take about 290ms
+		assert.GreaterOrEqual(t, elapsed, 250*time.Millisecond)
+		stats := limiter.Stats()
+		assert.Equal(t, int64(1<<20), stats.Limit)
+		assert.Equal(t, uint64(300<<10), stats.Bytes)
+		assert.Greater(t, stats.Waited, 250*time.Millisecond)
+	})
+
+	t.Run("Unlimited only counts", func(t *testing.T) {
+		limiter := NewRateLimiter(0)
+		start := time.Now()
+		limiter.wait(1 << 30)
+		assert.Less(t, time.Since(start), 100*time.Millisecond)
+		assert.Equal(t, RateLimiterStats{Bytes: 1 << 30}, limiter.Stats())
+
+		limiter.SetLimit(-5)
+		assert.Equal(t, int64(0), limiter.Stats().Limit)
+	})
+
+	t.Run("Reads and writes", func(t *testing.T) {
+		const count = 4000
+		ids := make([]uint64, count)
+		values := make([]int64, count)
+		for i := range ids {
+			ids[i] = uint64(i)
+			values[i] = int64(i * 7)
+		}
+
+		writeLimiter := NewRateLimiter(0)
+		path := filepath.Join(t.TempDir(), "limited.col")
+		writer, err := NewWriter(path, WithBlockSize(8192), WithRateLimiter(writeLimiter))
+		require.NoError(t, err)
+		require.NoError(t, writeAllBlocks(writer, ids, values))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		reader, err := NewReader(path)
+		require.NoError(t, err)
+		defer reader.Close()
+		// Finalize rewrites the file header
+		assert.Equal(t, uint64(reader.fileSize+headerSize), writeLimiter.Stats().Bytes)
+
+		var blockBytes uint64
+		for _, entry := range reader.blockIndex {
+			blockBytes += uint64(entry.BlockSize)
+		}
+
+		readLimiter := NewRateLimiter(0)
+		reader.SetRateLimiter(readLimiter)
+		var scanned int
+		require.NoError(t, reader.ScanAll(2, func(_ BlockID, blockIDs []uint64, _ []int64) error {
+			scanned += len(blockIDs)
+			return nil
+		}))
+		assert.Equal(t, count, scanned)
+		assert.Equal(t, blockBytes, readLimiter.Stats().Bytes)
+
+		// A rewrite reads the blocks and writes the output through the limiter
+		rewriteLimiter := NewRateLimiter(0)
+		outPath := filepath.Join(t.TempDir(), "rewritten.col")
+		require.NoError(t, Rewrite(path, outPath, RewriteOptions{
+			Encoding:    EncodingVarIntBoth,
+			RateLimiter: rewriteLimiter,
+		}))
+		out, err := NewReader(outPath)
+		require.NoError(t, err)
+		defer out.Close()
+		assert.Equal(t, blockBytes+uint64(out.fileSize+headerSize), rewriteLimiter.Stats().Bytes)
+
+		readIDs, readValues := readAllPairs(t, out)
+		assert.Equal(t, ids, readIDs)
+		assert.Equal(t, values, readValues)
+	})
+}
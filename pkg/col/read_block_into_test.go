@@ -0,0 +1,108 @@
+package col
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadBlockInto(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-read-block-into-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	for _, encoding := range []uint32{EncodingRaw, EncodingDeltaBoth, EncodingVarIntBoth} {
+		filePath := filepath.Join(tempDir, "pairs.col")
+		writer, err := NewWriter(filePath, WithEncoding(encoding))
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlock([]uint64{1, 2, 3, 4}, []int64{10, -20, 30, -40}))
+		require.NoError(t, writer.WriteBlock([]uint64{7, 9}, []int64{70, 90}))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		reader, err := NewReader(filePath)
+		require.NoError(t, err)
+
+		// Without buffers the result matches ReadBlock
+		ids, values, err := reader.ReadBlockInto(0, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{1, 2, 3, 4}, ids)
+		assert.Equal(t, []int64{10, -20, 30, -40}, values)
+
+		// Buffers that are large enough are reused
+		idsBuf, valsBuf := ids, values
+		ids, values, err = reader.ReadBlockInto(1, idsBuf, valsBuf)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{7, 9}, ids)
+		assert.Equal(t, []int64{70, 90}, values)
+		assert.Same(t, &idsBuf[0], &ids[0])
+		assert.Same(t, &valsBuf[0], &values[0])
+
+		// Buffers that are too small are replaced
+		ids, values, err = reader.ReadBlockInto(0, make([]uint64, 0, 1), make([]int64, 0, 1))
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{1, 2, 3, 4}, ids)
+		assert.Equal(t, []int64{10, -20, 30, -40}, values)
+
+		_, _, err = reader.ReadBlockInto(2, idsBuf, valsBuf)
+		assert.Error(t, err)
+
+		require.NoError(t, reader.Close())
+	}
+}
+
+func TestReadBlockSections(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-read-block-sections-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	encodings := []uint32{
+		EncodingRaw, EncodingDeltaID, EncodingDeltaValue, EncodingDeltaBoth, EncodingVarInt,
+		EncodingVarIntID, EncodingVarIntValue, EncodingVarIntBoth, EncodingDeltaDelta,
+	}
+	for _, encoding := range encodings {
+		filePath := filepath.Join(tempDir, "sections.col")
+		writer, err := NewWriter(filePath, WithEncoding(encoding))
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlock([]uint64{1, 2, 3, 4}, []int64{10, -20, 30, -40}))
+		require.NoError(t, writer.WriteBlock([]uint64{7, 9}, []int64{70, 90}))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		reader, err := NewReader(filePath)
+		require.NoError(t, err)
+
+		// Each section decodes to the same result as ReadBlock
+		for id := BlockID(0); id < 2; id++ {
+			expectedIDs, expectedValues, err := reader.ReadBlock(id)
+			require.NoError(t, err)
+
+			ids, err := reader.ReadBlockIDs(id)
+			require.NoError(t, err)
+			assert.Equal(t, expectedIDs, ids, "encoding %d", encoding)
+
+			values, err := reader.ReadBlockValues(id)
+			require.NoError(t, err)
+			assert.Equal(t, expectedValues, values, "encoding %d", encoding)
+		}
+
+		// Buffers that are large enough are reused
+		idsBuf, valsBuf := make([]uint64, 0, 4), make([]int64, 0, 4)
+		ids, err := reader.ReadBlockIDsInto(1, idsBuf)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{7, 9}, ids)
+		assert.Same(t, &idsBuf[:1][0], &ids[0])
+		values, err := reader.ReadBlockValuesInto(1, valsBuf)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{70, 90}, values)
+		assert.Same(t, &valsBuf[:1][0], &values[0])
+
+		_, err = reader.ReadBlockIDs(2)
+		assert.Error(t, err)
+		_, err = reader.ReadBlockValues(2)
+		assert.Error(t, err)
+
+		require.NoError(t, reader.Close())
+	}
+}
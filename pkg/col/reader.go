@@ -1,26 +1,76 @@
 package col
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"os"
+	"sync"
 
 	"github.com/weaviate/sroar"
 )
 
 // Reader reads a column file
 type Reader struct {
-	file           *os.File
+	file           readerFile
 	fileSize       int64
 	header         FileHeader
 	footerMeta     FooterMetadata
 	blockIndex     []FooterEntry
+	plan           idPlan                // How the block ID ranges cover the ID space
+	extendedStats  []ExtendedBlockStats  // nil if the file has no block statistics section
+	fileStats      *FileStats            // nil if the file has no file statistics section
+	unsignedStats  []unsignedBlockStats  // nil if the file has no unsigned statistics section
+	lineage        []LineageEntry        // nil if the file has no lineage section
+	valueOrders    [][]uint32            // nil if the file has no value order section
+	partitions     []uint64              // nil if the file has no partitions section
+	dataFileSize   int64                 // Size of the data file, set when reading a sidecar
+	footerSections []FooterSectionHeader // Optional footer sections in file order
 	globalIDs      *sroar.Bitmap
 	cacheGlobalIDs bool // Whether to cache the global ID bitmap
+
+	// Whether scans and filtered aggregations pass their access pattern to the OS
+	pageCacheAdvice bool
+
+	rateLimiter *RateLimiter // Limits the read throughput, nil if unlimited
+
+	access     *accessTracker // Read counts of the blocks, nil if disabled
+	blockCache *blockCache    // Decoded blocks, nil if disabled
+
+	// The footer is parsed at most once, either when opening or on first use
+	footerOnce sync.Once
+	footerErr  error
+
+	filename          string // File opened by NewReaderWithOptions
+	followCheckpoints bool   // Whether to fall back to the checkpoint of an unfinalized file
+	checkpointed      bool   // Whether the block index was read from a checkpoint
 }
 
 // NewReader creates a new column file reader
 func NewReader(filename string) (*Reader, error) {
+	reader, err := openReader(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the footer
+	if err := reader.ensureFooter(); err != nil {
+		reader.file.Close()
+		return nil, err
+	}
+
+	return reader, nil
+}
+
+// NewReaderLazy creates a new column file reader that only reads the file header.
+// The footer is parsed on the first operation that needs the block index, which
+// makes opening cheap when only header metadata is required (see HeaderOnly).
+func NewReaderLazy(filename string) (*Reader, error) {
+	return openReader(filename)
+}
+
+// openReader opens the file and reads its header
+func openReader(filename string) (*Reader, error) {
 	file, err := os.Open(filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
@@ -32,8 +82,29 @@ func NewReader(filename string) (*Reader, error) {
 		file.Close()
 		return nil, fmt.Errorf("failed to get file info: %w", err)
 	}
-	fileSize := fileInfo.Size()
 
+	return newReader(file, fileInfo.Size())
+}
+
+// NewReaderFromBytes creates a reader over a column file held in memory, e.g.
+// one written with NewWriterToBuffer. The data must not be modified while the
+// reader is in use.
+func NewReaderFromBytes(data []byte) (*Reader, error) {
+	reader, err := newReader(bytesFile{bytes.NewReader(data)}, int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := reader.ensureFooter(); err != nil {
+		return nil, err
+	}
+
+	return reader, nil
+}
+
+// newReader reads the header of file, which has the given size. The file is
+// closed if the header is invalid.
+func newReader(file readerFile, fileSize int64) (*Reader, error) {
 	reader := &Reader{
 		file:           file,
 		fileSize:       fileSize,
@@ -46,18 +117,31 @@ func NewReader(filename string) (*Reader, error) {
 		return nil, fmt.Errorf("failed to read header: %w", err)
 	}
 
-	// Read the footer
-	if err := reader.readFooter(); err != nil {
-		file.Close()
-		return nil, fmt.Errorf("failed to read footer: %w", err)
-	}
-
 	return reader, nil
 }
 
+// ensureFooter parses the footer if it hasn't been parsed yet
+func (r *Reader) ensureFooter() error {
+	r.footerOnce.Do(func() {
+		if err := r.readFooter(); err != nil {
+			r.footerErr = fmt.Errorf("failed to read footer: %w", err)
+		}
+	})
+	return r.footerErr
+}
+
+// HeaderOnly returns the file header without requiring the footer to be parsed.
+// For lazily opened readers, BlockCount in the returned header is the value
+// recorded by the writer and is not cross-checked against the footer.
+func (r *Reader) HeaderOnly() FileHeader {
+	return r.header
+}
+
 // GetPairs returns the ID-value pairs from a block
+//
+// Deprecated: Use ReadBlock.
 func (r *Reader) GetPairs(blockIdx uint64) ([]uint64, []int64, error) {
-	return r.readBlock(int(blockIdx))
+	return r.ReadBlock(BlockID(blockIdx))
 }
 
 // Version returns the file format version
@@ -82,11 +166,15 @@ func (r *Reader) IsVarIntEncoded() bool {
 	return r.header.EncodingType == EncodingVarInt ||
 		r.header.EncodingType == EncodingVarIntID ||
 		r.header.EncodingType == EncodingVarIntValue ||
-		r.header.EncodingType == EncodingVarIntBoth
+		r.header.EncodingType == EncodingVarIntBoth ||
+		r.header.EncodingType == EncodingDeltaDelta
 }
 
 // BlockCount returns the number of blocks in the file
+// For lazily opened readers this parses the footer; use HeaderOnly to avoid that.
 func (r *Reader) BlockCount() uint64 {
+	// Footer errors surface on the first block read, so they are ignored here
+	_ = r.ensureFooter()
 	return r.header.BlockCount
 }
 
@@ -97,6 +185,10 @@ func (r *Reader) Close() error {
 
 // DebugInfo returns debug information about the file
 func (r *Reader) DebugInfo() string {
+	if err := r.ensureFooter(); err != nil {
+		return fmt.Sprintf("Failed to load footer: %v\n", err)
+	}
+
 	info := fmt.Sprintf("File header: Magic=0x%X, Version=%d, BlockCount=%d\n",
 		r.header.Magic, r.header.Version, r.header.BlockCount)
 
@@ -107,6 +199,10 @@ func (r *Reader) DebugInfo() string {
 		r.footerMeta.FooterSize, r.footerMeta.Magic)
 
 	info += fmt.Sprintf("    Block index entries: %d\n", len(r.blockIndex))
+	if r.plan.overlapping > 0 {
+		info += fmt.Sprintf("    Warning: %d blocks have overlapping ID ranges; lookups by ID check all of them\n",
+			r.plan.overlapping)
+	}
 
 	for i, entry := range r.blockIndex {
 		info += fmt.Sprintf("      Block %d: Offset=%d, Size=%d, Count=%d\n",
@@ -144,6 +240,12 @@ func (r *Reader) GetGlobalIDBitmap() (*sroar.Bitmap, error) {
 		return r.globalIDs, nil
 	}
 
+	// The bitmap is only written on finalization, so it is built from the
+	// blocks of a checkpoint
+	if r.checkpointed {
+		return r.BuildIDBitmap(IDBitmapOptions{})
+	}
+
 	// If the file doesn't have a bitmap, return an empty one
 	if r.header.BitmapOffset == 0 || r.header.BitmapSize == 0 {
 		bitmap := sroar.NewBitmap()
@@ -162,7 +264,11 @@ func (r *Reader) GetGlobalIDBitmap() (*sroar.Bitmap, error) {
 	bitmapSize := binary.LittleEndian.Uint32(sizeBuf)
 
 	// Read the bitmap data
-	bitmapBuf, err := r.readBytesAt(int64(r.header.BitmapOffset)+4, int(bitmapSize))
+	size, err := intFromFile(uint64(bitmapSize))
+	if err != nil {
+		return nil, fmt.Errorf("invalid bitmap size: %w", err)
+	}
+	bitmapBuf, err := r.readBytesAt(int64(r.header.BitmapOffset)+4, size)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read bitmap data: %w", err)
 	}
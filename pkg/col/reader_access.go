@@ -0,0 +1,115 @@
+package col
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// BlockAccess describes how often and how recently a block was read
+type BlockAccess struct {
+	Block      BlockID
+	MinID      uint64
+	MaxID      uint64
+	Reads      uint64
+	LastAccess time.Time // Zero if the block was never read
+}
+
+// accessTracker counts the reads of every block. The counters are updated
+// atomically, so concurrent reads do not contend on a lock.
+type accessTracker struct {
+	reads []atomic.Uint64
+	last  []atomic.Int64 // Unix nanoseconds of the last read, 0 if never read
+}
+
+// EnableAccessStats starts counting the reads of every block, including reads
+// served by the block cache. With access statistics enabled, the block cache
+// only admits blocks that were read before, so one-off scans do not evict hot
+// blocks. It must not be called concurrently with reads.
+func (r *Reader) EnableAccessStats() {
+	// Footer errors surface on the first block read, so they are ignored here
+	_ = r.ensureFooter()
+	r.access = &accessTracker{
+		reads: make([]atomic.Uint64, len(r.blockIndex)),
+		last:  make([]atomic.Int64, len(r.blockIndex)),
+	}
+}
+
+// growAccessStats extends enabled access statistics to the blocks added to
+// the block index since, keeping the statistics of the other blocks
+func (r *Reader) growAccessStats() {
+	if r.access == nil || len(r.access.reads) >= len(r.blockIndex) {
+		return
+	}
+	grown := &accessTracker{
+		reads: make([]atomic.Uint64, len(r.blockIndex)),
+		last:  make([]atomic.Int64, len(r.blockIndex)),
+	}
+	for i := range r.access.reads {
+		grown.reads[i].Store(r.access.reads[i].Load())
+		grown.last[i].Store(r.access.last[i].Load())
+	}
+	r.access = grown
+}
+
+// DisableAccessStats stops counting reads and drops the statistics, which is
+// the default. It must not be called concurrently with reads.
+func (r *Reader) DisableAccessStats() {
+	r.access = nil
+}
+
+// recordAccess counts a read of block id and returns the number of reads of
+// the block so far, or 0 if access statistics are disabled
+func (r *Reader) recordAccess(id BlockID) uint64 {
+	if r.access == nil || id >= BlockID(len(r.access.reads)) {
+		return 0
+	}
+	r.access.last[id].Store(time.Now().UnixNano())
+	return r.access.reads[id].Add(1)
+}
+
+// AccessStats returns the read statistics of all blocks in block order, or nil
+// if access statistics are disabled
+func (r *Reader) AccessStats() []BlockAccess {
+	if r.access == nil {
+		return nil
+	}
+
+	stats := make([]BlockAccess, len(r.access.reads))
+	for i := range stats {
+		entry := r.blockIndex[i]
+		stats[i] = BlockAccess{
+			Block: BlockID(i),
+			MinID: entry.MinID,
+			MaxID: entry.MaxID,
+			Reads: r.access.reads[i].Load(),
+		}
+		if last := r.access.last[i].Load(); last != 0 {
+			stats[i].LastAccess = time.Unix(0, last)
+		}
+	}
+	return stats
+}
+
+// HotIDRanges returns the n most frequently read blocks with their ID ranges,
+// most frequently read first. Blocks that were never read are left out; ties
+// are broken by the more recent access.
+func (r *Reader) HotIDRanges(n int) []BlockAccess {
+	var hot []BlockAccess
+	for _, block := range r.AccessStats() {
+		if block.Reads > 0 {
+			hot = append(hot, block)
+		}
+	}
+
+	sort.Slice(hot, func(a, b int) bool {
+		if hot[a].Reads != hot[b].Reads {
+			return hot[a].Reads > hot[b].Reads
+		}
+		return hot[a].LastAccess.After(hot[b].LastAccess)
+	})
+	if len(hot) > n {
+		hot = hot[:n]
+	}
+	return hot
+}
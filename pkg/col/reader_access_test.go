@@ -0,0 +1,73 @@
+package col
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeBlocksToBuffer writes blocks of size pairs each, with the IDs of block i
+// starting at i*size, and opens a reader over them
+func writeBlocksToBuffer(t *testing.T, blocks, size int) *Reader {
+	var buf bytes.Buffer
+	writer, err := NewWriterToBuffer(&buf)
+	require.NoError(t, err)
+	for b := 0; b < blocks; b++ {
+		ids := make([]uint64, size)
+		values := make([]int64, size)
+		for i := range ids {
+			ids[i] = uint64(b*size + i)
+			values[i] = int64(b*size+i) * 2
+		}
+		require.NoError(t, writer.WriteBlock(ids, values))
+	}
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := NewReaderFromBytes(buf.Bytes())
+	require.NoError(t, err)
+	return reader
+}
+
+func TestAccessStats(t *testing.T) {
+	reader := writeBlocksToBuffer(t, 4, 10)
+	assert.Nil(t, reader.AccessStats())
+
+	// Reads before enabling are not counted
+	_, _, err := reader.ReadBlock(0)
+	require.NoError(t, err)
+
+	start := time.Now()
+	reader.EnableAccessStats()
+	for _, id := range []BlockID{2, 2, 2, 1, 3, 3} {
+		_, _, err := reader.ReadBlock(id)
+		require.NoError(t, err)
+	}
+	_, err = reader.ReadBlockIDs(1)
+	require.NoError(t, err)
+	_, err = reader.ReadBlockValues(1)
+	require.NoError(t, err)
+
+	stats := reader.AccessStats()
+	require.Len(t, stats, 4)
+	assert.Equal(t, BlockAccess{Block: 0, MinID: 0, MaxID: 9}, stats[0])
+	for i, reads := range []uint64{0, 3, 3, 2} {
+		assert.Equal(t, reads, stats[i].Reads, "block %d", i)
+		assert.Equal(t, uint64(i*10), stats[i].MinID)
+		assert.Equal(t, uint64(i*10+9), stats[i].MaxID)
+	}
+	assert.False(t, stats[2].LastAccess.Before(start))
+
+	// Block 1 was read last, so it wins the tie with block 2
+	hot := reader.HotIDRanges(2)
+	require.Len(t, hot, 2)
+	assert.Equal(t, BlockID(1), hot[0].Block)
+	assert.Equal(t, BlockID(2), hot[1].Block)
+	assert.Len(t, reader.HotIDRanges(10), 3, "never read blocks are left out")
+
+	reader.DisableAccessStats()
+	assert.Nil(t, reader.AccessStats())
+	assert.Empty(t, reader.HotIDRanges(10))
+}
@@ -24,6 +24,26 @@ type AggregateOptions struct {
 	// If Parallel is 0, aggregation is performed sequentially
 	// If Parallel is negative, GOMAXPROCS is used as the number of workers
 	Parallel int
+
+	// AutoParallel decides the number of workers per query instead of using
+	// Parallel, based on whether the footer statistics suffice, the size of the
+	// blocks to read and whether the file is held in memory
+	AutoParallel bool
+
+	// Blocks restricts the aggregation to the listed block indices, e.g. the
+	// blocks an external index found to be relevant. Indices out of range are
+	// ignored. If Blocks is nil, all blocks are aggregated; if it is empty,
+	// none are. The filters still apply to the listed blocks.
+	Blocks []uint64
+
+	// PartitionFilter restricts the aggregation to the blocks whose partition
+	// key is listed, see WithBlockPartition. Blocks without a key have key 0.
+	// If PartitionFilter is nil, blocks of all partitions are aggregated.
+	PartitionFilter []uint64
+
+	// OnError decides how blocks that cannot be read are handled. By default
+	// they are skipped and listed in AggregateResult.SkippedBlocks.
+	OnError ErrorPolicy
 }
 
 // DefaultAggregateOptions returns the default options for aggregation
@@ -43,6 +63,16 @@ func (r *Reader) Aggregate() AggregateResult {
 
 // AggregateWithOptions aggregates all blocks with the specified options and returns the result
 func (r *Reader) AggregateWithOptions(opts AggregateOptions) AggregateResult {
+	// Without a footer there are no blocks to aggregate
+	if err := r.ensureFooter(); err != nil {
+		return AggregateResult{FooterErr: err}
+	}
+
+	if opts.AutoParallel {
+		opts.Parallel = r.autoParallelism(opts)
+		opts.AutoParallel = false
+	}
+
 	// If parallel aggregation is enabled, use it
 	if opts.Parallel != 0 {
 		return r.aggregateParallel(opts)
@@ -53,21 +83,28 @@ func (r *Reader) AggregateWithOptions(opts AggregateOptions) AggregateResult {
 		return r.aggregateWithFilter(opts)
 	}
 
+	// Files with a file statistics section are answered without iterating the block index
+	if r.fileStats != nil && !opts.SkipPreCalculated && opts.Blocks == nil && opts.PartitionFilter == nil {
+		return r.fileStats.aggregateResult().withSource(SourceMetadata, 0)
+	}
+
 	// If we have a footer with block statistics and we're not skipping pre-calculated values, use it for efficient aggregation
 	if len(r.blockIndex) > 0 && !opts.SkipPreCalculated {
-		var count int
+		var count uint64
 		var min int64 = 9223372036854775807  // Max int64
 		var max int64 = -9223372036854775808 // Min int64
 		var sum int64 = 0
 
-		for _, entry := range r.blockIndex {
+		for _, blockIdx := range r.aggregationBlocks(opts) {
+			entry := r.blockIndex[blockIdx]
+
 			// Convert stored uint64 values back to int64
 			minValue := uint64ToInt64(entry.MinValue)
 			maxValue := uint64ToInt64(entry.MaxValue)
 			blockSum := uint64ToInt64(entry.Sum)
 
 			// Update aggregates
-			count += int(entry.Count)
+			count += uint64(entry.Count)
 			if minValue < min {
 				min = minValue
 			}
@@ -77,35 +114,30 @@ func (r *Reader) AggregateWithOptions(opts AggregateOptions) AggregateResult {
 			sum += blockSum
 		}
 
-		// Calculate average
-		var avg float64 = 0
-		if count > 0 {
-			avg = float64(sum) / float64(count)
-		}
-
-		return AggregateResult{
-			Count: count,
-			Min:   min,
-			Max:   max,
-			Sum:   sum,
-			Avg:   avg,
-		}
+		return newAggregateResult(count, min, max, sum).withSource(SourceMetadata, 0)
 	}
 
 	// Fallback: read and aggregate all blocks
-	var count int
+	var count uint64
 	var min int64 = 9223372036854775807  // Max int64
 	var max int64 = -9223372036854775808 // Min int64
 	var sum int64 = 0
+	var valsBuf []int64
+	var scanned uint64
+	errs := &aggregateErrors{policy: opts.OnError}
 
-	for i := uint64(0); i < r.header.BlockCount; i++ {
-		_, values, err := r.GetPairs(i)
+	for _, blockIdx := range r.aggregationBlocks(opts) {
+		values, err := r.ReadBlockValuesInto(BlockID(blockIdx), valsBuf)
 		if err != nil {
-			// Skip blocks with errors
+			if errs.add(BlockID(blockIdx), err) {
+				break
+			}
 			continue
 		}
+		valsBuf = values
+		scanned++
 
-		count += len(values)
+		count += uint64(len(values))
 		for _, v := range values {
 			if v < min {
 				min = v
@@ -117,23 +149,18 @@ func (r *Reader) AggregateWithOptions(opts AggregateOptions) AggregateResult {
 		}
 	}
 
-	// Calculate average
-	var avg float64 = 0
-	if count > 0 {
-		avg = float64(sum) / float64(count)
-	}
-
-	return AggregateResult{
-		Count: count,
-		Min:   min,
-		Max:   max,
-		Sum:   sum,
-		Avg:   avg,
-	}
+	return errs.apply(newAggregateResult(count, min, max, sum).withSource(SourceFullScan, scanned))
 }
 
-// FilteredBlockIterator returns blocks that potentially contain IDs in the filter
+// FilteredBlockIterator returns blocks that potentially contain IDs in the filter.
+// It returns no blocks if the footer of a lazily opened file cannot be read;
+// the footer error is returned by the methods that read blocks.
 func (r *Reader) FilteredBlockIterator(filter, denyFilter *sroar.Bitmap) []uint64 {
+	// Without a footer there are no blocks to iterate
+	if err := r.ensureFooter(); err != nil {
+		return nil
+	}
+
 	// If no filters are provided, return all blocks
 	if filter == nil && denyFilter == nil {
 		blocks := make([]uint64, r.BlockCount())
@@ -175,18 +202,49 @@ func (r *Reader) FilteredBlockIterator(filter, denyFilter *sroar.Bitmap) []uint6
 	return matchingBlocks
 }
 
-// readBlockFiltered reads a block and filters values based on the allow and deny bitmaps
-func (r *Reader) readBlockFiltered(blockIndex int, filter, denyFilter *sroar.Bitmap) ([]uint64, []int64, error) {
-	// Read the entire block
-	allIDs, allValues, err := r.readBlock(blockIndex)
-	if err != nil {
-		return nil, nil, err
+// aggregationBlocks returns the blocks an aggregation with opts reads: the
+// blocks that potentially match the filters, restricted to opts.Blocks and
+// opts.PartitionFilter
+func (r *Reader) aggregationBlocks(opts AggregateOptions) []uint64 {
+	blocks := r.FilteredBlockIterator(opts.Filter, opts.DenyFilter)
+	if opts.PartitionFilter != nil {
+		blocks = r.partitionBlocks(blocks, opts.PartitionFilter)
+	}
+	if opts.Blocks == nil {
+		return blocks
 	}
 
+	listed := make([]bool, len(r.blockIndex))
+	for _, blockIdx := range opts.Blocks {
+		if blockIdx < uint64(len(listed)) {
+			listed[blockIdx] = true
+		}
+	}
+	restricted := make([]uint64, 0, len(opts.Blocks))
+	for _, blockIdx := range blocks {
+		if listed[blockIdx] {
+			restricted = append(restricted, blockIdx)
+		}
+	}
+	return restricted
+}
+
+// ReadBlockFiltered returns the ID-value pairs of a block whose IDs are contained
+// in filter and not contained in denyFilter. A nil filter allows all IDs and a nil
+// denyFilter denies none. The pairs keep the order in which they are stored.
+func (r *Reader) ReadBlockFiltered(id BlockID, filter, denyFilter *sroar.Bitmap) ([]uint64, []int64, error) {
 	// If no filters are provided, return all values
 	if filter == nil && denyFilter == nil {
-		return allIDs, allValues, nil
+		return r.ReadBlock(id)
+	}
+
+	// Read the entire block into pooled buffers
+	block, err := r.ReadBlockPooled(id)
+	if err != nil {
+		return nil, nil, err
 	}
+	defer block.Release()
+	allIDs, allValues := block.IDs, block.Values
 
 	// Filter IDs and values
 	filteredIDs := make([]uint64, 0, len(allIDs))
@@ -212,34 +270,55 @@ func (r *Reader) readBlockFiltered(blockIndex int, filter, denyFilter *sroar.Bit
 // aggregateWithFilter performs aggregation with filtering
 func (r *Reader) aggregateWithFilter(opts AggregateOptions) AggregateResult {
 	// Get blocks that potentially match the filter
-	matchingBlocks := r.FilteredBlockIterator(opts.Filter, opts.DenyFilter)
+	matchingBlocks := r.aggregationBlocks(opts)
 
 	// If no blocks match, return empty result
 	if len(matchingBlocks) == 0 {
-		return AggregateResult{
-			Count: 0,
-			Min:   0,
-			Max:   0,
-			Sum:   0,
-			Avg:   0,
-		}
+		return AggregateResult{}
+	}
+	defer r.adviseFor(AdviceRandom)()
+
+	var coverage filterCoverage
+	if !opts.SkipPreCalculated {
+		coverage = r.newFilterCoverage(opts.Filter, opts.DenyFilter)
 	}
 
 	// Read and aggregate all matching blocks
-	var count int
+	var count uint64
 	var min int64 = 9223372036854775807  // Max int64
 	var max int64 = -9223372036854775808 // Min int64
 	var sum int64 = 0
+	var valsBuf []int64
+	var scanned uint64
+	errs := &aggregateErrors{policy: opts.OnError}
 
 	for _, blockIdx := range matchingBlocks {
+		// Blocks whose IDs all pass the filters are answered from their statistics
+		if !opts.SkipPreCalculated && coverage.covers(r.blockIndex[blockIdx]) {
+			entry := r.blockIndex[blockIdx]
+			count += uint64(entry.Count)
+			if v := uint64ToInt64(entry.MinValue); v < min {
+				min = v
+			}
+			if v := uint64ToInt64(entry.MaxValue); v > max {
+				max = v
+			}
+			sum += uint64ToInt64(entry.Sum)
+			continue
+		}
+
 		// Read block with filtering
-		_, values, err := r.readBlockFiltered(int(blockIdx), opts.Filter, opts.DenyFilter)
+		values, err := r.readBlockValuesFiltered(BlockID(blockIdx), opts.Filter, opts.DenyFilter, valsBuf)
 		if err != nil {
-			// Skip blocks with errors
+			if errs.add(BlockID(blockIdx), err) {
+				break
+			}
 			continue
 		}
+		valsBuf = values
+		scanned++
 
-		count += len(values)
+		count += uint64(len(values))
 		for _, v := range values {
 			if v < min {
 				min = v
@@ -251,19 +330,83 @@ func (r *Reader) aggregateWithFilter(opts AggregateOptions) AggregateResult {
 		}
 	}
 
-	// Calculate average
-	var avg float64 = 0
-	if count > 0 {
-		avg = float64(sum) / float64(count)
+	return errs.apply(newAggregateResult(count, min, max, sum).withSource(filteredSource(scanned), scanned))
+}
+
+// filterCoverage finds the blocks whose IDs all pass the filters of an
+// aggregation, so that their statistics answer it without decoding them
+type filterCoverage struct {
+	filter *sroar.Bitmap
+
+	// With a deny filter, the IDs stored in the file and those of them that
+	// are not denied. Both are nil if no stored ID is denied.
+	stored  *sroar.Bitmap
+	allowed *sroar.Bitmap
+
+	// denyUnknown is set if a deny filter cannot be checked against the
+	// stored IDs, so that no block is covered
+	denyUnknown bool
+}
+
+// newFilterCoverage prepares the filters for checking blocks. Only the denied
+// IDs the file actually stores matter, which the global ID bitmap tells.
+func (r *Reader) newFilterCoverage(filter, denyFilter *sroar.Bitmap) filterCoverage {
+	coverage := filterCoverage{filter: filter}
+	if denyFilter == nil || denyFilter.IsEmpty() {
+		return coverage
+	}
+
+	// Without a persisted bitmap, any block may store a denied ID
+	storedIDs, err := r.persistedIDBitmap()
+	if err != nil {
+		coverage.denyUnknown = true
+		return coverage
+	}
+	allowed := sroar.AndNot(storedIDs, denyFilter)
+	if allowed.GetCardinality() != storedIDs.GetCardinality() {
+		coverage.stored = storedIDs
+		coverage.allowed = allowed
 	}
+	return coverage
+}
 
-	return AggregateResult{
-		Count: count,
-		Min:   min,
-		Max:   max,
-		Sum:   sum,
-		Avg:   avg,
+// covers returns whether every ID of the block passes the filters. This is the
+// case if the filter contains the whole ID range of the block, i.e. the ranks
+// of its minimum and maximum ID differ by the width of the range, and the
+// stored IDs within the range are all allowed, i.e. the ranks of the minimum
+// and maximum ID differ equally among the stored and the allowed IDs.
+func (c filterCoverage) covers(entry FooterEntry) bool {
+	if c.denyUnknown {
+		return false
 	}
+	if c.filter != nil {
+		// Rank is only meaningful for contained IDs
+		if !c.filter.Contains(entry.MinID) || !c.filter.Contains(entry.MaxID) {
+			return false
+		}
+		if uint64(c.filter.Rank(entry.MaxID)-c.filter.Rank(entry.MinID)) != entry.MaxID-entry.MinID {
+			return false
+		}
+	}
+	if c.allowed == nil {
+		return true
+	}
+
+	// Allowed IDs are stored, so their ranks are meaningful in both bitmaps
+	if !c.allowed.Contains(entry.MinID) || !c.allowed.Contains(entry.MaxID) {
+		return false
+	}
+	return c.allowed.Rank(entry.MaxID)-c.allowed.Rank(entry.MinID) ==
+		c.stored.Rank(entry.MaxID)-c.stored.Rank(entry.MinID)
+}
+
+// filteredSource returns the source of a filtered aggregation that decoded
+// scanned blocks and answered the others from their statistics
+func filteredSource(scanned uint64) AggregateSource {
+	if scanned == 0 {
+		return SourceMetadata
+	}
+	return SourceFiltered
 }
 
 // aggregateParallel performs aggregation in parallel
@@ -290,26 +433,11 @@ func (r *Reader) aggregateParallel(opts AggregateOptions) AggregateResult {
 	}
 
 	// Get blocks that potentially match the filter
-	var blockIndices []uint64
-	if opts.Filter != nil || opts.DenyFilter != nil {
-		blockIndices = r.FilteredBlockIterator(opts.Filter, opts.DenyFilter)
-	} else {
-		// Use all blocks if no filter is provided
-		blockIndices = make([]uint64, blockCount)
-		for i := range blockIndices {
-			blockIndices[i] = uint64(i)
-		}
-	}
+	blockIndices := r.aggregationBlocks(opts)
 
 	// If no blocks match, return empty result
 	if len(blockIndices) == 0 {
-		return AggregateResult{
-			Count: 0,
-			Min:   0,
-			Max:   0,
-			Sum:   0,
-			Avg:   0,
-		}
+		return AggregateResult{}
 	}
 
 	// If we have a footer with block statistics and we're not skipping pre-calculated values,
@@ -350,7 +478,7 @@ func (r *Reader) aggregateParallelWithFooter(blockIndices []uint64, numWorkers i
 			}
 
 			// Process blocks assigned to this worker
-			var count int
+			var count uint64
 			var min int64 = 9223372036854775807  // Max int64
 			var max int64 = -9223372036854775808 // Min int64
 			var sum int64 = 0
@@ -365,7 +493,7 @@ func (r *Reader) aggregateParallelWithFooter(blockIndices []uint64, numWorkers i
 				blockSum := uint64ToInt64(entry.Sum)
 
 				// Update aggregates
-				count += int(entry.Count)
+				count += uint64(entry.Count)
 				if minValue < min {
 					min = minValue
 				}
@@ -375,20 +503,8 @@ func (r *Reader) aggregateParallelWithFooter(blockIndices []uint64, numWorkers i
 				sum += blockSum
 			}
 
-			// Calculate average
-			var avg float64 = 0
-			if count > 0 {
-				avg = float64(sum) / float64(count)
-			}
-
 			// Send result to channel
-			resultChan <- AggregateResult{
-				Count: count,
-				Min:   min,
-				Max:   max,
-				Sum:   sum,
-				Avg:   avg,
-			}
+			resultChan <- newAggregateResult(count, min, max, sum).withSource(SourceMetadata, 0)
 		}(w)
 	}
 
@@ -397,89 +513,84 @@ func (r *Reader) aggregateParallelWithFooter(blockIndices []uint64, numWorkers i
 	close(resultChan)
 
 	// Merge results
-	var finalResult AggregateResult
-	var totalCount int
-	var totalSum int64
-
+	results := make([]AggregateResult, 0, numWorkers)
 	for result := range resultChan {
-		totalCount += result.Count
-		totalSum += result.Sum
-
-		if result.Min < finalResult.Min || finalResult.Count == 0 {
-			finalResult.Min = result.Min
-		}
-
-		if result.Max > finalResult.Max || finalResult.Count == 0 {
-			finalResult.Max = result.Max
-		}
-
-		finalResult.Count += result.Count
-	}
-
-	// Calculate final average
-	if totalCount > 0 {
-		finalResult.Avg = float64(totalSum) / float64(totalCount)
+		results = append(results, result)
 	}
-
-	finalResult.Sum = totalSum
-
-	return finalResult
+	return MergeAggregates(results...)
 }
 
 // aggregateParallelWithReading performs parallel aggregation by reading blocks
 func (r *Reader) aggregateParallelWithReading(blockIndices []uint64, opts AggregateOptions, numWorkers int) AggregateResult {
+	defer r.adviseFor(AdviceRandom)()
+
+	// Workers take blocks from a shared queue rather than fixed ranges, so
+	// skewed block sizes or selectivity do not leave one worker with most of the work
+	queue := r.blockQueue(blockIndices)
+
 	// Create a channel for workers to send their results
 	resultChan := make(chan AggregateResult, numWorkers)
-
-	// Calculate how many blocks each worker should process
-	blocksPerWorker := (len(blockIndices) + numWorkers - 1) / numWorkers
+	errs := &aggregateErrors{policy: opts.OnError}
+	filtered := opts.Filter != nil || opts.DenyFilter != nil
+	var coverage filterCoverage
+	if filtered && !opts.SkipPreCalculated {
+		coverage = r.newFilterCoverage(opts.Filter, opts.DenyFilter)
+	}
 
 	// Start workers
 	var wg sync.WaitGroup
 	for w := 0; w < numWorkers; w++ {
 		wg.Add(1)
-		go func(workerID int) {
+		go func() {
 			defer wg.Done()
 
-			// Calculate the range of blocks this worker should process
-			startIdx := workerID * blocksPerWorker
-			endIdx := (workerID + 1) * blocksPerWorker
-			if endIdx > len(blockIndices) {
-				endIdx = len(blockIndices)
-			}
-
-			// Skip if this worker has no blocks to process
-			if startIdx >= endIdx {
-				return
-			}
-
 			// Process blocks assigned to this worker
-			var count int
+			var count uint64
 			var min int64 = 9223372036854775807  // Max int64
 			var max int64 = -9223372036854775808 // Min int64
 			var sum int64 = 0
+			var valsBuf []int64 // Reused for all blocks of this worker
+			var scanned uint64
 
-			for i := startIdx; i < endIdx; i++ {
-				blockIdx := blockIndices[i]
+			for blockIdx := range queue {
+				if errs.stopped() {
+					break
+				}
+
+				// Blocks whose IDs all pass the filters are answered from their statistics
+				if filtered && !opts.SkipPreCalculated && coverage.covers(r.blockIndex[blockIdx]) {
+					entry := r.blockIndex[blockIdx]
+					count += uint64(entry.Count)
+					if v := uint64ToInt64(entry.MinValue); v < min {
+						min = v
+					}
+					if v := uint64ToInt64(entry.MaxValue); v > max {
+						max = v
+					}
+					sum += uint64ToInt64(entry.Sum)
+					continue
+				}
 
 				// Read block with filtering if needed
 				var values []int64
 				var err error
 
-				if opts.Filter != nil || opts.DenyFilter != nil {
+				if filtered {
 					// Read block with filtering
-					_, values, err = r.readBlockFiltered(int(blockIdx), opts.Filter, opts.DenyFilter)
+					values, err = r.readBlockValuesFiltered(BlockID(blockIdx), opts.Filter, opts.DenyFilter, valsBuf)
 				} else {
 					// Read block without filtering
-					_, values, err = r.GetPairs(blockIdx)
+					values, err = r.ReadBlockValuesInto(BlockID(blockIdx), valsBuf)
 				}
 
 				if err != nil {
-					// Skip blocks with errors
+					errs.add(BlockID(blockIdx), err)
 					continue
 				}
+				valsBuf = values
+				scanned++
 
-				count += len(values)
+				count += uint64(len(values))
 				for _, v := range values {
 					if v < min {
 						min = v
@@ -491,21 +602,13 @@ func (r *Reader) aggregateParallelWithReading(blockIndices []uint64, opts Aggreg
 				}
 			}
 
-			// Calculate average
-			var avg float64 = 0
-			if count > 0 {
-				avg = float64(sum) / float64(count)
-			}
-
 			// Send result to channel
-			resultChan <- AggregateResult{
-				Count: count,
-				Min:   min,
-				Max:   max,
-				Sum:   sum,
-				Avg:   avg,
+			source := SourceFullScan
+			if filtered {
+				source = filteredSource(scanned)
 			}
-		}(w)
+			resultChan <- newAggregateResult(count, min, max, sum).withSource(source, scanned)
+		}()
 	}
 
 	// Wait for all workers to finish
@@ -513,31 +616,9 @@ func (r *Reader) aggregateParallelWithReading(blockIndices []uint64, opts Aggreg
 	close(resultChan)
 
 	// Merge results
-	var finalResult AggregateResult
-	var totalCount int
-	var totalSum int64
-
+	results := make([]AggregateResult, 0, numWorkers)
 	for result := range resultChan {
-		totalCount += result.Count
-		totalSum += result.Sum
-
-		if result.Min < finalResult.Min || finalResult.Count == 0 {
-			finalResult.Min = result.Min
-		}
-
-		if result.Max > finalResult.Max || finalResult.Count == 0 {
-			finalResult.Max = result.Max
-		}
-
-		finalResult.Count += result.Count
+		results = append(results, result)
 	}
-
-	// Calculate final average
-	if totalCount > 0 {
-		finalResult.Avg = float64(totalSum) / float64(totalCount)
-	}
-
-	finalResult.Sum = totalSum
-
-	return finalResult
+	return errs.apply(MergeAggregates(results...))
 }
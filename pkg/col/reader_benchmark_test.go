@@ -76,6 +76,41 @@ func BenchmarkReaderGetPairs(b *testing.B) {
 	}
 }
 
+// BenchmarkReaderReadBlockInto benchmarks the ReadBlockInto method with reused buffers
+func BenchmarkReaderReadBlockInto(b *testing.B) {
+	// Create a test file with 10 blocks
+	filename := setupTestFile(b, 10)
+	defer os.Remove(filename)
+
+	// Create a reader
+	reader, err := NewReader(filename)
+	if err != nil {
+		b.Fatalf("Failed to create reader: %v", err)
+	}
+	defer reader.Close()
+
+	var ids []uint64
+	var values []int64
+
+	// Reset the timer
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	// Run the benchmark
+	for i := 0; i < b.N; i++ {
+		// Read each block
+		for j := 0; j < 10; j++ {
+			ids, values, err = reader.ReadBlockInto(BlockID(j), ids, values)
+			if err != nil {
+				b.Fatalf("Failed to read block: %v", err)
+			}
+			if len(ids) != 1000 || len(values) != 1000 {
+				b.Fatalf("Unexpected number of pairs: %d, %d", len(ids), len(values))
+			}
+		}
+	}
+}
+
 // BenchmarkReaderReadAllBlocks benchmarks reading all blocks sequentially
 func BenchmarkReaderReadAllBlocks(b *testing.B) {
 	// Create a test file with 100 blocks
@@ -1,69 +1,222 @@
 package col
 
 import (
-	"encoding/binary"
 	"fmt"
+	"sync"
 )
 
-// readBlock reads a block from the file
-func (r *Reader) readBlock(blockIndex int) ([]uint64, []int64, error) {
-	// Validate block index
-	if blockIndex < 0 || blockIndex >= len(r.blockIndex) {
-		return nil, nil, fmt.Errorf("invalid block index: %d", blockIndex)
-	}
+// blockBufferPool holds scratch buffers for the encoded data of a block. The
+// decoded IDs and values never alias these buffers, so they can be reused as
+// soon as a block has been decoded.
+var blockBufferPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, 0, defaultBlockSize)
+		return &buf
+	},
+}
 
-	// Get block information from the index
-	blockOffset := int64(r.blockIndex[blockIndex].BlockOffset)
-	blockSize := int64(r.blockIndex[blockIndex].BlockSize)
-	count := int(r.blockIndex[blockIndex].Count)
+// ReadBlock returns the ID-value pairs of a block
+func (r *Reader) ReadBlock(id BlockID) ([]uint64, []int64, error) {
+	return r.readBlockInto(id, nil, nil)
+}
 
-	// Read the entire block data in one call (excluding the block header)
-	// We need to read the layout section (16 bytes) and the data sections
-	dataOffset := blockOffset + blockHeaderSize
-	dataSize := int(blockSize) - blockHeaderSize
+// ReadBlockInto returns the ID-value pairs of a block like ReadBlock, but decodes
+// them into idsBuf and valsBuf when their capacity suffices, so a scan over many
+// blocks can reuse the same buffers. The returned slices may share their backing
+// arrays with the buffers and are only valid until the buffers are reused.
+func (r *Reader) ReadBlockInto(id BlockID, idsBuf []uint64, valsBuf []int64) ([]uint64, []int64, error) {
+	return r.readBlockInto(id, idsBuf, valsBuf)
+}
 
-	// Read all data after the header in one call
-	blockData, err := r.readBytesAt(dataOffset, dataSize)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read block data: %w", err)
+// ReadBlockIDs returns the IDs of a block. Only the ID section is decoded, which
+// saves the work of decoding the values when e.g. building a bitmap or checking
+// for the existence of IDs.
+func (r *Reader) ReadBlockIDs(id BlockID) ([]uint64, error) {
+	return r.ReadBlockIDsInto(id, nil)
+}
+
+// ReadBlockIDsInto returns the IDs of a block like ReadBlockIDs, decoding them
+// into idsBuf when its capacity suffices
+func (r *Reader) ReadBlockIDsInto(id BlockID, idsBuf []uint64) ([]uint64, error) {
+	r.recordAccess(id)
+	if r.blockCache != nil {
+		if ids, _, ok := r.blockCache.get(id, idsBuf, nil, false, true); ok {
+			return ids, nil
+		}
 	}
 
-	// Parse the layout section (first 16 bytes)
-	idSectionOffset := binary.LittleEndian.Uint32(blockData[0:4])
-	idSectionSize := binary.LittleEndian.Uint32(blockData[4:8])
-	valueSectionOffset := binary.LittleEndian.Uint32(blockData[8:12])
-	valueSectionSize := binary.LittleEndian.Uint32(blockData[12:16])
+	sections, release, err := r.readBlockSections(id)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 
-	// Validate header values
-	if idSectionSize == 0 {
-		return nil, nil, fmt.Errorf("ID section size in header is 0")
+	idEncoding, _, err := sectionEncodings(sections.encodingType)
+	if err != nil {
+		return nil, err
 	}
-	if valueSectionSize == 0 {
-		return nil, nil, fmt.Errorf("Value section size in header is 0")
+	return decodeBlockIDs(sections.idBytes, sections.count, idEncoding, r.header.ColumnType, idsBuf)
+}
+
+// ReadBlockValues returns the values of a block in ID order. Only the value
+// section is decoded, which saves the work of decoding the IDs when e.g.
+// aggregating all values.
+func (r *Reader) ReadBlockValues(id BlockID) ([]int64, error) {
+	return r.ReadBlockValuesInto(id, nil)
+}
+
+// ReadBlockValuesInto returns the values of a block like ReadBlockValues,
+// decoding them into valsBuf when its capacity suffices
+func (r *Reader) ReadBlockValuesInto(id BlockID, valsBuf []int64) ([]int64, error) {
+	r.recordAccess(id)
+	if r.blockCache != nil {
+		if _, values, ok := r.blockCache.get(id, nil, valsBuf, true, false); ok {
+			return values, nil
+		}
 	}
 
-	// Extract ID and value sections from the buffer
-	// The layout section is 16 bytes, followed by the data sections
-	idStart := 16 + int(idSectionOffset)
-	idEnd := idStart + int(idSectionSize)
+	sections, release, err := r.readBlockSections(id)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 
-	valueStart := 16 + int(valueSectionOffset)
-	valueEnd := valueStart + int(valueSectionSize)
+	_, valueEncoding, err := sectionEncodings(sections.encodingType)
+	if err != nil {
+		return nil, err
+	}
+	return decodeValueSection(sections.valueBytes, sections.count, valueEncoding, r.header.ColumnType, valsBuf), nil
+}
 
-	// Validate buffer boundaries
-	if idEnd > len(blockData) || valueEnd > len(blockData) {
-		return nil, nil, fmt.Errorf("section boundaries exceed block data size")
+// readBlockInto reads a block from the block cache or the file, decoding it into
+// the backing arrays of idsBuf and valuesBuf if they are large enough
+func (r *Reader) readBlockInto(blockIndex BlockID, idsBuf []uint64, valuesBuf []int64) ([]uint64, []int64, error) {
+	reads := r.recordAccess(blockIndex)
+	cache := r.blockCache
+	if cache != nil {
+		if ids, values, ok := cache.get(blockIndex, idsBuf, valuesBuf, false, false); ok {
+			return ids, values, nil
+		}
 	}
 
-	// Extract the sections
-	idBytes := blockData[idStart:idEnd]
-	valueBytes := blockData[valueStart:valueEnd]
+	sections, release, err := r.readBlockSections(blockIndex)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer release()
 
 	// Decode IDs and values
-	ids, values, err := decodeBlockData(idBytes, valueBytes, count, r.header.EncodingType)
+	ids, values, err := decodeBlockDataInto(sections.idBytes, sections.valueBytes, sections.count,
+		sections.encodingType, r.header.ColumnType, idsBuf, valuesBuf)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	if cache != nil {
+		cache.add(blockIndex, ids, values, r.admitToCache(reads))
+	}
 	return ids, values, nil
 }
+
+// blockSections holds the encoded data sections of a block
+type blockSections struct {
+	idBytes      []byte
+	valueBytes   []byte
+	count        int
+	encodingType uint32 // Encoding of the block, which may override the file default
+}
+
+// readBlockSections reads a block from the file into a pooled scratch buffer and
+// returns its encoded data sections. The sections are only valid until release
+// is called, which returns the buffer to the pool.
+func (r *Reader) readBlockSections(blockIndex BlockID) (blockSections, func(), error) {
+	// Make sure the block index is available for lazily opened readers
+	if err := r.ensureFooter(); err != nil {
+		return blockSections{}, nil, err
+	}
+
+	// Validate block index
+	if blockIndex >= BlockID(len(r.blockIndex)) {
+		return blockSections{}, nil, fmt.Errorf("invalid block index: %d", blockIndex)
+	}
+
+	// Get block information from the index
+	blockOffset := int64(r.blockIndex[blockIndex].BlockOffset)
+	blockSize, err := intFromFile(uint64(r.blockIndex[blockIndex].BlockSize))
+	if err != nil {
+		return blockSections{}, nil, fmt.Errorf("block %d: %w", blockIndex, err)
+	}
+	count, err := intFromFile(uint64(r.blockIndex[blockIndex].Count))
+	if err != nil {
+		return blockSections{}, nil, fmt.Errorf("block %d: %w", blockIndex, err)
+	}
+
+	// Read the entire block in one call into a pooled scratch buffer. We need the
+	// block header for the encoding, followed by the layout section (16 bytes)
+	// and the data sections.
+	if blockSize < blockHeaderSize+blockLayoutSize {
+		return blockSections{}, nil, fmt.Errorf("block %d too small: %d bytes", blockIndex, blockSize)
+	}
+	scratch := blockBufferPool.Get().(*[]byte)
+	release := func() { blockBufferPool.Put(scratch) }
+	if cap(*scratch) < blockSize {
+		*scratch = make([]byte, blockSize)
+	}
+	block := (*scratch)[:blockSize]
+	if err := r.readBytesInto(block, blockOffset); err != nil {
+		release()
+		return blockSections{}, nil, fmt.Errorf("failed to read block data: %w", err)
+	}
+
+	sections, err := parseBlockSections(block, blockIndex, count)
+	if err != nil {
+		release()
+		return blockSections{}, nil, err
+	}
+	return sections, release, nil
+}
+
+// parseBlockSections locates the data sections of a block holding count pairs
+func parseBlockSections(block []byte, blockIndex BlockID, count int) (blockSections, error) {
+	var header BlockHeader
+	if err := header.UnmarshalBinary(block[:blockHeaderSize]); err != nil {
+		return blockSections{}, err
+	}
+	var layout BlockLayout
+	if err := layout.UnmarshalBinary(block[blockHeaderSize : blockHeaderSize+blockLayoutSize]); err != nil {
+		return blockSections{}, err
+	}
+
+	// Each block records its own encoding, which may override the file default
+	if header.CompressionType != CompressionNone {
+		return blockSections{}, fmt.Errorf("block %d uses unsupported compression type: %d", blockIndex, header.CompressionType)
+	}
+
+	// Validate layout values
+	if layout.IDSectionSize == 0 {
+		return blockSections{}, fmt.Errorf("ID section size in header is 0")
+	}
+	if layout.ValueSectionSize == 0 {
+		return blockSections{}, fmt.Errorf("Value section size in header is 0")
+	}
+
+	// Extract ID and value sections from the data following the layout section
+	blockData := block[blockHeaderSize+blockLayoutSize:]
+	idStart := uint64(layout.IDSectionOffset)
+	idEnd := idStart + uint64(layout.IDSectionSize)
+
+	valueStart := uint64(layout.ValueSectionOffset)
+	valueEnd := valueStart + uint64(layout.ValueSectionSize)
+
+	// Validate buffer boundaries
+	if idEnd > uint64(len(blockData)) || valueEnd > uint64(len(blockData)) {
+		return blockSections{}, fmt.Errorf("section boundaries exceed block data size")
+	}
+
+	return blockSections{
+		idBytes:      blockData[idStart:idEnd],
+		valueBytes:   blockData[valueStart:valueEnd],
+		count:        count,
+		encodingType: header.EncodingType,
+	}, nil
+}
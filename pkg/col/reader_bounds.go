@@ -0,0 +1,71 @@
+package col
+
+import (
+	"fmt"
+)
+
+// IDRange is an inclusive range of IDs
+type IDRange struct {
+	Min uint64
+	Max uint64
+}
+
+// ValueRange is an inclusive range of values. Like Aggregate, it describes the
+// values of DataTypeUint64 columns as their int64 bit patterns; see
+// AggregateUint64 for their unsigned bounds.
+type ValueRange struct {
+	Min int64
+	Max int64
+}
+
+// IDBounds returns the smallest and largest ID of the file from the footer,
+// without decoding any block. It returns false for files without pairs.
+func (r *Reader) IDBounds() (IDRange, bool, error) {
+	stats, err := r.FileStats()
+	if err != nil || stats.Count == 0 {
+		return IDRange{}, false, err
+	}
+	return IDRange{Min: stats.MinID, Max: stats.MaxID}, true, nil
+}
+
+// ValueBounds returns the smallest and largest value of the file from the
+// footer, without decoding any block. It returns false for files without
+// pairs.
+func (r *Reader) ValueBounds() (ValueRange, bool, error) {
+	stats, err := r.FileStats()
+	if err != nil || stats.Count == 0 {
+		return ValueRange{}, false, err
+	}
+	return ValueRange{Min: stats.MinValue, Max: stats.MaxValue}, true, nil
+}
+
+// BlockIDBounds returns the smallest and largest ID of a block from the block
+// index
+func (r *Reader) BlockIDBounds(id BlockID) (IDRange, error) {
+	entry, err := r.blockIndexEntry(id)
+	if err != nil {
+		return IDRange{}, err
+	}
+	return IDRange{Min: entry.MinID, Max: entry.MaxID}, nil
+}
+
+// BlockValueBounds returns the smallest and largest value of a block from the
+// block index
+func (r *Reader) BlockValueBounds(id BlockID) (ValueRange, error) {
+	entry, err := r.blockIndexEntry(id)
+	if err != nil {
+		return ValueRange{}, err
+	}
+	return ValueRange{Min: uint64ToInt64(entry.MinValue), Max: uint64ToInt64(entry.MaxValue)}, nil
+}
+
+// blockIndexEntry returns the block index entry of a block
+func (r *Reader) blockIndexEntry(id BlockID) (FooterEntry, error) {
+	if err := r.ensureFooter(); err != nil {
+		return FooterEntry{}, err
+	}
+	if id >= BlockID(len(r.blockIndex)) {
+		return FooterEntry{}, fmt.Errorf("invalid block index: %d", id)
+	}
+	return r.blockIndex[id], nil
+}
@@ -0,0 +1,60 @@
+package col
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReaderBounds(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewWriterToBuffer(&buf, WithEncoding(EncodingVarIntBoth))
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteBlock([]uint64{10, 20, 30}, []int64{5, -7, 3}))
+	require.NoError(t, writer.WriteBlock([]uint64{2, 40}, []int64{100, 0}))
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := NewReaderFromBytes(buf.Bytes())
+	require.NoError(t, err)
+	defer reader.Close()
+
+	ids, ok, err := reader.IDBounds()
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, IDRange{Min: 2, Max: 40}, ids)
+
+	values, ok, err := reader.ValueBounds()
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, ValueRange{Min: -7, Max: 100}, values)
+
+	blockIDs, err := reader.BlockIDBounds(0)
+	require.NoError(t, err)
+	assert.Equal(t, IDRange{Min: 10, Max: 30}, blockIDs)
+	blockValues, err := reader.BlockValueBounds(1)
+	require.NoError(t, err)
+	assert.Equal(t, ValueRange{Min: 0, Max: 100}, blockValues)
+
+	_, err = reader.BlockIDBounds(2)
+	assert.Error(t, err)
+	_, err = reader.BlockValueBounds(2)
+	assert.Error(t, err)
+
+	// Files without pairs have no bounds
+	var empty bytes.Buffer
+	writer, err = NewWriterToBuffer(&empty)
+	require.NoError(t, err)
+	require.NoError(t, writer.FinalizeAndClose())
+	emptyReader, err := NewReaderFromBytes(empty.Bytes())
+	require.NoError(t, err)
+	defer emptyReader.Close()
+
+	_, ok, err = emptyReader.IDBounds()
+	require.NoError(t, err)
+	assert.False(t, ok)
+	_, ok, err = emptyReader.ValueBounds()
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
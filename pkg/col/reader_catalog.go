@@ -0,0 +1,100 @@
+package col
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// CatalogEntry describes a column file for external metadata catalogs. It is
+// encoded as a JSON document with the field names given by the tags; the
+// encoding, data type and checksum type are the numbers of the format spec.
+type CatalogEntry struct {
+	FileSize       int64         `json:"file_size"`
+	RowCount       uint64        `json:"row_count"`
+	BlockCount     int           `json:"block_count"`
+	DataType       uint32        `json:"data_type"`
+	IDRange        *CatalogRange `json:"id_range,omitempty"`    // Nil for empty files
+	ValueRange     *CatalogRange `json:"value_range,omitempty"` // Nil for empty files
+	Encoding       uint32        `json:"encoding"`              // Default encoding of the file
+	BlockEncodings []uint32      `json:"block_encodings"`       // Distinct encodings of the blocks, ascending
+	CreatedAt      time.Time     `json:"created_at"`
+	ChecksumType   uint32        `json:"checksum_type"`
+	Checksum       string        `json:"checksum,omitempty"` // Hexadecimal, empty without a checksum
+}
+
+// CatalogRange is an inclusive range of IDs or values. The bounds are JSON
+// numbers, so the values of DataTypeUint64 columns keep their unsigned value.
+type CatalogRange struct {
+	Min json.Number `json:"min"`
+	Max json.Number `json:"max"`
+}
+
+// CatalogEntry returns the description of the file for metadata catalogs. The
+// statistics come from the footer; only the block headers are read to collect
+// the block encodings, and the values of DataTypeUint64 columns are decoded if
+// the file lacks the unsigned statistics section.
+func (r *Reader) CatalogEntry() (CatalogEntry, error) {
+	if err := r.ensureFooter(); err != nil {
+		return CatalogEntry{}, err
+	}
+
+	stats, err := r.FileStats()
+	if err != nil {
+		return CatalogEntry{}, err
+	}
+
+	entry := CatalogEntry{
+		FileSize:     r.fileSize,
+		RowCount:     stats.Count,
+		BlockCount:   len(r.blockIndex),
+		DataType:     r.header.ColumnType,
+		Encoding:     r.header.EncodingType,
+		CreatedAt:    time.Unix(int64(r.header.CreationTime), 0).UTC(),
+		ChecksumType: r.header.ChecksumType,
+	}
+	if r.header.ChecksumType != ChecksumNone {
+		entry.Checksum = fmt.Sprintf("%016x", r.footerMeta.Checksum)
+	}
+
+	if stats.Count > 0 {
+		entry.IDRange = &CatalogRange{
+			Min: json.Number(strconv.FormatUint(stats.MinID, 10)),
+			Max: json.Number(strconv.FormatUint(stats.MaxID, 10)),
+		}
+		entry.ValueRange = &CatalogRange{
+			Min: json.Number(strconv.FormatInt(stats.MinValue, 10)),
+			Max: json.Number(strconv.FormatInt(stats.MaxValue, 10)),
+		}
+		if r.header.ColumnType == DataTypeUint64 {
+			unsigned, err := r.AggregateUint64()
+			if err != nil {
+				return CatalogEntry{}, err
+			}
+			entry.ValueRange = &CatalogRange{
+				Min: json.Number(strconv.FormatUint(unsigned.Min, 10)),
+				Max: json.Number(strconv.FormatUint(unsigned.Max, 10)),
+			}
+		}
+	}
+
+	encodings := make(map[uint32]bool)
+	for i := range r.blockIndex {
+		meta, err := r.BlockMeta(BlockID(i))
+		if err != nil {
+			return CatalogEntry{}, err
+		}
+		encodings[meta.Encoding] = true
+	}
+	entry.BlockEncodings = make([]uint32, 0, len(encodings))
+	for encoding := range encodings {
+		entry.BlockEncodings = append(entry.BlockEncodings, encoding)
+	}
+	sort.Slice(entry.BlockEncodings, func(a, b int) bool {
+		return entry.BlockEncodings[a] < entry.BlockEncodings[b]
+	})
+
+	return entry, nil
+}
@@ -0,0 +1,94 @@
+package col
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalogEntry(t *testing.T) {
+	t.Run("Int64 column", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer, err := NewWriterToBuffer(&buf, WithEncoding(EncodingVarIntBoth), WithChecksum(ChecksumXXHash64))
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlock([]uint64{5, 6, 7}, []int64{-10, 0, 10}))
+		require.NoError(t, writer.WriteBlockWithOptions([]uint64{20, 30}, []int64{100, 200}, WithBlockEncoding(EncodingRaw)))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		reader, err := NewReaderFromBytes(buf.Bytes())
+		require.NoError(t, err)
+		defer reader.Close()
+
+		entry, err := reader.CatalogEntry()
+		require.NoError(t, err)
+		assert.Equal(t, int64(buf.Len()), entry.FileSize)
+		assert.Equal(t, uint64(5), entry.RowCount)
+		assert.Equal(t, 2, entry.BlockCount)
+		assert.Equal(t, DataTypeInt64, entry.DataType)
+		assert.Equal(t, &CatalogRange{Min: "5", Max: "30"}, entry.IDRange)
+		assert.Equal(t, &CatalogRange{Min: "-10", Max: "200"}, entry.ValueRange)
+		assert.Equal(t, EncodingVarIntBoth, entry.Encoding)
+		assert.Equal(t, []uint32{EncodingRaw, EncodingVarIntBoth}, entry.BlockEncodings)
+		assert.Equal(t, int64(reader.HeaderOnly().CreationTime), entry.CreatedAt.Unix())
+		assert.Equal(t, ChecksumXXHash64, entry.ChecksumType)
+		assert.Equal(t, fmt.Sprintf("%016x", reader.footerMeta.Checksum), entry.Checksum)
+
+		doc, err := json.Marshal(entry)
+		require.NoError(t, err)
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal(doc, &decoded))
+		assert.Equal(t, float64(5), decoded["row_count"])
+		assert.Equal(t, map[string]interface{}{"min": float64(-10), "max": float64(200)}, decoded["value_range"])
+		assert.Equal(t, []interface{}{float64(EncodingRaw), float64(EncodingVarIntBoth)}, decoded["block_encodings"])
+		assert.Contains(t, decoded, "created_at")
+	})
+
+	t.Run("Uint64 column", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer, err := NewWriterToBuffer(&buf, WithDataType(DataTypeUint64), WithChecksum(ChecksumNone))
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlock([]uint64{1, 2}, []int64{1, -1}))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		reader, err := NewReaderFromBytes(buf.Bytes())
+		require.NoError(t, err)
+		defer reader.Close()
+
+		entry, err := reader.CatalogEntry()
+		require.NoError(t, err)
+		assert.Equal(t, &CatalogRange{Min: "1", Max: "18446744073709551615"}, entry.ValueRange)
+		assert.Empty(t, entry.Checksum)
+
+		doc, err := json.Marshal(entry)
+		require.NoError(t, err)
+		assert.Contains(t, string(doc), `"value_range":{"min":1,"max":18446744073709551615}`)
+		assert.NotContains(t, string(doc), `"checksum":`)
+	})
+
+	t.Run("Empty file", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer, err := NewWriterToBuffer(&buf)
+		require.NoError(t, err)
+		require.NoError(t, writer.FinalizeAndClose())
+
+		reader, err := NewReaderFromBytes(buf.Bytes())
+		require.NoError(t, err)
+		defer reader.Close()
+
+		entry, err := reader.CatalogEntry()
+		require.NoError(t, err)
+		assert.Zero(t, entry.RowCount)
+		assert.Nil(t, entry.IDRange)
+		assert.Nil(t, entry.ValueRange)
+		assert.Empty(t, entry.BlockEncodings)
+
+		doc, err := json.Marshal(entry)
+		require.NoError(t, err)
+		assert.Contains(t, string(doc), `"block_encodings":[]`)
+		assert.NotContains(t, string(doc), "id_range")
+	})
+}
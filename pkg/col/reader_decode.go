@@ -7,20 +7,43 @@ import (
 
 // decodeBlockData decodes the ID and value byte arrays into usable slices
 func decodeBlockData(idBytes, valueBytes []byte, count int, encodingType uint32) ([]uint64, []int64, error) {
-	// Decode IDs
-	var ids []uint64
-	var err error
+	return decodeBlockDataInto(idBytes, valueBytes, count, encodingType, DataTypeInt64, nil, nil)
+}
+
+// decodeBlockDataInto decodes the ID and value byte arrays of a column with the
+// given data type like decodeBlockData, reusing the backing arrays of idsBuf and
+// valuesBuf if they are large enough
+func decodeBlockDataInto(idBytes, valueBytes []byte, count int, encodingType uint32, dataType uint32, idsBuf []uint64, valuesBuf []int64) ([]uint64, []int64, error) {
+	idEncoding, valueEncoding, err := sectionEncodings(encodingType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids, err := decodeBlockIDs(idBytes, count, idEncoding, dataType, idsBuf)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	isVarInt := encodingType == EncodingVarInt ||
-		encodingType == EncodingVarIntID ||
-		encodingType == EncodingVarIntValue ||
-		encodingType == EncodingVarIntBoth
+	// A truncated fixed-width section limits both sections
+	values := decodeValueSection(valueBytes, len(ids), valueEncoding, dataType, valuesBuf)
+	if len(ids) > len(values) {
+		ids = ids[:len(values)]
+	}
+
+	return ids, values, nil
+}
+
+// decodeIDSection decodes up to count IDs from the ID section of a block,
+// reusing the backing array of idsBuf if it is large enough
+func decodeIDSection(idBytes []byte, count int, encoding sectionEncoding, idsBuf []uint64) ([]uint64, error) {
+	var ids []uint64
 
-	if isVarInt {
+	if encoding.varint {
 		// For variable-length encoding, use the decodeUVarInts function
-		ids, err = decodeUVarInts(idBytes, count)
+		var err error
+		ids, err = decodeUVarIntsInto(idBytes, count, idsBuf)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to decode varint IDs: %w", err)
+			return nil, fmt.Errorf("failed to decode varint IDs: %w", err)
 		}
 	} else {
 		// Calculate max number of IDs we can read
@@ -31,89 +54,103 @@ func decodeBlockData(idBytes, valueBytes []byte, count int, encodingType uint32)
 		}
 
 		// Read fixed-width IDs
-		ids = make([]uint64, count)
+		ids = resizeUint64s(idsBuf, count)
 		for i := 0; i < count; i++ {
-			if i*bytesPerID+bytesPerID <= len(idBytes) {
-				ids[i] = binary.LittleEndian.Uint64(idBytes[i*bytesPerID : i*bytesPerID+bytesPerID])
-			} else {
-				// Mock test data for out-of-bounds reads
-				ids[i] = uint64(i + 1)
+			ids[i] = binary.LittleEndian.Uint64(idBytes[i*bytesPerID : i*bytesPerID+bytesPerID])
+		}
+	}
+
+	// Apply delta decoding if needed
+	if encoding.deltaOfDelta {
+		// Undo the ZigZag encoding, then accumulate the deltas and the IDs
+		var delta uint64
+		for i := range ids {
+			zigzag := ids[i]
+			deltaOfDelta := (zigzag >> 1) ^ -(zigzag & 1)
+			if i == 0 {
+				ids[i] = deltaOfDelta
+				continue
 			}
+			delta += deltaOfDelta
+			ids[i] = ids[i-1] + delta
+		}
+	}
+	if encoding.delta {
+		for i := 1; i < len(ids); i++ {
+			ids[i] += ids[i-1]
 		}
 	}
 
-	// Decode values
+	return ids, nil
+}
+
+// decodeValueSection decodes up to count values from the value section of a
+// block of a column with the given data type, reusing the backing array of
+// valuesBuf if it is large enough
+func decodeValueSection(valueBytes []byte, count int, encoding sectionEncoding, dataType uint32, valuesBuf []int64) []int64 {
+	if dataType == DataTypeBool {
+		return decodeBoolValues(valueBytes, count, valuesBuf)
+	}
+
 	var values []int64
 
-	if isVarInt {
+	if encoding.varint {
 		// Decode variable-length values
-		values = make([]int64, count)
+		zigzag := zigzagValues(encoding, dataType)
+		values = resizeInt64s(valuesBuf, count)
 		offset := 0
-		for i := 0; i < count && offset < len(valueBytes); i++ {
+		i := 0
+		for ; i < count && offset < len(valueBytes); i++ {
 			var bytesRead int
-			if offset < len(valueBytes) {
+			if zigzag {
 				values[i], bytesRead = decodeSignedVarInt(valueBytes[offset:])
-				if bytesRead <= 0 {
-					// Mock test data for invalid varints
-					values[i] = int64((i + 1) * 100)
-					bytesRead = 1
-				}
-				offset += bytesRead
 			} else {
-				// Mock test data for out-of-bounds reads
+				var value uint64
+				value, bytesRead = decodeVarInt(valueBytes[offset:])
+				values[i] = int64(value)
+			}
+			if bytesRead <= 0 {
+				// Mock test data for invalid varints
 				values[i] = int64((i + 1) * 100)
+				bytesRead = 1
 			}
+			offset += bytesRead
 		}
+		// Values missing from the section are zero, also when reusing a buffer
+		clear(values[i:])
 	} else {
 		// Decode fixed-width values
 		bytesPerValue := 8
 		maxCount := len(valueBytes) / bytesPerValue
 		if count > maxCount {
 			count = maxCount
-			// Adjust IDs to match
-			if len(ids) > count {
-				ids = ids[:count]
-			}
 		}
 
-		values = make([]int64, count)
+		values = resizeInt64s(valuesBuf, count)
 		for i := 0; i < count; i++ {
-			if i*bytesPerValue+bytesPerValue <= len(valueBytes) {
-				values[i] = int64(binary.LittleEndian.Uint64(valueBytes[i*bytesPerValue : i*bytesPerValue+bytesPerValue]))
-			} else {
-				// Mock test data for out-of-bounds reads
-				values[i] = int64((i + 1) * 100)
-			}
+			values[i] = int64(binary.LittleEndian.Uint64(valueBytes[i*bytesPerValue : i*bytesPerValue+bytesPerValue]))
 		}
 	}
 
 	// Apply delta decoding if needed
-	if encodingType == EncodingDeltaBoth || encodingType == EncodingVarIntBoth {
-		// Delta decode both IDs and values
-		for i := 1; i < len(ids); i++ {
-			ids[i] += ids[i-1]
-		}
-		for i := 1; i < len(values); i++ {
-			values[i] += values[i-1]
-		}
-	} else if encodingType == EncodingDeltaID || encodingType == EncodingVarIntID {
-		// Delta decode only IDs
-		for i := 1; i < len(ids); i++ {
-			ids[i] += ids[i-1]
-		}
-	} else if encodingType == EncodingDeltaValue || encodingType == EncodingVarIntValue {
-		// Delta decode only values
+	if encoding.delta {
 		for i := 1; i < len(values); i++ {
 			values[i] += values[i-1]
 		}
 	}
 
-	return ids, values, nil
+	return values
 }
 
 // Helper function to decode exactly 'count' UVarInts from buf
 func decodeUVarInts(buf []byte, count int) ([]uint64, error) {
-	vals := make([]uint64, 0, count)
+	return decodeUVarIntsInto(buf, count, nil)
+}
+
+// decodeUVarIntsInto decodes like decodeUVarInts, reusing the backing array of
+// dst if it is large enough
+func decodeUVarIntsInto(buf []byte, count int, dst []uint64) ([]uint64, error) {
+	vals := resizeUint64s(dst, count)[:0]
 	offset := 0
 
 	// Try to decode up to 'count' varints, but stop if we run out of data
@@ -148,3 +185,21 @@ func decodeUVarInts(buf []byte, count int) ([]uint64, error) {
 
 	return vals, nil
 }
+
+// resizeUint64s returns a slice of length n, reusing the backing array of buf if
+// its capacity suffices. The contents of the returned slice are not cleared.
+func resizeUint64s(buf []uint64, n int) []uint64 {
+	if cap(buf) >= n {
+		return buf[:n]
+	}
+	return make([]uint64, n)
+}
+
+// resizeInt64s returns a slice of length n, reusing the backing array of buf if
+// its capacity suffices. The contents of the returned slice are not cleared.
+func resizeInt64s(buf []int64, n int) []int64 {
+	if cap(buf) >= n {
+		return buf[:n]
+	}
+	return make([]int64, n)
+}
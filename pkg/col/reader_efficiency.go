@@ -0,0 +1,133 @@
+package col
+
+import (
+	"fmt"
+)
+
+// rawPairSize is the size of an ID-value pair without encoding, the baseline
+// of the efficiency report
+const rawPairSize = 16
+
+// EfficiencyReport breaks the size of a file down into the encoded data and
+// the overhead around it, see Reader.EfficiencyReport
+type EfficiencyReport struct {
+	FileSize uint64
+	Count    uint64 // Number of ID-value pairs
+	RawSize  uint64 // Size of the pairs at 16 bytes per pair
+
+	DataSize        uint64 // Encoded ID and value sections of all blocks
+	BlockHeaderSize uint64 // Block headers and layouts
+	PaddingSize     uint64 // Alignment padding after blocks and before the footer
+	FileHeaderSize  uint64
+	BitmapSize      uint64 // Global ID bitmap, including its size field
+	FooterSize      uint64 // Footer including the footer metadata
+
+	Blocks []BlockEfficiency
+}
+
+// BlockEfficiency describes the size of a single block
+type BlockEfficiency struct {
+	Block       BlockID
+	Count       uint32
+	Encoding    uint32
+	RawSize     uint64 // Size of the pairs at 16 bytes per pair
+	DataSize    uint64 // Encoded ID and value sections
+	PaddingSize uint64 // Alignment padding after the block
+}
+
+// Ratio returns the encoded size of the block relative to its raw size
+func (b BlockEfficiency) Ratio() float64 {
+	if b.RawSize == 0 {
+		return 0
+	}
+	return float64(b.DataSize) / float64(b.RawSize)
+}
+
+// DataRatio returns the encoded size of all blocks relative to the raw size
+func (r EfficiencyReport) DataRatio() float64 {
+	if r.RawSize == 0 {
+		return 0
+	}
+	return float64(r.DataSize) / float64(r.RawSize)
+}
+
+// Amplification returns the file size relative to the raw size, i.e. the
+// bytes written per byte of data
+func (r EfficiencyReport) Amplification() float64 {
+	if r.RawSize == 0 {
+		return 0
+	}
+	return float64(r.FileSize) / float64(r.RawSize)
+}
+
+// OverheadSize returns the size of everything but the encoded data
+func (r EfficiencyReport) OverheadSize() uint64 {
+	return r.FileSize - r.DataSize
+}
+
+// EfficiencyReport compares the size of every block to the raw size of its
+// pairs and accounts for all other bytes of the file: headers, padding, the
+// global ID bitmap and the footer. Only block headers are read, no block data
+// is decoded.
+func (r *Reader) EfficiencyReport() (EfficiencyReport, error) {
+	if err := r.ensureFooter(); err != nil {
+		return EfficiencyReport{}, err
+	}
+
+	report := EfficiencyReport{
+		FileSize:       uint64(r.fileSize),
+		FileHeaderSize: headerSize,
+		BitmapSize:     r.header.BitmapSize,
+		FooterSize:     r.footerMeta.FooterSize + footerMetaSize,
+		Blocks:         make([]BlockEfficiency, len(r.blockIndex)),
+	}
+
+	for i, entry := range r.blockIndex {
+		buf, err := r.readBytesAt(int64(entry.BlockOffset), blockHeaderSize+blockLayoutSize)
+		if err != nil {
+			return EfficiencyReport{}, fmt.Errorf("failed to read header of block %d: %w", i, err)
+		}
+		var header BlockHeader
+		if err := header.UnmarshalBinary(buf[:blockHeaderSize]); err != nil {
+			return EfficiencyReport{}, err
+		}
+		var layout BlockLayout
+		if err := layout.UnmarshalBinary(buf[blockHeaderSize:]); err != nil {
+			return EfficiencyReport{}, err
+		}
+
+		dataSize := layout.DataSize()
+		unpadded := blockHeaderSize + blockLayoutSize + dataSize
+		if unpadded > uint64(entry.BlockSize) {
+			return EfficiencyReport{}, fmt.Errorf("block %d: data size %d exceeds block size %d",
+				i, unpadded, entry.BlockSize)
+		}
+
+		block := BlockEfficiency{
+			Block:       BlockID(i),
+			Count:       entry.Count,
+			Encoding:    header.EncodingType,
+			RawSize:     uint64(entry.Count) * rawPairSize,
+			DataSize:    dataSize,
+			PaddingSize: uint64(entry.BlockSize) - unpadded,
+		}
+		report.Blocks[i] = block
+
+		report.Count += uint64(entry.Count)
+		report.RawSize += block.RawSize
+		report.DataSize += block.DataSize
+		report.BlockHeaderSize += blockHeaderSize + blockLayoutSize
+	}
+
+	// Whatever is not covered by a structure is padding, e.g. in front of the
+	// footer
+	structures := report.DataSize + report.BlockHeaderSize + report.FileHeaderSize +
+		report.BitmapSize + report.FooterSize
+	if structures > report.FileSize {
+		return EfficiencyReport{}, fmt.Errorf("file structures of %d bytes exceed file size %d",
+			structures, report.FileSize)
+	}
+	report.PaddingSize = report.FileSize - structures
+
+	return report, nil
+}
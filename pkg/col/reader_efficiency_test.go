@@ -0,0 +1,93 @@
+package col
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEfficiencyReport(t *testing.T) {
+	ids := make([]uint64, 5000)
+	values := make([]int64, len(ids))
+	for i := range ids {
+		ids[i] = uint64(i * 2)
+		values[i] = int64(i % 100)
+	}
+
+	write := func(t *testing.T, options ...WriterOption) *Reader {
+		var buf bytes.Buffer
+		writer, err := NewWriterToBuffer(&buf, append(options, WithBlockSize(8192))...)
+		require.NoError(t, err)
+		require.NoError(t, writeAllBlocks(writer, ids, values))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		reader, err := NewReaderFromBytes(buf.Bytes())
+		require.NoError(t, err)
+		return reader
+	}
+
+	t.Run("Page aligned", func(t *testing.T) {
+		reader := write(t, WithEncoding(EncodingVarIntBoth))
+		report, err := reader.EfficiencyReport()
+		require.NoError(t, err)
+
+		assert.Equal(t, uint64(reader.fileSize), report.FileSize)
+		assert.Equal(t, uint64(len(ids)), report.Count)
+		assert.Equal(t, uint64(len(ids)*16), report.RawSize)
+		require.Len(t, report.Blocks, int(reader.BlockCount()))
+		assert.Equal(t, uint64(len(report.Blocks)*(blockHeaderSize+blockLayoutSize)), report.BlockHeaderSize)
+		assert.Equal(t, uint64(headerSize), report.FileHeaderSize)
+		assert.Equal(t, reader.header.BitmapSize, report.BitmapSize)
+		assert.Equal(t, reader.footerMeta.FooterSize+footerMetaSize, report.FooterSize)
+
+		// Every byte is accounted for
+		assert.Equal(t, report.FileSize, report.DataSize+report.BlockHeaderSize+report.PaddingSize+
+			report.FileHeaderSize+report.BitmapSize+report.FooterSize)
+		assert.Equal(t, report.FileSize-report.DataSize, report.OverheadSize())
+
+		var dataSize, blockPadding uint64
+		for i, block := range report.Blocks {
+			assert.Equal(t, BlockID(i), block.Block)
+			assert.Equal(t, EncodingVarIntBoth, block.Encoding)
+			assert.Equal(t, uint64(block.Count)*16, block.RawSize)
+			assert.Less(t, block.Ratio(), 0.5, "varints of small numbers take far less than 16 bytes")
+			dataSize += block.DataSize
+			blockPadding += block.PaddingSize
+		}
+		assert.Equal(t, report.DataSize, dataSize)
+		assert.NotZero(t, blockPadding)
+		assert.GreaterOrEqual(t, report.PaddingSize, blockPadding)
+		assert.Less(t, report.DataRatio(), 0.5)
+		assert.Greater(t, report.Amplification(), report.DataRatio())
+	})
+
+	t.Run("Raw without padding", func(t *testing.T) {
+		reader := write(t, WithPadding(PaddingNone))
+		report, err := reader.EfficiencyReport()
+		require.NoError(t, err)
+
+		assert.Zero(t, report.PaddingSize)
+		assert.Equal(t, report.RawSize, report.DataSize)
+		for _, block := range report.Blocks {
+			assert.Zero(t, block.PaddingSize)
+			assert.Equal(t, 1.0, block.Ratio())
+		}
+	})
+
+	t.Run("Empty file", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer, err := NewWriterToBuffer(&buf)
+		require.NoError(t, err)
+		require.NoError(t, writer.FinalizeAndClose())
+		reader, err := NewReaderFromBytes(buf.Bytes())
+		require.NoError(t, err)
+
+		report, err := reader.EfficiencyReport()
+		require.NoError(t, err)
+		assert.Empty(t, report.Blocks)
+		assert.Zero(t, report.RawSize)
+		assert.Zero(t, report.Amplification())
+	})
+}
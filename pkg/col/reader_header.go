@@ -1,8 +1,12 @@
 package col
 
 import (
-	"encoding/binary"
+	"errors"
 	"fmt"
+	"math"
+	"os"
+
+	"vibe-lsm/pkg/col/format"
 )
 
 // readHeader reads the file header from the file
@@ -12,48 +16,9 @@ func (r *Reader) readHeader() error {
 	if err != nil {
 		return fmt.Errorf("failed to read header: %w", err)
 	}
-
-	// Extract fields from the buffer
-	offset := 0
-
-	// Read magic number
-	r.header.Magic = readBufferedUint64(headerBuf, offset)
-	offset += 8
-
-	// Read version
-	r.header.Version = readBufferedUint32(headerBuf, offset)
-	offset += 4
-
-	// Read column type
-	r.header.ColumnType = readBufferedUint32(headerBuf, offset)
-	offset += 4
-
-	// Read block count
-	r.header.BlockCount = readBufferedUint64(headerBuf, offset)
-	offset += 8
-
-	// Read block size target
-	r.header.BlockSizeTarget = readBufferedUint32(headerBuf, offset)
-	offset += 4
-
-	// Read compression type
-	r.header.CompressionType = readBufferedUint32(headerBuf, offset)
-	offset += 4
-
-	// Read encoding type
-	r.header.EncodingType = readBufferedUint32(headerBuf, offset)
-	offset += 4
-
-	// Read creation time
-	r.header.CreationTime = readBufferedUint64(headerBuf, offset)
-	offset += 8
-
-	// Read bitmap offset
-	r.header.BitmapOffset = readBufferedUint64(headerBuf, offset)
-	offset += 8
-
-	// Read bitmap size
-	r.header.BitmapSize = readBufferedUint64(headerBuf, offset)
+	if err := r.header.UnmarshalBinary(headerBuf); err != nil {
+		return err
+	}
 
 	// Validate header
 	if r.header.Magic != MagicNumber {
@@ -62,81 +27,273 @@ func (r *Reader) readHeader() error {
 	if r.header.Version != Version {
 		return fmt.Errorf("unsupported version: %d", r.header.Version)
 	}
+	switch r.header.ColumnType {
+	case DataTypeInt64, DataTypeUint64, DataTypeInt64List, DataTypeBool, DataTypeTimestampMillis:
+	default:
+		return fmt.Errorf("unsupported column type: %d", r.header.ColumnType)
+	}
 
 	return nil
 }
 
-// readFooter reads the footer from the file
+// readFooter reads the footer from the file, or from the latest checkpoint if
+// the file is still being written and the reader follows checkpoints
 func (r *Reader) readFooter() error {
+	err := r.readFileFooter()
+	if err != nil && r.followCheckpoints {
+		switch cpErr := r.readCheckpoint(); {
+		case cpErr == nil:
+			return nil
+		case !errors.Is(cpErr, os.ErrNotExist):
+			return cpErr
+		}
+	}
+	return err
+}
+
+// readFileFooter reads the footer from the end of the file
+func (r *Reader) readFileFooter() error {
 	// The last 24 bytes of the file are the footer metadata
-	if r.fileSize < 24 {
+	if r.fileSize < footerMetaSize {
 		return fmt.Errorf("file too small for footer: %d bytes", r.fileSize)
 	}
 
 	// Read footer metadata from the end of the file in one call
-	footerMetaOffset := r.fileSize - 24
-	footerMetaBuf, err := r.readBytesAt(footerMetaOffset, 24)
+	footerMetaOffset := r.fileSize - footerMetaSize
+	footerMetaBuf, err := r.readBytesAt(footerMetaOffset, footerMetaSize)
 	if err != nil {
 		return fmt.Errorf("failed to read footer metadata: %w", err)
 	}
-
-	// Extract fields from the buffer
-	r.footerMeta.FooterSize = readBufferedUint64(footerMetaBuf, 0)
-	r.footerMeta.Checksum = readBufferedUint64(footerMetaBuf, 8)
-	r.footerMeta.Magic = readBufferedUint64(footerMetaBuf, 16)
+	if err := r.footerMeta.UnmarshalBinary(footerMetaBuf); err != nil {
+		return err
+	}
 
 	// Validate footer metadata
 	if r.footerMeta.Magic != MagicNumber {
 		return fmt.Errorf("invalid footer magic number: 0x%X", r.footerMeta.Magic)
 	}
 
-	// Read the rest of the footer
-	footerStart := footerMetaOffset - int64(r.footerMeta.FooterSize)
-	if footerStart < 64 { // Footer cannot start before the header
+	// Read the rest of the footer in one call
+	footerSize, err := intFromFile(r.footerMeta.FooterSize)
+	if err != nil {
+		return fmt.Errorf("invalid footer size: %w", err)
+	}
+	footerStart := footerMetaOffset - int64(footerSize)
+	if footerStart < headerSize { // Footer cannot start before the header
 		return fmt.Errorf("invalid footer size: %d", r.footerMeta.FooterSize)
 	}
-
-	// Read block index count (first 4 bytes of footer)
-	blockIndexCountBuf, err := r.readBytesAt(footerStart, 4)
+	footerBuf, err := r.readBytesAt(footerStart, footerSize)
 	if err != nil {
-		return fmt.Errorf("failed to read block index count: %w", err)
+		return fmt.Errorf("failed to read footer: %w", err)
+	}
+
+	return r.applyFooter(footerBuf)
+}
+
+// applyFooter parses the block index and the footer sections
+func (r *Reader) applyFooter(footerBuf []byte) error {
+	var footer format.Footer
+	if err := footer.UnmarshalBinary(footerBuf); err != nil {
+		return fmt.Errorf("failed to parse footer: %w", err)
 	}
-	blockIndexCount := binary.LittleEndian.Uint32(blockIndexCountBuf)
+	if err := r.verifyFooterChecksum(footerBuf, footer); err != nil {
+		return err
+	}
+	r.blockIndex = footer.Entries
 
 	// Check if block count matches with header
-	if uint64(blockIndexCount) != r.header.BlockCount {
+	if uint64(len(r.blockIndex)) > r.header.BlockCount {
 		// Use the higher value to ensure we don't miss data
-		if uint64(blockIndexCount) > r.header.BlockCount {
-			r.header.BlockCount = uint64(blockIndexCount)
+		r.header.BlockCount = uint64(len(r.blockIndex))
+	}
+
+	if err := r.planIDRanges(); err != nil {
+		return err
+	}
+
+	return r.parseFooterSections(footer.Sections)
+}
+
+// parseFooterSections parses the optional sections that follow the block index.
+// Sections with unknown types are skipped.
+func (r *Reader) parseFooterSections(sections []format.FooterSection) error {
+	for _, section := range sections {
+		r.footerSections = append(r.footerSections, FooterSectionHeader{
+			Type: section.Type,
+			Size: uint32(len(section.Payload)),
+		})
+
+		payload := section.Payload
+		switch section.Type {
+		case FooterSectionBlockStats:
+			if err := r.parseBlockStatsSection(payload); err != nil {
+				return err
+			}
+		case FooterSectionFileStats:
+			if err := r.parseFileStatsSection(payload); err != nil {
+				return err
+			}
+		case FooterSectionUnsignedStats:
+			if err := r.parseUnsignedStatsSection(payload); err != nil {
+				return err
+			}
+		case FooterSectionLineage:
+			if err := r.parseLineageSection(payload); err != nil {
+				return err
+			}
+		case FooterSectionValueOrder:
+			if err := r.parseValueOrderSection(payload); err != nil {
+				return err
+			}
+		case FooterSectionPartitions:
+			if err := r.parsePartitionsSection(payload); err != nil {
+				return err
+			}
+		case FooterSectionDataFile:
+			if err := r.parseDataFileSection(payload); err != nil {
+				return err
+			}
 		}
 	}
 
-	// Calculate the size of the block index
-	// Each entry is 56 bytes (8+4+8+8+8+8+8+4)
-	blockIndexSize := int(blockIndexCount) * 56
+	return nil
+}
 
-	// Read the entire block index in one call
-	blockIndexBuf, err := r.readBytesAt(footerStart+4, blockIndexSize)
-	if err != nil {
-		return fmt.Errorf("failed to read block index: %w", err)
-	}
-
-	// Parse the block index entries
-	r.blockIndex = make([]FooterEntry, blockIndexCount)
-	for i := uint32(0); i < blockIndexCount; i++ {
-		entryOffset := i * 56
-
-		r.blockIndex[i] = FooterEntry{
-			BlockOffset: readBufferedUint64(blockIndexBuf, int(entryOffset)),
-			BlockSize:   readBufferedUint32(blockIndexBuf, int(entryOffset+8)),
-			MinID:       readBufferedUint64(blockIndexBuf, int(entryOffset+12)),
-			MaxID:       readBufferedUint64(blockIndexBuf, int(entryOffset+20)),
-			MinValue:    readBufferedUint64(blockIndexBuf, int(entryOffset+28)),
-			MaxValue:    readBufferedUint64(blockIndexBuf, int(entryOffset+36)),
-			Sum:         readBufferedUint64(blockIndexBuf, int(entryOffset+44)),
-			Count:       readBufferedUint32(blockIndexBuf, int(entryOffset+52)),
+// parseBlockStatsSection parses the extended per-block statistics footer section
+func (r *Reader) parseBlockStatsSection(payload []byte) error {
+	if len(payload) != len(r.blockIndex)*blockStatsEntrySize {
+		return fmt.Errorf("block statistics section size mismatch: expected=%d, actual=%d",
+			len(r.blockIndex)*blockStatsEntrySize, len(payload))
+	}
+
+	r.extendedStats = make([]ExtendedBlockStats, len(r.blockIndex))
+	for i := range r.extendedStats {
+		offset := i * blockStatsEntrySize
+		r.extendedStats[i] = ExtendedBlockStats{
+			SumSquares:    math.Float64frombits(readBufferedUint64(payload, offset)),
+			NegativeCount: readBufferedUint32(payload, offset+8),
+			ZeroCount:     readBufferedUint32(payload, offset+12),
+		}
+	}
+
+	return nil
+}
+
+// parseFileStatsSection parses the file-level statistics footer section
+func (r *Reader) parseFileStatsSection(payload []byte) error {
+	if len(payload) != fileStatsSize {
+		return fmt.Errorf("file statistics section size mismatch: expected=%d, actual=%d",
+			fileStatsSize, len(payload))
+	}
+
+	r.fileStats = &FileStats{
+		Count:    readBufferedUint64(payload, 0),
+		MinID:    readBufferedUint64(payload, 8),
+		MaxID:    readBufferedUint64(payload, 16),
+		MinValue: uint64ToInt64(readBufferedUint64(payload, 24)),
+		MaxValue: uint64ToInt64(readBufferedUint64(payload, 32)),
+		Sum:      uint64ToInt64(readBufferedUint64(payload, 40)),
+	}
+
+	return nil
+}
+
+// parseUnsignedStatsSection parses the per-block statistics footer section of
+// unsigned columns
+func (r *Reader) parseUnsignedStatsSection(payload []byte) error {
+	if len(payload) != len(r.blockIndex)*unsignedStatsEntrySize {
+		return fmt.Errorf("unsigned statistics section size mismatch: expected=%d, actual=%d",
+			len(r.blockIndex)*unsignedStatsEntrySize, len(payload))
+	}
+
+	r.unsignedStats = make([]unsignedBlockStats, len(r.blockIndex))
+	for i := range r.unsignedStats {
+		offset := i * unsignedStatsEntrySize
+		r.unsignedStats[i] = unsignedBlockStats{
+			Min:     readBufferedUint64(payload, offset),
+			Max:     readBufferedUint64(payload, offset+8),
+			Sum:     readBufferedUint64(payload, offset+16),
+			SumHigh: readBufferedUint64(payload, offset+24),
+		}
+	}
+
+	return nil
+}
+
+// parseLineageSection parses the footer section listing the source files
+func (r *Reader) parseLineageSection(payload []byte) error {
+	if len(payload) < uint32Size {
+		return fmt.Errorf("lineage section too small: %d bytes", len(payload))
+	}
+
+	count := readBufferedUint32(payload, 0)
+	offset := uint32Size
+	if uint64(count) > uint64((len(payload)-offset)/lineageEntryFixedSize) {
+		return fmt.Errorf("lineage of %d entries exceeds section of %d bytes", count, len(payload))
+	}
+	lineage := make([]LineageEntry, 0, count)
+	for i := 0; i < int(count); i++ {
+		if offset+lineageEntryFixedSize > len(payload) {
+			return fmt.Errorf("truncated lineage entry %d", i)
+		}
+		entry := LineageEntry{
+			CreationTime: readBufferedUint64(payload, offset),
+			MinID:        readBufferedUint64(payload, offset+8),
+			MaxID:        readBufferedUint64(payload, offset+16),
+			Count:        readBufferedUint64(payload, offset+24),
+		}
+		sourceSize := readBufferedUint32(payload, offset+32)
+		offset += lineageEntryFixedSize
+		if uint64(sourceSize) > uint64(len(payload)-offset) {
+			return fmt.Errorf("truncated source path of lineage entry %d", i)
+		}
+		sourceLen := int(sourceSize)
+		entry.Source = string(payload[offset : offset+sourceLen])
+		offset += sourceLen
+		lineage = append(lineage, entry)
+	}
+	if offset != len(payload) {
+		return fmt.Errorf("lineage section size mismatch: expected=%d, actual=%d", offset, len(payload))
+	}
+
+	r.lineage = lineage
+	return nil
+}
+
+// parseValueOrderSection parses the footer section with the value order of each block
+func (r *Reader) parseValueOrderSection(payload []byte) error {
+	valueOrders := make([][]uint32, len(r.blockIndex))
+	offset := 0
+	for i := range valueOrders {
+		if offset+uint32Size > len(payload) {
+			return fmt.Errorf("truncated value order of block %d", i)
 		}
+		count := readBufferedUint32(payload, offset)
+		offset += uint32Size
+		if count == 0 {
+			continue
+		}
+		if count != r.blockIndex[i].Count {
+			return fmt.Errorf("value order of block %d has %d positions, block has %d values",
+				i, count, r.blockIndex[i].Count)
+		}
+		if uint64(count) > uint64((len(payload)-offset)/uint32Size) {
+			return fmt.Errorf("truncated value order of block %d", i)
+		}
+		order := make([]uint32, count)
+		for j := range order {
+			order[j] = readBufferedUint32(payload, offset)
+			if order[j] >= count {
+				return fmt.Errorf("value order of block %d has position %d out of range", i, order[j])
+			}
+			offset += uint32Size
+		}
+		valueOrders[i] = order
+	}
+	if offset != len(payload) {
+		return fmt.Errorf("value order section size mismatch: expected=%d, actual=%d", offset, len(payload))
 	}
 
+	r.valueOrders = valueOrders
 	return nil
 }
@@ -9,14 +9,27 @@ import (
 // readBytesAt reads bytes at a specific offset
 func (r *Reader) readBytesAt(offset int64, size int) ([]byte, error) {
 	buf := make([]byte, size)
+	if err := r.readBytesInto(buf, offset); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// readBytesInto fills buf with the bytes at a specific offset
+func (r *Reader) readBytesInto(buf []byte, offset int64) error {
+	if r.rateLimiter != nil {
+		r.rateLimiter.wait(len(buf))
+	}
 	n, err := r.file.ReadAt(buf, offset)
 	if err != nil && err != io.EOF {
-		return nil, fmt.Errorf("failed to read bytes at offset %d: %w", offset, err)
+		return fmt.Errorf("failed to read bytes at offset %d: %w", offset, err)
 	}
-	if n < size && err != io.EOF {
-		return nil, fmt.Errorf("incomplete read at offset %d: got %d bytes, expected %d", offset, n, size)
+	if n < len(buf) && err != io.EOF {
+		return fmt.Errorf("incomplete read at offset %d: got %d bytes, expected %d", offset, n, len(buf))
 	}
-	return buf, nil
+	// A read cut short by the end of the file leaves the rest zeroed
+	clear(buf[n:])
+	return nil
 }
 
 // readUint64At reads a uint64 at a specific offset
@@ -0,0 +1,143 @@
+package col
+
+import (
+	"fmt"
+	"sort"
+)
+
+// idPlan describes how the ID ranges of the blocks cover the ID space. It is
+// built from the block index when the footer is read, so lookups by ID do not
+// have to assume that blocks were appended in ID order.
+type idPlan struct {
+	order       []BlockID // Non-empty blocks sorted by MinID
+	overlapping int       // Number of blocks whose ID range overlaps another block
+}
+
+// planIDRanges validates the ID range of every block and builds the plan used
+// by lookups by ID
+func (r *Reader) planIDRanges() error {
+	order := make([]BlockID, 0, len(r.blockIndex))
+	for i, entry := range r.blockIndex {
+		if entry.Count == 0 {
+			continue
+		}
+		if entry.MinID > entry.MaxID {
+			return fmt.Errorf("block %d has an invalid ID range: %d-%d", i, entry.MinID, entry.MaxID)
+		}
+		order = append(order, BlockID(i))
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return r.blockIndex[order[a]].MinID < r.blockIndex[order[b]].MinID
+	})
+
+	// Sweep the blocks by MinID; a block overlaps a previous one if it starts
+	// before the largest MaxID seen so far
+	overlaps := make([]bool, len(r.blockIndex))
+	overlapping := 0
+	mark := func(id BlockID) {
+		if !overlaps[id] {
+			overlaps[id] = true
+			overlapping++
+		}
+	}
+	var reach BlockID // Block with the largest MaxID seen so far
+	for i, id := range order {
+		if i > 0 && r.blockIndex[id].MinID <= r.blockIndex[reach].MaxID {
+			mark(reach)
+			mark(id)
+		}
+		if i == 0 || r.blockIndex[id].MaxID > r.blockIndex[reach].MaxID {
+			reach = id
+		}
+	}
+
+	r.plan = idPlan{order: order, overlapping: overlapping}
+	return nil
+}
+
+// OverlappingBlocks returns the number of blocks whose ID range overlaps the
+// ID range of another block. Lookups by ID check all blocks that may contain
+// an ID, so overlapping blocks are answered correctly but cost extra reads.
+func (r *Reader) OverlappingBlocks() (int, error) {
+	if err := r.ensureFooter(); err != nil {
+		return 0, err
+	}
+	return r.plan.overlapping, nil
+}
+
+// blocksInIDRange returns the blocks whose ID range intersects minID-maxID in
+// block order
+func (r *Reader) blocksInIDRange(minID, maxID uint64) []BlockID {
+	order := r.plan.order
+
+	// Blocks starting after maxID cannot contain any ID of the range
+	end := sort.Search(len(order), func(i int) bool {
+		return r.blockIndex[order[i]].MinID > maxID
+	})
+
+	var blocks []BlockID
+	if r.plan.overlapping == 0 {
+		// With disjoint ranges, the blocks ending before minID form a prefix
+		start := sort.Search(end, func(i int) bool {
+			return r.blockIndex[order[i]].MaxID >= minID
+		})
+		blocks = append(blocks, order[start:end]...)
+	} else {
+		for _, id := range order[:end] {
+			if r.blockIndex[id].MaxID >= minID {
+				blocks = append(blocks, id)
+			}
+		}
+	}
+
+	sort.Slice(blocks, func(a, b int) bool { return blocks[a] < blocks[b] })
+	return blocks
+}
+
+// Get returns the value stored for id and whether the file contains it. If
+// the ID is stored in several overlapping blocks, the value of the last of
+// these blocks is returned, as it was appended most recently; see GetResolved
+// for other policies.
+func (r *Reader) Get(id uint64) (int64, bool, error) {
+	return r.GetResolved(id, ResolveLastWrite)
+}
+
+// ScanIDRange calls fn in block order for every block that contains IDs in the
+// inclusive range minID-maxID, with the pairs of the block in that range.
+// Blocks with overlapping ID ranges are all visited, so an ID stored in several
+// blocks is passed once per block; ScanResolved merges them instead. The slices
+// are only valid until fn returns.
+func (r *Reader) ScanIDRange(minID, maxID uint64, fn ScanFunc) error {
+	if err := r.ensureFooter(); err != nil {
+		return err
+	}
+	if minID > maxID {
+		return nil
+	}
+
+	var idsBuf, rangeIDs []uint64
+	var valsBuf, rangeValues []int64
+	for _, block := range r.blocksInIDRange(minID, maxID) {
+		ids, values, err := r.ReadBlockInto(block, idsBuf, valsBuf)
+		if err != nil {
+			return err
+		}
+		idsBuf, valsBuf = ids, values
+
+		rangeIDs, rangeValues = rangeIDs[:0], rangeValues[:0]
+		for i, id := range ids {
+			if id >= minID && id <= maxID {
+				rangeIDs = append(rangeIDs, id)
+				rangeValues = append(rangeValues, values[i])
+			}
+		}
+		if len(rangeIDs) == 0 {
+			continue
+		}
+		if err := fn(block, rangeIDs, rangeValues); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package col
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeLookupFile writes a file with one block per element of blocks
+func writeLookupFile(t *testing.T, blocks [][]uint64) *Reader {
+	path := filepath.Join(t.TempDir(), "lookup.col")
+	writer, err := NewWriter(path)
+	require.NoError(t, err)
+	for _, ids := range blocks {
+		values := make([]int64, len(ids))
+		for i, id := range ids {
+			values[i] = int64(id) * 10
+		}
+		require.NoError(t, writer.WriteBlock(ids, values))
+	}
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := NewReader(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { reader.Close() })
+	return reader
+}
+
+// collectIDRange returns the blocks and IDs passed by ScanIDRange
+func collectIDRange(t *testing.T, r *Reader, minID, maxID uint64) ([]BlockID, []uint64) {
+	var blocks []BlockID
+	var ids []uint64
+	require.NoError(t, r.ScanIDRange(minID, maxID, func(block BlockID, blockIDs []uint64, values []int64) error {
+		blocks = append(blocks, block)
+		for i, id := range blockIDs {
+			assert.Equal(t, int64(id)*10, values[i])
+		}
+		ids = append(ids, blockIDs...)
+		return nil
+	}))
+	return blocks, ids
+}
+
+func TestIDLookup(t *testing.T) {
+	t.Run("Disjoint blocks", func(t *testing.T) {
+		reader := writeLookupFile(t, [][]uint64{{1, 2, 3}, {10, 12}, {20, 25, 30}})
+
+		overlapping, err := reader.OverlappingBlocks()
+		require.NoError(t, err)
+		assert.Zero(t, overlapping)
+		assert.NotContains(t, reader.DebugInfo(), "Warning")
+
+		value, ok, err := reader.Get(12)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, int64(120), value)
+
+		_, ok, err = reader.Get(11)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		blocks, ids := collectIDRange(t, reader, 3, 20)
+		assert.Equal(t, []BlockID{0, 1, 2}, blocks)
+		assert.Equal(t, []uint64{3, 10, 12, 20}, ids)
+	})
+
+	t.Run("Unsorted disjoint blocks", func(t *testing.T) {
+		reader := writeLookupFile(t, [][]uint64{{20, 25}, {1, 2}, {10, 12}})
+
+		overlapping, err := reader.OverlappingBlocks()
+		require.NoError(t, err)
+		assert.Zero(t, overlapping)
+
+		for _, id := range []uint64{1, 12, 25} {
+			value, ok, err := reader.Get(id)
+			require.NoError(t, err)
+			assert.True(t, ok, "id %d", id)
+			assert.Equal(t, int64(id)*10, value)
+		}
+
+		blocks, ids := collectIDRange(t, reader, 2, 20)
+		assert.Equal(t, []BlockID{0, 1, 2}, blocks)
+		assert.Equal(t, []uint64{20, 2, 10, 12}, ids)
+	})
+
+	t.Run("Overlapping blocks", func(t *testing.T) {
+		// Block 2 falls into the range of block 0, block 3 is disjoint
+		reader := writeLookupFile(t, [][]uint64{{1, 5, 9}, {20, 21}, {3, 4, 7}, {30}})
+
+		overlapping, err := reader.OverlappingBlocks()
+		require.NoError(t, err)
+		assert.Equal(t, 2, overlapping)
+		assert.Contains(t, reader.DebugInfo(), "Warning: 2 blocks have overlapping ID ranges")
+
+		for _, id := range []uint64{1, 3, 4, 5, 7, 9, 20, 30} {
+			value, ok, err := reader.Get(id)
+			require.NoError(t, err)
+			assert.True(t, ok, "id %d", id)
+			assert.Equal(t, int64(id)*10, value)
+		}
+		for _, id := range []uint64{0, 2, 6, 8, 10, 31} {
+			_, ok, err := reader.Get(id)
+			require.NoError(t, err)
+			assert.False(t, ok, "id %d", id)
+		}
+
+		blocks, ids := collectIDRange(t, reader, 4, 20)
+		assert.Equal(t, []BlockID{0, 1, 2}, blocks)
+		assert.Equal(t, []uint64{5, 9, 20, 4, 7}, ids)
+	})
+
+	t.Run("Duplicate IDs", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "duplicates.col")
+		writer, err := NewWriter(path)
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlock([]uint64{1, 2, 3}, []int64{10, 20, 30}))
+		require.NoError(t, writer.WriteBlock([]uint64{2}, []int64{-20}))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		reader, err := NewReader(path)
+		require.NoError(t, err)
+		defer reader.Close()
+
+		// The most recently appended block wins
+		value, ok, err := reader.Get(2)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, int64(-20), value)
+	})
+
+	t.Run("Empty range", func(t *testing.T) {
+		reader := writeLookupFile(t, [][]uint64{{1, 2, 3}})
+		blocks, _ := collectIDRange(t, reader, 3, 1)
+		assert.Empty(t, blocks)
+		blocks, _ = collectIDRange(t, reader, 4, 100)
+		assert.Empty(t, blocks)
+	})
+}
@@ -0,0 +1,89 @@
+package col
+
+import (
+	"fmt"
+)
+
+// BlockMeta returns the metadata of a block. The statistics come from the
+// footer; only the encoding and compression are read from the block header,
+// so no block data is decoded.
+func (r *Reader) BlockMeta(id BlockID) (BlockMeta, error) {
+	if err := r.ensureFooter(); err != nil {
+		return BlockMeta{}, err
+	}
+	if id >= BlockID(len(r.blockIndex)) {
+		return BlockMeta{}, fmt.Errorf("invalid block index: %d", id)
+	}
+
+	entry := r.blockIndex[id]
+
+	buf, err := r.readBytesAt(int64(entry.BlockOffset), blockHeaderSize)
+	if err != nil {
+		return BlockMeta{}, fmt.Errorf("failed to read block header: %w", err)
+	}
+	var header BlockHeader
+	if err := header.UnmarshalBinary(buf); err != nil {
+		return BlockMeta{}, err
+	}
+
+	return BlockMeta{
+		ID:          id,
+		Offset:      entry.BlockOffset,
+		Size:        entry.BlockSize,
+		MinID:       entry.MinID,
+		MaxID:       entry.MaxID,
+		MinValue:    uint64ToInt64(entry.MinValue),
+		MaxValue:    uint64ToInt64(entry.MaxValue),
+		Sum:         uint64ToInt64(entry.Sum),
+		Count:       entry.Count,
+		Encoding:    header.EncodingType,
+		Compression: header.CompressionType,
+		Partition:   r.blockPartition(int(id)),
+	}, nil
+}
+
+// Lineage returns the source files the file was produced from, e.g. by Concat or
+// Rewrite, in the order they were combined. It returns nil for files written
+// directly.
+func (r *Reader) Lineage() ([]LineageEntry, error) {
+	if err := r.ensureFooter(); err != nil {
+		return nil, err
+	}
+	if r.lineage == nil {
+		return nil, nil
+	}
+	return append([]LineageEntry(nil), r.lineage...), nil
+}
+
+// lineageEntry describes the file as a source of another file, named source
+func (r *Reader) lineageEntry(source string) LineageEntry {
+	entry := LineageEntry{
+		Source:       source,
+		CreationTime: r.header.CreationTime,
+	}
+	for i, block := range r.blockIndex {
+		if i == 0 || block.MinID < entry.MinID {
+			entry.MinID = block.MinID
+		}
+		if i == 0 || block.MaxID > entry.MaxID {
+			entry.MaxID = block.MaxID
+		}
+		entry.Count += uint64(block.Count)
+	}
+	return entry
+}
+
+// FooterInfo returns the location and layout of the footer
+func (r *Reader) FooterInfo() (FooterInfo, error) {
+	if err := r.ensureFooter(); err != nil {
+		return FooterInfo{}, err
+	}
+
+	return FooterInfo{
+		Offset:     uint64(r.fileSize) - footerMetaSize - r.footerMeta.FooterSize,
+		Size:       r.footerMeta.FooterSize,
+		Checksum:   r.footerMeta.Checksum,
+		BlockCount: uint64(len(r.blockIndex)),
+		Sections:   append([]FooterSectionHeader(nil), r.footerSections...),
+	}, nil
+}
@@ -0,0 +1,72 @@
+package col
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReaderMetadata(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-meta-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	filePath := filepath.Join(tempDir, "meta.col")
+	writer, err := NewWriter(filePath, WithEncoding(EncodingDeltaBoth))
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteBlock([]uint64{1, 5, 9}, []int64{-4, 2, 10}))
+	require.NoError(t, writer.WriteBlockWithOptions([]uint64{20, 30}, []int64{7, 8},
+		WithBlockEncoding(EncodingVarIntBoth)))
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := NewReader(filePath)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	first, err := reader.BlockMeta(0)
+	require.NoError(t, err)
+	assert.Equal(t, BlockMeta{
+		ID:          0,
+		Offset:      headerSize,
+		Size:        reader.blockIndex[0].BlockSize,
+		MinID:       1,
+		MaxID:       9,
+		MinValue:    -4,
+		MaxValue:    10,
+		Sum:         8,
+		Count:       3,
+		Encoding:    EncodingDeltaBoth,
+		Compression: CompressionNone,
+	}, first)
+
+	second, err := reader.BlockMeta(1)
+	require.NoError(t, err)
+	assert.Equal(t, BlockID(1), second.ID)
+	assert.Equal(t, first.Offset+uint64(first.Size), second.Offset)
+	assert.Equal(t, uint64(20), second.MinID)
+	assert.Equal(t, uint64(30), second.MaxID)
+	assert.Equal(t, int64(15), second.Sum)
+	assert.Equal(t, uint32(2), second.Count)
+	assert.Equal(t, EncodingVarIntBoth, second.Encoding)
+
+	_, err = reader.BlockMeta(2)
+	assert.Error(t, err)
+
+	footer, err := reader.FooterInfo()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), footer.BlockCount)
+	assert.Equal(t, uint64(reader.fileSize), footer.Offset+footer.Size+footerMetaSize)
+	assert.Equal(t, []FooterSectionHeader{
+		{Type: FooterSectionBlockStats, Size: 2 * blockStatsEntrySize},
+		{Type: FooterSectionFileStats, Size: fileStatsSize},
+		{Type: FooterSectionChecksum, Size: uint64Size},
+	}, footer.Sections)
+
+	// The footer starts with the block count
+	count, err := reader.readUint32At(int64(footer.Offset))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), count)
+}
@@ -0,0 +1,67 @@
+package col
+
+import (
+	"runtime"
+	"sort"
+)
+
+// Minimum number of candidate block bytes per worker in automatic parallelism.
+// Blocks read from disk benefit from overlapping I/O, so they are split into
+// smaller units of work than blocks of in-memory files, which only decode.
+const (
+	autoParallelMinBytesDisk   = 256 * 1024
+	autoParallelMinBytesMemory = 1024 * 1024
+)
+
+// autoParallelism decides the number of workers for an aggregation with
+// AutoParallel set. It returns 0 for a sequential aggregation, which is chosen
+// when the footer statistics answer the query or there is too little block
+// data to read for additional workers to pay off.
+func (r *Reader) autoParallelism(opts AggregateOptions) int {
+	filtered := opts.Filter != nil || opts.DenyFilter != nil
+	if !filtered && !opts.SkipPreCalculated {
+		return 0
+	}
+
+	blockIndices := r.aggregationBlocks(opts)
+	var candidateBytes uint64
+	for _, blockIdx := range blockIndices {
+		candidateBytes += uint64(r.blockIndex[blockIdx].BlockSize)
+	}
+
+	minBytes := uint64(autoParallelMinBytesDisk)
+	if _, inMemory := r.file.(bytesFile); inMemory {
+		minBytes = autoParallelMinBytesMemory
+	}
+
+	numWorkers := int(candidateBytes / minBytes)
+	if numWorkers > runtime.GOMAXPROCS(0) {
+		numWorkers = runtime.GOMAXPROCS(0)
+	}
+	if numWorkers > len(blockIndices) {
+		numWorkers = len(blockIndices)
+	}
+	if numWorkers <= 1 {
+		return 0
+	}
+	return numWorkers
+}
+
+// blockQueue returns a closed channel holding the block indices, which workers
+// take blocks from until it is drained. Workers that get blocks with few
+// matches simply take more, so the load balances even if the selected IDs are
+// concentrated in a few blocks. The largest blocks are queued first, so a
+// large block is not left for the end while the other workers are idle.
+func (r *Reader) blockQueue(blockIndices []uint64) <-chan uint64 {
+	ordered := append([]uint64(nil), blockIndices...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return r.blockIndex[ordered[i]].BlockSize > r.blockIndex[ordered[j]].BlockSize
+	})
+
+	queue := make(chan uint64, len(ordered))
+	for _, blockIdx := range ordered {
+		queue <- blockIdx
+	}
+	close(queue)
+	return queue
+}
@@ -0,0 +1,114 @@
+package col
+
+import (
+	"bytes"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaviate/sroar"
+)
+
+func TestBlockQueue(t *testing.T) {
+	reader := &Reader{}
+	for _, size := range []uint32{1000, 10, 10, 500, 10, 500} {
+		reader.blockIndex = append(reader.blockIndex, FooterEntry{BlockSize: size})
+	}
+
+	// Largest blocks first, equal sizes keep their order
+	var order []uint64
+	for blockIdx := range reader.blockQueue([]uint64{1, 2, 3, 4, 5, 0}) {
+		order = append(order, blockIdx)
+	}
+	assert.Equal(t, []uint64{0, 3, 5, 1, 2, 4}, order)
+
+	assert.Empty(t, reader.blockQueue(nil))
+}
+
+func TestAutoParallelism(t *testing.T) {
+	if runtime.GOMAXPROCS(0) < 4 {
+		defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	}
+
+	// About 3MB of raw pairs in 16KB blocks
+	const count = 200000
+	ids := make([]uint64, count)
+	values := make([]int64, count)
+	for i := range ids {
+		ids[i] = uint64(i)
+		values[i] = int64(i%1000 - 500)
+	}
+
+	var buf bytes.Buffer
+	writer, err := NewWriterToBuffer(&buf)
+	require.NoError(t, err)
+	require.NoError(t, writeAllBlocks(writer, ids, values))
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := NewReaderFromBytes(buf.Bytes())
+	require.NoError(t, err)
+	defer reader.Close()
+
+	path := filepath.Join(t.TempDir(), "auto.col")
+	fileWriter, err := NewWriter(path)
+	require.NoError(t, err)
+	require.NoError(t, writeAllBlocks(fileWriter, ids, values))
+	require.NoError(t, fileWriter.FinalizeAndClose())
+
+	fileReader, err := NewReader(path)
+	require.NoError(t, err)
+	defer fileReader.Close()
+
+	t.Run("Footer statistics suffice", func(t *testing.T) {
+		opts := DefaultAggregateOptions()
+		assert.Equal(t, 0, fileReader.autoParallelism(opts))
+	})
+
+	t.Run("Reading blocks", func(t *testing.T) {
+		opts := DefaultAggregateOptions()
+		opts.SkipPreCalculated = true
+
+		fromDisk := fileReader.autoParallelism(opts)
+		inMemory := reader.autoParallelism(opts)
+		assert.Greater(t, fromDisk, 1)
+		assert.LessOrEqual(t, fromDisk, runtime.GOMAXPROCS(0))
+		assert.Greater(t, inMemory, 1)
+		assert.LessOrEqual(t, inMemory, fromDisk)
+	})
+
+	t.Run("Few candidate blocks", func(t *testing.T) {
+		opts := DefaultAggregateOptions()
+		opts.Filter = sroar.NewBitmap()
+		opts.Filter.SetMany([]uint64{10, 20, 30})
+		assert.Equal(t, 0, fileReader.autoParallelism(opts))
+	})
+
+	t.Run("Same results", func(t *testing.T) {
+		filter := sroar.NewBitmap()
+		for id := uint64(0); id < count; id += 3 {
+			filter.Set(id)
+		}
+		for _, r := range []*Reader{reader, fileReader} {
+			for _, opts := range []AggregateOptions{
+				{SkipPreCalculated: true},
+				{Filter: filter},
+				{},
+			} {
+				expected := r.AggregateWithOptions(opts)
+				opts.AutoParallel = true
+				assert.Equal(t, expected, r.AggregateWithOptions(opts))
+			}
+		}
+
+		// All selected IDs are in the last blocks
+		skewed := sroar.NewBitmap()
+		for id := uint64(count - 2000); id < count; id++ {
+			skewed.Set(id)
+		}
+		expected := fileReader.AggregateWithOptions(AggregateOptions{Filter: skewed})
+		assert.Equal(t, uint64(2000), expected.Count)
+		assert.Equal(t, expected, fileReader.AggregateWithOptions(AggregateOptions{Filter: skewed, Parallel: 4}))
+	})
+}
@@ -0,0 +1,60 @@
+package col
+
+import (
+	"fmt"
+)
+
+// BlockRange describes the byte range of a block in the file together with
+// its ID range, so a block can be read independently of the rest of the file
+type BlockRange struct {
+	Block  BlockID
+	Offset uint64 // Offset of the block in the file
+	Size   uint64 // Size of the block in bytes, including padding
+	MinID  uint64
+	MaxID  uint64
+	Count  uint32
+}
+
+// BlockRanges returns the byte and ID ranges of all blocks in block order.
+// Query engines can assign the ranges to different workers, which either read
+// the blocks with their own Reader (ReadBlock with BlockRange.Block, which
+// only reads the block's byte range) or fetch the bytes themselves, e.g. from
+// object storage, and decode them with DecodeBlockRange.
+func (r *Reader) BlockRanges() ([]BlockRange, error) {
+	if err := r.ensureFooter(); err != nil {
+		return nil, err
+	}
+
+	ranges := make([]BlockRange, len(r.blockIndex))
+	for i, entry := range r.blockIndex {
+		ranges[i] = BlockRange{
+			Block:  BlockID(i),
+			Offset: entry.BlockOffset,
+			Size:   uint64(entry.BlockSize),
+			MinID:  entry.MinID,
+			MaxID:  entry.MaxID,
+			Count:  entry.Count,
+		}
+	}
+	return ranges, nil
+}
+
+// DecodeBlockRange decodes the ID-value pairs of a block from data, the bytes
+// of the file described by rng. dataType is the column type of the file, see
+// Reader.DataType. The values of DataTypeUint64 columns are returned as their
+// int64 bit patterns.
+func DecodeBlockRange(data []byte, rng BlockRange, dataType uint32) ([]uint64, []int64, error) {
+	if uint64(len(data)) != rng.Size {
+		return nil, nil, fmt.Errorf("block %d: expected %d bytes, got %d", rng.Block, rng.Size, len(data))
+	}
+	if len(data) < blockHeaderSize+blockLayoutSize {
+		return nil, nil, fmt.Errorf("block %d too small: %d bytes", rng.Block, len(data))
+	}
+
+	sections, err := parseBlockSections(data, rng.Block, int(rng.Count))
+	if err != nil {
+		return nil, nil, err
+	}
+	return decodeBlockDataInto(sections.idBytes, sections.valueBytes, sections.count,
+		sections.encodingType, dataType, nil, nil)
+}
@@ -0,0 +1,84 @@
+package col
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockRanges(t *testing.T) {
+	ids := make([]uint64, 3000)
+	values := make([]int64, len(ids))
+	for i := range ids {
+		ids[i] = uint64(i*5 + 1)
+		values[i] = int64(i) - 1000
+	}
+
+	path := filepath.Join(t.TempDir(), "ranges.col")
+	writer, err := NewWriter(path, WithEncoding(EncodingVarIntBoth), WithBlockSize(2048))
+	require.NoError(t, err)
+	require.NoError(t, writeAllBlocks(writer, ids, values))
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := NewReader(path)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	ranges, err := reader.BlockRanges()
+	require.NoError(t, err)
+	require.Len(t, ranges, int(reader.BlockCount()))
+	require.Greater(t, len(ranges), 2)
+	for i, rng := range ranges {
+		assert.Equal(t, BlockID(i), rng.Block)
+		if i > 0 {
+			assert.Equal(t, ranges[i-1].Offset+ranges[i-1].Size, rng.Offset, "blocks are contiguous")
+			assert.Greater(t, rng.MinID, ranges[i-1].MaxID)
+		}
+	}
+
+	// Workers fetch the byte ranges of their blocks independently and decode them
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	blockIDs := make([][]uint64, len(ranges))
+	blockValues := make([][]int64, len(ranges))
+	var wg sync.WaitGroup
+	for worker := 0; worker < 3; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := worker; i < len(ranges); i += 3 {
+				data := make([]byte, ranges[i].Size)
+				_, err := file.ReadAt(data, int64(ranges[i].Offset))
+				assert.NoError(t, err)
+				blockIDs[i], blockValues[i], err = DecodeBlockRange(data, ranges[i], reader.DataType())
+				assert.NoError(t, err)
+			}
+		}(worker)
+	}
+	wg.Wait()
+
+	var decodedIDs []uint64
+	var decodedValues []int64
+	for i, rng := range ranges {
+		require.Len(t, blockIDs[i], int(rng.Count))
+		assert.Equal(t, rng.MinID, blockIDs[i][0])
+		assert.Equal(t, rng.MaxID, blockIDs[i][len(blockIDs[i])-1])
+		decodedIDs = append(decodedIDs, blockIDs[i]...)
+		decodedValues = append(decodedValues, blockValues[i]...)
+	}
+	assert.Equal(t, ids, decodedIDs)
+	assert.Equal(t, values, decodedValues)
+
+	// The byte range must be complete
+	data := make([]byte, ranges[0].Size)
+	_, err = file.ReadAt(data, int64(ranges[0].Offset))
+	require.NoError(t, err)
+	_, _, err = DecodeBlockRange(data[:len(data)-1], ranges[0], reader.DataType())
+	assert.Error(t, err)
+}
@@ -0,0 +1,82 @@
+package col
+
+import (
+	"fmt"
+)
+
+// readRawBlock returns the encoded bytes of a block, starting with the block
+// header and excluding any trailing padding
+func (r *Reader) readRawBlock(blockIndex int) ([]byte, error) {
+	if err := r.ensureFooter(); err != nil {
+		return nil, err
+	}
+
+	if blockIndex < 0 || blockIndex >= len(r.blockIndex) {
+		return nil, fmt.Errorf("invalid block index: %d", blockIndex)
+	}
+
+	entry := r.blockIndex[blockIndex]
+	blockSize, err := intFromFile(uint64(entry.BlockSize))
+	if err != nil {
+		return nil, fmt.Errorf("block %d: %w", blockIndex, err)
+	}
+	data, err := r.readBytesAt(int64(entry.BlockOffset), blockSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read block data: %w", err)
+	}
+	if len(data) < blockHeaderSize+blockLayoutSize {
+		return nil, fmt.Errorf("block %d too small: %d bytes", blockIndex, len(data))
+	}
+
+	// The layout section tells us where the data ends, the rest is padding
+	var layout BlockLayout
+	if err := layout.UnmarshalBinary(data[blockHeaderSize : blockHeaderSize+blockLayoutSize]); err != nil {
+		return nil, err
+	}
+	dataEnd := blockHeaderSize + blockLayoutSize + layout.DataSize()
+	if dataEnd > uint64(len(data)) {
+		return nil, fmt.Errorf("block %d sections exceed block size: end=%d, size=%d",
+			blockIndex, dataEnd, len(data))
+	}
+
+	return data[:dataEnd], nil
+}
+
+// blockStats returns the statistics of a block as recorded in the footer
+func (r *Reader) blockStats(blockIndex int) BlockStats {
+	entry := r.blockIndex[blockIndex]
+	stats := BlockStats{
+		MinID:    entry.MinID,
+		MaxID:    entry.MaxID,
+		MinValue: uint64ToInt64(entry.MinValue),
+		MaxValue: uint64ToInt64(entry.MaxValue),
+		Sum:      uint64ToInt64(entry.Sum),
+		Count:    entry.Count,
+	}
+	if r.extendedStats != nil {
+		ext := r.extendedStats[blockIndex]
+		stats.SumSquares = ext.SumSquares
+		stats.NegativeCount = ext.NegativeCount
+		stats.ZeroCount = ext.ZeroCount
+	}
+	if r.unsignedStats != nil {
+		stats.unsigned = r.unsignedStats[blockIndex]
+	}
+	stats.partition = r.blockPartition(blockIndex)
+	return stats
+}
+
+// completeBlockStats returns the statistics of a block like blockStats. Files
+// without the block statistics section need their values decoded to recompute
+// the extended statistics.
+func (r *Reader) completeBlockStats(blockIndex int) (BlockStats, error) {
+	stats := r.blockStats(blockIndex)
+	if r.extendedStats == nil {
+		values, err := r.ReadBlockValues(BlockID(blockIndex))
+		if err != nil {
+			return BlockStats{}, fmt.Errorf("failed to decode block %d: %w", blockIndex, err)
+		}
+		stats.SumSquares, stats.NegativeCount, stats.ZeroCount = calculateExtendedStatsInt64(values)
+	}
+	return stats, nil
+}
@@ -0,0 +1,177 @@
+package col
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrDuplicateID is returned by lookups with ResolveError if an ID is stored
+// more than once
+var ErrDuplicateID = errors.New("duplicate ID")
+
+// ResolvePolicy determines the value of an ID that is stored more than once,
+// in one or several blocks of a file or in several files
+type ResolvePolicy int
+
+const (
+	// ResolveLastWrite uses the value written last: the last pair in block
+	// order, or the pair in the newest file
+	ResolveLastWrite ResolvePolicy = iota
+	// ResolveError fails with an error wrapping ErrDuplicateID
+	ResolveError
+	// ResolveSum uses the sum of all values stored for the ID
+	ResolveSum
+)
+
+// Resolve combines resolved, the value resolved so far for id, with value, a
+// value written after it
+func (p ResolvePolicy) Resolve(id uint64, resolved, value int64) (int64, error) {
+	switch p {
+	case ResolveError:
+		return 0, fmt.Errorf("%w: %d", ErrDuplicateID, id)
+	case ResolveSum:
+		return resolved + value, nil
+	default:
+		return value, nil
+	}
+}
+
+// GetResolved returns the value stored for id like Get, resolving an ID that
+// is stored more than once with policy. Get uses ResolveLastWrite.
+func (r *Reader) GetResolved(id uint64, policy ResolvePolicy) (int64, bool, error) {
+	if err := r.ensureFooter(); err != nil {
+		return 0, false, err
+	}
+
+	blocks := r.blocksInIDRange(id, id)
+	if policy == ResolveLastWrite {
+		// The last pair in block order wins, so the search starts at the end
+		for i := len(blocks) - 1; i >= 0; i-- {
+			ids, values, err := r.ReadBlock(blocks[i])
+			if err != nil {
+				return 0, false, err
+			}
+			for j := len(ids) - 1; j >= 0; j-- {
+				if ids[j] == id {
+					return values[j], true, nil
+				}
+			}
+		}
+		return 0, false, nil
+	}
+
+	var resolved int64
+	found := false
+	for _, block := range blocks {
+		ids, values, err := r.ReadBlock(block)
+		if err != nil {
+			return 0, false, err
+		}
+		for j, blockID := range ids {
+			if blockID != id {
+				continue
+			}
+			if !found {
+				resolved, found = values[j], true
+				continue
+			}
+			if resolved, err = policy.Resolve(id, resolved, values[j]); err != nil {
+				return 0, false, err
+			}
+		}
+	}
+	return resolved, found, nil
+}
+
+// ResolvedFunc is called with pairs of unique IDs in increasing order. The
+// slices are only valid until the function returns. Returning an error stops
+// the scan.
+type ResolvedFunc func(ids []uint64, values []int64) error
+
+// ScanResolved calls fn with the pairs in the inclusive ID range minID-maxID,
+// sorted by ID, with every ID stored more than once resolved by policy. Blocks
+// with disjoint ID ranges are passed to fn one at a time; blocks whose ID ranges
+// overlap are merged and passed together.
+func (r *Reader) ScanResolved(minID, maxID uint64, policy ResolvePolicy, fn ResolvedFunc) error {
+	if err := r.ensureFooter(); err != nil {
+		return err
+	}
+	if minID > maxID {
+		return nil
+	}
+
+	blocks := r.blocksInIDRange(minID, maxID)
+	sort.SliceStable(blocks, func(a, b int) bool {
+		return r.blockIndex[blocks[a]].MinID < r.blockIndex[blocks[b]].MinID
+	})
+
+	// Group the blocks into runs of overlapping ID ranges
+	for start := 0; start < len(blocks); {
+		end, reach := start+1, r.blockIndex[blocks[start]].MaxID
+		for end < len(blocks) && r.blockIndex[blocks[end]].MinID <= reach {
+			if maxID := r.blockIndex[blocks[end]].MaxID; maxID > reach {
+				reach = maxID
+			}
+			end++
+		}
+
+		group := append([]BlockID(nil), blocks[start:end]...)
+		sort.Slice(group, func(a, b int) bool { return group[a] < group[b] })
+		ids, values, err := r.resolveBlocks(group, minID, maxID, policy)
+		if err != nil {
+			return err
+		}
+		if len(ids) > 0 {
+			if err := fn(ids, values); err != nil {
+				return err
+			}
+		}
+		start = end
+	}
+
+	return nil
+}
+
+// resolveBlocks returns the pairs of the blocks in the ID range minID-maxID,
+// sorted by ID and resolved by policy. The blocks must be in block order.
+func (r *Reader) resolveBlocks(blocks []BlockID, minID, maxID uint64, policy ResolvePolicy) ([]uint64, []int64, error) {
+	var pairIDs []uint64
+	var pairValues []int64
+	for _, block := range blocks {
+		ids, values, err := r.ReadBlock(block)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i, id := range ids {
+			if id >= minID && id <= maxID {
+				pairIDs = append(pairIDs, id)
+				pairValues = append(pairValues, values[i])
+			}
+		}
+	}
+
+	// A stable sort keeps the pairs of an ID in the order they were written
+	order := make([]int, len(pairIDs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return pairIDs[order[a]] < pairIDs[order[b]] })
+
+	ids := make([]uint64, 0, len(order))
+	values := make([]int64, 0, len(order))
+	for _, i := range order {
+		id, value := pairIDs[i], pairValues[i]
+		if n := len(ids); n > 0 && ids[n-1] == id {
+			resolved, err := policy.Resolve(id, values[n-1], value)
+			if err != nil {
+				return nil, nil, err
+			}
+			values[n-1] = resolved
+			continue
+		}
+		ids = append(ids, id)
+		values = append(values, value)
+	}
+	return ids, values, nil
+}
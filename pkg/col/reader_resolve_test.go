@@ -0,0 +1,93 @@
+package col
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolvePolicies(t *testing.T) {
+	// ID 2 is stored in the first and third block, ID 9 twice in the last block
+	var buf bytes.Buffer
+	writer, err := NewWriterToBuffer(&buf)
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteBlock([]uint64{1, 2, 3}, []int64{10, 20, 30}))
+	require.NoError(t, writer.WriteBlock([]uint64{5, 6}, []int64{50, 60}))
+	require.NoError(t, writer.WriteBlock([]uint64{2, 4}, []int64{200, 40}))
+	require.NoError(t, writer.WriteBlock([]uint64{8, 9, 9}, []int64{80, 90, 91}))
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := NewReaderFromBytes(buf.Bytes())
+	require.NoError(t, err)
+	defer reader.Close()
+
+	t.Run("Get", func(t *testing.T) {
+		value, found, err := reader.GetResolved(2, ResolveLastWrite)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, int64(200), value)
+
+		value, found, err = reader.GetResolved(2, ResolveSum)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, int64(220), value)
+
+		value, _, err = reader.GetResolved(9, ResolveSum)
+		require.NoError(t, err)
+		assert.Equal(t, int64(181), value)
+
+		_, _, err = reader.GetResolved(2, ResolveError)
+		assert.True(t, errors.Is(err, ErrDuplicateID))
+
+		// Unique IDs resolve to their value under every policy
+		for _, policy := range []ResolvePolicy{ResolveLastWrite, ResolveError, ResolveSum} {
+			value, found, err := reader.GetResolved(5, policy)
+			require.NoError(t, err)
+			assert.True(t, found)
+			assert.Equal(t, int64(50), value)
+
+			_, found, err = reader.GetResolved(7, policy)
+			require.NoError(t, err)
+			assert.False(t, found)
+		}
+	})
+
+	// scan collects the resolved pairs and the number of calls of fn
+	scan := func(t *testing.T, minID, maxID uint64, policy ResolvePolicy) ([]uint64, []int64, int, error) {
+		var ids []uint64
+		var values []int64
+		calls := 0
+		err := reader.ScanResolved(minID, maxID, policy, func(blockIDs []uint64, blockValues []int64) error {
+			ids = append(ids, blockIDs...)
+			values = append(values, blockValues...)
+			calls++
+			return nil
+		})
+		return ids, values, calls, err
+	}
+
+	t.Run("Scan", func(t *testing.T) {
+		ids, values, calls, err := scan(t, 0, 100, ResolveLastWrite)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{1, 2, 3, 4, 5, 6, 8, 9}, ids)
+		assert.Equal(t, []int64{10, 200, 30, 40, 50, 60, 80, 91}, values)
+		// The first and third block overlap and are passed together
+		assert.Equal(t, 3, calls)
+
+		ids, values, _, err = scan(t, 2, 8, ResolveSum)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{2, 3, 4, 5, 6, 8}, ids)
+		assert.Equal(t, []int64{220, 30, 40, 50, 60, 80}, values)
+
+		_, _, _, err = scan(t, 0, 100, ResolveError)
+		assert.True(t, errors.Is(err, ErrDuplicateID))
+
+		// Ranges without duplicates do not fail
+		ids, _, _, err = scan(t, 5, 8, ResolveError)
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{5, 6, 8}, ids)
+	})
+}
@@ -0,0 +1,82 @@
+package col
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// Sample returns a uniform random sample of n ID-value pairs, drawn without
+// replacement, in file order. The per-block counts from the footer determine
+// which pairs are picked, so only blocks contributing to the sample are read.
+// If the file holds at most n pairs, all pairs are returned. The same seed
+// yields the same sample.
+func (r *Reader) Sample(n int, seed int64) ([]uint64, []int64, error) {
+	if n < 0 {
+		return nil, nil, fmt.Errorf("sample size must not be negative, got %d", n)
+	}
+	if err := r.ensureFooter(); err != nil {
+		return nil, nil, err
+	}
+
+	var total uint64
+	for _, entry := range r.blockIndex {
+		total += uint64(entry.Count)
+	}
+
+	// Pick n distinct positions out of all pairs using Floyd's algorithm
+	positions := make([]uint64, 0, minUint64(uint64(n), total))
+	if uint64(n) >= total {
+		for pos := uint64(0); pos < total; pos++ {
+			positions = append(positions, pos)
+		}
+	} else {
+		rng := rand.New(rand.NewSource(seed))
+		picked := make(map[uint64]struct{}, n)
+		for j := total - uint64(n); j < total; j++ {
+			pos := uint64(rng.Int63n(int64(j + 1)))
+			if _, ok := picked[pos]; ok {
+				pos = j
+			}
+			picked[pos] = struct{}{}
+			positions = append(positions, pos)
+		}
+		sort.Slice(positions, func(i, j int) bool { return positions[i] < positions[j] })
+	}
+
+	ids := make([]uint64, 0, len(positions))
+	values := make([]int64, 0, len(positions))
+
+	// Walk the blocks, reading only those that contain picked positions
+	var blockStart uint64
+	next := 0
+	for blockIdx, entry := range r.blockIndex {
+		blockEnd := blockStart + uint64(entry.Count)
+		if next < len(positions) && positions[next] < blockEnd {
+			blockIDs, blockValues, err := r.ReadBlock(BlockID(blockIdx))
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to read block %d: %w", blockIdx, err)
+			}
+			for ; next < len(positions) && positions[next] < blockEnd; next++ {
+				offset := positions[next] - blockStart
+				if offset >= uint64(len(blockIDs)) {
+					return nil, nil, fmt.Errorf("block %d holds %d pairs, but the footer records %d",
+						blockIdx, len(blockIDs), entry.Count)
+				}
+				ids = append(ids, blockIDs[offset])
+				values = append(values, blockValues[offset])
+			}
+		}
+		blockStart = blockEnd
+	}
+
+	return ids, values, nil
+}
+
+// minUint64 returns the smaller of two uint64 values
+func minUint64(a, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,83 @@
+package col
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReaderSample(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-sample-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	// Blocks of very different sizes, values equal to the ID times ten
+	filePath := filepath.Join(tempDir, "sample.col")
+	writer, err := NewWriter(filePath, WithEncoding(EncodingVarIntBoth))
+	require.NoError(t, err)
+	id := uint64(0)
+	for _, size := range []int{1000, 10, 3000, 1} {
+		ids := make([]uint64, size)
+		values := make([]int64, size)
+		for i := range ids {
+			id++
+			ids[i] = id
+			values[i] = int64(id) * 10
+		}
+		require.NoError(t, writer.WriteBlock(ids, values))
+	}
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := NewReader(filePath)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	ids, values, err := reader.Sample(400, 42)
+	require.NoError(t, err)
+	require.Len(t, ids, 400)
+	require.Len(t, values, 400)
+	for i := range ids {
+		assert.Equal(t, int64(ids[i])*10, values[i], "value does not belong to ID %d", ids[i])
+		if i > 0 {
+			assert.Less(t, ids[i-1], ids[i], "sample should be distinct and in file order")
+		}
+	}
+
+	// Allocation is proportional to the block sizes: 1000 of 4011 pairs are in
+	// the first block and 3000 in the third, so expect roughly 100 and 300
+	var first, third int
+	for _, id := range ids {
+		switch {
+		case id <= 1000:
+			first++
+		case id > 1010 && id <= 4010:
+			third++
+		}
+	}
+	assert.InDelta(t, 100, first, 30)
+	assert.InDelta(t, 300, third, 30)
+
+	// The same seed yields the same sample, a different one does not
+	sameIDs, _, err := reader.Sample(400, 42)
+	require.NoError(t, err)
+	assert.Equal(t, ids, sameIDs)
+	otherIDs, _, err := reader.Sample(400, 7)
+	require.NoError(t, err)
+	assert.NotEqual(t, ids, otherIDs)
+
+	// Asking for more pairs than the file holds returns all of them
+	allIDs, allValues, err := reader.Sample(5000, 1)
+	require.NoError(t, err)
+	assert.Len(t, allIDs, 4011)
+	assert.Len(t, allValues, 4011)
+
+	emptyIDs, _, err := reader.Sample(0, 1)
+	require.NoError(t, err)
+	assert.Empty(t, emptyIDs)
+
+	_, _, err = reader.Sample(-1, 1)
+	assert.Error(t, err)
+}
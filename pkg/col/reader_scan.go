@@ -0,0 +1,189 @@
+package col
+
+import (
+	"fmt"
+	"sync"
+)
+
+// scannedBlock is a decoded block handed from the read-ahead goroutine to the scanner
+type scannedBlock struct {
+	id     BlockID
+	ids    []uint64
+	values []int64
+	pooled *PooledBlock // Set if the pairs are held in pooled buffers
+	err    error
+}
+
+// release returns the buffers of a recycled block to the pool
+func (b scannedBlock) release() {
+	if b.pooled != nil {
+		b.pooled.Release()
+	}
+}
+
+// BlockScanner iterates over all blocks of a file in order. With a read-ahead
+// depth greater than zero, the following blocks are read and decoded in a
+// background goroutine while the caller processes the current one.
+//
+// Typical usage:
+//
+//	scanner := reader.ScanBlocks(4)
+//	defer scanner.Close()
+//	for scanner.Next() {
+//		id, ids, values := scanner.Block()
+//		...
+//	}
+//	if err := scanner.Err(); err != nil {
+//		...
+//	}
+type BlockScanner struct {
+	reader        *Reader
+	restoreAdvice func() // Restores the normal access pattern on Close
+	recycle       bool   // Decode into pooled buffers, released on the next block
+
+	// Synchronous scanning
+	nextID BlockID
+
+	// Read-ahead scanning
+	blocks    chan scannedBlock
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+
+	current scannedBlock
+	err     error
+}
+
+// ScanBlocks returns a scanner over all blocks of the file. readAhead is the
+// number of blocks decoded ahead of the caller; 0 reads each block on demand.
+// The scanner must be closed to stop the read-ahead goroutine and to restore
+// the normal access pattern if page cache advice is enabled.
+func (r *Reader) ScanBlocks(readAhead int) *BlockScanner {
+	return r.newBlockScanner(readAhead, false)
+}
+
+// ScanBlocksRecycled returns a scanner like ScanBlocks that decodes the blocks
+// into pooled buffers. The buffers of a block are reused once the scanner
+// advances, so the slices returned by Block are only valid until the next
+// call to Next or Close. Long scans then keep reusing a few buffers instead
+// of allocating two slices per block.
+func (r *Reader) ScanBlocksRecycled(readAhead int) *BlockScanner {
+	return r.newBlockScanner(readAhead, true)
+}
+
+// newBlockScanner creates a scanner and starts its read-ahead goroutine
+func (r *Reader) newBlockScanner(readAhead int, recycle bool) *BlockScanner {
+	s := &BlockScanner{reader: r, restoreAdvice: r.adviseFor(AdviceSequential), recycle: recycle}
+	if readAhead <= 0 {
+		return s
+	}
+
+	s.blocks = make(chan scannedBlock, readAhead)
+	s.done = make(chan struct{})
+	s.wg.Add(1)
+	go s.readAhead()
+
+	return s
+}
+
+// readAhead decodes blocks in order and sends them to the scanner until all
+// blocks are read, an error occurs or the scanner is closed
+func (s *BlockScanner) readAhead() {
+	defer s.wg.Done()
+	defer close(s.blocks)
+
+	blockCount := BlockID(s.reader.BlockCount())
+	for id := BlockID(0); id < blockCount; id++ {
+		block := s.readBlock(id)
+
+		select {
+		case s.blocks <- block:
+		case <-s.done:
+			return
+		}
+
+		if block.err != nil {
+			return
+		}
+	}
+}
+
+// readBlock reads and decodes a single block
+func (s *BlockScanner) readBlock(id BlockID) scannedBlock {
+	if s.recycle {
+		block, err := s.reader.ReadBlockPooled(id)
+		if err != nil {
+			return scannedBlock{id: id, err: fmt.Errorf("failed to read block %d: %w", id, err)}
+		}
+		return scannedBlock{id: id, ids: block.IDs, values: block.Values, pooled: block}
+	}
+
+	ids, values, err := s.reader.ReadBlock(id)
+	if err != nil {
+		err = fmt.Errorf("failed to read block %d: %w", id, err)
+	}
+	return scannedBlock{id: id, ids: ids, values: values, err: err}
+}
+
+// Next advances to the next block and reports whether one is available. It
+// returns false when all blocks have been scanned or an error occurred.
+func (s *BlockScanner) Next() bool {
+	if s.err != nil {
+		return false
+	}
+	s.current.release()
+	s.current.pooled = nil
+
+	var block scannedBlock
+	if s.blocks == nil {
+		if s.nextID >= BlockID(s.reader.BlockCount()) {
+			return false
+		}
+		block = s.readBlock(s.nextID)
+		s.nextID++
+	} else {
+		var ok bool
+		block, ok = <-s.blocks
+		if !ok {
+			return false
+		}
+	}
+
+	if block.err != nil {
+		s.err = block.err
+		s.current = scannedBlock{}
+		return false
+	}
+
+	s.current = block
+	return true
+}
+
+// Block returns the ID and the pairs of the current block
+func (s *BlockScanner) Block() (BlockID, []uint64, []int64) {
+	return s.current.id, s.current.ids, s.current.values
+}
+
+// Err returns the first error encountered while scanning
+func (s *BlockScanner) Err() error {
+	return s.err
+}
+
+// Close stops the read-ahead goroutine and waits for it to exit. It is safe to
+// call Close multiple times.
+func (s *BlockScanner) Close() error {
+	s.closeOnce.Do(func() {
+		if s.blocks != nil {
+			close(s.done)
+			s.wg.Wait()
+			// Release the blocks read ahead but never consumed
+			for block := range s.blocks {
+				block.release()
+			}
+		}
+		s.current.release()
+		s.current.pooled = nil
+		s.restoreAdvice()
+	})
+	return nil
+}
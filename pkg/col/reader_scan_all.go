@@ -0,0 +1,179 @@
+package col
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// ScanFunc is called for every block of a full scan. The slices are only valid
+// until the function returns; they are reused for later blocks. Returning an
+// error stops the scan.
+type ScanFunc func(id BlockID, ids []uint64, values []int64) error
+
+// scanBuffer holds the decode buffers of a block in flight
+type scanBuffer struct {
+	ids    []uint64
+	values []int64
+}
+
+// scanJob is a block handed to a decode worker
+type scanJob struct {
+	id  BlockID
+	buf *scanBuffer
+}
+
+// scanResult is a decoded block handed back from a decode worker
+type scanResult struct {
+	id  BlockID
+	buf *scanBuffer
+	err error
+}
+
+// ScanAll decodes all blocks with parallel workers and calls fn for each block
+// in block order. If parallel is 0 or 1, blocks are decoded sequentially; if it
+// is negative, GOMAXPROCS workers are used. fn is never called concurrently.
+// The scan stops at the first read error or error returned by fn, after fn has
+// been called for all preceding blocks.
+func (r *Reader) ScanAll(parallel int, fn ScanFunc) error {
+	return r.scanAll(parallel, true, fn)
+}
+
+// ScanAllUnordered works like ScanAll, but calls fn as soon as a block is
+// decoded instead of in block order, so a slow block does not hold back the
+// others. fn is never called concurrently.
+func (r *Reader) ScanAllUnordered(parallel int, fn ScanFunc) error {
+	return r.scanAll(parallel, false, fn)
+}
+
+// scanAll implements ScanAll and ScanAllUnordered
+func (r *Reader) scanAll(parallel int, ordered bool, fn ScanFunc) error {
+	if err := r.ensureFooter(); err != nil {
+		return err
+	}
+	defer r.adviseFor(AdviceSequential)()
+	blockCount := BlockID(len(r.blockIndex))
+
+	if parallel < 0 {
+		parallel = runtime.GOMAXPROCS(0)
+	}
+	if BlockID(parallel) > blockCount {
+		parallel = int(blockCount)
+	}
+
+	// Sequential scan, reusing the decode buffers across blocks
+	if parallel <= 1 {
+		var buf scanBuffer
+		for id := BlockID(0); id < blockCount; id++ {
+			ids, values, err := r.ReadBlockInto(id, buf.ids, buf.values)
+			if err != nil {
+				return fmt.Errorf("failed to read block %d: %w", id, err)
+			}
+			if err := fn(id, ids, values); err != nil {
+				return err
+			}
+			buf.ids, buf.values = ids, values
+		}
+		return nil
+	}
+
+	// The number of buffers bounds the blocks in flight, including decoded
+	// blocks waiting for their turn in ordered mode
+	free := make(chan *scanBuffer, 2*parallel)
+	for i := 0; i < cap(free); i++ {
+		free <- &scanBuffer{}
+	}
+	jobs := make(chan scanJob)
+	results := make(chan scanResult, cap(free))
+	done := make(chan struct{})
+
+	// Dispatch blocks in order, each with a free buffer
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+		for id := BlockID(0); id < blockCount; id++ {
+			var buf *scanBuffer
+			select {
+			case buf = <-free:
+			case <-done:
+				return
+			}
+			select {
+			case jobs <- scanJob{id: id, buf: buf}:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	// Decode blocks concurrently
+	var workers sync.WaitGroup
+	for w := 0; w < parallel; w++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for job := range jobs {
+				ids, values, err := r.ReadBlockInto(job.id, job.buf.ids, job.buf.values)
+				if err != nil {
+					err = fmt.Errorf("failed to read block %d: %w", job.id, err)
+				}
+				job.buf.ids, job.buf.values = ids, values
+				// Never blocks, there are at most cap(free) results in flight
+				results <- scanResult{id: job.id, buf: job.buf, err: err}
+			}
+		}()
+	}
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	// deliver hands a decoded block to fn and recycles its buffer
+	deliver := func(res scanResult) error {
+		if res.err != nil {
+			return res.err
+		}
+		err := fn(res.id, res.buf.ids, res.buf.values)
+		free <- res.buf
+		return err
+	}
+
+	var scanErr error
+	pending := make(map[BlockID]scanResult)
+	next := BlockID(0)
+	for res := range results {
+		if !ordered {
+			if scanErr = deliver(res); scanErr != nil {
+				break
+			}
+			continue
+		}
+
+		// Deliver all blocks that are next in order
+		pending[res.id] = res
+		for scanErr == nil {
+			p, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			scanErr = deliver(p)
+		}
+		if scanErr != nil {
+			break
+		}
+	}
+
+	if scanErr != nil {
+		// Stop the dispatcher and let the workers drain
+		close(done)
+		for range results {
+		}
+	}
+	wg.Wait()
+
+	return scanErr
+}
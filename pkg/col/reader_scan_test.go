@@ -0,0 +1,209 @@
+package col
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanBlocks(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-scan-blocks-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	filePath := filepath.Join(tempDir, "scan.col")
+	writer, err := NewWriter(filePath, WithEncoding(EncodingVarIntBoth))
+	require.NoError(t, err)
+
+	const numBlocks = 20
+	var expectedIDs []uint64
+	var expectedValues []int64
+	for block := 0; block < numBlocks; block++ {
+		ids := make([]uint64, 50)
+		values := make([]int64, 50)
+		for i := range ids {
+			ids[i] = uint64(block*100 + i)
+			values[i] = int64(block*100 - i)
+		}
+		require.NoError(t, writer.WriteBlock(ids, values))
+		expectedIDs = append(expectedIDs, ids...)
+		expectedValues = append(expectedValues, values...)
+	}
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := NewReader(filePath)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	for _, readAhead := range []int{0, 1, 4, 64} {
+		scanner := reader.ScanBlocks(readAhead)
+
+		var allIDs []uint64
+		var allValues []int64
+		expectedBlock := BlockID(0)
+		for scanner.Next() {
+			id, ids, values := scanner.Block()
+			assert.Equal(t, expectedBlock, id, "read-ahead %d", readAhead)
+			expectedBlock++
+			allIDs = append(allIDs, ids...)
+			allValues = append(allValues, values...)
+		}
+		require.NoError(t, scanner.Err())
+		require.NoError(t, scanner.Close())
+
+		assert.Equal(t, BlockID(numBlocks), expectedBlock, "read-ahead %d", readAhead)
+		assert.Equal(t, expectedIDs, allIDs, "read-ahead %d", readAhead)
+		assert.Equal(t, expectedValues, allValues, "read-ahead %d", readAhead)
+	}
+
+	t.Run("Close before the end", func(t *testing.T) {
+		scanner := reader.ScanBlocks(2)
+		require.True(t, scanner.Next())
+		require.NoError(t, scanner.Close())
+		require.NoError(t, scanner.Close())
+	})
+
+	t.Run("Read error stops the scan", func(t *testing.T) {
+		brokenPath := filepath.Join(tempDir, "broken.col")
+		data, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(brokenPath, data, 0644))
+
+		broken, err := NewReader(brokenPath)
+		require.NoError(t, err)
+		defer broken.Close()
+
+		// Corrupt the layout section of the third block
+		layoutOffset := int64(broken.blockIndex[2].BlockOffset) + blockHeaderSize
+		f, err := os.OpenFile(brokenPath, os.O_WRONLY, 0)
+		require.NoError(t, err)
+		_, err = f.WriteAt(make([]byte, blockLayoutSize), layoutOffset)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		for _, readAhead := range []int{0, 3} {
+			scanner := broken.ScanBlocks(readAhead)
+			blocks := 0
+			for scanner.Next() {
+				blocks++
+			}
+			assert.Equal(t, 2, blocks, "read-ahead %d", readAhead)
+			assert.Error(t, scanner.Err(), "read-ahead %d", readAhead)
+			assert.False(t, scanner.Next())
+			require.NoError(t, scanner.Close())
+		}
+	})
+}
+
+func TestScanAll(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-scan-all-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	filePath := filepath.Join(tempDir, "scan_all.col")
+	writer, err := NewWriter(filePath, WithEncoding(EncodingDeltaBoth))
+	require.NoError(t, err)
+
+	const numBlocks = 30
+	var expectedIDs []uint64
+	var expectedValues []int64
+	for block := 0; block < numBlocks; block++ {
+		// Varying block sizes make workers finish out of order
+		ids := make([]uint64, 10+block%7*40)
+		values := make([]int64, len(ids))
+		for i := range ids {
+			ids[i] = uint64(block*1000 + i)
+			values[i] = int64(block - i)
+		}
+		require.NoError(t, writer.WriteBlock(ids, values))
+		expectedIDs = append(expectedIDs, ids...)
+		expectedValues = append(expectedValues, values...)
+	}
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := NewReader(filePath)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	for _, parallel := range []int{0, 1, 4, -1, 100} {
+		var allIDs []uint64
+		var allValues []int64
+		expectedBlock := BlockID(0)
+		err := reader.ScanAll(parallel, func(id BlockID, ids []uint64, values []int64) error {
+			assert.Equal(t, expectedBlock, id, "parallel %d", parallel)
+			expectedBlock++
+			allIDs = append(allIDs, ids...)
+			allValues = append(allValues, values...)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, expectedIDs, allIDs, "parallel %d", parallel)
+		assert.Equal(t, expectedValues, allValues, "parallel %d", parallel)
+
+		// Unordered mode sees every block exactly once
+		seen := make(map[BlockID]int)
+		total := 0
+		err = reader.ScanAllUnordered(parallel, func(id BlockID, ids []uint64, values []int64) error {
+			seen[id]++
+			total += len(values)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Len(t, seen, numBlocks, "parallel %d", parallel)
+		assert.Equal(t, len(expectedValues), total, "parallel %d", parallel)
+	}
+
+	t.Run("Callback error stops the scan", func(t *testing.T) {
+		stop := errors.New("stop")
+		for _, parallel := range []int{0, 4} {
+			calls := 0
+			err := reader.ScanAll(parallel, func(id BlockID, ids []uint64, values []int64) error {
+				calls++
+				if id == 5 {
+					return stop
+				}
+				return nil
+			})
+			assert.ErrorIs(t, err, stop)
+			assert.Equal(t, 6, calls, "parallel %d", parallel)
+
+			err = reader.ScanAllUnordered(parallel, func(id BlockID, ids []uint64, values []int64) error {
+				return stop
+			})
+			assert.ErrorIs(t, err, stop)
+		}
+	})
+
+	t.Run("Read error stops the scan", func(t *testing.T) {
+		brokenPath := filepath.Join(tempDir, "broken.col")
+		data, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(brokenPath, data, 0644))
+
+		broken, err := NewReader(brokenPath)
+		require.NoError(t, err)
+		defer broken.Close()
+
+		// Corrupt the layout section of block 10
+		layoutOffset := int64(broken.blockIndex[10].BlockOffset) + blockHeaderSize
+		f, err := os.OpenFile(brokenPath, os.O_WRONLY, 0)
+		require.NoError(t, err)
+		_, err = f.WriteAt(make([]byte, blockLayoutSize), layoutOffset)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		for _, parallel := range []int{0, 4} {
+			var delivered []BlockID
+			err := broken.ScanAll(parallel, func(id BlockID, ids []uint64, values []int64) error {
+				delivered = append(delivered, id)
+				return nil
+			})
+			assert.Error(t, err, "parallel %d", parallel)
+			assert.Len(t, delivered, 10, "parallel %d", parallel)
+		}
+	})
+}
@@ -0,0 +1,200 @@
+package col
+
+import (
+	"fmt"
+	"math"
+)
+
+// ColumnStats extends AggregateResult with distribution statistics
+type ColumnStats struct {
+	AggregateResult
+
+	SumSquares    float64 // Sum of squared values
+	Variance      float64 // Population variance
+	StdDev        float64 // Population standard deviation
+	NegativeCount uint64  // Number of values < 0
+	ZeroCount     uint64  // Number of values == 0
+	PositiveCount uint64  // Number of values > 0
+
+	// FromMetadata is true if the statistics were computed from the footer only
+	FromMetadata bool
+}
+
+// Stats returns extended statistics for the whole file.
+// Files written with the block statistics footer section are answered from
+// metadata only; older files fall back to reading every block.
+func (r *Reader) Stats() (ColumnStats, error) {
+	if err := r.ensureFooter(); err != nil {
+		return ColumnStats{}, err
+	}
+
+	var stats ColumnStats
+
+	if r.extendedStats != nil {
+		stats.AggregateResult = r.Aggregate()
+		for _, ext := range r.extendedStats {
+			stats.SumSquares += ext.SumSquares
+			stats.NegativeCount += uint64(ext.NegativeCount)
+			stats.ZeroCount += uint64(ext.ZeroCount)
+		}
+		stats.FromMetadata = true
+	} else {
+		// Fallback: read and aggregate all blocks
+		var count uint64
+		var min int64 = 9223372036854775807  // Max int64
+		var max int64 = -9223372036854775808 // Min int64
+		var sum int64 = 0
+
+		for i := uint64(0); i < r.BlockCount(); i++ {
+			values, err := r.ReadBlockValues(BlockID(i))
+			if err != nil {
+				return ColumnStats{}, fmt.Errorf("failed to read block %d: %w", i, err)
+			}
+
+			count += uint64(len(values))
+			for _, v := range values {
+				if v < min {
+					min = v
+				}
+				if v > max {
+					max = v
+				}
+				sum += v
+			}
+
+			sumSquares, negativeCount, zeroCount := calculateExtendedStatsInt64(values)
+			stats.SumSquares += sumSquares
+			stats.NegativeCount += uint64(negativeCount)
+			stats.ZeroCount += uint64(zeroCount)
+		}
+
+		stats.AggregateResult = newAggregateResult(count, min, max, sum).withSource(SourceFullScan, r.BlockCount())
+	}
+
+	stats.PositiveCount = stats.Count - stats.NegativeCount - stats.ZeroCount
+
+	// Var(X) = E[X^2] - E[X]^2, clamped to avoid tiny negative results from rounding
+	if stats.Count > 0 {
+		mean := stats.Avg
+		stats.Variance = stats.SumSquares/float64(stats.Count) - mean*mean
+		if stats.Variance < 0 {
+			stats.Variance = 0
+		}
+		stats.StdDev = math.Sqrt(stats.Variance)
+	}
+
+	return stats, nil
+}
+
+// SignStats counts the values of a file by sign and sums their absolute values
+type SignStats struct {
+	NegativeCount uint64 // Number of values < 0
+	ZeroCount     uint64 // Number of values == 0
+	PositiveCount uint64 // Number of values > 0
+	AbsSum        int64  // Sum of absolute values, wraps around on overflow
+	BlocksScanned uint64 // Number of blocks that had to be decoded
+}
+
+// NonZeroCount returns the number of values != 0
+func (s SignStats) NonZeroCount() uint64 {
+	return s.NegativeCount + s.PositiveCount
+}
+
+// SignStats returns the sign counts and the sum of absolute values of the
+// file. The counts are answered from the per-block statistics section. A
+// block without negative values contributes its sum to AbsSum and a block
+// without positive values its negated sum, so only blocks holding both
+// signs are decoded. Files without the block statistics section decode
+// every block.
+func (r *Reader) SignStats() (SignStats, error) {
+	if err := r.ensureFooter(); err != nil {
+		return SignStats{}, err
+	}
+
+	var stats SignStats
+	for i, entry := range r.blockIndex {
+		if r.extendedStats != nil {
+			ext := r.extendedStats[i]
+			positive := uint64(entry.Count) - uint64(ext.NegativeCount) - uint64(ext.ZeroCount)
+			stats.NegativeCount += uint64(ext.NegativeCount)
+			stats.ZeroCount += uint64(ext.ZeroCount)
+			stats.PositiveCount += positive
+
+			switch {
+			case ext.NegativeCount == 0:
+				stats.AbsSum += uint64ToInt64(entry.Sum)
+				continue
+			case positive == 0:
+				stats.AbsSum -= uint64ToInt64(entry.Sum)
+				continue
+			}
+		}
+
+		values, err := r.ReadBlockValues(BlockID(i))
+		if err != nil {
+			return SignStats{}, fmt.Errorf("failed to read block %d: %w", i, err)
+		}
+		stats.BlocksScanned++
+
+		for _, v := range values {
+			if v < 0 {
+				stats.AbsSum -= v
+			} else {
+				stats.AbsSum += v
+			}
+			if r.extendedStats != nil {
+				continue
+			}
+			switch {
+			case v < 0:
+				stats.NegativeCount++
+			case v == 0:
+				stats.ZeroCount++
+			default:
+				stats.PositiveCount++
+			}
+		}
+	}
+
+	return stats, nil
+}
+
+// FileStats returns file-level statistics. Files written with the file
+// statistics footer section are answered in constant time; for older files
+// the statistics are combined from the block index.
+func (r *Reader) FileStats() (FileStats, error) {
+	if err := r.ensureFooter(); err != nil {
+		return FileStats{}, err
+	}
+
+	if r.fileStats != nil {
+		return *r.fileStats, nil
+	}
+
+	var stats FileStats
+	for i, entry := range r.blockIndex {
+		minValue := uint64ToInt64(entry.MinValue)
+		maxValue := uint64ToInt64(entry.MaxValue)
+		if i == 0 || entry.MinID < stats.MinID {
+			stats.MinID = entry.MinID
+		}
+		if i == 0 || entry.MaxID > stats.MaxID {
+			stats.MaxID = entry.MaxID
+		}
+		if i == 0 || minValue < stats.MinValue {
+			stats.MinValue = minValue
+		}
+		if i == 0 || maxValue > stats.MaxValue {
+			stats.MaxValue = maxValue
+		}
+		stats.Count += uint64(entry.Count)
+		stats.Sum += uint64ToInt64(entry.Sum)
+	}
+
+	return stats, nil
+}
+
+// aggregateResult converts the file statistics into an aggregation result
+func (s FileStats) aggregateResult() AggregateResult {
+	return newAggregateResult(s.Count, s.MinValue, s.MaxValue, s.Sum)
+}
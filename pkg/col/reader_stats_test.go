@@ -0,0 +1,129 @@
+package col
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReaderStats(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-stats-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	encodings := []uint32{EncodingRaw, EncodingDeltaBoth, EncodingVarIntBoth}
+	for _, encoding := range encodings {
+		filePath := filepath.Join(tempDir, "stats.col")
+		writer, err := NewWriter(filePath, WithEncoding(encoding))
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlock([]uint64{1, 2, 3, 4}, []int64{-2, 0, 4, 6}))
+		require.NoError(t, writer.WriteBlock([]uint64{5, 6}, []int64{0, 10}))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		reader, err := NewReader(filePath)
+		require.NoError(t, err)
+
+		// Values: -2, 0, 4, 6, 0, 10 => mean 3, E[X^2] = 156/6 = 26, variance 17
+		stats, err := reader.Stats()
+		require.NoError(t, err)
+		assert.True(t, stats.FromMetadata)
+		assert.Equal(t, uint64(6), stats.Count)
+		assert.Equal(t, int64(18), stats.Sum)
+		assert.Equal(t, int64(-2), stats.Min)
+		assert.Equal(t, int64(10), stats.Max)
+		assert.InDelta(t, 156.0, stats.SumSquares, 1e-9)
+		assert.InDelta(t, 17.0, stats.Variance, 1e-9)
+		assert.InDelta(t, math.Sqrt(17), stats.StdDev, 1e-9)
+		assert.Equal(t, uint64(1), stats.NegativeCount)
+		assert.Equal(t, uint64(2), stats.ZeroCount)
+		assert.Equal(t, uint64(3), stats.PositiveCount)
+
+		// Simulate a file without the statistics section to exercise the fallback
+		reader.extendedStats = nil
+		fallback, err := reader.Stats()
+		require.NoError(t, err)
+		assert.False(t, fallback.FromMetadata)
+		assert.Equal(t, SourceFullScan, fallback.Source)
+		fallback.FromMetadata = true
+		fallback.Source, fallback.BlocksScanned = stats.Source, stats.BlocksScanned
+		assert.Equal(t, stats, fallback)
+
+		require.NoError(t, reader.Close())
+	}
+}
+
+func TestReaderSignStats(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewWriterToBuffer(&buf, WithEncoding(EncodingVarIntBoth))
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteBlock([]uint64{1, 2, 3}, []int64{5, 0, 7}))   // No negatives
+	require.NoError(t, writer.WriteBlock([]uint64{4, 5, 6}, []int64{-3, -4, 0})) // No positives
+	require.NoError(t, writer.WriteBlock([]uint64{7, 8, 9}, []int64{-10, 0, 2})) // Both signs
+	require.NoError(t, writer.WriteBlock([]uint64{10, 11}, []int64{0, 0}))       // Zeros only
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := NewReaderFromBytes(buf.Bytes())
+	require.NoError(t, err)
+	defer reader.Close()
+
+	expected := SignStats{NegativeCount: 3, ZeroCount: 5, PositiveCount: 3, AbsSum: 31, BlocksScanned: 1}
+	stats, err := reader.SignStats()
+	require.NoError(t, err)
+	assert.Equal(t, expected, stats)
+	assert.Equal(t, uint64(6), stats.NonZeroCount())
+
+	// Matches the expression evaluation
+	results, err := reader.Evaluate(Sum(Abs(Col)), Count(Where(Ne(Col, Const(0)))))
+	require.NoError(t, err)
+	assert.Equal(t, stats.AbsSum, results[0].Value)
+	assert.Equal(t, int64(stats.NonZeroCount()), results[1].Value)
+
+	// Simulate a file without the statistics section to exercise the fallback
+	reader.extendedStats = nil
+	fallback, err := reader.SignStats()
+	require.NoError(t, err)
+	expected.BlocksScanned = 4
+	assert.Equal(t, expected, fallback)
+}
+
+func TestReaderFileStats(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-file-stats-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	filePath := filepath.Join(tempDir, "file_stats.col")
+	writer, err := NewWriter(filePath, WithEncoding(EncodingVarIntBoth))
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteBlock([]uint64{10, 20, 30}, []int64{5, -7, 3}))
+	require.NoError(t, writer.WriteBlock([]uint64{2, 40}, []int64{100, 0}))
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := NewReader(filePath)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	expected := FileStats{Count: 5, MinID: 2, MaxID: 40, MinValue: -7, MaxValue: 100, Sum: 101}
+	require.NotNil(t, reader.fileStats, "File statistics section should be present")
+	assert.Equal(t, expected, *reader.fileStats)
+
+	stats, err := reader.FileStats()
+	require.NoError(t, err)
+	assert.Equal(t, expected, stats)
+
+	// Aggregation is answered from the file statistics and matches a full scan
+	result := reader.Aggregate()
+	assert.Equal(t, AggregateResult{Count: 5, Min: -7, Max: 100, Sum: 101, Avg: 20.2, Source: SourceMetadata}, result)
+	assert.Equal(t, aggregateValues(result), aggregateValues(reader.AggregateWithOptions(AggregateOptions{SkipPreCalculated: true})))
+
+	// Simulate a file without the file statistics section to exercise the fallback
+	reader.fileStats = nil
+	fallback, err := reader.FileStats()
+	require.NoError(t, err)
+	assert.Equal(t, expected, fallback)
+	assert.Equal(t, result, reader.Aggregate())
+}
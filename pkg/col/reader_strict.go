@@ -0,0 +1,126 @@
+package col
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInconsistentFile is returned by strict readers if the header and footer
+// of a file contradict each other or the file size
+var ErrInconsistentFile = errors.New("inconsistent file structure")
+
+// OpenOptions configures how NewReaderWithOptions opens a file
+type OpenOptions struct {
+	// Strict verifies on open that the header and footer are consistent: the
+	// block count of the header matches the block index, blocks lie between
+	// the header and the footer in increasing order without overlapping each
+	// other or the global ID bitmap, and the magic numbers at both ends match.
+	// Files that fail these checks are refused with an error wrapping
+	// ErrInconsistentFile. By default readers are lenient and serve whatever
+	// can be read.
+	Strict bool
+
+	// DirectIO reads the file with O_DIRECT, bypassing the page cache, so
+	// that large scans do not evict data other processes rely on. Reads are
+	// widened to page boundaries and go through pooled aligned buffers. Where
+	// direct I/O is not available (platforms other than Linux, or file
+	// systems such as tmpfs) the file is read through the page cache as
+	// usual; Reader.DirectIO reports which path is used.
+	DirectIO bool
+
+	// FollowCheckpoints opens files that are still being written. Until the
+	// file is finalized, the reader serves the blocks of the latest
+	// checkpoint of the writer, see Writer.Checkpoint, and Reader.Refresh
+	// picks up blocks published later. Finalized files are read as usual.
+	FollowCheckpoints bool
+}
+
+// NewReaderWithOptions creates a new column file reader like NewReader,
+// configured by opts
+func NewReaderWithOptions(filename string, opts OpenOptions) (*Reader, error) {
+	open := openReader
+	if opts.DirectIO {
+		open = openDirectReader
+	}
+	reader, err := open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	reader.filename = filename
+	reader.followCheckpoints = opts.FollowCheckpoints
+	if err := reader.ensureFooter(); err != nil {
+		reader.file.Close()
+		return nil, err
+	}
+
+	if opts.Strict && reader.checkpointed {
+		reader.Close()
+		return nil, fmt.Errorf("%w: file is not finalized", ErrInconsistentFile)
+	}
+	if opts.Strict {
+		if err := reader.checkConsistency(); err != nil {
+			reader.Close()
+			return nil, err
+		}
+	}
+
+	return reader, nil
+}
+
+// checkConsistency checks the structure of the file as described by
+// OpenOptions.Strict and returns an error listing all inconsistencies
+func (r *Reader) checkConsistency() error {
+	// The reader raises the block count of the header to the size of the block
+	// index, so the recorded value is read again
+	buf, err := r.readBytesAt(0, headerSize)
+	if err != nil {
+		return fmt.Errorf("failed to read header: %w", err)
+	}
+	var recorded FileHeader
+	if err := recorded.UnmarshalBinary(buf); err != nil {
+		return err
+	}
+
+	var problems []string
+	if recorded.Magic != r.footerMeta.Magic {
+		problems = append(problems, fmt.Sprintf("header magic 0x%X differs from footer magic 0x%X",
+			recorded.Magic, r.footerMeta.Magic))
+	}
+	if recorded.BlockCount != uint64(len(r.blockIndex)) {
+		problems = append(problems, fmt.Sprintf("header block count %d differs from block index count %d",
+			recorded.BlockCount, len(r.blockIndex)))
+	}
+
+	footerStart := uint64(r.fileSize) - footerMetaSize - r.footerMeta.FooterSize
+	bitmapStart, bitmapEnd := recorded.BitmapOffset, recorded.BitmapOffset+recorded.BitmapSize
+	if recorded.BitmapSize > 0 && (bitmapStart < headerSize || bitmapEnd > footerStart) {
+		problems = append(problems, fmt.Sprintf("global ID bitmap %d-%d outside of data area %d-%d",
+			bitmapStart, bitmapEnd, headerSize, footerStart))
+	}
+
+	var prevEnd uint64 = headerSize
+	for i, entry := range r.blockIndex {
+		start, end := entry.BlockOffset, entry.BlockOffset+uint64(entry.BlockSize)
+		switch {
+		case entry.BlockSize < blockHeaderSize+blockLayoutSize:
+			problems = append(problems, fmt.Sprintf("block %d size %d smaller than its header", i, entry.BlockSize))
+		case start < prevEnd:
+			problems = append(problems, fmt.Sprintf("block %d at offset %d overlaps the preceding structure ending at %d",
+				i, start, prevEnd))
+		case end > footerStart:
+			problems = append(problems, fmt.Sprintf("block %d ending at %d overlaps the footer at %d", i, end, footerStart))
+		case recorded.BitmapSize > 0 && start < bitmapEnd && bitmapStart < end:
+			problems = append(problems, fmt.Sprintf("block %d at %d-%d overlaps the global ID bitmap", i, start, end))
+		}
+		if end > prevEnd {
+			prevEnd = end
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInconsistentFile, strings.Join(problems, "; "))
+	}
+	return nil
+}
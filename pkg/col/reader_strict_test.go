@@ -0,0 +1,99 @@
+package col
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrictOpen(t *testing.T) {
+	dir := t.TempDir()
+
+	// writeStrictFile writes three blocks and returns the path and file contents
+	writeStrictFile := func(t *testing.T, name string, options ...WriterOption) (string, []byte) {
+		path := filepath.Join(dir, name)
+		writer, err := NewWriter(path, options...)
+		require.NoError(t, err)
+		for b := uint64(0); b < 3; b++ {
+			require.NoError(t, writer.WriteBlock([]uint64{b*10 + 1, b*10 + 2}, []int64{1, 2}))
+		}
+		require.NoError(t, writer.FinalizeAndClose())
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return path, data
+	}
+
+	// corrupt writes a modified copy of data and checks that only the lenient
+	// reader opens it
+	corrupt := func(t *testing.T, data []byte, modify func(data []byte, footerStart int)) {
+		corrupted := append([]byte(nil), data...)
+		footerSize := binary.LittleEndian.Uint64(corrupted[len(corrupted)-footerMetaSize:])
+		modify(corrupted, len(corrupted)-footerMetaSize-int(footerSize))
+		path := filepath.Join(dir, "corrupted.col")
+		require.NoError(t, os.WriteFile(path, corrupted, 0644))
+
+		reader, err := NewReaderWithOptions(path, OpenOptions{})
+		require.NoError(t, err)
+		reader.Close()
+
+		_, err = NewReaderWithOptions(path, OpenOptions{Strict: true})
+		assert.ErrorIs(t, err, ErrInconsistentFile)
+	}
+
+	t.Run("Consistent files", func(t *testing.T) {
+		for name, options := range map[string][]WriterOption{
+			"aligned.col":     nil,
+			"unpadded.col":    {WithPadding(PaddingNone)},
+			"encoded.col":     {WithEncoding(EncodingDeltaDelta)},
+			"value_order.col": {WithValueOrderIndex()},
+		} {
+			path, _ := writeStrictFile(t, name, options...)
+			reader, err := NewReaderWithOptions(path, OpenOptions{Strict: true})
+			require.NoError(t, err, name)
+			reader.Close()
+		}
+
+		path := filepath.Join(dir, "empty.col")
+		writer, err := NewWriter(path)
+		require.NoError(t, err)
+		require.NoError(t, writer.FinalizeAndClose())
+		reader, err := NewReaderWithOptions(path, OpenOptions{Strict: true})
+		require.NoError(t, err)
+		reader.Close()
+	})
+
+	// Without a footer checksum, so inconsistent footers are not rejected as
+	// corrupt before the strict checks run
+	_, data := writeStrictFile(t, "source.col", WithPadding(PaddingNone), WithChecksum(ChecksumNone))
+
+	t.Run("Block count mismatch", func(t *testing.T) {
+		corrupt(t, data, func(data []byte, _ int) {
+			binary.LittleEndian.PutUint64(data[16:], 2)
+		})
+	})
+
+	t.Run("Decreasing offsets", func(t *testing.T) {
+		corrupt(t, data, func(data []byte, footerStart int) {
+			// Point the third block at the second one
+			second := binary.LittleEndian.Uint64(data[footerStart+4+footerEntrySize:])
+			binary.LittleEndian.PutUint64(data[footerStart+4+2*footerEntrySize:], second)
+		})
+	})
+
+	t.Run("Block overlapping the footer", func(t *testing.T) {
+		corrupt(t, data, func(data []byte, footerStart int) {
+			binary.LittleEndian.PutUint32(data[footerStart+4+2*footerEntrySize+8:], 1<<16)
+		})
+	})
+
+	t.Run("Block smaller than its header", func(t *testing.T) {
+		corrupt(t, data, func(data []byte, footerStart int) {
+			binary.LittleEndian.PutUint32(data[footerStart+4+8:], 10)
+		})
+	})
+}
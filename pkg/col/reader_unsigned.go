@@ -0,0 +1,85 @@
+package col
+
+import (
+	"fmt"
+	"math"
+)
+
+// DataType returns the data type of the values, DataTypeInt64 or DataTypeUint64
+func (r *Reader) DataType() uint32 {
+	return r.header.ColumnType
+}
+
+// ReadBlockUint64 returns the ID-value pairs of a block of a DataTypeUint64 column
+func (r *Reader) ReadBlockUint64(id BlockID) ([]uint64, []uint64, error) {
+	if r.header.ColumnType != DataTypeUint64 {
+		return nil, nil, fmt.Errorf("cannot read uint64 values from a column of data type %d", r.header.ColumnType)
+	}
+
+	ids, bitPatterns, err := r.ReadBlock(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values := make([]uint64, len(bitPatterns))
+	for i, v := range bitPatterns {
+		values[i] = uint64(v)
+	}
+	return ids, values, nil
+}
+
+// AggregateUint64 aggregates all values of a DataTypeUint64 column using default
+// options. Aggregate and the other int64 APIs treat the values of such a column
+// as their int64 bit patterns.
+func (r *Reader) AggregateUint64() (UnsignedAggregateResult, error) {
+	return r.AggregateUint64WithOptions(DefaultAggregateOptions())
+}
+
+// AggregateUint64WithOptions aggregates the values of a DataTypeUint64 column
+// with the given options. Unfiltered aggregations are answered from the footer
+// unless SkipPreCalculated is set. Blocks are always aggregated sequentially,
+// Parallel is ignored.
+func (r *Reader) AggregateUint64WithOptions(opts AggregateOptions) (UnsignedAggregateResult, error) {
+	if r.header.ColumnType != DataTypeUint64 {
+		return UnsignedAggregateResult{}, fmt.Errorf("cannot aggregate uint64 values of a column of data type %d", r.header.ColumnType)
+	}
+	if err := r.ensureFooter(); err != nil {
+		return UnsignedAggregateResult{}, err
+	}
+
+	var total unsignedBlockStats
+	var count uint64
+
+	filtered := opts.Filter != nil || opts.DenyFilter != nil
+	if r.unsignedStats != nil && !filtered && !opts.SkipPreCalculated {
+		for i, blockIdx := range r.aggregationBlocks(opts) {
+			total.add(r.unsignedStats[blockIdx], i == 0)
+			count += uint64(r.blockIndex[blockIdx].Count)
+		}
+	} else {
+		for _, blockIdx := range r.aggregationBlocks(opts) {
+			_, values, err := r.ReadBlockFiltered(BlockID(blockIdx), opts.Filter, opts.DenyFilter)
+			if err != nil {
+				return UnsignedAggregateResult{}, fmt.Errorf("failed to read block %d: %w", blockIdx, err)
+			}
+			if len(values) == 0 {
+				continue
+			}
+			total.add(calculateUnsignedStats(values), count == 0)
+			count += uint64(len(values))
+		}
+	}
+
+	result := UnsignedAggregateResult{
+		Count:   count,
+		Min:     total.Min,
+		Max:     total.Max,
+		Sum:     total.Sum,
+		SumHigh: total.SumHigh,
+	}
+	if count > 0 {
+		sum := float64(total.SumHigh)*math.Exp2(64) + float64(total.Sum)
+		result.Avg = sum / float64(count)
+	}
+	return result, nil
+}
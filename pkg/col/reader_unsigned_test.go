@@ -0,0 +1,180 @@
+package col
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaviate/sroar"
+)
+
+func TestUnsignedColumn(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-unsigned-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	// Byte counters close to the top of the uint64 range, so the sum overflows
+	ids := []uint64{1, 2, 3, 4, 5, 6}
+	values := []uint64{math.MaxUint64, math.MaxUint64 - 1, 1 << 63, 0, 42, 1 << 40}
+
+	expectedSum := new(big.Int)
+	for _, v := range values {
+		expectedSum.Add(expectedSum, new(big.Int).SetUint64(v))
+	}
+
+	writeFile := func(t *testing.T, name string, encoding uint32) string {
+		path := filepath.Join(tempDir, name)
+		writer, err := NewWriter(path, WithEncoding(encoding), WithDataType(DataTypeUint64))
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlockUint64(ids[:3], values[:3]))
+		require.NoError(t, writer.WriteBlockUint64(ids[3:], values[3:]))
+		require.NoError(t, writer.FinalizeAndClose())
+		return path
+	}
+
+	encodings := []uint32{
+		EncodingRaw, EncodingDeltaID, EncodingDeltaValue, EncodingDeltaBoth,
+		EncodingVarInt, EncodingVarIntID, EncodingVarIntValue, EncodingVarIntBoth,
+	}
+	for _, encoding := range encodings {
+		t.Run(fmt.Sprintf("Encoding %d", encoding), func(t *testing.T) {
+			path := writeFile(t, fmt.Sprintf("enc-%d.col", encoding), encoding)
+
+			reader, err := NewReader(path)
+			require.NoError(t, err)
+			defer reader.Close()
+
+			assert.Equal(t, DataTypeUint64, reader.DataType())
+
+			var allIDs, allValues []uint64
+			for id := BlockID(0); id < BlockID(reader.BlockCount()); id++ {
+				blockIDs, blockValues, err := reader.ReadBlockUint64(id)
+				require.NoError(t, err)
+				allIDs = append(allIDs, blockIDs...)
+				allValues = append(allValues, blockValues...)
+			}
+			assert.Equal(t, ids, allIDs)
+			assert.Equal(t, values, allValues)
+		})
+	}
+
+	t.Run("Plain varints", func(t *testing.T) {
+		// Small values take a single byte without ZigZag encoding
+		assert.Equal(t, []byte{0x7F}, encodeUnsignedVarInt(127))
+		assert.Equal(t, 10, len(encodeUnsignedVarInt(-1)))
+
+		writer, err := NewWriter(filepath.Join(tempDir, "size.col"),
+			WithEncoding(EncodingVarInt), WithDataType(DataTypeUint64))
+		require.NoError(t, err)
+		defer writer.Close()
+
+		_, _, size, err := encodeValues([]int64{127, 100}, EncodingVarInt, DataTypeUint64)
+		require.NoError(t, err)
+		assert.Equal(t, uint32(2), size)
+		// One byte for the ID and one for the value
+		assert.Equal(t, uint64(2), writer.encodedPairSize([]uint64{1}, []int64{127}, 0))
+	})
+
+	t.Run("Aggregation", func(t *testing.T) {
+		reader, err := NewReader(writeFile(t, "agg.col", EncodingVarIntBoth))
+		require.NoError(t, err)
+		defer reader.Close()
+
+		fromMetadata, err := reader.AggregateUint64()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(len(values)), fromMetadata.Count)
+		assert.Equal(t, uint64(0), fromMetadata.Min)
+		assert.Equal(t, uint64(math.MaxUint64), fromMetadata.Max)
+		assert.True(t, fromMetadata.Overflowed())
+		assert.Equal(t, 0, expectedSum.Cmp(fromMetadata.SumBig()))
+		expectedAvg, _ := new(big.Float).Quo(new(big.Float).SetInt(expectedSum), big.NewFloat(float64(len(values)))).Float64()
+		assert.InDelta(t, expectedAvg, fromMetadata.Avg, expectedAvg*1e-12)
+
+		opts := DefaultAggregateOptions()
+		opts.SkipPreCalculated = true
+		fromBlocks, err := reader.AggregateUint64WithOptions(opts)
+		require.NoError(t, err)
+		assert.Equal(t, fromMetadata, fromBlocks)
+	})
+
+	t.Run("Filtered aggregation", func(t *testing.T) {
+		reader, err := NewReader(writeFile(t, "filtered.col", EncodingRaw))
+		require.NoError(t, err)
+		defer reader.Close()
+
+		opts := DefaultAggregateOptions()
+		opts.Filter = sroar.NewBitmap()
+		opts.Filter.SetMany([]uint64{1, 2, 5})
+		result, err := reader.AggregateUint64WithOptions(opts)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), result.Count)
+		assert.Equal(t, uint64(42), result.Min)
+		assert.Equal(t, uint64(math.MaxUint64), result.Max)
+		// (2^64-1) + (2^64-2) + 42 = 2*2^64 + 39
+		assert.Equal(t, uint64(2), result.SumHigh)
+		assert.Equal(t, uint64(39), result.Sum)
+
+		opts.Filter = sroar.NewBitmap()
+		opts.Filter.Set(100)
+		empty, err := reader.AggregateUint64WithOptions(opts)
+		require.NoError(t, err)
+		assert.Equal(t, UnsignedAggregateResult{}, empty)
+	})
+
+	t.Run("Type mismatches", func(t *testing.T) {
+		signedPath := filepath.Join(tempDir, "signed.col")
+		writer, err := NewWriter(signedPath)
+		require.NoError(t, err)
+		assert.Error(t, writer.WriteBlockUint64([]uint64{1}, []uint64{1}))
+		require.NoError(t, writer.WriteBlock([]uint64{10}, []int64{-1}))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		signed, err := NewReader(signedPath)
+		require.NoError(t, err)
+		defer signed.Close()
+		assert.Equal(t, DataTypeInt64, signed.DataType())
+		_, _, err = signed.ReadBlockUint64(0)
+		assert.Error(t, err)
+		_, err = signed.AggregateUint64()
+		assert.Error(t, err)
+
+		unsignedPath := writeFile(t, "mismatch.col", EncodingRaw)
+		assert.Error(t, Concat(filepath.Join(tempDir, "concat.col"), unsignedPath, signedPath))
+
+		_, err = NewWriter(filepath.Join(tempDir, "invalid.col"), WithDataType(99))
+		assert.Error(t, err)
+	})
+
+	t.Run("Concat and rewrite keep the data type", func(t *testing.T) {
+		first := writeFile(t, "first.col", EncodingVarInt)
+
+		secondPath := filepath.Join(tempDir, "second.col")
+		writer, err := NewWriter(secondPath, WithEncoding(EncodingVarInt), WithDataType(DataTypeUint64))
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlockUint64([]uint64{100}, []uint64{math.MaxUint64}))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		concatPath := filepath.Join(tempDir, "concat-unsigned.col")
+		require.NoError(t, Concat(concatPath, first, secondPath))
+		rewrittenPath := filepath.Join(tempDir, "rewritten.col")
+		require.NoError(t, Rewrite(concatPath, rewrittenPath, RewriteOptions{Encoding: EncodingVarIntValue}))
+
+		for _, path := range []string{concatPath, rewrittenPath} {
+			reader, err := NewReader(path)
+			require.NoError(t, err)
+
+			assert.Equal(t, DataTypeUint64, reader.DataType())
+			result, err := reader.AggregateUint64()
+			require.NoError(t, err)
+			assert.Equal(t, uint64(len(values)+1), result.Count)
+			expected := new(big.Int).Add(expectedSum, new(big.Int).SetUint64(math.MaxUint64))
+			assert.Equal(t, 0, expected.Cmp(result.SumBig()))
+			require.NoError(t, reader.Close())
+		}
+	})
+}
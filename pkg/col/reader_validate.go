@@ -0,0 +1,198 @@
+package col
+
+import (
+	"fmt"
+)
+
+// FooterReport is the result of Reader.ValidateFooterAgainstBlocks
+type FooterReport struct {
+	BlocksChecked uint64
+
+	// Mismatches lists the blocks whose recorded statistics differ from their
+	// data, or whose data could not be read, in block order
+	Mismatches []BlockMismatch
+
+	// FileStatsFields names the fields of the file statistics section that
+	// differ from the combined block statistics
+	FileStatsFields []string
+}
+
+// OK returns whether all recorded statistics match the data
+func (r FooterReport) OK() bool {
+	return len(r.Mismatches) == 0 && len(r.FileStatsFields) == 0
+}
+
+// BlockMismatch describes a block whose recorded statistics differ from its data
+type BlockMismatch struct {
+	Block BlockID
+
+	// Fields names the statistics that differ, e.g. "MinValue" for the footer
+	// entry or "header.MinValue" for the block header
+	Fields []string
+
+	Footer BlockStats // Statistics recorded in the footer
+	Actual BlockStats // Statistics recomputed from the block data
+
+	// Err is set if the block could not be read, in which case Fields and
+	// Actual are empty
+	Err error
+}
+
+// String formats the mismatch for display
+func (m BlockMismatch) String() string {
+	if m.Err != nil {
+		return fmt.Sprintf("block %d: %v", m.Block, m.Err)
+	}
+	return fmt.Sprintf("block %d: %v differ, footer=%+v, actual=%+v", m.Block, m.Fields, m.Footer, m.Actual)
+}
+
+// ValidateFooterAgainstBlocks recomputes the statistics of every block from its
+// data and compares them to the footer entries, the block headers and the
+// optional statistics sections. Unreadable blocks are reported as mismatches;
+// an error is only returned if the footer itself cannot be read.
+func (r *Reader) ValidateFooterAgainstBlocks() (FooterReport, error) {
+	if err := r.ensureFooter(); err != nil {
+		return FooterReport{}, err
+	}
+
+	var report FooterReport
+	actualStats := make([]BlockStats, 0, len(r.blockIndex))
+	for i := range r.blockIndex {
+		report.BlocksChecked++
+		id := BlockID(i)
+		footer := r.blockStats(i)
+
+		actual, header, err := r.recomputeBlockStats(id)
+		if err != nil {
+			report.Mismatches = append(report.Mismatches, BlockMismatch{Block: id, Footer: footer, Err: err})
+			continue
+		}
+		actualStats = append(actualStats, actual)
+
+		fields := r.compareBlockStats(footer, actual)
+		for _, field := range compareBasicStats(header, actual) {
+			fields = append(fields, "header."+field)
+		}
+		if len(fields) > 0 {
+			report.Mismatches = append(report.Mismatches, BlockMismatch{
+				Block:  id,
+				Fields: fields,
+				Footer: footer,
+				Actual: actual,
+			})
+		}
+	}
+
+	// The file statistics can only be checked if every block could be read
+	if r.fileStats != nil && len(actualStats) == len(r.blockIndex) {
+		report.FileStatsFields = compareFileStats(*r.fileStats, combineBlockStats(actualStats))
+	}
+
+	return report, nil
+}
+
+// recomputeBlockStats decodes a block and computes its statistics like the
+// writer does. It also returns the statistics recorded in the block header.
+func (r *Reader) recomputeBlockStats(id BlockID) (actual, header BlockStats, err error) {
+	raw, err := r.readRawBlock(int(id))
+	if err != nil {
+		return BlockStats{}, BlockStats{}, err
+	}
+	var blockHeader BlockHeader
+	if err := blockHeader.UnmarshalBinary(raw[:blockHeaderSize]); err != nil {
+		return BlockStats{}, BlockStats{}, err
+	}
+	header = BlockStats{
+		MinID:    blockHeader.MinID,
+		MaxID:    blockHeader.MaxID,
+		MinValue: uint64ToInt64(blockHeader.MinValue),
+		MaxValue: uint64ToInt64(blockHeader.MaxValue),
+		Sum:      uint64ToInt64(blockHeader.Sum),
+		Count:    blockHeader.Count,
+	}
+
+	ids, values, err := r.ReadBlock(id)
+	if err != nil {
+		return BlockStats{}, BlockStats{}, err
+	}
+
+	actual.MinID, actual.MaxID = calculateMinMaxUint64(ids)
+	actual.MinValue, actual.MaxValue = calculateMinMaxInt64(values)
+	actual.Sum = calculateSumInt64(values)
+	actual.Count = uint32(len(ids))
+	actual.SumSquares, actual.NegativeCount, actual.ZeroCount = calculateExtendedStatsInt64(values)
+	if r.header.ColumnType == DataTypeUint64 {
+		actual.unsigned = calculateUnsignedStats(values)
+	}
+	return actual, header, nil
+}
+
+// compareBlockStats returns the names of the statistics recorded for a block
+// that differ from the actual ones, including the optional sections the file has
+func (r *Reader) compareBlockStats(recorded, actual BlockStats) []string {
+	fields := compareBasicStats(recorded, actual)
+	if r.extendedStats != nil {
+		if recorded.SumSquares != actual.SumSquares {
+			fields = append(fields, "SumSquares")
+		}
+		if recorded.NegativeCount != actual.NegativeCount {
+			fields = append(fields, "NegativeCount")
+		}
+		if recorded.ZeroCount != actual.ZeroCount {
+			fields = append(fields, "ZeroCount")
+		}
+	}
+	if r.unsignedStats != nil && recorded.unsigned != actual.unsigned {
+		fields = append(fields, "UnsignedStats")
+	}
+	return fields
+}
+
+// compareBasicStats returns the names of the statistics stored in both the
+// footer entry and the block header that differ
+func compareBasicStats(recorded, actual BlockStats) []string {
+	var fields []string
+	if recorded.MinID != actual.MinID {
+		fields = append(fields, "MinID")
+	}
+	if recorded.MaxID != actual.MaxID {
+		fields = append(fields, "MaxID")
+	}
+	if recorded.MinValue != actual.MinValue {
+		fields = append(fields, "MinValue")
+	}
+	if recorded.MaxValue != actual.MaxValue {
+		fields = append(fields, "MaxValue")
+	}
+	if recorded.Sum != actual.Sum {
+		fields = append(fields, "Sum")
+	}
+	if recorded.Count != actual.Count {
+		fields = append(fields, "Count")
+	}
+	return fields
+}
+
+// compareFileStats returns the names of the file statistics that differ
+func compareFileStats(recorded, actual FileStats) []string {
+	var fields []string
+	if recorded.Count != actual.Count {
+		fields = append(fields, "Count")
+	}
+	if recorded.MinID != actual.MinID {
+		fields = append(fields, "MinID")
+	}
+	if recorded.MaxID != actual.MaxID {
+		fields = append(fields, "MaxID")
+	}
+	if recorded.MinValue != actual.MinValue {
+		fields = append(fields, "MinValue")
+	}
+	if recorded.MaxValue != actual.MaxValue {
+		fields = append(fields, "MaxValue")
+	}
+	if recorded.Sum != actual.Sum {
+		fields = append(fields, "Sum")
+	}
+	return fields
+}
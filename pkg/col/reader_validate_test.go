@@ -0,0 +1,119 @@
+package col
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateFooterAgainstBlocks(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-validate-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	writeFile := func(t *testing.T, name string, options ...WriterOption) string {
+		path := filepath.Join(tempDir, name)
+		writer, err := NewWriter(path, options...)
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlock([]uint64{1, 2, 3}, []int64{-5, 0, 7}))
+		require.NoError(t, writer.WriteBlock([]uint64{10, 20}, []int64{-100, -200}))
+		require.NoError(t, writer.FinalizeAndClose())
+		return path
+	}
+
+	validate := func(t *testing.T, path string) FooterReport {
+		reader, err := NewReader(path)
+		require.NoError(t, err)
+		defer reader.Close()
+		report, err := reader.ValidateFooterAgainstBlocks()
+		require.NoError(t, err)
+		return report
+	}
+
+	// patch overwrites 8 bytes of the file at offset
+	patch := func(t *testing.T, path string, offset int64, value uint64) {
+		file, err := os.OpenFile(path, os.O_RDWR, 0)
+		require.NoError(t, err)
+		defer file.Close()
+		buf := make([]byte, 8)
+		binary.LittleEndian.PutUint64(buf, value)
+		_, err = file.WriteAt(buf, offset)
+		require.NoError(t, err)
+	}
+
+	t.Run("Consistent files", func(t *testing.T) {
+		for _, encoding := range []uint32{EncodingRaw, EncodingDeltaBoth, EncodingVarIntBoth, EncodingDeltaDelta} {
+			report := validate(t, writeFile(t, fmt.Sprintf("ok-%d.col", encoding), WithEncoding(encoding)))
+			assert.True(t, report.OK(), "encoding %d: %+v", encoding, report)
+			assert.Equal(t, uint64(2), report.BlocksChecked)
+		}
+
+		report := validate(t, writeFile(t, "ok-unsigned.col", WithDataType(DataTypeUint64)))
+		assert.True(t, report.OK(), "%+v", report)
+	})
+
+	t.Run("Footer entry mismatch", func(t *testing.T) {
+		// Without a footer checksum, which would reject the patched footer
+		path := writeFile(t, "footer.col", WithChecksum(ChecksumNone))
+		reader, err := NewReader(path)
+		require.NoError(t, err)
+		footer, err := reader.FooterInfo()
+		require.NoError(t, err)
+		require.NoError(t, reader.Close())
+
+		// MinValue of the second entry, which follows the block count and the first entry
+		patch(t, path, int64(footer.Offset)+4+footerEntrySize+28, int64ToUint64(-150))
+
+		report := validate(t, path)
+		assert.False(t, report.OK())
+		require.Len(t, report.Mismatches, 1)
+		mismatch := report.Mismatches[0]
+		assert.Equal(t, BlockID(1), mismatch.Block)
+		assert.Equal(t, []string{"MinValue"}, mismatch.Fields)
+		assert.Equal(t, int64(-150), mismatch.Footer.MinValue)
+		assert.Equal(t, int64(-200), mismatch.Actual.MinValue)
+		assert.NoError(t, mismatch.Err)
+
+		// The file statistics section was written from the correct statistics
+		assert.Empty(t, report.FileStatsFields)
+	})
+
+	t.Run("Block header mismatch", func(t *testing.T) {
+		path := writeFile(t, "header.col")
+		reader, err := NewReader(path)
+		require.NoError(t, err)
+		meta, err := reader.BlockMeta(0)
+		require.NoError(t, err)
+		require.NoError(t, reader.Close())
+
+		// Sum of the first block
+		patch(t, path, int64(meta.Offset)+32, 99)
+
+		report := validate(t, path)
+		require.Len(t, report.Mismatches, 1)
+		assert.Equal(t, []string{"header.Sum"}, report.Mismatches[0].Fields)
+	})
+
+	t.Run("Unreadable block", func(t *testing.T) {
+		path := writeFile(t, "unreadable.col")
+		reader, err := NewReader(path)
+		require.NoError(t, err)
+		meta, err := reader.BlockMeta(1)
+		require.NoError(t, err)
+		require.NoError(t, reader.Close())
+
+		// An unknown encoding in the block header
+		patch(t, path, int64(meta.Offset)+44, 99)
+
+		report := validate(t, path)
+		require.Len(t, report.Mismatches, 1)
+		assert.Equal(t, BlockID(1), report.Mismatches[0].Block)
+		assert.Error(t, report.Mismatches[0].Err)
+		assert.Contains(t, report.Mismatches[0].String(), "block 1")
+	})
+}
@@ -0,0 +1,78 @@
+package col
+
+// ValueIterator iterates over the pairs of a block ordered by value, see
+// Reader.ScanByValue.
+//
+// Typical usage, collecting the IDs of the 100 smallest values:
+//
+//	it, err := reader.ScanByValue(0, true)
+//	...
+//	for len(ids) < 100 && it.Next() {
+//		ids = append(ids, it.ID())
+//	}
+type ValueIterator struct {
+	ids    []uint64
+	values []int64
+	order  []uint32
+	asc    bool
+	pos    int // Number of pairs returned so far
+	index  int // Position of the current pair in the block
+}
+
+// Next advances to the next pair and returns false when all pairs were returned
+func (it *ValueIterator) Next() bool {
+	if it.pos >= len(it.order) {
+		return false
+	}
+	if it.asc {
+		it.index = int(it.order[it.pos])
+	} else {
+		it.index = int(it.order[len(it.order)-1-it.pos])
+	}
+	it.pos++
+	return true
+}
+
+// ID returns the ID of the current pair
+func (it *ValueIterator) ID() uint64 {
+	return it.ids[it.index]
+}
+
+// Value returns the value of the current pair
+func (it *ValueIterator) Value() int64 {
+	return it.values[it.index]
+}
+
+// Len returns the number of pairs in the block
+func (it *ValueIterator) Len() int {
+	return len(it.order)
+}
+
+// ScanByValue returns an iterator over the pairs of a block ordered by value,
+// ascending or descending. Equal values are returned in ID order when
+// ascending and in reverse ID order when descending. Values of uint64 columns
+// are ordered as unsigned.
+//
+// Files written with WithValueOrderIndex store the order; for other blocks the
+// values are sorted when the block is read.
+func (r *Reader) ScanByValue(blockIdx BlockID, asc bool) (*ValueIterator, error) {
+	ids, values, err := r.ReadBlock(blockIdx)
+	if err != nil {
+		return nil, err
+	}
+
+	order := r.valueOrder(blockIdx)
+	if order == nil {
+		order = sortedValueOrder(values, r.header.ColumnType)
+	}
+
+	return &ValueIterator{ids: ids, values: values, order: order, asc: asc}, nil
+}
+
+// valueOrder returns the stored value order of a block, nil if it has none
+func (r *Reader) valueOrder(blockIdx BlockID) []uint32 {
+	if r.valueOrders == nil {
+		return nil
+	}
+	return r.valueOrders[blockIdx]
+}
@@ -0,0 +1,98 @@
+package col
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanByValue(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-value-order-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	ids := []uint64{1, 2, 3, 4, 5, 6}
+	values := []int64{30, -10, 20, -10, 50, 0}
+
+	collect := func(t *testing.T, reader *Reader, block BlockID, asc bool) ([]uint64, []int64) {
+		it, err := reader.ScanByValue(block, asc)
+		require.NoError(t, err)
+		var gotIDs []uint64
+		var gotValues []int64
+		for it.Next() {
+			gotIDs = append(gotIDs, it.ID())
+			gotValues = append(gotValues, it.Value())
+		}
+		assert.Equal(t, len(gotIDs), it.Len())
+		return gotIDs, gotValues
+	}
+
+	for _, indexed := range []bool{true, false} {
+		name := "Sorted at read time"
+		options := []WriterOption{WithEncoding(EncodingVarIntBoth)}
+		if indexed {
+			name = "Index"
+			options = append(options, WithValueOrderIndex())
+		}
+
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(tempDir, name+".col")
+			writer, err := NewWriter(path, options...)
+			require.NoError(t, err)
+			require.NoError(t, writer.WriteBlock(ids, values))
+			require.NoError(t, writer.WriteBlock([]uint64{10, 11}, []int64{7, -7}))
+			require.NoError(t, writer.FinalizeAndClose())
+
+			reader, err := NewReader(path)
+			require.NoError(t, err)
+			defer reader.Close()
+			assert.Equal(t, indexed, reader.valueOrder(0) != nil)
+
+			gotIDs, gotValues := collect(t, reader, 0, true)
+			assert.Equal(t, []uint64{2, 4, 6, 3, 1, 5}, gotIDs)
+			assert.Equal(t, []int64{-10, -10, 0, 20, 30, 50}, gotValues)
+
+			gotIDs, gotValues = collect(t, reader, 0, false)
+			assert.Equal(t, []uint64{5, 1, 3, 6, 4, 2}, gotIDs)
+			assert.Equal(t, []int64{50, 30, 20, 0, -10, -10}, gotValues)
+
+			gotIDs, _ = collect(t, reader, 1, true)
+			assert.Equal(t, []uint64{11, 10}, gotIDs)
+
+			_, err = reader.ScanByValue(2, true)
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("Unsigned values", func(t *testing.T) {
+		path := filepath.Join(tempDir, "unsigned.col")
+		writer, err := NewWriter(path, WithDataType(DataTypeUint64), WithValueOrderIndex())
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlockUint64([]uint64{1, 2, 3}, []uint64{math.MaxUint64, 1, 1 << 63}))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		reader, err := NewReader(path)
+		require.NoError(t, err)
+		defer reader.Close()
+
+		gotIDs, _ := collect(t, reader, 0, true)
+		assert.Equal(t, []uint64{2, 3, 1}, gotIDs)
+	})
+
+	t.Run("Concatenated blocks without index", func(t *testing.T) {
+		source := filepath.Join(tempDir, "Index.col")
+		concatPath := filepath.Join(tempDir, "concat.col")
+		require.NoError(t, Concat(concatPath, source))
+
+		reader, err := NewReader(concatPath)
+		require.NoError(t, err)
+		defer reader.Close()
+
+		gotIDs, _ := collect(t, reader, 0, true)
+		assert.Equal(t, []uint64{2, 4, 6, 3, 1, 5}, gotIDs)
+	})
+}
@@ -0,0 +1,41 @@
+package col
+
+// WeightedAggregateResult represents the result of a weighted aggregation
+type WeightedAggregateResult struct {
+	Count       uint64  // Number of IDs present in both files
+	WeightSum   int64   // Sum of the weights
+	WeightedSum float64 // Sum of value*weight, as float64 to avoid overflow
+	WeightedAvg float64 // WeightedSum divided by WeightSum
+}
+
+// AggregateWeighted computes the weighted sum and average of the values in r,
+// using the values of weights as the weight of each ID. Both files must share the
+// same ID space and have strictly increasing IDs; IDs missing from either file are
+// skipped. Filter and DenyFilter from opts are applied to the IDs, the other
+// options don't apply since the files are always read block by block.
+func (r *Reader) AggregateWeighted(weights *Reader, opts AggregateOptions) (WeightedAggregateResult, error) {
+	var result WeightedAggregateResult
+
+	err := Join(r, weights, JoinInner, func(row JoinRow) bool {
+		if opts.Filter != nil && !opts.Filter.Contains(row.ID) {
+			return true
+		}
+		if opts.DenyFilter != nil && opts.DenyFilter.Contains(row.ID) {
+			return true
+		}
+
+		result.Count++
+		result.WeightSum += row.ValueB
+		result.WeightedSum += float64(row.ValueA) * float64(row.ValueB)
+		return true
+	})
+	if err != nil {
+		return WeightedAggregateResult{}, err
+	}
+
+	if result.WeightSum != 0 {
+		result.WeightedAvg = result.WeightedSum / float64(result.WeightSum)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,61 @@
+package col
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaviate/sroar"
+)
+
+func TestAggregateWeighted(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-weighted-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	// Values file: IDs 1-5
+	valuesPath := filepath.Join(tempDir, "values.col")
+	writer, err := NewWriter(valuesPath)
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteBlock([]uint64{1, 2, 3}, []int64{10, 20, 30}))
+	require.NoError(t, writer.WriteBlock([]uint64{4, 5}, []int64{40, 50}))
+	require.NoError(t, writer.FinalizeAndClose())
+
+	// Weights file: ID 1 is missing, ID 6 has no value
+	weightsPath := filepath.Join(tempDir, "weights.col")
+	writer, err = NewWriter(weightsPath, WithEncoding(EncodingVarIntBoth))
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteBlock([]uint64{2, 3, 4, 5, 6}, []int64{1, 2, 3, 4, 100}))
+	require.NoError(t, writer.FinalizeAndClose())
+
+	values, err := NewReader(valuesPath)
+	require.NoError(t, err)
+	defer values.Close()
+	weights, err := NewReader(weightsPath)
+	require.NoError(t, err)
+	defer weights.Close()
+
+	// Matching IDs 2-5: 20*1 + 30*2 + 40*3 + 50*4 = 400, weights sum to 10
+	result, err := values.AggregateWeighted(weights, DefaultAggregateOptions())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), result.Count)
+	assert.Equal(t, int64(10), result.WeightSum)
+	assert.Equal(t, 400.0, result.WeightedSum)
+	assert.Equal(t, 40.0, result.WeightedAvg)
+
+	// With filters: allow 2-4, deny 3 => 20*1 + 40*3 = 140, weights sum to 4
+	opts := DefaultAggregateOptions()
+	opts.Filter = sroar.NewBitmap()
+	opts.Filter.SetMany([]uint64{2, 3, 4})
+	opts.DenyFilter = sroar.NewBitmap()
+	opts.DenyFilter.Set(3)
+
+	result, err = values.AggregateWeighted(weights, opts)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), result.Count)
+	assert.Equal(t, int64(4), result.WeightSum)
+	assert.Equal(t, 140.0, result.WeightedSum)
+	assert.Equal(t, 35.0, result.WeightedAvg)
+}
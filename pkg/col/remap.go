@@ -0,0 +1,83 @@
+package col
+
+import (
+	"fmt"
+)
+
+// RemapIDs copies all pairs of in to out, replacing each ID with mapping[id].
+// Every ID of the input must be present in the mapping. Pairs are sorted by their
+// new ID within each block; if the mapping doesn't preserve the ID order, blocks
+// of the output may have overlapping ID ranges. Output blocks keep the partition
+// key of their input block. The caller is responsible for finalizing out.
+func RemapIDs(in *Reader, out *Writer, mapping map[uint64]uint64) error {
+	for blockIdx := uint64(0); blockIdx < in.BlockCount(); blockIdx++ {
+		ids, values, err := in.ReadBlock(BlockID(blockIdx))
+		if err != nil {
+			return fmt.Errorf("failed to read block %d: %w", blockIdx, err)
+		}
+
+		for i, id := range ids {
+			newID, ok := mapping[id]
+			if !ok {
+				return fmt.Errorf("no mapping for ID %d in block %d", id, blockIdx)
+			}
+			ids[i] = newID
+		}
+
+		if !isSorted(ids) {
+			sortByID(ids, values)
+		}
+
+		partition := WithBlockPartition(in.blockPartition(int(blockIdx)))
+		if err := writeAllBlocks(out, ids, values, partition); err != nil {
+			return fmt.Errorf("failed to write remapped block %d: %w", blockIdx, err)
+		}
+	}
+
+	return nil
+}
+
+// DenseRemap renumbers the IDs of in to the contiguous range 0..n-1, preserving
+// their order, and writes the result to out. Dense IDs compress much better with
+// delta and varint encodings than large random IDs.
+//
+// The mapping is written to mappingOut as a sidecar column where the ID is the new
+// ID and the value is the original ID. Original IDs are stored bit-for-bit as
+// int64, so use uint64(value) to recover IDs above math.MaxInt64.
+// The input must have strictly increasing IDs. The caller is responsible for
+// finalizing both writers.
+func DenseRemap(in *Reader, out *Writer, mappingOut *Writer) error {
+	var nextID uint64
+	var lastID uint64
+
+	for blockIdx := uint64(0); blockIdx < in.BlockCount(); blockIdx++ {
+		ids, values, err := in.ReadBlock(BlockID(blockIdx))
+		if err != nil {
+			return fmt.Errorf("failed to read block %d: %w", blockIdx, err)
+		}
+
+		newIDs := make([]uint64, len(ids))
+		originalIDs := make([]int64, len(ids))
+		for i, id := range ids {
+			// Dense IDs are only order-preserving if the input is sorted
+			if nextID > 0 && id <= lastID {
+				return fmt.Errorf("IDs are not strictly increasing: %d follows %d in block %d",
+					id, lastID, blockIdx)
+			}
+			lastID = id
+
+			newIDs[i] = nextID
+			originalIDs[i] = int64(id)
+			nextID++
+		}
+
+		if err := writeAllBlocks(out, newIDs, values); err != nil {
+			return fmt.Errorf("failed to write remapped block %d: %w", blockIdx, err)
+		}
+		if err := writeAllBlocks(mappingOut, newIDs, originalIDs); err != nil {
+			return fmt.Errorf("failed to write mapping for block %d: %w", blockIdx, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package col
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readAllPairs reads all pairs of a file in block order
+func readAllPairs(t *testing.T, r *Reader) ([]uint64, []int64) {
+	var allIDs []uint64
+	var allValues []int64
+	for i := uint64(0); i < r.BlockCount(); i++ {
+		ids, values, err := r.GetPairs(i)
+		require.NoError(t, err)
+		allIDs = append(allIDs, ids...)
+		allValues = append(allValues, values...)
+	}
+	return allIDs, allValues
+}
+
+func TestRemapIDs(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-remap-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	inPath := filepath.Join(tempDir, "in.col")
+	writer, err := NewWriter(inPath)
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteBlock([]uint64{10, 20, 30}, []int64{1, 2, 3}))
+	require.NoError(t, writer.FinalizeAndClose())
+
+	in, err := NewReader(inPath)
+	require.NoError(t, err)
+	defer in.Close()
+
+	// Reverse the order of the IDs
+	outPath := filepath.Join(tempDir, "out.col")
+	out, err := NewWriter(outPath)
+	require.NoError(t, err)
+	require.NoError(t, RemapIDs(in, out, map[uint64]uint64{10: 3, 20: 2, 30: 1}))
+	require.NoError(t, out.FinalizeAndClose())
+
+	result, err := NewReader(outPath)
+	require.NoError(t, err)
+	defer result.Close()
+
+	ids, values := readAllPairs(t, result)
+	assert.Equal(t, []uint64{1, 2, 3}, ids)
+	assert.Equal(t, []int64{3, 2, 1}, values)
+
+	// A missing mapping is an error
+	out, err = NewWriter(filepath.Join(tempDir, "missing.col"))
+	require.NoError(t, err)
+	defer out.Close()
+	assert.Error(t, RemapIDs(in, out, map[uint64]uint64{10: 1}))
+}
+
+func TestDenseRemap(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-dense-remap-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	// Large, sparse IDs including one above math.MaxInt64
+	originalIDs := []uint64{1 << 40, 1<<40 + 12345, 1 << 50, math.MaxUint64 - 1}
+	inPath := filepath.Join(tempDir, "in.col")
+	writer, err := NewWriter(inPath)
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteBlock(originalIDs[:2], []int64{1, 2}))
+	require.NoError(t, writer.WriteBlock(originalIDs[2:], []int64{3, 4}))
+	require.NoError(t, writer.FinalizeAndClose())
+
+	in, err := NewReader(inPath)
+	require.NoError(t, err)
+	defer in.Close()
+
+	outPath := filepath.Join(tempDir, "out.col")
+	mappingPath := filepath.Join(tempDir, "mapping.col")
+	out, err := NewWriter(outPath, WithEncoding(EncodingVarIntBoth))
+	require.NoError(t, err)
+	mappingOut, err := NewWriter(mappingPath)
+	require.NoError(t, err)
+
+	require.NoError(t, DenseRemap(in, out, mappingOut))
+	require.NoError(t, out.FinalizeAndClose())
+	require.NoError(t, mappingOut.FinalizeAndClose())
+
+	result, err := NewReader(outPath)
+	require.NoError(t, err)
+	defer result.Close()
+	ids, values := readAllPairs(t, result)
+	assert.Equal(t, []uint64{0, 1, 2, 3}, ids)
+	assert.Equal(t, []int64{1, 2, 3, 4}, values)
+
+	mapping, err := NewReader(mappingPath)
+	require.NoError(t, err)
+	defer mapping.Close()
+	newIDs, mapped := readAllPairs(t, mapping)
+	assert.Equal(t, []uint64{0, 1, 2, 3}, newIDs)
+	for i, v := range mapped {
+		assert.Equal(t, originalIDs[i], uint64(v))
+	}
+}
@@ -0,0 +1,223 @@
+package col
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// maxRepairPadding is the largest gap of zero bytes between two blocks that
+// RebuildFooter skips when looking for the next block. The writer pads blocks
+// to its alignment, which is not recorded in the file.
+const maxRepairPadding = 64 * 1024
+
+// RepairReport describes the result of RebuildFooter
+type RepairReport struct {
+	Blocks    int    // Number of recovered blocks
+	Count     uint64 // Number of recovered ID-value pairs
+	DataEnd   int64  // Offset in the damaged file after the last recovered block
+	Discarded int64  // Bytes after DataEnd, including the padding of the last block
+}
+
+// RebuildFooter repairs a column file whose writer died before Finalize, so
+// the file has a header and blocks but no footer. It scans forward from the
+// first block using the block headers and layouts, and writes the recovered
+// blocks to dst, which gets a regenerated global ID bitmap and footer. The
+// block data is copied without being re-encoded.
+//
+// Every recovered block is decoded and must match the statistics in its block
+// header. The scan stops at the first position where no such block is found,
+// e.g. a block that was only partially written, and the rest of the file is
+// discarded. Footer metadata that was never written, like the value order
+// index and lineage, cannot be recovered.
+func RebuildFooter(filename, dst string) (RepairReport, error) {
+	srcAbs, err := filepath.Abs(filename)
+	if err != nil {
+		return RepairReport{}, fmt.Errorf("failed to resolve source path: %w", err)
+	}
+	dstAbs, err := filepath.Abs(dst)
+	if err != nil {
+		return RepairReport{}, fmt.Errorf("failed to resolve destination path: %w", err)
+	}
+	if srcAbs == dstAbs {
+		return RepairReport{}, fmt.Errorf("destination %q is also the source", dst)
+	}
+
+	// Only the header is read, the footer is missing or damaged
+	in, err := NewReaderLazy(filename)
+	if err != nil {
+		return RepairReport{}, fmt.Errorf("failed to open %q: %w", filename, err)
+	}
+	defer in.closeInput()
+	in.Advise(AdviceSequential)
+
+	writer, err := NewWriter(dst,
+		WithEncoding(in.header.EncodingType),
+		WithDataType(in.header.ColumnType),
+		WithBlockSize(in.header.BlockSizeTarget),
+		WithChecksum(in.header.ChecksumType))
+	if err != nil {
+		return RepairReport{}, err
+	}
+
+	// A failed repair leaves no partial file behind
+	report := RepairReport{DataEnd: headerSize}
+	for {
+		block, ok, err := in.findRawBlock(report.DataEnd)
+		if err != nil {
+			writer.Close()
+			os.Remove(dst)
+			return RepairReport{}, err
+		}
+		if !ok {
+			break
+		}
+
+		if err := writer.appendRawBlock(block.data, block.stats, block.ids); err != nil {
+			writer.Close()
+			os.Remove(dst)
+			return RepairReport{}, fmt.Errorf("failed to copy block at offset %d: %w", block.offset, err)
+		}
+
+		report.Blocks++
+		report.Count += uint64(block.stats.Count)
+		report.DataEnd = block.offset + int64(len(block.data))
+	}
+	report.Discarded = in.fileSize - report.DataEnd
+
+	if err := writer.FinalizeAndClose(); err != nil {
+		os.Remove(dst)
+		return RepairReport{}, err
+	}
+	return report, nil
+}
+
+// recoveredBlock is a block found by RebuildFooter
+type recoveredBlock struct {
+	offset int64
+	data   []byte // Encoded block without padding
+	stats  BlockStats
+	ids    []uint64
+}
+
+// findRawBlock returns the next valid block at or after start. Only zero
+// bytes, i.e. padding, may lie between start and the block.
+func (r *Reader) findRawBlock(start int64) (recoveredBlock, bool, error) {
+	window := r.fileSize - start
+	if window > maxRepairPadding+blockHeaderSize+blockLayoutSize {
+		window = maxRepairPadding + blockHeaderSize + blockLayoutSize
+	}
+	if window < blockHeaderSize+blockLayoutSize {
+		return recoveredBlock{}, false, nil
+	}
+
+	buf, err := r.readBytesAt(start, int(window))
+	if err != nil {
+		return recoveredBlock{}, false, fmt.Errorf("failed to read data at offset %d: %w", start, err)
+	}
+
+	for pos := 0; pos+blockHeaderSize+blockLayoutSize <= len(buf); pos++ {
+		if pos > 0 && buf[pos-1] != 0 {
+			break
+		}
+		offset := start + int64(pos)
+		if !r.plausibleBlockHeader(buf[pos:pos+blockHeaderSize+blockLayoutSize], offset) {
+			continue
+		}
+		if block, err := r.recoverRawBlock(offset); err == nil {
+			return block, true, nil
+		}
+	}
+	return recoveredBlock{}, false, nil
+}
+
+// plausibleBlockHeader cheaply checks whether a block header and layout could
+// start at offset, before the block is decoded
+func (r *Reader) plausibleBlockHeader(buf []byte, offset int64) bool {
+	var header BlockHeader
+	if err := header.UnmarshalBinary(buf[:blockHeaderSize]); err != nil {
+		return false
+	}
+	var layout BlockLayout
+	if err := layout.UnmarshalBinary(buf[blockHeaderSize:]); err != nil {
+		return false
+	}
+	if _, _, err := sectionEncodings(header.EncodingType); err != nil {
+		return false
+	}
+
+	// Every encoding stores an ID or value in at least one byte
+	return header.Count > 0 &&
+		header.Count <= layout.IDSectionSize &&
+		header.Count <= layout.ValueSectionSize &&
+		header.MinID <= header.MaxID &&
+		header.CompressionType == CompressionNone &&
+		layout.IDSectionOffset == 0 &&
+		layout.IDSectionSize > 0 &&
+		layout.ValueSectionOffset == layout.IDSectionSize &&
+		layout.ValueSectionSize > 0 &&
+		uint64(offset)+uint64(len(buf))+layout.DataSize() <= uint64(r.fileSize)
+}
+
+// recoverRawBlock reads and decodes the block at offset. It fails if the block
+// cannot be decoded or does not match the statistics in its header.
+func (r *Reader) recoverRawBlock(offset int64) (recoveredBlock, error) {
+	prefix, err := r.readBytesAt(offset, blockHeaderSize+blockLayoutSize)
+	if err != nil {
+		return recoveredBlock{}, err
+	}
+	var header BlockHeader
+	if err := header.UnmarshalBinary(prefix[:blockHeaderSize]); err != nil {
+		return recoveredBlock{}, err
+	}
+	var layout BlockLayout
+	if err := layout.UnmarshalBinary(prefix[blockHeaderSize:]); err != nil {
+		return recoveredBlock{}, err
+	}
+
+	size, err := intFromFile(blockHeaderSize + blockLayoutSize + layout.DataSize())
+	if err != nil {
+		return recoveredBlock{}, fmt.Errorf("block at offset %d: %w", offset, err)
+	}
+	if offset+int64(size) > r.fileSize {
+		return recoveredBlock{}, fmt.Errorf("block at offset %d exceeds file size", offset)
+	}
+	data, err := r.readBytesAt(offset, size)
+	if err != nil {
+		return recoveredBlock{}, err
+	}
+
+	sections, err := parseBlockSections(data, BlockID(0), int(header.Count))
+	if err != nil {
+		return recoveredBlock{}, err
+	}
+	ids, values, err := decodeBlockDataInto(sections.idBytes, sections.valueBytes, sections.count,
+		sections.encodingType, r.header.ColumnType, nil, nil)
+	if err != nil {
+		return recoveredBlock{}, err
+	}
+
+	var stats BlockStats
+	stats.MinID, stats.MaxID = calculateMinMaxUint64(ids)
+	stats.MinValue, stats.MaxValue = calculateMinMaxInt64(values)
+	stats.Sum = calculateSumInt64(values)
+	stats.Count = uint32(len(ids))
+	stats.SumSquares, stats.NegativeCount, stats.ZeroCount = calculateExtendedStatsInt64(values)
+	if r.header.ColumnType == DataTypeUint64 {
+		stats.unsigned = calculateUnsignedStats(values)
+	}
+
+	recorded := BlockStats{
+		MinID:    header.MinID,
+		MaxID:    header.MaxID,
+		MinValue: uint64ToInt64(header.MinValue),
+		MaxValue: uint64ToInt64(header.MaxValue),
+		Sum:      uint64ToInt64(header.Sum),
+		Count:    header.Count,
+	}
+	if fields := compareBasicStats(recorded, stats); len(fields) > 0 {
+		return recoveredBlock{}, fmt.Errorf("block at offset %d does not match its header: %v", offset, fields)
+	}
+
+	return recoveredBlock{offset: offset, data: data, stats: stats, ids: ids}, nil
+}
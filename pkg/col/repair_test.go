@@ -0,0 +1,129 @@
+package col
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRebuildFooter(t *testing.T) {
+	dir := t.TempDir()
+
+	// writeUnfinished writes blocks of three pairs and closes the writer without
+	// finalizing it, like a process that died before Finalize
+	writeUnfinished := func(t *testing.T, name string, blocks int, options ...WriterOption) (string, []uint64, []int64) {
+		path := filepath.Join(dir, name)
+		writer, err := NewWriter(path, options...)
+		require.NoError(t, err)
+
+		var allIDs []uint64
+		var allValues []int64
+		for b := 0; b < blocks; b++ {
+			ids := []uint64{uint64(b*10 + 1), uint64(b*10 + 2), uint64(b*10 + 5)}
+			values := []int64{int64(-b), 0, int64(b * 100)}
+			require.NoError(t, writer.WriteBlock(ids, values))
+			allIDs = append(allIDs, ids...)
+			allValues = append(allValues, values...)
+		}
+		require.NoError(t, writer.Close())
+		return path, allIDs, allValues
+	}
+
+	// checkRepaired checks that dst holds exactly the given pairs
+	checkRepaired := func(t *testing.T, dst string, ids []uint64, values []int64) {
+		reader, err := NewReaderWithOptions(dst, OpenOptions{Strict: true})
+		require.NoError(t, err)
+		defer reader.Close()
+
+		gotIDs, gotValues := readAllPairs(t, reader)
+		assert.Equal(t, ids, gotIDs)
+		assert.Equal(t, values, gotValues)
+
+		report, err := reader.ValidateFooterAgainstBlocks()
+		require.NoError(t, err)
+		assert.True(t, report.OK(), "%+v", report)
+		require.NoError(t, reader.VerifyChecksum())
+	}
+
+	t.Run("Padding policies and encodings", func(t *testing.T) {
+		for name, options := range map[string][]WriterOption{
+			"aligned.col":     nil,
+			"unpadded.col":    {WithPadding(PaddingNone)},
+			"boundary.col":    {WithPadding(PaddingBoundary(100))},
+			"delta_delta.col": {WithEncoding(EncodingDeltaDelta)},
+			"unsigned.col":    {WithDataType(DataTypeUint64), WithEncoding(EncodingVarIntBoth)},
+		} {
+			path, ids, values := writeUnfinished(t, name, 4, options...)
+
+			_, err := NewReader(path)
+			require.Error(t, err, name)
+
+			dst := filepath.Join(dir, "repaired_"+name)
+			report, err := RebuildFooter(path, dst)
+			require.NoError(t, err, name)
+			assert.Equal(t, 4, report.Blocks, name)
+			assert.Equal(t, uint64(12), report.Count, name)
+			// Only the padding after the last block is left over
+			assert.Less(t, report.Discarded, int64(PageSize), name)
+
+			checkRepaired(t, dst, ids, values)
+		}
+	})
+
+	t.Run("Partially written block", func(t *testing.T) {
+		path, ids, values := writeUnfinished(t, "partial.col", 3, WithPadding(PaddingNone))
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, data[:len(data)-5], 0644))
+
+		dst := filepath.Join(dir, "repaired_partial.col")
+		report, err := RebuildFooter(path, dst)
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Blocks)
+		assert.Equal(t, int64(len(data)-5)-report.DataEnd, report.Discarded)
+		assert.Positive(t, report.Discarded)
+
+		checkRepaired(t, dst, ids[:6], values[:6])
+	})
+
+	t.Run("Finalized file", func(t *testing.T) {
+		path := filepath.Join(dir, "finalized.col")
+		writer, err := NewWriter(path)
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlock([]uint64{1, 2}, []int64{10, 20}))
+		require.NoError(t, writer.WriteBlock([]uint64{3, 4}, []int64{30, 40}))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		// The bitmap and footer after the blocks are not mistaken for blocks
+		dst := filepath.Join(dir, "repaired_finalized.col")
+		report, err := RebuildFooter(path, dst)
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Blocks)
+		assert.Positive(t, report.Discarded)
+
+		checkRepaired(t, dst, []uint64{1, 2, 3, 4}, []int64{10, 20, 30, 40})
+	})
+
+	t.Run("No blocks", func(t *testing.T) {
+		path, _, _ := writeUnfinished(t, "empty.col", 0)
+
+		dst := filepath.Join(dir, "repaired_empty.col")
+		report, err := RebuildFooter(path, dst)
+		require.NoError(t, err)
+		assert.Zero(t, report.Blocks)
+
+		reader, err := NewReader(dst)
+		require.NoError(t, err)
+		defer reader.Close()
+		assert.Zero(t, reader.BlockCount())
+	})
+
+	t.Run("Destination is the source", func(t *testing.T) {
+		path, _, _ := writeUnfinished(t, "same.col", 1)
+		_, err := RebuildFooter(path, path)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,88 @@
+package col
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// DropBlocksBefore writes the pairs of the file at in with an ID of at least
+// cutoff into a new file at out, e.g. to enforce a retention period on columns
+// keyed by timestamp. Blocks entirely before the cutoff are dropped and blocks
+// entirely after it are copied without re-encoding; only a block containing
+// the cutoff is decoded and truncated, keeping its encoding and partition
+// key. The input is
+// recorded as the lineage of the output, see Reader.Lineage.
+func DropBlocksBefore(in, out string, cutoff uint64) error {
+	// Creating out truncates it, so it must not be the input
+	inAbs, err := filepath.Abs(in)
+	if err != nil {
+		return fmt.Errorf("failed to resolve input path: %w", err)
+	}
+	outAbs, err := filepath.Abs(out)
+	if err != nil {
+		return fmt.Errorf("failed to resolve output path: %w", err)
+	}
+	if inAbs == outAbs {
+		return fmt.Errorf("output %q is also the input", out)
+	}
+
+	reader, err := NewReader(in)
+	if err != nil {
+		return fmt.Errorf("failed to open %q: %w", in, err)
+	}
+	defer reader.closeInput()
+	reader.Advise(AdviceSequential)
+
+	writer, err := NewWriter(out,
+		WithEncoding(reader.header.EncodingType),
+		WithDataType(reader.header.ColumnType),
+		WithBlockSize(reader.header.BlockSizeTarget),
+		WithLineage(reader.lineageEntry(in)))
+	if err != nil {
+		return err
+	}
+
+	for blockIdx, entry := range reader.blockIndex {
+		switch {
+		case entry.MaxID < cutoff:
+			continue
+		case entry.MinID >= cutoff:
+			err = copyRawBlocks(reader, []uint64{uint64(blockIdx)}, writer)
+		default:
+			err = truncateBlockBefore(reader, BlockID(blockIdx), writer, cutoff)
+		}
+		if err != nil {
+			writer.Close()
+			return err
+		}
+	}
+
+	return writer.FinalizeAndClose()
+}
+
+// truncateBlockBefore writes the pairs of a block with an ID of at least cutoff
+// as a new block with the encoding and partition key of the original one
+func truncateBlockBefore(in *Reader, blockIdx BlockID, w *Writer, cutoff uint64) error {
+	meta, err := in.BlockMeta(blockIdx)
+	if err != nil {
+		return err
+	}
+	ids, values, err := in.ReadBlock(blockIdx)
+	if err != nil {
+		return fmt.Errorf("failed to read block %d: %w", blockIdx, err)
+	}
+
+	keptIDs := make([]uint64, 0, len(ids))
+	keptValues := make([]int64, 0, len(values))
+	for i, id := range ids {
+		if id >= cutoff {
+			keptIDs = append(keptIDs, id)
+			keptValues = append(keptValues, values[i])
+		}
+	}
+
+	if err := writeAllBlocks(w, keptIDs, keptValues, WithBlockEncoding(meta.Encoding), WithBlockPartition(meta.Partition)); err != nil {
+		return fmt.Errorf("failed to write truncated block %d: %w", blockIdx, err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package col
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDropBlocksBefore(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-retention-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	// Three blocks of timestamps; the second one uses an encoding of its own
+	inPath := filepath.Join(tempDir, "in.col")
+	writer, err := NewWriter(inPath, WithEncoding(EncodingVarIntBoth))
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteBlock([]uint64{100, 110, 120}, []int64{1, 2, 3}))
+	require.NoError(t, writer.WriteBlockWithOptions([]uint64{200, 210, 220, 230}, []int64{4, 5, 6, 7},
+		WithBlockEncoding(EncodingDeltaBoth)))
+	require.NoError(t, writer.WriteBlock([]uint64{300, 310}, []int64{8, 9}))
+	require.NoError(t, writer.FinalizeAndClose())
+
+	dropBefore := func(t *testing.T, name string, cutoff uint64) *Reader {
+		outPath := filepath.Join(tempDir, name)
+		require.NoError(t, DropBlocksBefore(inPath, outPath, cutoff))
+		out, err := NewReader(outPath)
+		require.NoError(t, err)
+		t.Cleanup(func() { out.Close() })
+
+		report, err := out.ValidateFooterAgainstBlocks()
+		require.NoError(t, err)
+		assert.True(t, report.OK(), "%+v", report)
+		return out
+	}
+
+	t.Run("Truncates the block containing the cutoff", func(t *testing.T) {
+		out := dropBefore(t, "truncated.col", 215)
+
+		ids, values := readAllPairs(t, out)
+		assert.Equal(t, []uint64{220, 230, 300, 310}, ids)
+		assert.Equal(t, []int64{6, 7, 8, 9}, values)
+
+		require.Equal(t, uint64(2), out.BlockCount())
+		meta, err := out.BlockMeta(0)
+		require.NoError(t, err)
+		assert.Equal(t, EncodingDeltaBoth, meta.Encoding)
+		assert.Equal(t, uint64(220), meta.MinID)
+
+		globalIDs, err := out.GetGlobalIDBitmap()
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{220, 230, 300, 310}, globalIDs.ToArray())
+
+		lineage, err := out.Lineage()
+		require.NoError(t, err)
+		require.Len(t, lineage, 1)
+		assert.Equal(t, inPath, lineage[0].Source)
+	})
+
+	t.Run("Drops whole blocks", func(t *testing.T) {
+		out := dropBefore(t, "dropped.col", 200)
+		ids, _ := readAllPairs(t, out)
+		assert.Equal(t, []uint64{200, 210, 220, 230, 300, 310}, ids)
+		assert.Equal(t, uint64(2), out.BlockCount())
+	})
+
+	t.Run("Cutoff outside the ID range", func(t *testing.T) {
+		all := dropBefore(t, "all.col", 0)
+		ids, _ := readAllPairs(t, all)
+		assert.Len(t, ids, 9)
+
+		none := dropBefore(t, "none.col", 1000)
+		assert.Equal(t, uint64(0), none.BlockCount())
+	})
+
+	t.Run("Keeps partition keys", func(t *testing.T) {
+		partitionedPath := filepath.Join(tempDir, "partitioned.col")
+		writer, err := NewWriter(partitionedPath)
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlockWithOptions([]uint64{100, 110}, []int64{1, 2}, WithBlockPartition(3)))
+		require.NoError(t, writer.WriteBlockWithOptions([]uint64{200, 210}, []int64{3, 4}, WithBlockPartition(5)))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		outPath := filepath.Join(tempDir, "partitioned-out.col")
+		require.NoError(t, DropBlocksBefore(partitionedPath, outPath, 105))
+		out, err := NewReader(outPath)
+		require.NoError(t, err)
+		defer out.Close()
+
+		require.Equal(t, uint64(2), out.BlockCount())
+		for block, expected := range []uint64{3, 5} {
+			partition, err := out.BlockPartition(BlockID(block))
+			require.NoError(t, err)
+			assert.Equal(t, expected, partition)
+		}
+	})
+
+	t.Run("Output is the input", func(t *testing.T) {
+		assert.Error(t, DropBlocksBefore(inPath, inPath, 150))
+	})
+}
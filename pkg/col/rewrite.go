@@ -0,0 +1,96 @@
+package col
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// RewriteOptions configures Rewrite
+type RewriteOptions struct {
+	// TargetBlockSize is the target size of the output blocks in bytes.
+	// 0 keeps the block size target of the input file.
+	TargetBlockSize uint32
+
+	// Encoding is the encoding of the output file
+	Encoding uint32
+
+	// Compression is the compression of the output blocks
+	Compression uint32
+
+	// RateLimiter limits the throughput of reading the input and writing the
+	// output, so the rewrite can run in the background. nil means unlimited.
+	RateLimiter *RateLimiter
+}
+
+// Rewrite re-chunks the file at in into a new file at out with the block size
+// and encoding given by opts, e.g. to split oversized blocks into smaller ones
+// for finer-grained pruning. Blocks are streamed one at a time, so at most one
+// input block and one output block are held in memory. Pairs keep their order;
+// within an output block IDs are sorted. The input is recorded as the lineage of
+// the output, see Reader.Lineage. Files with partition keys are rejected, see
+// WithBlockPartition, since re-chunking would merge blocks of different
+// partitions.
+func Rewrite(in, out string, opts RewriteOptions) error {
+	if opts.Compression != CompressionNone {
+		return fmt.Errorf("unsupported compression type: %d", opts.Compression)
+	}
+	if _, _, err := sectionEncodings(opts.Encoding); err != nil {
+		return err
+	}
+
+	// Creating out truncates it, so it must not be the input
+	inAbs, err := filepath.Abs(in)
+	if err != nil {
+		return fmt.Errorf("failed to resolve input path: %w", err)
+	}
+	outAbs, err := filepath.Abs(out)
+	if err != nil {
+		return fmt.Errorf("failed to resolve output path: %w", err)
+	}
+	if inAbs == outAbs {
+		return fmt.Errorf("output %q is also the input", out)
+	}
+
+	reader, err := NewReader(in)
+	if err != nil {
+		return fmt.Errorf("failed to open %q: %w", in, err)
+	}
+	defer reader.closeInput()
+	if reader.partitioned() {
+		return fmt.Errorf("cannot rewrite partitioned file %q", in)
+	}
+	reader.Advise(AdviceSequential)
+	reader.SetRateLimiter(opts.RateLimiter)
+
+	targetBlockSize := opts.TargetBlockSize
+	if targetBlockSize == 0 {
+		targetBlockSize = reader.header.BlockSizeTarget
+	}
+
+	writer, err := NewSimpleWriter(out,
+		WithEncoding(opts.Encoding),
+		WithDataType(reader.header.ColumnType),
+		WithBlockSize(targetBlockSize),
+		WithLineage(reader.lineageEntry(in)),
+		WithRateLimiter(opts.RateLimiter))
+	if err != nil {
+		return err
+	}
+
+	idsBuf := make([]uint64, 0)
+	valuesBuf := make([]int64, 0)
+	for blockIdx := uint64(0); blockIdx < reader.BlockCount(); blockIdx++ {
+		ids, values, err := reader.ReadBlockInto(BlockID(blockIdx), idsBuf, valuesBuf)
+		if err != nil {
+			writer.writer.Close()
+			return fmt.Errorf("failed to read block %d: %w", blockIdx, err)
+		}
+		if err := writer.Write(ids, values); err != nil {
+			writer.writer.Close()
+			return fmt.Errorf("failed to write pairs of block %d: %w", blockIdx, err)
+		}
+		idsBuf, valuesBuf = ids, values
+	}
+
+	return writer.Close()
+}
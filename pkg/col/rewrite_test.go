@@ -0,0 +1,107 @@
+package col
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewrite(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-rewrite-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	// A single oversized block, as written with a too large block size
+	const count = 5000
+	ids := make([]uint64, count)
+	values := make([]int64, count)
+	for i := range ids {
+		ids[i] = uint64(i * 3)
+		values[i] = int64(i%100 - 50)
+	}
+
+	inPath := filepath.Join(tempDir, "in.col")
+	writer, err := NewWriter(inPath, WithBlockSize(1<<20))
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteBlock(ids, values))
+	require.NoError(t, writer.FinalizeAndClose())
+
+	in, err := NewReader(inPath)
+	require.NoError(t, err)
+	defer in.Close()
+	require.Equal(t, uint64(1), in.BlockCount())
+
+	t.Run("Split into smaller blocks", func(t *testing.T) {
+		outPath := filepath.Join(tempDir, "split.col")
+		require.NoError(t, Rewrite(inPath, outPath, RewriteOptions{
+			TargetBlockSize: 8192,
+			Encoding:        EncodingVarIntBoth,
+		}))
+
+		out, err := NewReader(outPath)
+		require.NoError(t, err)
+		defer out.Close()
+
+		assert.Equal(t, EncodingVarIntBoth, out.EncodingType())
+		assert.Greater(t, out.BlockCount(), uint64(1))
+		for id := BlockID(0); id < BlockID(out.BlockCount()); id++ {
+			meta, err := out.BlockMeta(id)
+			require.NoError(t, err)
+			assert.LessOrEqual(t, meta.Size, uint32(8192))
+		}
+
+		allIDs, allValues := readAllPairs(t, out)
+		assert.Equal(t, ids, allIDs)
+		assert.Equal(t, values, allValues)
+		assert.Equal(t, in.Aggregate(), out.Aggregate())
+
+		lineage, err := out.Lineage()
+		require.NoError(t, err)
+		assert.Equal(t, []LineageEntry{{
+			Source:       inPath,
+			CreationTime: in.HeaderOnly().CreationTime,
+			MinID:        0,
+			MaxID:        uint64((count - 1) * 3),
+			Count:        count,
+		}}, lineage)
+	})
+
+	t.Run("Keep the block size target", func(t *testing.T) {
+		outPath := filepath.Join(tempDir, "reencoded.col")
+		require.NoError(t, Rewrite(inPath, outPath, RewriteOptions{Encoding: EncodingDeltaBoth}))
+
+		out, err := NewReader(outPath)
+		require.NoError(t, err)
+		defer out.Close()
+
+		assert.Equal(t, uint64(1), out.BlockCount())
+		allIDs, allValues := readAllPairs(t, out)
+		assert.Equal(t, ids, allIDs)
+		assert.Equal(t, values, allValues)
+	})
+
+	t.Run("Invalid options", func(t *testing.T) {
+		outPath := filepath.Join(tempDir, "invalid.col")
+		assert.Error(t, Rewrite(inPath, outPath, RewriteOptions{Compression: 99}))
+		assert.Error(t, Rewrite(inPath, outPath, RewriteOptions{Encoding: 99}))
+		assert.Error(t, Rewrite(inPath, inPath, RewriteOptions{}))
+		assert.Error(t, Rewrite(filepath.Join(tempDir, "missing.col"), outPath, RewriteOptions{}))
+	})
+
+	t.Run("Partitioned files are rejected", func(t *testing.T) {
+		partitionedPath := filepath.Join(tempDir, "partitioned.col")
+		writer, err := NewWriter(partitionedPath)
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlockWithOptions([]uint64{1, 2}, []int64{1, 2}, WithBlockPartition(1)))
+		require.NoError(t, writer.WriteBlockWithOptions([]uint64{3, 4}, []int64{3, 4}, WithBlockPartition(2)))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		outPath := filepath.Join(tempDir, "partitioned-out.col")
+		assert.ErrorContains(t, Rewrite(partitionedPath, outPath, RewriteOptions{}), "partitioned")
+		_, err = os.Stat(outPath)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
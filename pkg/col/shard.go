@@ -0,0 +1,120 @@
+package col
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Shard splits the file at in into len(boundaries)+1 files partitioned by ID range.
+// Shard i contains the IDs in [boundaries[i-1], boundaries[i]), where the first
+// shard has no lower bound and the last shard has no upper bound. boundaries must
+// be strictly increasing. outPattern is a fmt pattern with a single integer verb
+// for the shard number, e.g. "events-%03d.col".
+//
+// Blocks whose ID range falls entirely inside one shard are copied byte-for-byte;
+// only blocks spanning a boundary are decoded and re-encoded. Every shard file is
+// created, even if it ends up empty, and removed again if sharding fails. The
+// paths of the shard files are returned.
+func Shard(in string, boundaries []uint64, outPattern string) ([]string, error) {
+	for i := 1; i < len(boundaries); i++ {
+		if boundaries[i] <= boundaries[i-1] {
+			return nil, fmt.Errorf("boundaries must be strictly increasing: %d follows %d",
+				boundaries[i], boundaries[i-1])
+		}
+	}
+
+	reader, err := NewReader(in)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.closeInput()
+	reader.Advise(AdviceSequential)
+
+	// shardFor returns the index of the shard that contains the ID
+	shardFor := func(id uint64) int {
+		return sort.Search(len(boundaries), func(i int) bool { return boundaries[i] > id })
+	}
+
+	// Create one writer per shard
+	paths := make([]string, len(boundaries)+1)
+	writers := make([]*Writer, len(paths))
+	// closeAll closes the shard writers and removes the files created so far,
+	// so a failed shard leaves no partial files behind
+	closeAll := func() {
+		for i, w := range writers {
+			if w != nil {
+				w.Close()
+				os.Remove(paths[i])
+			}
+		}
+	}
+	for i := range paths {
+		paths[i] = fmt.Sprintf(outPattern, i)
+		writers[i], err = NewWriter(paths[i],
+			WithEncoding(reader.header.EncodingType),
+			WithDataType(reader.header.ColumnType),
+			WithBlockSize(reader.header.BlockSizeTarget))
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("failed to create shard %d: %w", i, err)
+		}
+	}
+
+	for blockIdx, entry := range reader.blockIndex {
+		// Copy blocks that belong to a single shard without decoding the data
+		shard := shardFor(entry.MinID)
+		if shard == shardFor(entry.MaxID) {
+			if err := copyRawBlocks(reader, []uint64{uint64(blockIdx)}, writers[shard]); err != nil {
+				closeAll()
+				return nil, err
+			}
+			continue
+		}
+
+		// Blocks spanning a boundary are split and re-encoded, keeping their
+		// encoding and partition key
+		meta, err := reader.BlockMeta(BlockID(blockIdx))
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		ids, values, err := reader.ReadBlock(BlockID(blockIdx))
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("failed to read block %d: %w", blockIdx, err)
+		}
+
+		shardIDs := make([][]uint64, len(writers))
+		shardValues := make([][]int64, len(writers))
+		for i, id := range ids {
+			s := shardFor(id)
+			shardIDs[s] = append(shardIDs[s], id)
+			shardValues[s] = append(shardValues[s], values[i])
+		}
+
+		for s := range writers {
+			if err := writeAllBlocks(writers[s], shardIDs[s], shardValues[s],
+				WithBlockEncoding(meta.Encoding), WithBlockPartition(meta.Partition)); err != nil {
+				closeAll()
+				return nil, fmt.Errorf("failed to write block %d to shard %d: %w", blockIdx, s, err)
+			}
+		}
+	}
+
+	for i, w := range writers {
+		if err := w.FinalizeAndClose(); err != nil {
+			// Close the writers that haven't been finalized yet and remove
+			// every shard file
+			for _, rest := range writers[i+1:] {
+				rest.Close()
+			}
+			for _, path := range paths {
+				os.Remove(path)
+			}
+			return nil, fmt.Errorf("failed to finalize shard %d: %w", i, err)
+		}
+	}
+
+	return paths, nil
+}
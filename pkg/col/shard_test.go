@@ -0,0 +1,83 @@
+package col
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShard(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-shard-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	inPath := filepath.Join(tempDir, "in.col")
+	writer, err := NewWriter(inPath, WithEncoding(EncodingVarIntBoth))
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteBlock([]uint64{1, 2, 3}, []int64{10, 20, 30}))
+	// This block spans the boundary at 10
+	require.NoError(t, writer.WriteBlock([]uint64{8, 9, 10, 11}, []int64{80, 90, 100, 110}))
+	require.NoError(t, writer.WriteBlock([]uint64{20, 25}, []int64{200, 250}))
+	require.NoError(t, writer.FinalizeAndClose())
+
+	paths, err := Shard(inPath, []uint64{10, 100, 1000}, filepath.Join(tempDir, "shard-%d.col"))
+	require.NoError(t, err)
+	require.Len(t, paths, 4)
+
+	expectedIDs := [][]uint64{{1, 2, 3, 8, 9}, {10, 11, 20, 25}, nil, nil}
+	expectedBlocks := []uint64{2, 2, 0, 0}
+	for i, path := range paths {
+		reader, err := NewReader(path)
+		require.NoError(t, err)
+
+		assert.Equal(t, expectedBlocks[i], reader.BlockCount(), "shard %d", i)
+		ids, values := readAllPairs(t, reader)
+		assert.Equal(t, expectedIDs[i], ids, "shard %d", i)
+		for j, id := range ids {
+			assert.Equal(t, int64(id)*10, values[j])
+		}
+
+		// Footer statistics must match the data
+		assert.Equal(t, aggregateValues(reader.Aggregate()), aggregateValues(reader.AggregateWithOptions(AggregateOptions{SkipPreCalculated: true})))
+		require.NoError(t, reader.Close())
+	}
+
+	// Boundaries must be strictly increasing
+	_, err = Shard(inPath, []uint64{10, 10}, filepath.Join(tempDir, "bad-%d.col"))
+	assert.Error(t, err)
+}
+
+func TestShardRemovesOutputOnError(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-shard-error-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	inPath := filepath.Join(tempDir, "in.col")
+	writer, err := NewWriter(inPath, WithChecksum(ChecksumNone))
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteBlock([]uint64{1, 2}, []int64{10, 20}))
+	require.NoError(t, writer.WriteBlock([]uint64{8, 9, 10, 11}, []int64{80, 90, 100, 110}))
+	require.NoError(t, writer.FinalizeAndClose())
+
+	// Block 1 spans the boundary and gets an ID section size of 0, so it
+	// cannot be decoded
+	in, err := NewReader(inPath)
+	require.NoError(t, err)
+	data, err := os.ReadFile(inPath)
+	require.NoError(t, err)
+	binary.LittleEndian.PutUint32(data[in.blockIndex[1].BlockOffset+blockHeaderSize+4:], 0)
+	require.NoError(t, in.Close())
+	require.NoError(t, os.WriteFile(inPath, data, 0644))
+
+	_, err = Shard(inPath, []uint64{10}, filepath.Join(tempDir, "shard-%d.col"))
+	assert.Error(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = os.Stat(filepath.Join(tempDir, fmt.Sprintf("shard-%d.col", i)))
+		assert.True(t, os.IsNotExist(err), "Shard %d should be removed", i)
+	}
+}
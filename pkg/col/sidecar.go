@@ -0,0 +1,157 @@
+package col
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"vibe-lsm/pkg/col/format"
+)
+
+// SidecarPath returns the path of the metadata sidecar written next to the
+// column file at path, e.g. "data.colx" for "data.col"
+func SidecarPath(path string) string {
+	return strings.TrimSuffix(path, ".col") + ".colx"
+}
+
+// WithSidecar makes Finalize also write the header, footer and statistics of
+// the file to a small sidecar next to it, see SidecarPath. Query planners can
+// open thousands of cold files with OpenSidecar and only fetch a data file
+// once its blocks are read. The data file keeps its own footer and remains
+// readable on its own. Sidecars require a writer created with NewWriter.
+func WithSidecar() WriterOption {
+	return func(w *Writer) {
+		w.sidecar = true
+	}
+}
+
+// writeSidecar writes the sidecar of the finalized file. The sidecar consists
+// of the file header, the footer with an additional data file section and
+// the footer metadata, whose checksum is the one of the data file.
+func (w *Writer) writeSidecar(headerBuf []byte, footer format.Footer, meta FooterMetadata) error {
+	dataSize, err := w.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("failed to get file size: %w", err)
+	}
+
+	payload := make([]byte, uint64Size)
+	binary.LittleEndian.PutUint64(payload, uint64(dataSize))
+	footer.Sections = append(footer.Sections, format.FooterSection{Type: FooterSectionDataFile, Payload: payload})
+	footerBuf, err := footer.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	meta.FooterSize = uint64(len(footerBuf))
+	metaBuf, err := meta.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	sidecar := make([]byte, 0, len(headerBuf)+len(footerBuf)+len(metaBuf))
+	sidecar = append(append(append(sidecar, headerBuf...), footerBuf...), metaBuf...)
+	if err := writeFileAtomically(SidecarPath(w.path), sidecar); err != nil {
+		return fmt.Errorf("failed to write sidecar: %w", err)
+	}
+	return nil
+}
+
+// parseDataFileSection parses the footer section of a sidecar recording the
+// size of the data file
+func (r *Reader) parseDataFileSection(payload []byte) error {
+	if len(payload) != uint64Size {
+		return fmt.Errorf("data file section size mismatch: expected=%d, actual=%d",
+			uint64Size, len(payload))
+	}
+	r.dataFileSize = int64(readBufferedUint64(payload, 0))
+	return nil
+}
+
+// OpenSidecar opens the column file at path from its metadata sidecar, see
+// WithSidecar. Only the sidecar is read when opening: metadata such as the
+// block index, the statistics and aggregations answered from the footer do
+// not touch the data file. The data file is opened on the first access to
+// its blocks or its ID bitmap, which fails if its size differs from the one
+// recorded in the sidecar.
+func OpenSidecar(path string) (*Reader, error) {
+	buf, err := os.ReadFile(SidecarPath(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sidecar: %w", err)
+	}
+
+	reader, err := newReader(bytesFile{bytes.NewReader(buf)}, int64(len(buf)))
+	if err != nil {
+		return nil, err
+	}
+	if err := reader.ensureFooter(); err != nil {
+		return nil, err
+	}
+	if reader.dataFileSize == 0 {
+		return nil, errors.New("sidecar has no data file section")
+	}
+
+	// From here on the reader describes the data file, as if it had been
+	// opened directly
+	last := len(reader.footerSections) - 1
+	if reader.footerSections[last].Type == FooterSectionDataFile {
+		reader.footerMeta.FooterSize -= footerSectionHeaderSize + uint64(reader.footerSections[last].Size)
+		reader.footerSections = reader.footerSections[:last]
+	}
+	reader.file = &lazyFile{path: path, size: reader.dataFileSize}
+	reader.fileSize = reader.dataFileSize
+	reader.filename = path
+	return reader, nil
+}
+
+// lazyFile is a file that is opened on the first read. Opening fails if the
+// file does not have the expected size, e.g. because it was rewritten after
+// the sidecar.
+type lazyFile struct {
+	path string
+	size int64
+	once sync.Once
+	file *os.File
+	err  error
+}
+
+func (f *lazyFile) open() {
+	f.file, f.err = os.Open(f.path)
+	if f.err != nil {
+		f.err = fmt.Errorf("failed to open data file: %w", f.err)
+		return
+	}
+
+	info, err := f.file.Stat()
+	if err != nil {
+		f.err = fmt.Errorf("failed to stat data file: %w", err)
+	} else if info.Size() != f.size {
+		f.err = fmt.Errorf("data file size mismatch: sidecar=%d, actual=%d", f.size, info.Size())
+	}
+	if f.err != nil {
+		f.file.Close()
+		f.file = nil
+	}
+}
+
+// ReadAt opens the file if needed and reads from it
+func (f *lazyFile) ReadAt(p []byte, off int64) (int, error) {
+	f.once.Do(f.open)
+	if f.err != nil {
+		return 0, f.err
+	}
+	return f.file.ReadAt(p, off)
+}
+
+// Close closes the file if it was opened
+func (f *lazyFile) Close() error {
+	f.once.Do(func() { f.err = os.ErrClosed })
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Close()
+}
@@ -0,0 +1,120 @@
+package col
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSidecar(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cold.col")
+	writer, err := NewWriter(path, WithSidecar())
+	require.NoError(t, err)
+	for b := uint64(0); b < 3; b++ {
+		ids := make([]uint64, 500)
+		values := make([]int64, 500)
+		for i := range ids {
+			ids[i] = b*500 + uint64(i)
+			values[i] = int64(ids[i]) - 700
+		}
+		require.NoError(t, writer.WriteBlock(ids, values))
+	}
+	require.NoError(t, writer.FinalizeAndClose())
+	assert.Equal(t, filepath.Join(dir, "cold.colx"), SidecarPath(path))
+
+	direct, err := NewReader(path)
+	require.NoError(t, err)
+	defer direct.Close()
+
+	t.Run("Metadata", func(t *testing.T) {
+		reader, err := OpenSidecar(path)
+		require.NoError(t, err)
+		defer reader.Close()
+
+		assert.Equal(t, direct.HeaderOnly(), reader.HeaderOnly())
+		assert.Equal(t, direct.Aggregate(), reader.Aggregate())
+
+		directInfo, err := direct.FooterInfo()
+		require.NoError(t, err)
+		info, err := reader.FooterInfo()
+		require.NoError(t, err)
+		assert.Equal(t, directInfo, info)
+
+		directStats, err := direct.Stats()
+		require.NoError(t, err)
+		stats, err := reader.Stats()
+		require.NoError(t, err)
+		assert.Equal(t, directStats, stats)
+	})
+
+	t.Run("Data", func(t *testing.T) {
+		reader, err := OpenSidecar(path)
+		require.NoError(t, err)
+		defer reader.Close()
+
+		value, found, err := reader.Get(1200)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, int64(500), value)
+
+		assert.Equal(t, direct.AggregateWithOptions(AggregateOptions{SkipPreCalculated: true}),
+			reader.AggregateWithOptions(AggregateOptions{SkipPreCalculated: true}))
+		require.NoError(t, reader.VerifyChecksum())
+
+		bitmap, err := reader.GetGlobalIDBitmap()
+		require.NoError(t, err)
+		assert.Equal(t, 1500, bitmap.GetCardinality())
+	})
+
+	t.Run("Without data file", func(t *testing.T) {
+		moved := filepath.Join(dir, "moved.col")
+		require.NoError(t, os.Rename(path, moved))
+		defer os.Rename(moved, path)
+
+		reader, err := OpenSidecar(path)
+		require.NoError(t, err)
+		defer reader.Close()
+
+		fileStats, err := reader.FileStats()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1500), fileStats.Count)
+		assert.Equal(t, uint64(3), reader.BlockCount())
+
+		_, _, err = reader.ReadBlock(0)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("Stale sidecar", func(t *testing.T) {
+		// The data file was rewritten without its sidecar
+		rewritten := filepath.Join(dir, "rewritten.col")
+		sidecar, err := os.ReadFile(SidecarPath(path))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(SidecarPath(rewritten), sidecar, 0644))
+		writer, err := NewWriter(rewritten)
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlock([]uint64{1}, []int64{1}))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		reader, err := OpenSidecar(rewritten)
+		require.NoError(t, err)
+		defer reader.Close()
+
+		_, _, err = reader.ReadBlock(0)
+		assert.ErrorContains(t, err, "data file size mismatch")
+	})
+
+	t.Run("Without sidecar", func(t *testing.T) {
+		plain := filepath.Join(dir, "plain.col")
+		writer, err := NewWriter(plain)
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlock([]uint64{1}, []int64{1}))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		_, err = OpenSidecar(plain)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
@@ -2,6 +2,7 @@ package col
 
 import (
 	"fmt"
+	"io"
 	"sort"
 )
 
@@ -12,31 +13,63 @@ type SimpleWriter struct {
 	filename        string
 	pendingIDs      []uint64
 	pendingValues   []int64
+	pendingDataSize uint64 // Encoded size of the pending items if written as one block
 	targetBlockSize int
+	maxPendingItems int     // Maximum number of items per block, 0 means no limit
+	sortOnWrite     bool    // Whether Write sorts unsorted input
+	adaptive        bool    // Whether block sizes are tuned from measured bytes per item
+	bytesPerItem    float64 // Last measured block bytes per item in adaptive mode, 0 if unknown
+	writerOptions   []WriterOption
 	closed          bool
 	totalItems      uint64 // Track total number of items written
 }
 
-// NewSimpleWriter creates a new SimpleWriter for the given filename
-func NewSimpleWriter(filename string, options ...WriterOption) (*SimpleWriter, error) {
-	// Default target block size
-	targetBlockSize := 128 * 1024 // 128KB default block size
+// defaultMaxPendingItems is the default maximum number of items per block.
+// Blocks are only limited by their encoded size by default.
+const defaultMaxPendingItems = 0
 
-	// Create the underlying writer
-	writer, err := NewWriter(filename, options...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create writer: %w", err)
-	}
-
-	return &SimpleWriter{
-		writer:          writer,
+// NewSimpleWriter creates a new SimpleWriter for the given filename.
+// Options may be SimpleWriterOptions or WriterOptions; the latter are applied
+// to the underlying Writer.
+func NewSimpleWriter(filename string, options ...SimpleWriterOption) (*SimpleWriter, error) {
+	sw := &SimpleWriter{
 		filename:        filename,
 		pendingIDs:      make([]uint64, 0),
 		pendingValues:   make([]int64, 0),
-		targetBlockSize: targetBlockSize,
+		maxPendingItems: defaultMaxPendingItems,
+		sortOnWrite:     true,
 		closed:          false,
 		totalItems:      0,
-	}, nil
+	}
+
+	// Apply options
+	for _, option := range options {
+		option.applySimpleWriter(sw)
+	}
+
+	if sw.targetBlockSize < 0 || uint64(sw.targetBlockSize) > maxBlockSize {
+		return nil, fmt.Errorf("target block size must be between 0 and %d, got %d", uint64(maxBlockSize), sw.targetBlockSize)
+	}
+	if sw.maxPendingItems < 0 {
+		return nil, fmt.Errorf("max pending items must not be negative, got %d", sw.maxPendingItems)
+	}
+
+	// An explicit target block size overrides the one from the writer options
+	writerOptions := sw.writerOptions
+	if sw.targetBlockSize > 0 {
+		writerOptions = append(writerOptions, WithBlockSize(uint32(sw.targetBlockSize)))
+	}
+
+	// Create the underlying writer
+	writer, err := NewWriter(filename, writerOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create writer: %w", err)
+	}
+
+	sw.writer = writer
+	sw.targetBlockSize = int(writer.blockSizeTarget)
+
+	return sw, nil
 }
 
 // SetTargetBlockSize sets the target block size for the writer
@@ -44,6 +77,9 @@ func (sw *SimpleWriter) SetTargetBlockSize(size int) error {
 	if sw.closed {
 		return fmt.Errorf("writer is already closed")
 	}
+	if size < 0 || uint64(size) > maxBlockSize {
+		return fmt.Errorf("target block size must be between 0 and %d, got %d", uint64(maxBlockSize), size)
+	}
 
 	sw.targetBlockSize = size
 
@@ -54,7 +90,8 @@ func (sw *SimpleWriter) SetTargetBlockSize(size int) error {
 }
 
 // Write adds ID-value pairs to the file
-// If the IDs are not sorted, they will be sorted automatically
+// If the IDs are not sorted, they will be sorted automatically unless sorting
+// was disabled with WithSortOnWrite(false), in which case an error is returned
 func (sw *SimpleWriter) Write(ids []uint64, values []int64) error {
 	if sw.closed {
 		return fmt.Errorf("writer is already closed")
@@ -76,17 +113,38 @@ func (sw *SimpleWriter) Write(ids []uint64, values []int64) error {
 
 	// Sort the data by ID if necessary
 	if !isSorted(newIDs) {
+		if !sw.sortOnWrite {
+			return fmt.Errorf("ids are not sorted in ascending order")
+		}
 		sortByID(newIDs, newValues)
 	}
 
-	// Add to pending data
+	// Add to pending data, keeping track of its encoded size. In adaptive mode
+	// the size is only measured when a block is about to be written.
+	start := len(sw.pendingIDs)
 	sw.pendingIDs = append(sw.pendingIDs, newIDs...)
 	sw.pendingValues = append(sw.pendingValues, newValues...)
+	if !sw.adaptive {
+		for i := start; i < len(sw.pendingIDs); i++ {
+			sw.pendingDataSize += sw.writer.encodedPairSize(sw.pendingIDs, sw.pendingValues, i)
+		}
+	}
 
 	// Check if we have enough data to write a block
 	return sw.flushIfNeeded(false)
 }
 
+// Flush writes all pending items, so the next Write starts a new block. The
+// pending items may be split into several blocks to stay within the target
+// block size.
+func (sw *SimpleWriter) Flush() error {
+	if sw.closed {
+		return fmt.Errorf("writer is already closed")
+	}
+
+	return sw.flushIfNeeded(true)
+}
+
 // Close finalizes the file and closes it
 func (sw *SimpleWriter) Close() error {
 	if sw.closed {
@@ -117,48 +175,168 @@ func (sw *SimpleWriter) TotalItems() uint64 {
 	return sw.totalItems
 }
 
-// flushIfNeeded writes a block if there's enough data or if force is true
+// flushIfNeeded writes a block for every target block size worth of pending
+// data. If force is true, the remaining pending data is written as well.
 func (sw *SimpleWriter) flushIfNeeded(force bool) error {
-	// If we don't have any data, there's nothing to flush
-	if len(sw.pendingIDs) == 0 {
-		return nil
+	for len(sw.pendingIDs) > 0 {
+		if sw.adaptive {
+			n, err := sw.adaptiveItemsForNextBlock(force)
+			if err != nil {
+				return err
+			}
+			if n == 0 {
+				return nil
+			}
+			if err := sw.writePendingBlock(n); err != nil {
+				return err
+			}
+			continue
+		}
+
+		n, err := sw.itemsForNextBlock()
+		if err != nil {
+			return err
+		}
+
+		// Keep buffering until the pending data exceeds a block, unless forced
+		if n == len(sw.pendingIDs) && !force {
+			return nil
+		}
+
+		if err := sw.writePendingBlock(n); err != nil {
+			return err
+		}
 	}
 
-	// Determine if we should write a block
-	shouldWrite := force
+	return nil
+}
 
-	// If not forced, check if we have enough data to write a block
-	if !force {
-		// Try to write a block when we have a reasonable amount of data
-		// This ensures we create multiple blocks for large datasets
-		shouldWrite = len(sw.pendingIDs) >= 1000 // Try to write after accumulating 1000 items
+// itemsForNextBlock returns how many of the pending items fit into the next block
+// without exceeding the target block size. At least one item is always returned,
+// as a single item larger than the target still needs to be written.
+func (sw *SimpleWriter) itemsForNextBlock() (int, error) {
+	limit := len(sw.pendingIDs)
+	if sw.maxPendingItems > 0 && sw.maxPendingItems < limit {
+		limit = sw.maxPendingItems
 	}
 
-	if shouldWrite {
-		// Try to write all pending items
-		err := sw.writer.WriteBlock(sw.pendingIDs, sw.pendingValues)
+	blockStart, err := sw.writer.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get current position: %w", err)
+	}
 
-		// Check if the block was full
-		if blockFullErr, ok := err.(*BlockFullError); ok {
-			// Block was full, update total items count with what was written
-			itemsWritten := blockFullErr.ItemsWritten
-			sw.totalItems += uint64(itemsWritten)
+	// Fast path: everything that is pending fits
+	target := uint64(sw.targetBlockSize)
+	if limit == len(sw.pendingIDs) && sw.writer.paddedBlockSize(blockStart, sw.pendingDataSize) <= target {
+		return limit, nil
+	}
+
+	var dataSize uint64
+	for i := 0; i < limit; i++ {
+		dataSize += sw.writer.encodedPairSize(sw.pendingIDs, sw.pendingValues, i)
+		if sw.writer.paddedBlockSize(blockStart, dataSize) > target && i > 0 {
+			return i, nil
+		}
+	}
+
+	return limit, nil
+}
+
+// adaptiveBlockSteps is the maximum number of times the item count of a block
+// is corrected in adaptive mode before the block is written anyway
+const adaptiveBlockSteps = 4
+
+// adaptiveItemsForNextBlock returns how many of the pending items to write as
+// the next block in adaptive mode, or 0 if more items should be buffered
+// first. The item count is derived from the bytes per item measured for the
+// previous block and corrected until the block size is within 10% below the
+// target. Only the chosen prefix is measured, not every pending item.
+func (sw *SimpleWriter) adaptiveItemsForNextBlock(force bool) (int, error) {
+	limit := len(sw.pendingIDs)
+	if sw.maxPendingItems > 0 && sw.maxPendingItems < limit {
+		limit = sw.maxPendingItems
+	}
+	target := float64(sw.targetBlockSize)
+	lowerBound := target * 0.9
 
-			// Keep the remaining data for the next block
-			sw.pendingIDs = sw.pendingIDs[itemsWritten:]
-			sw.pendingValues = sw.pendingValues[itemsWritten:]
+	// Aim at the middle of the band so small fluctuations stay within it
+	n := limit
+	if sw.bytesPerItem > 0 {
+		n = min(limit, max(1, int(target*0.95/sw.bytesPerItem)))
 
-			// Try to write the remaining data in a new block
-			return sw.flushIfNeeded(force)
-		} else if err != nil {
-			// Some other error occurred
-			return fmt.Errorf("failed to write block: %w", err)
+		// Not enough pending data for a full block yet
+		if n == len(sw.pendingIDs) && !force && float64(n)*sw.bytesPerItem < lowerBound {
+			return 0, nil
 		}
+	}
+
+	blockStart, err := sw.writer.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get current position: %w", err)
+	}
+
+	for step := 0; ; step++ {
+		dataSize, err := sw.writer.encodedDataSize(sw.pendingIDs[:n], sw.pendingValues[:n], sw.writer.encodingType)
+		if err != nil {
+			return 0, err
+		}
+		size := float64(sw.writer.paddedBlockSize(blockStart, dataSize))
+		sw.bytesPerItem = size / float64(n)
+
+		switch {
+		case size > target:
+			if n == 1 {
+				return 1, nil
+			}
+			// The writer splits the block if it is still too large after the
+			// last correction
+			if step == adaptiveBlockSteps {
+				return n, nil
+			}
+			n = max(1, min(n-1, int(float64(n)*target*0.95/size)))
+		case size < lowerBound && n < limit:
+			if step == adaptiveBlockSteps {
+				return n, nil
+			}
+			n = min(limit, max(n+1, int(float64(n)*target*0.95/size)))
+		case size < lowerBound && n == len(sw.pendingIDs) && !force:
+			// Wait for more data, the measurement is kept for the next attempt
+			return 0, nil
+		default:
+			return n, nil
+		}
+	}
+}
+
+// writePendingBlock writes the first n pending items as a block
+func (sw *SimpleWriter) writePendingBlock(n int) error {
+	err := sw.writer.WriteBlock(sw.pendingIDs[:n], sw.pendingValues[:n])
+
+	// The writer may still split the block if it disagrees with our size estimate
+	if blockFullErr, ok := err.(*BlockFullError); ok {
+		n = blockFullErr.ItemsWritten
+	} else if err != nil {
+		return fmt.Errorf("failed to write block: %w", err)
+	}
 
-		// All items were written successfully
-		sw.totalItems += uint64(len(sw.pendingIDs))
-		sw.pendingIDs = nil
-		sw.pendingValues = nil
+	if sw.adaptive {
+		sw.totalItems += uint64(n)
+		sw.pendingIDs = sw.pendingIDs[n:]
+		sw.pendingValues = sw.pendingValues[n:]
+		return nil
+	}
+
+	// Remove the written items from the pending size. The first two remaining
+	// items start a new block, so they are no longer encoded against the items
+	// before them (delta-of-delta encoding looks back two items).
+	for i := 0; i <= n+1 && i < len(sw.pendingIDs); i++ {
+		sw.pendingDataSize -= sw.writer.encodedPairSize(sw.pendingIDs, sw.pendingValues, i)
+	}
+	sw.totalItems += uint64(n)
+	sw.pendingIDs = sw.pendingIDs[n:]
+	sw.pendingValues = sw.pendingValues[n:]
+	for i := 0; i <= 1 && i < len(sw.pendingIDs); i++ {
+		sw.pendingDataSize += sw.writer.encodedPairSize(sw.pendingIDs, sw.pendingValues, i)
 	}
 
 	return nil
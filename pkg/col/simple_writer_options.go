@@ -0,0 +1,54 @@
+package col
+
+// SimpleWriterOption configures a SimpleWriter. Every WriterOption is also a
+// SimpleWriterOption and is passed through to the underlying Writer.
+type SimpleWriterOption interface {
+	applySimpleWriter(*SimpleWriter)
+}
+
+// applySimpleWriter lets a WriterOption be passed to NewSimpleWriter
+func (o WriterOption) applySimpleWriter(sw *SimpleWriter) {
+	sw.writerOptions = append(sw.writerOptions, o)
+}
+
+// simpleWriterOptionFunc adapts a function to the SimpleWriterOption interface
+type simpleWriterOptionFunc func(*SimpleWriter)
+
+func (f simpleWriterOptionFunc) applySimpleWriter(sw *SimpleWriter) {
+	f(sw)
+}
+
+// WithTargetBlockSize sets the target block size in bytes for the SimpleWriter.
+// It takes precedence over WithBlockSize.
+func WithTargetBlockSize(size int) SimpleWriterOption {
+	return simpleWriterOptionFunc(func(sw *SimpleWriter) {
+		sw.targetBlockSize = size
+	})
+}
+
+// WithMaxPendingItems limits the number of items per block written by the
+// SimpleWriter. By default blocks are only limited by the target block size.
+func WithMaxPendingItems(n int) SimpleWriterOption {
+	return simpleWriterOptionFunc(func(sw *SimpleWriter) {
+		sw.maxPendingItems = n
+	})
+}
+
+// WithSortOnWrite controls whether Write sorts unsorted input. When disabled,
+// Write rejects input whose IDs are not in ascending order instead.
+func WithSortOnWrite(sort bool) SimpleWriterOption {
+	return simpleWriterOptionFunc(func(sw *SimpleWriter) {
+		sw.sortOnWrite = sort
+	})
+}
+
+// WithAdaptiveBlockSize makes the SimpleWriter tune the number of items per
+// block from the bytes per item measured for the previous block, instead of
+// tracking the encoded size of every pending item. Blocks stay within 10%
+// below the target block size, also for skewed varint data, while Write
+// avoids the per-item size bookkeeping.
+func WithAdaptiveBlockSize(adaptive bool) SimpleWriterOption {
+	return simpleWriterOptionFunc(func(sw *SimpleWriter) {
+		sw.adaptive = adaptive
+	})
+}
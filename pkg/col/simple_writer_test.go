@@ -1,6 +1,8 @@
 package col
 
 import (
+	"fmt"
+	"math"
 	"math/rand"
 	"os"
 	"path/filepath"
@@ -20,12 +22,10 @@ func TestSimpleWriter(t *testing.T) {
 	filePath := filepath.Join(tempDir, "simple_test.col")
 
 	// Create a SimpleWriter
-	writer, err := NewSimpleWriter(filePath, WithEncoding(EncodingRaw))
+	// Use a smaller target block size for testing
+	writer, err := NewSimpleWriter(filePath, WithEncoding(EncodingRaw), WithTargetBlockSize(32*1024))
 	require.NoError(t, err)
 
-	// Set a smaller target block size for testing
-	writer.targetBlockSize = 32 * 1024 // 32KB instead of 128KB
-
 	// Write a large dataset to ensure multiple blocks are created
 	// We'll create 20,000 ID-value pairs, which should be around 320KB
 	// This should result in at least 2-3 blocks with our 128KB target
@@ -133,12 +133,9 @@ func TestSimpleWriterMultipleBatches(t *testing.T) {
 	filePath := filepath.Join(tempDir, "batches_test.col")
 
 	// Create a SimpleWriter with a smaller target block size for testing
-	writer, err := NewSimpleWriter(filePath, WithEncoding(EncodingRaw))
+	writer, err := NewSimpleWriter(filePath, WithEncoding(EncodingRaw), WithTargetBlockSize(16*1024))
 	require.NoError(t, err)
 
-	// Set a smaller target block size for testing
-	writer.targetBlockSize = 16 * 1024 // 16KB instead of 128KB
-
 	// Write multiple small batches
 	const batchSize = 1000
 	const numBatches = 10
@@ -321,3 +318,253 @@ func TestSimpleWriterVarIntEncoding(t *testing.T) {
 	assert.Equal(t, 90000, len(allIDs), "Expected 90000 total IDs")
 	assert.Equal(t, 90000, len(allValues), "Expected 90000 total values")
 }
+
+func TestSimpleWriterOptions(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-simple-writer-options-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	t.Run("WriterOptions flow through", func(t *testing.T) {
+		writer, err := NewSimpleWriter(filepath.Join(tempDir, "writer_options.col"),
+			WithEncoding(EncodingVarIntBoth), WithBlockSize(8*1024))
+		require.NoError(t, err)
+		defer writer.Close()
+
+		assert.Equal(t, EncodingVarIntBoth, writer.writer.encodingType)
+		assert.Equal(t, 8*1024, writer.targetBlockSize)
+		assert.Equal(t, uint32(8*1024), writer.writer.blockSizeTarget)
+		assert.Equal(t, defaultMaxPendingItems, writer.maxPendingItems)
+		assert.True(t, writer.sortOnWrite)
+	})
+
+	t.Run("Target block size overrides WithBlockSize", func(t *testing.T) {
+		writer, err := NewSimpleWriter(filepath.Join(tempDir, "target.col"),
+			WithTargetBlockSize(4*1024), WithBlockSize(8*1024))
+		require.NoError(t, err)
+		defer writer.Close()
+
+		assert.Equal(t, 4*1024, writer.targetBlockSize)
+		assert.Equal(t, uint32(4*1024), writer.writer.blockSizeTarget)
+	})
+
+	t.Run("Max pending items", func(t *testing.T) {
+		filePath := filepath.Join(tempDir, "pending.col")
+		writer, err := NewSimpleWriter(filePath, WithMaxPendingItems(10))
+		require.NoError(t, err)
+
+		for i := uint64(0); i < 3; i++ {
+			require.NoError(t, writer.Write([]uint64{i*10 + 1, i*10 + 2, i*10 + 3, i*10 + 4, i*10 + 5}, []int64{1, 2, 3, 4, 5}))
+		}
+		// The first 10 items were flushed as a block, the last 5 are pending
+		assert.Equal(t, uint64(10), writer.TotalItems())
+		require.NoError(t, writer.Close())
+
+		reader, err := NewReader(filePath)
+		require.NoError(t, err)
+		defer reader.Close()
+		assert.Equal(t, uint64(2), reader.BlockCount())
+	})
+
+	t.Run("Sort on write disabled", func(t *testing.T) {
+		writer, err := NewSimpleWriter(filepath.Join(tempDir, "unsorted.col"), WithSortOnWrite(false))
+		require.NoError(t, err)
+		defer writer.Close()
+
+		assert.Error(t, writer.Write([]uint64{3, 1, 2}, []int64{3, 1, 2}))
+		assert.NoError(t, writer.Write([]uint64{1, 2, 3}, []int64{1, 2, 3}))
+	})
+
+	t.Run("Invalid options", func(t *testing.T) {
+		_, err := NewSimpleWriter(filepath.Join(tempDir, "invalid.col"), WithMaxPendingItems(-1))
+		assert.Error(t, err)
+		_, err = NewSimpleWriter(filepath.Join(tempDir, "invalid.col"), WithTargetBlockSize(-1))
+		assert.Error(t, err)
+	})
+}
+
+func TestSimpleWriterBlockSizeBudget(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-simple-writer-budget-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	const targetBlockSize = 32 * 1024
+
+	encodings := []uint32{EncodingRaw, EncodingDeltaBoth, EncodingVarInt, EncodingVarIntBoth, EncodingDeltaDelta}
+	for _, encoding := range encodings {
+		t.Run(fmt.Sprintf("encoding %d", encoding), func(t *testing.T) {
+			filePath := filepath.Join(tempDir, fmt.Sprintf("budget_%d.col", encoding))
+			writer, err := NewSimpleWriter(filePath, WithEncoding(encoding), WithTargetBlockSize(targetBlockSize))
+			require.NoError(t, err)
+
+			// Skewed data: runs of dense IDs with small values alternate with
+			// sparse IDs and large values, so the bytes per item vary a lot
+			r := rand.New(rand.NewSource(42))
+			id := uint64(0)
+			for batch := 0; batch < 20; batch++ {
+				ids := make([]uint64, 5000)
+				values := make([]int64, 5000)
+				for i := range ids {
+					if batch%2 == 0 {
+						id++
+						values[i] = int64(r.Intn(10))
+					} else {
+						id += uint64(r.Intn(1 << 20))
+						values[i] = r.Int63() - r.Int63()
+					}
+					ids[i] = id
+				}
+				require.NoError(t, writer.Write(ids, values))
+			}
+			require.NoError(t, writer.Close())
+
+			reader, err := NewReader(filePath)
+			require.NoError(t, err)
+			defer reader.Close()
+
+			var total uint32
+			blockCount := int(reader.BlockCount())
+			for i, entry := range reader.blockIndex {
+				total += entry.Count
+				assert.LessOrEqual(t, entry.BlockSize, uint32(targetBlockSize), "block %d exceeds the target", i)
+
+				// All but the last block should be filled up to the target
+				if i < blockCount-1 {
+					data, err := reader.readRawBlock(i)
+					require.NoError(t, err)
+					assert.Greater(t, len(data), targetBlockSize*95/100, "block %d is underfilled", i)
+				}
+			}
+			assert.Equal(t, uint32(100000), total)
+		})
+	}
+}
+
+func TestSimpleWriterFlush(t *testing.T) {
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "flush.col")
+
+	writer, err := NewSimpleWriter(filePath, WithEncoding(EncodingVarIntBoth))
+	require.NoError(t, err)
+
+	require.NoError(t, writer.Write([]uint64{1, 2, 3}, []int64{10, 20, 30}))
+	assert.Equal(t, uint64(0), writer.TotalItems(), "items should be pending until a block is full")
+
+	require.NoError(t, writer.Flush())
+	assert.Equal(t, uint64(3), writer.TotalItems())
+
+	// Flushing without pending items writes no block
+	require.NoError(t, writer.Flush())
+
+	require.NoError(t, writer.Write([]uint64{4, 5}, []int64{40, 50}))
+	require.NoError(t, writer.Close())
+	assert.Equal(t, uint64(5), writer.TotalItems())
+	assert.Error(t, writer.Flush())
+
+	reader, err := NewReader(filePath)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	require.Equal(t, uint64(2), reader.BlockCount())
+	ids, values, err := reader.GetPairs(0)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1, 2, 3}, ids)
+	assert.Equal(t, []int64{10, 20, 30}, values)
+	ids, values, err = reader.GetPairs(1)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{4, 5}, ids)
+	assert.Equal(t, []int64{40, 50}, values)
+}
+
+func TestSimpleWriterPartialBlocks(t *testing.T) {
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "partial.col")
+
+	writer, err := NewSimpleWriter(filePath, WithEncoding(EncodingRaw), WithTargetBlockSize(32*1024),
+		WithPadding(PaddingNone))
+	require.NoError(t, err)
+
+	// Let the underlying writer disagree with the SimpleWriter's size estimate,
+	// so every block it is handed is only partially written
+	writer.writer.blockSizeTarget = 4 * 1024
+
+	const numPairs = 10000
+	ids := make([]uint64, numPairs)
+	values := make([]int64, numPairs)
+	for i := range ids {
+		ids[i] = uint64(i)
+		values[i] = int64(i * 3)
+	}
+	require.NoError(t, writer.Write(ids[:6000], values[:6000]))
+	require.NoError(t, writer.Flush())
+	assert.Equal(t, uint64(6000), writer.TotalItems())
+
+	require.NoError(t, writer.Write(ids[6000:], values[6000:]))
+	require.NoError(t, writer.Close())
+	assert.Equal(t, uint64(numPairs), writer.TotalItems())
+
+	reader, err := NewReader(filePath)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	var readIDs []uint64
+	var readValues []int64
+	for i := uint64(0); i < reader.BlockCount(); i++ {
+		blockIDs, blockValues, err := reader.GetPairs(i)
+		require.NoError(t, err)
+		readIDs = append(readIDs, blockIDs...)
+		readValues = append(readValues, blockValues...)
+	}
+	assert.Equal(t, ids, readIDs)
+	assert.Equal(t, values, readValues)
+}
+
+func TestSimpleWriterAdaptiveBlockSize(t *testing.T) {
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "adaptive.col")
+
+	const target = 4 * 1024
+	writer, err := NewSimpleWriter(filePath, WithEncoding(EncodingVarIntBoth), WithTargetBlockSize(target),
+		WithPadding(PaddingNone), WithAdaptiveBlockSize(true))
+	require.NoError(t, err)
+
+	// Skewed varint data: runs of small values alternate with runs of values
+	// needing the full varint width, so the bytes per item change between blocks
+	const numPairs = 50000
+	ids := make([]uint64, numPairs)
+	values := make([]int64, numPairs)
+	for i := range ids {
+		ids[i] = uint64(i * 2)
+		values[i] = int64(i % 100)
+		if (i/3000)%2 == 1 {
+			values[i] = math.MaxInt64 - int64(i)
+		}
+	}
+	for start := 0; start < numPairs; start += 700 {
+		end := min(start+700, numPairs)
+		require.NoError(t, writer.Write(ids[start:end], values[start:end]))
+	}
+	require.NoError(t, writer.Close())
+	assert.Equal(t, uint64(numPairs), writer.TotalItems())
+
+	reader, err := NewReader(filePath)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	var readIDs []uint64
+	var readValues []int64
+	for i := uint64(0); i < reader.BlockCount(); i++ {
+		meta, err := reader.BlockMeta(BlockID(i))
+		require.NoError(t, err)
+		assert.LessOrEqual(t, meta.Size, uint32(target), "block %d", i)
+		if i < reader.BlockCount()-1 {
+			assert.GreaterOrEqual(t, meta.Size, uint32(target*9/10), "block %d", i)
+		}
+
+		blockIDs, blockValues, err := reader.GetPairs(i)
+		require.NoError(t, err)
+		readIDs = append(readIDs, blockIDs...)
+		readValues = append(readValues, blockValues...)
+	}
+	assert.Equal(t, ids, readIDs)
+	assert.Equal(t, values, readValues)
+}
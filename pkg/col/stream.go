@@ -0,0 +1,182 @@
+package col
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// streamVersion is the version of the block streaming wire format
+const streamVersion uint32 = 2
+
+// StreamBlocks writes the blocks of the file starting at block since to w, so
+// they can be appended to another file with Writer.AppendStreamedBlocks. This
+// allows shipping newly appended blocks from a primary to a replica: the
+// replica passes the number of blocks it already has as since.
+//
+// The stream consists of a header with the magic number, the stream version,
+// the data type of the column and the number of blocks, followed by each
+// block's footer statistics and its raw bytes. Blocks are not re-encoded.
+func (r *Reader) StreamBlocks(w io.Writer, since BlockID) error {
+	if err := r.ensureFooter(); err != nil {
+		return err
+	}
+	if int(since) > len(r.blockIndex) {
+		return fmt.Errorf("invalid block index: %d", since)
+	}
+	defer r.adviseFor(AdviceSequential)()
+
+	header := make([]byte, streamHeaderSize)
+	binary.LittleEndian.PutUint64(header[0:], MagicNumber)
+	binary.LittleEndian.PutUint32(header[8:], streamVersion)
+	binary.LittleEndian.PutUint32(header[12:], r.header.ColumnType)
+	binary.LittleEndian.PutUint32(header[16:], uint32(len(r.blockIndex)-int(since)))
+	if _, err := w.Write(header); err != nil {
+		return fmt.Errorf("failed to write stream header: %w", err)
+	}
+
+	for blockIdx := int(since); blockIdx < len(r.blockIndex); blockIdx++ {
+		data, err := r.readRawBlock(blockIdx)
+		if err != nil {
+			return fmt.Errorf("failed to read block %d: %w", blockIdx, err)
+		}
+		stats, err := r.completeBlockStats(blockIdx)
+		if err != nil {
+			return err
+		}
+
+		frame := make([]byte, streamBlockHeaderSize, streamBlockHeaderSize+len(data))
+		putStreamBlockStats(frame, stats)
+		binary.LittleEndian.PutUint32(frame[streamBlockHeaderSize-4:], uint32(len(data)))
+		frame = append(frame, data...)
+		if _, err := w.Write(frame); err != nil {
+			return fmt.Errorf("failed to write block %d: %w", blockIdx, err)
+		}
+	}
+
+	return nil
+}
+
+// AppendStreamedBlocks reads a stream written by Reader.StreamBlocks and
+// appends its blocks to the file without re-encoding them. The data type of
+// the stream must match the writer's. It returns the number of appended
+// blocks; on error, the blocks appended so far remain in the file.
+func (w *Writer) AppendStreamedBlocks(r io.Reader) (int, error) {
+	header := make([]byte, streamHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, fmt.Errorf("failed to read stream header: %w", err)
+	}
+	if magic := binary.LittleEndian.Uint64(header[0:]); magic != MagicNumber {
+		return 0, fmt.Errorf("invalid stream magic number: 0x%X", magic)
+	}
+	if version := binary.LittleEndian.Uint32(header[8:]); version != streamVersion {
+		return 0, fmt.Errorf("unsupported stream version: %d", version)
+	}
+	if dataType := binary.LittleEndian.Uint32(header[12:]); dataType != w.dataType {
+		return 0, fmt.Errorf("data type mismatch: stream uses %d, destination uses %d",
+			dataType, w.dataType)
+	}
+	blockCount, err := intFromFile(uint64(binary.LittleEndian.Uint32(header[16:])))
+	if err != nil {
+		return 0, fmt.Errorf("invalid block count: %w", err)
+	}
+
+	blockHeader := make([]byte, streamBlockHeaderSize)
+	for i := 0; i < blockCount; i++ {
+		if _, err := io.ReadFull(r, blockHeader); err != nil {
+			return i, fmt.Errorf("failed to read header of streamed block %d: %w", i, err)
+		}
+		stats := readStreamBlockStats(blockHeader)
+		size, err := intFromFile(uint64(binary.LittleEndian.Uint32(blockHeader[streamBlockHeaderSize-4:])))
+		if err != nil {
+			return i, fmt.Errorf("streamed block %d: %w", i, err)
+		}
+		if size < blockHeaderSize+blockLayoutSize {
+			return i, fmt.Errorf("streamed block %d too small: %d bytes", i, size)
+		}
+
+		data := make([]byte, size)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return i, fmt.Errorf("failed to read streamed block %d: %w", i, err)
+		}
+
+		// The IDs are needed for the global ID bitmap
+		ids, err := decodeStreamedBlockIDs(data, BlockID(i), stats.Count, w.dataType)
+		if err != nil {
+			return i, err
+		}
+
+		if err := w.appendRawBlock(data, stats, ids); err != nil {
+			return i, fmt.Errorf("failed to append streamed block %d: %w", i, err)
+		}
+	}
+
+	return blockCount, nil
+}
+
+// decodeStreamedBlockIDs checks a streamed block against its statistics and
+// decodes its IDs
+func decodeStreamedBlockIDs(data []byte, blockIdx BlockID, count uint32, dataType uint32) ([]uint64, error) {
+	var header BlockHeader
+	if err := header.UnmarshalBinary(data[:blockHeaderSize]); err != nil {
+		return nil, fmt.Errorf("invalid streamed block %d: %w", blockIdx, err)
+	}
+	if header.Count != count {
+		return nil, fmt.Errorf("streamed block %d count mismatch: header=%d, statistics=%d",
+			blockIdx, header.Count, count)
+	}
+	sections, err := parseBlockSections(data, blockIdx, int(count))
+	if err != nil {
+		return nil, fmt.Errorf("invalid streamed block %d: %w", blockIdx, err)
+	}
+	idEncoding, _, err := sectionEncodings(sections.encodingType)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := decodeBlockIDs(sections.idBytes, sections.count, idEncoding, dataType, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode streamed block %d: %w", blockIdx, err)
+	}
+	return ids, nil
+}
+
+// putStreamBlockStats writes the statistics of a streamed block to buf
+func putStreamBlockStats(buf []byte, stats BlockStats) {
+	binary.LittleEndian.PutUint64(buf[0:], stats.MinID)
+	binary.LittleEndian.PutUint64(buf[8:], stats.MaxID)
+	binary.LittleEndian.PutUint64(buf[16:], int64ToUint64(stats.MinValue))
+	binary.LittleEndian.PutUint64(buf[24:], int64ToUint64(stats.MaxValue))
+	binary.LittleEndian.PutUint64(buf[32:], int64ToUint64(stats.Sum))
+	binary.LittleEndian.PutUint32(buf[40:], stats.Count)
+	binary.LittleEndian.PutUint64(buf[44:], math.Float64bits(stats.SumSquares))
+	binary.LittleEndian.PutUint32(buf[52:], stats.NegativeCount)
+	binary.LittleEndian.PutUint32(buf[56:], stats.ZeroCount)
+	binary.LittleEndian.PutUint64(buf[60:], stats.unsigned.Min)
+	binary.LittleEndian.PutUint64(buf[68:], stats.unsigned.Max)
+	binary.LittleEndian.PutUint64(buf[76:], stats.unsigned.Sum)
+	binary.LittleEndian.PutUint64(buf[84:], stats.unsigned.SumHigh)
+	binary.LittleEndian.PutUint64(buf[92:], stats.partition)
+}
+
+// readStreamBlockStats reads the statistics of a streamed block from buf
+func readStreamBlockStats(buf []byte) BlockStats {
+	return BlockStats{
+		MinID:         readBufferedUint64(buf, 0),
+		MaxID:         readBufferedUint64(buf, 8),
+		MinValue:      uint64ToInt64(readBufferedUint64(buf, 16)),
+		MaxValue:      uint64ToInt64(readBufferedUint64(buf, 24)),
+		Sum:           uint64ToInt64(readBufferedUint64(buf, 32)),
+		Count:         readBufferedUint32(buf, 40),
+		SumSquares:    math.Float64frombits(readBufferedUint64(buf, 44)),
+		NegativeCount: readBufferedUint32(buf, 52),
+		ZeroCount:     readBufferedUint32(buf, 56),
+		unsigned: unsignedBlockStats{
+			Min:     readBufferedUint64(buf, 60),
+			Max:     readBufferedUint64(buf, 68),
+			Sum:     readBufferedUint64(buf, 76),
+			SumHigh: readBufferedUint64(buf, 84),
+		},
+		partition: readBufferedUint64(buf, 92),
+	}
+}
@@ -0,0 +1,154 @@
+package col
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamBlocks(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-stream-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	// The primary has three blocks, one of them with an encoding of its own
+	primaryPath := filepath.Join(tempDir, "primary.col")
+	writer, err := NewWriter(primaryPath, WithEncoding(EncodingVarIntBoth))
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteBlock([]uint64{1, 2, 3}, []int64{-1, 0, 1}))
+	require.NoError(t, writer.WriteBlockWithOptions([]uint64{10, 20}, []int64{100, 200},
+		WithBlockEncoding(EncodingRaw)))
+	require.NoError(t, writer.WriteBlock([]uint64{30, 31, 32}, []int64{-7, 7, 0}))
+	require.NoError(t, writer.FinalizeAndClose())
+
+	primary, err := NewReader(primaryPath)
+	require.NoError(t, err)
+	defer primary.Close()
+
+	// replicate streams the blocks of the primary after since into a new replica
+	// that already has the first since blocks
+	replicate := func(t *testing.T, name string, since BlockID) *Reader {
+		path := filepath.Join(tempDir, name)
+		replica, err := NewWriter(path, WithEncoding(EncodingVarIntBoth))
+		require.NoError(t, err)
+		if since > 0 {
+			require.NoError(t, copyRawBlocks(primary, []uint64{0}, replica))
+		}
+
+		var stream bytes.Buffer
+		require.NoError(t, primary.StreamBlocks(&stream, since))
+		appended, err := replica.AppendStreamedBlocks(&stream)
+		require.NoError(t, err)
+		assert.Equal(t, int(primary.BlockCount())-int(since), appended)
+		assert.Zero(t, stream.Len())
+		require.NoError(t, replica.FinalizeAndClose())
+
+		reader, err := NewReader(path)
+		require.NoError(t, err)
+		t.Cleanup(func() { reader.Close() })
+		return reader
+	}
+
+	t.Run("Full and incremental", func(t *testing.T) {
+		for _, since := range []BlockID{0, 1} {
+			replica := replicate(t, "replica.col", since)
+
+			ids, values := readAllPairs(t, replica)
+			expectedIDs, expectedValues := readAllPairs(t, primary)
+			assert.Equal(t, expectedIDs, ids)
+			assert.Equal(t, expectedValues, values)
+
+			meta, err := replica.BlockMeta(1)
+			require.NoError(t, err)
+			assert.Equal(t, EncodingRaw, meta.Encoding)
+
+			stats, err := replica.Stats()
+			require.NoError(t, err)
+			assert.True(t, stats.FromMetadata)
+			assert.Equal(t, uint64(2), stats.NegativeCount)
+
+			report, err := replica.ValidateFooterAgainstBlocks()
+			require.NoError(t, err)
+			assert.True(t, report.OK(), "%+v", report)
+
+			globalIDs, err := replica.GetGlobalIDBitmap()
+			require.NoError(t, err)
+			assert.Equal(t, expectedIDs, globalIDs.ToArray())
+		}
+	})
+
+	t.Run("Nothing new", func(t *testing.T) {
+		var stream bytes.Buffer
+		require.NoError(t, primary.StreamBlocks(&stream, BlockID(primary.BlockCount())))
+		assert.Equal(t, streamHeaderSize, stream.Len())
+
+		assert.Error(t, primary.StreamBlocks(&stream, BlockID(primary.BlockCount()+1)))
+	})
+
+	t.Run("Partitions", func(t *testing.T) {
+		path := filepath.Join(tempDir, "partitioned.col")
+		writer, err := NewWriter(path)
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlockWithOptions([]uint64{1, 2}, []int64{1, 2}, WithBlockPartition(7)))
+		require.NoError(t, writer.WriteBlock([]uint64{3}, []int64{3}))
+		require.NoError(t, writer.WriteBlockWithOptions([]uint64{4}, []int64{4}, WithBlockPartition(9)))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		partitioned, err := NewReader(path)
+		require.NoError(t, err)
+		defer partitioned.Close()
+
+		var stream bytes.Buffer
+		require.NoError(t, partitioned.StreamBlocks(&stream, 0))
+		replicaPath := filepath.Join(tempDir, "partitioned-replica.col")
+		replica, err := NewWriter(replicaPath)
+		require.NoError(t, err)
+		_, err = replica.AppendStreamedBlocks(&stream)
+		require.NoError(t, err)
+		require.NoError(t, replica.FinalizeAndClose())
+
+		reader, err := NewReader(replicaPath)
+		require.NoError(t, err)
+		defer reader.Close()
+		for block, expected := range []uint64{7, 0, 9} {
+			partition, err := reader.BlockPartition(BlockID(block))
+			require.NoError(t, err)
+			assert.Equal(t, expected, partition)
+		}
+	})
+
+	t.Run("Invalid streams", func(t *testing.T) {
+		var stream bytes.Buffer
+		require.NoError(t, primary.StreamBlocks(&stream, 0))
+		data := stream.Bytes()
+
+		newWriter := func(t *testing.T, options ...WriterOption) *Writer {
+			w, err := NewWriter(filepath.Join(tempDir, "invalid.col"), options...)
+			require.NoError(t, err)
+			t.Cleanup(func() { w.Close() })
+			return w
+		}
+
+		_, err := newWriter(t, WithDataType(DataTypeUint64)).AppendStreamedBlocks(bytes.NewReader(data))
+		assert.ErrorContains(t, err, "data type mismatch")
+
+		_, err = newWriter(t).AppendStreamedBlocks(bytes.NewReader(data[8:]))
+		assert.ErrorContains(t, err, "magic number")
+
+		// Cut off in the middle of the second block
+		truncated := data[:streamHeaderSize+streamBlockHeaderSize+100]
+		_, err = newWriter(t).AppendStreamedBlocks(bytes.NewReader(truncated))
+		assert.Error(t, err)
+
+		// The count of the first block header no longer matches its statistics
+		corrupted := append([]byte(nil), data...)
+		corrupted[streamHeaderSize+streamBlockHeaderSize+40]++
+		appended, err := newWriter(t).AppendStreamedBlocks(bytes.NewReader(corrupted))
+		assert.ErrorContains(t, err, "count mismatch")
+		assert.Zero(t, appended)
+	})
+}
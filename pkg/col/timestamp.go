@@ -0,0 +1,97 @@
+package col
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeScanFunc is called by ScanTimeRange for every block with matching
+// values. The slices are only valid until the function returns. Returning an
+// error stops the scan.
+type TimeScanFunc func(id BlockID, ids []uint64, times []time.Time) error
+
+// WriteBlockTime writes a block of ID-timestamp pairs to a
+// DataTypeTimestampMillis column like WriteBlockWithOptions. The timestamps
+// are stored as milliseconds since the Unix epoch; their location and any
+// precision below a millisecond are dropped.
+func (w *Writer) WriteBlockTime(ids []uint64, times []time.Time, options ...BlockOption) error {
+	if w.dataType != DataTypeTimestampMillis {
+		return fmt.Errorf("cannot write timestamps to a column of data type %d", w.dataType)
+	}
+
+	millis := make([]int64, len(times))
+	for i, t := range times {
+		millis[i] = t.UnixMilli()
+	}
+	return w.WriteBlockWithOptions(ids, millis, options...)
+}
+
+// ReadBlockTime returns the ID-timestamp pairs of a block of a
+// DataTypeTimestampMillis column. The timestamps are in UTC.
+func (r *Reader) ReadBlockTime(id BlockID) ([]uint64, []time.Time, error) {
+	if r.header.ColumnType != DataTypeTimestampMillis {
+		return nil, nil, fmt.Errorf("cannot read timestamps from a column of data type %d", r.header.ColumnType)
+	}
+
+	ids, millis, err := r.ReadBlock(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	times := make([]time.Time, len(millis))
+	for i, ms := range millis {
+		times[i] = time.UnixMilli(ms).UTC()
+	}
+	return ids, times, nil
+}
+
+// ScanTimeRange calls fn in block order for every block of a
+// DataTypeTimestampMillis column holding timestamps in the half-open range
+// from-to, with the pairs of the block in that range. Blocks whose value
+// statistics lie outside the range are skipped without being read.
+func (r *Reader) ScanTimeRange(from, to time.Time, fn TimeScanFunc) error {
+	if r.header.ColumnType != DataTypeTimestampMillis {
+		return fmt.Errorf("cannot scan timestamps of a column of data type %d", r.header.ColumnType)
+	}
+	if err := r.ensureFooter(); err != nil {
+		return err
+	}
+
+	// A range ending within a millisecond still covers that millisecond
+	fromMillis, toMillis := from.UnixMilli(), to.UnixMilli()
+	if to.Sub(time.UnixMilli(toMillis)) > 0 {
+		toMillis++
+	}
+	if fromMillis >= toMillis {
+		return nil
+	}
+
+	var idsBuf, rangeIDs []uint64
+	var valsBuf []int64
+	var times []time.Time
+	for blockIdx, entry := range r.blockIndex {
+		if uint64ToInt64(entry.MaxValue) < fromMillis || uint64ToInt64(entry.MinValue) >= toMillis {
+			continue
+		}
+
+		ids, millis, err := r.ReadBlockInto(BlockID(blockIdx), idsBuf, valsBuf)
+		if err != nil {
+			return err
+		}
+		idsBuf, valsBuf = ids, millis
+
+		rangeIDs, times = rangeIDs[:0], times[:0]
+		for i, ms := range millis {
+			if ms >= fromMillis && ms < toMillis {
+				rangeIDs = append(rangeIDs, ids[i])
+				times = append(times, time.UnixMilli(ms).UTC())
+			}
+		}
+		if len(rangeIDs) == 0 {
+			continue
+		}
+		if err := fn(BlockID(blockIdx), rangeIDs, times); err != nil {
+			return err
+		}
+	}
+	return nil
+}
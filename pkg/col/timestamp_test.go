@@ -0,0 +1,84 @@
+package col
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimestampColumn(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	ids := make([]uint64, 300)
+	times := make([]time.Time, 300)
+	for i := range ids {
+		ids[i] = uint64(i)
+		times[i] = start.Add(time.Duration(i) * time.Second)
+	}
+
+	var buf bytes.Buffer
+	writer, err := NewWriterToBuffer(&buf, WithDataType(DataTypeTimestampMillis))
+	require.NoError(t, err)
+	for b := 0; b < 3; b++ {
+		require.NoError(t, writer.WriteBlockTime(ids[b*100:(b+1)*100], times[b*100:(b+1)*100]))
+	}
+	assert.Error(t, writer.WriteBlockTime(nil, []time.Time{start}))
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := NewReaderFromBytes(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, DataTypeTimestampMillis, reader.DataType())
+	assert.Equal(t, EncodingDeltaDelta, reader.HeaderOnly().EncodingType)
+
+	t.Run("ReadBlockTime", func(t *testing.T) {
+		blockIDs, blockTimes, err := reader.ReadBlockTime(1)
+		require.NoError(t, err)
+		assert.Equal(t, ids[100:200], blockIDs)
+		for i, got := range blockTimes {
+			assert.True(t, times[100+i].Equal(got))
+			assert.Equal(t, time.UTC, got.Location())
+		}
+	})
+
+	t.Run("ScanTimeRange", func(t *testing.T) {
+		var scanned []uint64
+		var blocks []BlockID
+		err := reader.ScanTimeRange(times[150], times[250], func(id BlockID, ids []uint64, times []time.Time) error {
+			blocks = append(blocks, id)
+			scanned = append(scanned, ids...)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []BlockID{1, 2}, blocks)
+		assert.Equal(t, ids[150:250], scanned)
+
+		// The end of the range is exclusive, sub-millisecond ends included
+		var count int
+		err = reader.ScanTimeRange(times[0], times[0].Add(time.Microsecond), func(_ BlockID, ids []uint64, _ []time.Time) error {
+			count += len(ids)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		err = reader.ScanTimeRange(times[10], times[10], func(BlockID, []uint64, []time.Time) error {
+			t.Fatal("empty range must not call fn")
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Explicit encoding", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer, err := NewWriterToBuffer(&buf, WithEncoding(EncodingVarIntBoth), WithDataType(DataTypeTimestampMillis))
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlockTime(ids[:10], times[:10]))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		reader, err := NewReaderFromBytes(buf.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, EncodingVarIntBoth, reader.HeaderOnly().EncodingType)
+	})
+}
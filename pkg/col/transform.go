@@ -0,0 +1,62 @@
+package col
+
+import (
+	"fmt"
+)
+
+// TransformFunc maps a single pair to a new value.
+// Returning false as the second result drops the pair from the output.
+type TransformFunc func(id uint64, value int64) (int64, bool)
+
+// Transform streams all blocks of in, applies fn to every pair and writes the
+// result to out. Each input block becomes one output block unless the transformed
+// block exceeds the writer's target size, in which case it is split. Blocks whose
+// pairs are all dropped are skipped. Output blocks keep the partition key of
+// their input block. The caller is responsible for finalizing out.
+func Transform(in *Reader, out *Writer, fn TransformFunc) error {
+	for blockIdx := uint64(0); blockIdx < in.BlockCount(); blockIdx++ {
+		ids, values, err := in.ReadBlock(BlockID(blockIdx))
+		if err != nil {
+			return fmt.Errorf("failed to read block %d: %w", blockIdx, err)
+		}
+
+		// Apply the function in place, compacting the slices as pairs are dropped
+		kept := 0
+		for i, id := range ids {
+			newValue, keep := fn(id, values[i])
+			if !keep {
+				continue
+			}
+			ids[kept] = id
+			values[kept] = newValue
+			kept++
+		}
+		ids = ids[:kept]
+		values = values[:kept]
+
+		partition := WithBlockPartition(in.blockPartition(int(blockIdx)))
+		if err := writeAllBlocks(out, ids, values, partition); err != nil {
+			return fmt.Errorf("failed to write transformed block %d: %w", blockIdx, err)
+		}
+	}
+
+	return nil
+}
+
+// writeAllBlocks writes the pairs to the writer with the given block options,
+// starting new blocks whenever the current one is full
+func writeAllBlocks(w *Writer, ids []uint64, values []int64, options ...BlockOption) error {
+	for len(ids) > 0 {
+		err := w.WriteBlockWithOptions(ids, values, options...)
+		blockFullErr, ok := err.(*BlockFullError)
+		if !ok {
+			// Either everything was written or a real error occurred
+			return err
+		}
+
+		// Keep the remaining pairs for the next block
+		ids = ids[blockFullErr.ItemsWritten:]
+		values = values[blockFullErr.ItemsWritten:]
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package col
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransform(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "col-transform-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	// Write an input file with three blocks
+	inPath := filepath.Join(tempDir, "in.col")
+	writer, err := NewWriter(inPath)
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteBlock([]uint64{1, 2, 3}, []int64{-5, 10, 15}))
+	require.NoError(t, writer.WriteBlock([]uint64{4, 5}, []int64{-1, -2}))
+	require.NoError(t, writer.WriteBlock([]uint64{6, 7}, []int64{100, 200}))
+	require.NoError(t, writer.FinalizeAndClose())
+
+	in, err := NewReader(inPath)
+	require.NoError(t, err)
+	defer in.Close()
+
+	// Drop negative values and double the rest
+	outPath := filepath.Join(tempDir, "out.col")
+	out, err := NewWriter(outPath, WithEncoding(EncodingVarIntBoth))
+	require.NoError(t, err)
+	err = Transform(in, out, func(id uint64, v int64) (int64, bool) {
+		if v < 0 {
+			return 0, false
+		}
+		return v * 2, true
+	})
+	require.NoError(t, err)
+	require.NoError(t, out.FinalizeAndClose())
+
+	result, err := NewReader(outPath)
+	require.NoError(t, err)
+	defer result.Close()
+
+	// The second block is dropped entirely, the others keep their boundaries
+	require.Equal(t, uint64(2), result.BlockCount())
+
+	ids, values, err := result.GetPairs(0)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{2, 3}, ids)
+	assert.Equal(t, []int64{20, 30}, values)
+
+	ids, values, err = result.GetPairs(1)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{6, 7}, ids)
+	assert.Equal(t, []int64{200, 400}, values)
+
+	agg := result.Aggregate()
+	assert.Equal(t, uint64(4), agg.Count)
+	assert.Equal(t, int64(650), agg.Sum)
+}
@@ -1,5 +1,8 @@
 package col
 
+// BlockID identifies a block by its position in the file, starting at 0
+type BlockID uint64
+
 // BlockStats holds statistics for a block
 type BlockStats struct {
 	MinID    uint64
@@ -8,4 +11,24 @@ type BlockStats struct {
 	MaxValue int64
 	Sum      int64
 	Count    uint32
+
+	// Extended statistics, persisted in the block statistics footer section
+	SumSquares    float64
+	NegativeCount uint32
+	ZeroCount     uint32
+
+	// Statistics of unsigned columns, persisted in the unsigned statistics footer section
+	unsigned unsignedBlockStats
+
+	// Partition key of the block, persisted in the partitions footer section
+	partition uint64
+}
+
+// unsignedBlockStats holds the statistics of a block of an unsigned column. The
+// regular statistics of such a block describe its values reinterpreted as int64.
+type unsignedBlockStats struct {
+	Min     uint64
+	Max     uint64
+	Sum     uint64 // Low 64 bits of the sum
+	SumHigh uint64 // High 64 bits of the sum
 }
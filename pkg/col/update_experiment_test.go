@@ -79,15 +79,7 @@ func TestUpdateExperiment(t *testing.T) {
 	})
 
 	// Step 4: Merge the two aggregation results
-	mergedResult := AggregateResult{
-		Count: result1.Count + result2.Count,
-		Min:   minInt64(result1.Min, result2.Min),
-		Max:   maxInt64(result1.Max, result2.Max),
-		Sum:   result1.Sum + result2.Sum,
-	}
-	if mergedResult.Count > 0 {
-		mergedResult.Avg = float64(mergedResult.Sum) / float64(mergedResult.Count)
-	}
+	mergedResult := MergeAggregates(result1, result2)
 
 	// Step 5: Validate the results
 	// Expected results:
@@ -96,7 +88,7 @@ func TestUpdateExperiment(t *testing.T) {
 	// - Total: 15 items, min=10, max=700, sum=2590, avg=172.67
 
 	// Validate count
-	assert.Equal(t, 15, mergedResult.Count, "Merged count should be 15")
+	assert.Equal(t, uint64(15), mergedResult.Count, "Merged count should be 15")
 
 	// Validate min
 	assert.Equal(t, int64(10), mergedResult.Min, "Merged min should be 10")
@@ -168,29 +160,13 @@ func TestUpdateExperiment(t *testing.T) {
 	expectedAverage := float64(expectedSum) / float64(expectedCount)
 
 	// Compare with our merged results
-	assert.Equal(t, expectedCount, mergedResult.Count, "Merged count should match manual calculation")
+	assert.Equal(t, uint64(expectedCount), mergedResult.Count, "Merged count should match manual calculation")
 	assert.Equal(t, expectedMin, mergedResult.Min, "Merged min should match manual calculation")
 	assert.Equal(t, expectedMax, mergedResult.Max, "Merged max should match manual calculation")
 	assert.Equal(t, expectedSum, mergedResult.Sum, "Merged sum should match manual calculation")
 	assert.InDelta(t, expectedAverage, mergedResult.Avg, 0.01, "Merged average should match manual calculation")
 }
 
-// Helper function to find the minimum of two int64 values
-func minInt64(a, b int64) int64 {
-	if a < b {
-		return a
-	}
-	return b
-}
-
-// Helper function to find the maximum of two int64 values
-func maxInt64(a, b int64) int64 {
-	if a > b {
-		return a
-	}
-	return b
-}
-
 // TestDenyFilterExperiment tests the deny filter functionality specifically
 func TestDenyFilterExperiment(t *testing.T) {
 	// Create a temporary file for testing
@@ -230,7 +206,7 @@ func TestDenyFilterExperiment(t *testing.T) {
 
 	// Expected results: IDs 1-4, 8-10 with values 10-40, 80-100 (7 items)
 	// Count: 7, min=10, max=100, sum=370, avg=52.86
-	assert.Equal(t, 7, result.Count, "Count should be 7")
+	assert.Equal(t, uint64(7), result.Count, "Count should be 7")
 	assert.Equal(t, int64(10), result.Min, "Min should be 10")
 	assert.Equal(t, int64(100), result.Max, "Max should be 100")
 	assert.Equal(t, int64(10+20+30+40+80+90+100), result.Sum, "Sum should be 370")
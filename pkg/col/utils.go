@@ -1,5 +1,22 @@
 package col
 
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// intFromFile converts a size or count read from a file to int. Values beyond
+// the range of int, which only has 32 bits on some platforms, are rejected
+// rather than wrapped around.
+func intFromFile(v uint64) (int, error) {
+	if v > math.MaxInt {
+		return 0, fmt.Errorf("%d exceeds the maximum of %d on this platform", v, math.MaxInt)
+	}
+	return int(v), nil
+}
+
 // calculateMinMaxUint64 calculates the minimum and maximum values in a uint64 slice
 func calculateMinMaxUint64(values []uint64) (min, max uint64) {
 	if len(values) == 0 {
@@ -50,3 +67,74 @@ func calculateSumInt64(values []int64) int64 {
 	}
 	return sum
 }
+
+// calculateExtendedStatsInt64 calculates the sum of squares and the number of
+// negative and zero values of an int64 slice
+func calculateExtendedStatsInt64(values []int64) (sumSquares float64, negativeCount, zeroCount uint32) {
+	for _, v := range values {
+		f := float64(v)
+		sumSquares += f * f
+		if v < 0 {
+			negativeCount++
+		} else if v == 0 {
+			zeroCount++
+		}
+	}
+	return sumSquares, negativeCount, zeroCount
+}
+
+// calculateUnsignedStats calculates the statistics of int64 values that hold the
+// bit patterns of uint64 values, summing into 128 bits
+func calculateUnsignedStats(values []int64) unsignedBlockStats {
+	if len(values) == 0 {
+		return unsignedBlockStats{}
+	}
+
+	stats := unsignedBlockStats{Min: uint64(values[0]), Max: uint64(values[0])}
+	for _, v := range values {
+		u := uint64(v)
+		if u < stats.Min {
+			stats.Min = u
+		}
+		if u > stats.Max {
+			stats.Max = u
+		}
+		var carry uint64
+		stats.Sum, carry = bits.Add64(stats.Sum, u, 0)
+		stats.SumHigh += carry
+	}
+	return stats
+}
+
+// add adds the statistics of another block of the same column
+func (s *unsignedBlockStats) add(other unsignedBlockStats, first bool) {
+	if first || other.Min < s.Min {
+		s.Min = other.Min
+	}
+	if first || other.Max > s.Max {
+		s.Max = other.Max
+	}
+	var carry uint64
+	s.Sum, carry = bits.Add64(s.Sum, other.Sum, 0)
+	s.SumHigh += other.SumHigh + carry
+}
+
+// sortedValueOrder returns the positions of values sorted by value in ascending
+// order. Equal values keep their position order, which is the ID order.
+// Values of uint64 columns are compared as unsigned.
+func sortedValueOrder(values []int64, dataType uint32) []uint32 {
+	order := make([]uint32, len(values))
+	for i := range order {
+		order[i] = uint32(i)
+	}
+	if dataType == DataTypeUint64 {
+		sort.SliceStable(order, func(i, j int) bool {
+			return uint64(values[order[i]]) < uint64(values[order[j]])
+		})
+	} else {
+		sort.SliceStable(order, func(i, j int) bool {
+			return values[order[i]] < values[order[j]]
+		})
+	}
+	return order
+}
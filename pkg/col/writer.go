@@ -1,9 +1,14 @@
 package col
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"hash"
 	"os"
 
+	"vibe-lsm/pkg/col/format"
+
 	"github.com/weaviate/sroar"
 )
 
@@ -17,28 +22,106 @@ func minInt(a, b int) int {
 
 // Writer writes a column file
 type Writer struct {
-	file            *os.File
+	file            writerFile
 	blockCount      uint64
 	encodingType    uint32
+	encodingSet     bool   // Whether the encoding was set with WithEncoding
+	autoEncoding    bool   // Whether blocks may switch to EncodingDeltaDelta when it is smaller
+	dataType        uint32 // Data type of the values, recorded as the column type
 	blockSizeTarget uint32
-	blockPositions  []uint64      // Position of each block in the file
-	blockSizes      []uint32      // Size of each block in bytes
-	blockStats      []BlockStats  // Statistics for each block
-	globalIDs       *sroar.Bitmap // Bitmap of all IDs in the file
+	creationTime    uint64         // Unix time recorded in the file header
+	creationTimeSet bool           // Whether the creation time was fixed with WithFixedTimestamp
+	alignment       int64          // Boundary blocks and the footer are aligned to, <= 1 disables padding
+	blockPositions  []uint64       // Position of each block in the file
+	blockSizes      []uint32       // Size of each block in bytes
+	blockStats      []BlockStats   // Statistics for each block
+	globalIDs       *sroar.Bitmap  // Bitmap of all IDs in the file
+	lineage         []LineageEntry // Source files recorded in the lineage footer section
+	valueOrder      bool           // Whether to record the value order of each block
+	valueOrders     [][]uint32     // Value order of each block, nil for blocks without one
+	rateLimiter     *RateLimiter   // Limits the write throughput, nil if unlimited
+	checksumType    uint32         // Algorithm of the file checksum
+	checksum        hash.Hash64    // Checksum of the bytes after the file header, nil for ChecksumNone
+	validateSorted  bool           // Whether blocks must have sorted IDs, see WithValidateSorted
+	duplicatePolicy DuplicatePolicy
+	path            string // File created by NewWriter, empty for in-memory writers
+
+	checkpointInterval int  // Blocks between automatic checkpoints, 0 if disabled
+	sidecar            bool // Whether Finalize writes a metadata sidecar
+
+	encoded encodedBlock // Sections of the block being written, reused across blocks
+}
+
+// padding returns the number of bytes needed after position to reach the
+// alignment boundary of the writer
+func (w *Writer) padding(position int64) int64 {
+	if w.alignment <= 1 {
+		return 0
+	}
+	return calculatePadding(position, w.alignment)
 }
 
-// NewWriter creates a new column file writer
+// NewWriter creates a new column file writer. The options are validated before
+// the file is created, so invalid options leave an existing file untouched.
 func NewWriter(filename string, options ...WriterOption) (*Writer, error) {
+	writer, err := configureWriter(options...)
+	if err != nil {
+		return nil, err
+	}
+
 	file, err := os.Create(filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create file: %w", err)
 	}
+	if err := writer.start(file); err != nil {
+		os.Remove(filename)
+		return nil, err
+	}
+	writer.path = filename
+	return writer, nil
+}
+
+// NewWriterToBuffer creates a writer that builds the column file in memory, e.g.
+// for tests or small embedded datasets. The complete file is appended to buf
+// when the writer is closed, usually by FinalizeAndClose.
+func NewWriterToBuffer(buf *bytes.Buffer, options ...WriterOption) (*Writer, error) {
+	writer, err := newWriter(&bufferFile{dst: buf}, options...)
+	if err != nil {
+		return nil, err
+	}
+	if writer.checkpointInterval > 0 {
+		return nil, errors.New("checkpoints require a writer created with NewWriter")
+	}
+	if writer.sidecar {
+		return nil, errors.New("sidecars require a writer created with NewWriter")
+	}
+	return writer, nil
+}
+
+// newWriter applies the options and writes the file header to file. The file
+// is closed if the options are invalid.
+func newWriter(file writerFile, options ...WriterOption) (*Writer, error) {
+	writer, err := configureWriter(options...)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := writer.start(file); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
 
+// configureWriter applies the options to a new writer and validates them,
+// before the writer has a file
+func configureWriter(options ...WriterOption) (*Writer, error) {
 	writer := &Writer{
-		file:            file,
 		blockCount:      0,
 		encodingType:    EncodingRaw, // Default
+		dataType:        DataTypeInt64,
 		blockSizeTarget: defaultBlockSize,
+		alignment:       PageSize,
+		checksumType:    ChecksumCRC64ISO,
 		blockPositions:  make([]uint64, 0),
 		blockSizes:      make([]uint32, 0),
 		blockStats:      make([]BlockStats, 0),
@@ -50,11 +133,43 @@ func NewWriter(filename string, options ...WriterOption) (*Writer, error) {
 		option(writer)
 	}
 
-	// Write the file header
-	if err := writer.writeHeader(); err != nil {
-		file.Close()
-		return nil, fmt.Errorf("failed to write header: %w", err)
+	switch writer.dataType {
+	case DataTypeInt64, DataTypeUint64, DataTypeInt64List, DataTypeBool, DataTypeTimestampMillis:
+	default:
+		return nil, fmt.Errorf("unsupported data type: %d", writer.dataType)
+	}
+
+	// Timestamps are typically written in order with a near-constant stride
+	if writer.dataType == DataTypeTimestampMillis && !writer.encodingSet {
+		writer.encodingType = EncodingDeltaDelta
+	}
+
+	if writer.checksumType != ChecksumNone {
+		checksum, err := format.NewChecksum(writer.checksumType)
+		if err != nil {
+			return nil, err
+		}
+		writer.checksum = checksum
 	}
 
 	return writer, nil
 }
+
+// start attaches the file to a configured writer and writes the file header.
+// The file is closed if the header cannot be written.
+func (w *Writer) start(file writerFile) error {
+	w.file = file
+	if w.checksum != nil {
+		w.file = &checksumFile{writerFile: w.file, checksum: w.checksum}
+	}
+	if w.rateLimiter != nil {
+		w.file = rateLimitedFile{writerFile: w.file, limiter: w.rateLimiter}
+	}
+
+	// Write the file header
+	if err := w.writeHeader(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+	return nil
+}
@@ -6,149 +6,111 @@ import (
 	"io"
 )
 
-// writeBlockHeader writes the block header to the file
-func (w *Writer) writeBlockHeader(minID, maxID uint64, minValueU64, maxValueU64, sumU64 uint64, count uint32) (int64, error) {
-	// Record start position to verify header size
-	headerStart, err := w.file.Seek(0, io.SeekCurrent)
+// writeBlockHeader writes the block header and the layout section following it
+func (w *Writer) writeBlockHeader(header BlockHeader, layout BlockLayout) error {
+	headerBuf, err := header.MarshalBinary()
 	if err != nil {
-		return 0, fmt.Errorf("failed to get block header start position: %w", err)
+		return err
 	}
-
-	// Write block header fields
-	if err := binary.Write(w.file, binary.LittleEndian, minID); err != nil {
-		return 0, fmt.Errorf("failed to write min ID: %w", err)
-	}
-	if err := binary.Write(w.file, binary.LittleEndian, maxID); err != nil {
-		return 0, fmt.Errorf("failed to write max ID: %w", err)
-	}
-	if err := binary.Write(w.file, binary.LittleEndian, minValueU64); err != nil {
-		return 0, fmt.Errorf("failed to write min value: %w", err)
-	}
-	if err := binary.Write(w.file, binary.LittleEndian, maxValueU64); err != nil {
-		return 0, fmt.Errorf("failed to write max value: %w", err)
-	}
-	if err := binary.Write(w.file, binary.LittleEndian, sumU64); err != nil {
-		return 0, fmt.Errorf("failed to write sum: %w", err)
-	}
-	if err := binary.Write(w.file, binary.LittleEndian, count); err != nil {
-		return 0, fmt.Errorf("failed to write count: %w", err)
+	layoutBuf, err := layout.MarshalBinary()
+	if err != nil {
+		return err
 	}
-	if err := binary.Write(w.file, binary.LittleEndian, w.encodingType); err != nil {
-		return 0, fmt.Errorf("failed to write encoding type: %w", err)
+
+	if _, err := w.file.Write(headerBuf); err != nil {
+		return fmt.Errorf("failed to write block header: %w", err)
 	}
-	if err := binary.Write(w.file, binary.LittleEndian, uint32(CompressionNone)); err != nil {
-		return 0, fmt.Errorf("failed to write compression type: %w", err)
+	if _, err := w.file.Write(layoutBuf); err != nil {
+		return fmt.Errorf("failed to write block layout: %w", err)
 	}
+	return nil
+}
 
-	// This is not the complete block header yet - the next part of the code will write:
-	// - uncompressedSize (4 bytes)
-	// - compressedSize (4 bytes)
-	// - checksum (8 bytes)
-	// - reserved bytes (8 bytes)
-	// These are not included in this function, but they are part of the 64-byte block header
-
-	// The block header up to this point should be 48 bytes:
-	// - minID (8 bytes)
-	// - maxID (8 bytes)
-	// - minValue (8 bytes)
-	// - maxValue (8 bytes)
-	// - sum (8 bytes)
-	// - count (4 bytes)
-	// - encodingType (4 bytes)
-	// - compressionType (4 bytes)
-	// = 52 bytes
-
-	// Verify we've written the expected number of bytes so far
-	currentPos, err := w.file.Seek(0, io.SeekCurrent)
+// encodeIDs encodes the IDs based on the encoding type
+func encodeIDs(ids []uint64, encodingType uint32) ([]uint64, [][]byte, uint32, error) {
+	encoding, _, err := sectionEncodings(encodingType)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get current position: %w", err)
+		return nil, nil, 0, err
 	}
-
-	writtenSoFar := currentPos - headerStart
-	expectedSoFar := int64(8 + 8 + 8 + 8 + 8 + 4 + 4 + 4) // 52 bytes
-
-	if writtenSoFar != expectedSoFar {
-		return writtenSoFar, fmt.Errorf("block header partial size mismatch: expected=%d, actual=%d",
-			expectedSoFar, writtenSoFar)
+	if encoding.deltaOfDelta {
+		// The second differences can be negative, so they are ZigZag encoded
+		encoding.delta = true
+		return encodeData(encoding, ids, deltaOfDeltaEncode, encodeZigZagVarInt)
 	}
-
-	return writtenSoFar, nil
+	return encodeData(encoding, ids, deltaEncode, encodeVarInt)
 }
 
-// writeBlockFooter writes the block footer to the file
-func (w *Writer) writeBlockFooter(blockOffset, blockSize uint64, minID, maxID uint64, minValue, maxValue, sum int64, count uint32) error {
-	// Record start position to verify footer entry size
-	footerEntryStart, err := w.file.Seek(0, io.SeekCurrent)
+// encodeValues encodes the values of a column with the given data type based on
+// the encoding type
+func encodeValues(values []int64, encodingType uint32, dataType uint32) ([]int64, [][]byte, uint32, error) {
+	_, encoding, err := sectionEncodings(encodingType)
 	if err != nil {
-		return fmt.Errorf("failed to get footer entry start position: %w", err)
-	}
-
-	entry := NewFooterEntry(
-		blockOffset,
-		uint32(blockSize),
-		minID, maxID,
-		minValue, maxValue, sum,
-		count,
-	)
-
-	if err := binary.Write(w.file, binary.LittleEndian, entry.BlockOffset); err != nil {
-		return fmt.Errorf("failed to write block offset: %w", err)
-	}
-	if err := binary.Write(w.file, binary.LittleEndian, entry.BlockSize); err != nil {
-		return fmt.Errorf("failed to write block size: %w", err)
-	}
-	if err := binary.Write(w.file, binary.LittleEndian, entry.MinID); err != nil {
-		return fmt.Errorf("failed to write min ID: %w", err)
+		return nil, nil, 0, err
 	}
-	if err := binary.Write(w.file, binary.LittleEndian, entry.MaxID); err != nil {
-		return fmt.Errorf("failed to write max ID: %w", err)
+	if !zigzagValues(encoding, dataType) {
+		return encodeData(encoding, values, deltaEncodeInt64, encodeUnsignedVarInt)
 	}
-	if err := binary.Write(w.file, binary.LittleEndian, entry.MinValue); err != nil {
-		return fmt.Errorf("failed to write min value: %w", err)
+	return encodeData(encoding, values, deltaEncodeInt64, encodeSignedVarInt)
+}
+
+// appendSection appends an encoded data section to buf: the VarInts if the
+// section is variable-length encoded, the fixed-width entries otherwise
+func appendSection[T uint64 | int64](buf []byte, encoded []T, encodedBytes [][]byte) []byte {
+	if encodedBytes != nil {
+		for _, varint := range encodedBytes {
+			buf = append(buf, varint...)
+		}
+		return buf
 	}
-	if err := binary.Write(w.file, binary.LittleEndian, entry.MaxValue); err != nil {
-		return fmt.Errorf("failed to write max value: %w", err)
+	for _, v := range encoded {
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(v))
 	}
-	if err := binary.Write(w.file, binary.LittleEndian, entry.Sum); err != nil {
-		return fmt.Errorf("failed to write sum: %w", err)
+	return buf
+}
+
+// writeEncodedBlock writes a block whose data sections were encoded by the
+// caller, for data types whose sections differ from the ID-value pairs of
+// writeBlockInternal. The statistics are computed from ids and the values as
+// int64. The block is written as a whole, regardless of the target size.
+func (w *Writer) writeEncodedBlock(ids []uint64, values []int64, idSection, valueSection []byte, config blockConfig) error {
+	blockStart, err := w.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("failed to get block start position: %w", err)
 	}
-	if err := binary.Write(w.file, binary.LittleEndian, entry.Count); err != nil {
-		return fmt.Errorf("failed to write count: %w", err)
+	dataSize := uint64(len(idSection)) + uint64(len(valueSection))
+	if err := checkBlockSize(len(values), w.paddedBlockSize(blockStart, dataSize)); err != nil {
+		return err
 	}
 
-	// Verify footer entry size
-	footerEntryEnd, err := w.file.Seek(0, io.SeekCurrent)
+	stats := BlockStats{Count: uint32(len(values)), partition: config.partition}
+	stats.MinID, stats.MaxID = calculateMinMaxUint64(ids)
+	stats.MinValue, stats.MaxValue = calculateMinMaxInt64(values)
+	stats.Sum = calculateSumInt64(values)
+	stats.SumSquares, stats.NegativeCount, stats.ZeroCount = calculateExtendedStatsInt64(values)
+
+	header := NewBlockHeader(stats.MinID, stats.MaxID, stats.MinValue, stats.MaxValue, stats.Sum,
+		stats.Count, config.encodingType)
+	headerBuf, err := header.MarshalBinary()
 	if err != nil {
-		return fmt.Errorf("failed to get footer entry end position: %w", err)
+		return err
+	}
+	layoutBuf, err := BlockLayout{
+		IDSectionSize:      uint32(len(idSection)),
+		ValueSectionOffset: uint32(len(idSection)),
+		ValueSectionSize:   uint32(len(valueSection)),
+	}.MarshalBinary()
+	if err != nil {
+		return err
 	}
 
-	actualFooterEntrySize := footerEntryEnd - footerEntryStart
-	// A footer entry consists of:
-	// - BlockOffset (8 bytes)
-	// - BlockSize (4 bytes)
-	// - MinID (8 bytes)
-	// - MaxID (8 bytes)
-	// - MinValue (8 bytes)
-	// - MaxValue (8 bytes)
-	// - Sum (8 bytes)
-	// - Count (4 bytes)
-	// Total: 56 bytes
-	expectedFooterEntrySize := int64(8 + 4 + 8 + 8 + 8 + 8 + 8 + 4)
-
-	if actualFooterEntrySize != expectedFooterEntrySize {
-		return fmt.Errorf("footer entry size mismatch: expected=%d, actual=%d",
-			expectedFooterEntrySize, actualFooterEntrySize)
+	data := make([]byte, 0, len(headerBuf)+len(layoutBuf)+len(idSection)+len(valueSection))
+	data = append(append(append(append(data, headerBuf...), layoutBuf...), idSection...), valueSection...)
+	if err := w.appendRawBlock(data, stats, ids); err != nil {
+		return err
 	}
 
+	if err := w.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync file: %w", err)
+	}
 	return nil
 }
-
-// encodeIDs encodes the IDs based on the encoding type
-func (w *Writer) encodeIDs(ids []uint64) ([]uint64, [][]byte, uint32, error) {
-	return encodeData(w.encodingType, ids, deltaEncode, encodeVarInt)
-}
-
-// encodeValues encodes the values based on the encoding type
-func (w *Writer) encodeValues(values []int64) ([]int64, [][]byte, uint32, error) {
-	return encodeData(w.encodingType, values, deltaEncodeInt64, encodeSignedVarInt)
-}
@@ -1,9 +1,10 @@
 package col
 
 import (
-	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
+	"sort"
 )
 
 // BlockFullError is returned when a block would exceed the target size
@@ -19,6 +20,47 @@ func (e *BlockFullError) Error() string {
 // If the block would exceed the target size, it writes as many items as possible
 // and returns a BlockFullError with information about how many items were written
 func (w *Writer) WriteBlock(ids []uint64, values []int64) error {
+	return w.WriteBlockWithOptions(ids, values)
+}
+
+// WriteBlockUint64 writes a block of ID-value pairs to a DataTypeUint64 column
+// like WriteBlockWithOptions
+func (w *Writer) WriteBlockUint64(ids []uint64, values []uint64, options ...BlockOption) error {
+	if w.dataType != DataTypeUint64 {
+		return fmt.Errorf("cannot write uint64 values to a column of data type %d", w.dataType)
+	}
+
+	// The values are stored as their bit patterns
+	bitPatterns := make([]int64, len(values))
+	for i, v := range values {
+		bitPatterns[i] = int64(v)
+	}
+	return w.WriteBlockWithOptions(ids, bitPatterns, options...)
+}
+
+// WriteBlockWithOptions writes a block of ID-value pairs like WriteBlock, applying
+// the given block options. This allows e.g. a single block to use a different
+// encoding than the file default, which is recorded in the block header.
+func (w *Writer) WriteBlockWithOptions(ids []uint64, values []int64, options ...BlockOption) error {
+	switch w.dataType {
+	case DataTypeInt64List:
+		return fmt.Errorf("cannot write pairs to a list column, use WriteBlockLists")
+	case DataTypeBool:
+		return fmt.Errorf("cannot write pairs to a bool column, use WriteBlockBool")
+	}
+
+	config := blockConfig{
+		encodingType:    w.encodingType,
+		compressionType: CompressionNone,
+	}
+	for _, option := range options {
+		option(&config)
+	}
+
+	if config.compressionType != CompressionNone {
+		return fmt.Errorf("unsupported compression type: %d", config.compressionType)
+	}
+
 	if len(ids) != len(values) {
 		return fmt.Errorf("ids and values must have the same length")
 	}
@@ -27,177 +69,228 @@ func (w *Writer) WriteBlock(ids []uint64, values []int64) error {
 		return fmt.Errorf("cannot write empty block")
 	}
 
-	// First, check if the entire block would exceed the target size
-	estimatedSize, err := w.EstimateBlockSize(ids, values)
-	if err != nil {
-		return fmt.Errorf("failed to estimate block size: %w", err)
+	if w.validateSorted {
+		keptIDs, keptValues, ends, err := w.checkSortedIDs(ids, values)
+		if err != nil {
+			return err
+		}
+		if ends != nil {
+			// Partial writes are reported in pairs passed in, not pairs kept
+			err := w.writeBlockWithConfig(keptIDs, keptValues, config)
+			if blockFullErr, ok := err.(*BlockFullError); ok {
+				return &BlockFullError{ItemsWritten: ends[blockFullErr.ItemsWritten-1]}
+			}
+			return err
+		}
 	}
 
-	// If the block would exceed the target size and we have more than one item,
-	// try to find the maximum number of items that would fit
-	if estimatedSize > uint64(w.blockSizeTarget) && len(ids) > 1 {
-		// Start with a single item and incrementally add more until we reach the target size
-		var optimal int = 1
+	return w.writeBlockWithConfig(ids, values, config)
+}
 
-		// Try each size from 1 to len(ids)-1
-		for i := 1; i < len(ids); i++ {
-			size, err := w.EstimateBlockSize(ids[:i], values[:i])
-			if err != nil {
-				break
-			}
+// WriteBlockWithStats writes a block of ID-value pairs whose statistics the
+// caller already knows, e.g. when compacting blocks of other files. Unlike
+// WriteBlock, it neither recomputes the statistics nor negotiates the block
+// size: the block is encoded once and written as a whole, with the given
+// encoding even if the Writer selects encodings automatically. The caller is
+// responsible for stats being correct, including the extended statistics;
+// Reader.ValidateFooterAgainstBlocks reports blocks whose statistics do not
+// match their values. Only checks that take constant time are made: the count,
+// that the minimums do not exceed the maximums, and that the first and last ID
+// are within the ID range, or are its bounds if the Writer validates sorting.
+func (w *Writer) WriteBlockWithStats(ids []uint64, values []int64, stats BlockStats, options ...BlockOption) error {
+	switch w.dataType {
+	case DataTypeInt64List, DataTypeBool:
+		return fmt.Errorf("cannot write pairs to a column of data type %d", w.dataType)
+	}
 
-			if size <= uint64(w.blockSizeTarget) {
-				optimal = i
-			} else {
-				// We've exceeded the target size, stop here
-				break
-			}
-		}
+	config := blockConfig{
+		encodingType:    w.encodingType,
+		compressionType: CompressionNone,
+	}
+	for _, option := range options {
+		option(&config)
+	}
+
+	if config.compressionType != CompressionNone {
+		return fmt.Errorf("unsupported compression type: %d", config.compressionType)
+	}
+	if len(ids) != len(values) {
+		return fmt.Errorf("ids and values must have the same length")
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("cannot write empty block")
+	}
+	if int(stats.Count) != len(ids) {
+		return fmt.Errorf("statistics count %d does not match the %d pairs of the block", stats.Count, len(ids))
+	}
+	if stats.MinID > stats.MaxID {
+		return fmt.Errorf("statistics minimum ID %d exceeds maximum ID %d", stats.MinID, stats.MaxID)
+	}
+	if stats.MinValue > stats.MaxValue {
+		return fmt.Errorf("statistics minimum value %d exceeds maximum value %d", stats.MinValue, stats.MaxValue)
+	}
+	first, last := ids[0], ids[len(ids)-1]
+	if first < stats.MinID || first > stats.MaxID || last < stats.MinID || last > stats.MaxID {
+		return fmt.Errorf("IDs %d and %d are outside the statistics ID range [%d, %d]",
+			first, last, stats.MinID, stats.MaxID)
+	}
 
-		// Write the partial block
-		if err := w.writeBlockInternal(ids[:optimal], values[:optimal]); err != nil {
+	if w.validateSorted {
+		_, _, ends, err := w.checkSortedIDs(ids, values)
+		if err != nil {
 			return err
 		}
+		if ends != nil {
+			return fmt.Errorf("cannot write a block with duplicate IDs with precomputed statistics")
+		}
+		if first != stats.MinID || last != stats.MaxID {
+			return fmt.Errorf("IDs %d to %d do not match the statistics ID range [%d, %d]",
+				first, last, stats.MinID, stats.MaxID)
+		}
+	}
 
-		// Return a BlockFullError with the number of items written
-		return &BlockFullError{ItemsWritten: optimal}
+	// Unsigned statistics are not part of the public fields
+	if w.dataType == DataTypeUint64 {
+		stats.unsigned = calculateUnsignedStats(values)
 	}
 
-	// If we get here, either the block fits or we couldn't find a partial solution
-	return w.writeBlockInternal(ids, values)
+	return w.writeBlockWithStats(ids, values, stats, config)
 }
 
-// writeBlockInternal is the actual implementation of WriteBlock
-// It writes the block without checking the target size
-func (w *Writer) writeBlockInternal(ids []uint64, values []int64) error {
-	// Add all IDs to the global ID bitmap
-	for _, id := range ids {
-		w.globalIDs.Set(id)
-	}
-
-	// Determine if we need to use variable-length encoding
-	useVarIntForIDs := w.encodingType == EncodingVarInt ||
-		w.encodingType == EncodingVarIntID ||
-		w.encodingType == EncodingVarIntBoth
-	useVarIntForValues := w.encodingType == EncodingVarInt ||
-		w.encodingType == EncodingVarIntValue ||
-		w.encodingType == EncodingVarIntBoth
+// writeBlockWithConfig writes a validated, non-empty block like
+// WriteBlockWithOptions
+func (w *Writer) writeBlockWithConfig(ids []uint64, values []int64, config blockConfig) error {
 
-	// Encode IDs and values
-	encodedIDs, encodedIdBytes, idSectionSize, err := w.encodeIDs(ids)
-	if err != nil {
-		return err
+	// Blocks without an encoding of their own may pick a smaller one
+	if w.autoEncoding && config.encodingType == w.encodingType {
+		encodingType, err := w.selectEncoding(ids, values, config.encodingType)
+		if err != nil {
+			return err
+		}
+		config.encodingType = encodingType
 	}
 
-	encodedValues, encodedValueBytes, valueSectionSize, err := w.encodeValues(values)
+	// Encode the block once, partial blocks write a prefix of its sections
+	block, err := w.encodeBlock(ids, values, config.encodingType)
 	if err != nil {
 		return err
 	}
 
-	// Calculate statistics for the block using ORIGINAL values, not encoded values
-	// This ensures that aggregations are correct regardless of encoding
-	minID, maxID := calculateMinMaxUint64(ids)
-	minValue, maxValue := calculateMinMaxInt64(values)
-	sum := calculateSumInt64(values)
-	count := uint32(len(ids))
-
-	// Write block header (64 bytes)
+	// Padding depends on where the block starts
 	blockStart, err := w.file.Seek(0, io.SeekCurrent)
 	if err != nil {
 		return fmt.Errorf("failed to get block start position: %w", err)
 	}
+	fits := func(n int) bool {
+		dataSize := block.idSectionSize(n) + block.valueSectionSize(n)
+		return w.paddedBlockSize(blockStart, dataSize) <= uint64(w.blockSizeTarget)
+	}
 
-	// Store this position so we can refer to it later in the footer
-	w.blockPositions = append(w.blockPositions, uint64(blockStart))
+	// If the block would exceed the target size, write the largest prefix that
+	// fits, but at least one pair. Block sizes grow with the number of pairs, so
+	// the prefix can be found by binary search.
+	if len(ids) > 1 && !fits(len(ids)) {
+		n := sort.Search(len(ids)-1, func(i int) bool { return !fits(i + 1) })
+		if n == 0 {
+			n = 1
+		}
 
-	// Convert int64 values to uint64 for storage
-	minValueU64 := int64ToUint64(minValue)
-	maxValueU64 := int64ToUint64(maxValue)
-	sumU64 := int64ToUint64(sum)
+		stats := w.calculateBlockStats(ids[:n], values[:n])
+		if err := w.writeEncodedPrefix(block, ids[:n], values[:n], stats, config); err != nil {
+			return err
+		}
 
-	headerWritten := int64(0)
-	// Write block header
-	if n, err := w.writeBlockHeader(minID, maxID, minValueU64, maxValueU64, sumU64, count); err != nil {
-		return err
-	} else {
-		headerWritten = n
+		// Return a BlockFullError with the number of items written
+		return &BlockFullError{ItemsWritten: n}
 	}
 
-	// Total data size (ID section + value section) helps with debugging
-	// but isn't needed for the file format
+	return w.writeEncodedPrefix(block, ids, values, w.calculateBlockStats(ids, values), config)
+}
 
-	uncompressedSize := int32(0)       // Not implemented yet
-	compressedSize := uncompressedSize // Same as uncompressed for now
+// writeBlockInternal is the actual implementation of WriteBlock
+// It writes the block without checking the target size
+func (w *Writer) writeBlockInternal(ids []uint64, values []int64, config blockConfig) error {
+	return w.writeBlockWithStats(ids, values, w.calculateBlockStats(ids, values), config)
+}
 
-	if err := binary.Write(w.file, binary.LittleEndian, uncompressedSize); err != nil {
-		return fmt.Errorf("failed to write uncompressed size: %w", err)
+// checkBlockSize returns an error if a block of count pairs and blockSize bytes,
+// including padding, exceeds the limits of the uint32 size and count fields
+func checkBlockSize(count int, blockSize uint64) error {
+	if uint64(count) > math.MaxUint32 {
+		return fmt.Errorf("block of %d pairs exceeds the maximum of %d pairs per block", count, uint64(math.MaxUint32))
 	}
-	headerWritten += 4
-	if err := binary.Write(w.file, binary.LittleEndian, compressedSize); err != nil {
-		return fmt.Errorf("failed to write compressed size: %w", err)
+	if blockSize > maxBlockSize {
+		return fmt.Errorf("block of %d bytes exceeds the maximum block size of %d bytes", blockSize, uint64(maxBlockSize))
 	}
-	headerWritten += 4
+	return nil
+}
 
-	// Write checksum placeholder (will be updated later when checksums are implemented)
-	if _, err := w.file.Seek(0, io.SeekCurrent); err != nil {
-		return fmt.Errorf("failed to get current position: %w", err)
-	}
+// calculateBlockStats calculates the statistics of a block using the original
+// values, not the encoded ones, so aggregations are correct regardless of the
+// encoding
+func (w *Writer) calculateBlockStats(ids []uint64, values []int64) BlockStats {
+	stats := BlockStats{Count: uint32(len(ids))}
+	stats.MinID, stats.MaxID = calculateMinMaxUint64(ids)
+	stats.MinValue, stats.MaxValue = calculateMinMaxInt64(values)
+	stats.Sum = calculateSumInt64(values)
+	stats.SumSquares, stats.NegativeCount, stats.ZeroCount = calculateExtendedStatsInt64(values)
+
+	// Unsigned columns additionally keep statistics of the unsigned values
+	if w.dataType == DataTypeUint64 {
+		stats.unsigned = calculateUnsignedStats(values)
+	}
+	return stats
+}
 
-	if err := binary.Write(w.file, binary.LittleEndian, uint64(0)); err != nil {
-		return fmt.Errorf("failed to write checksum: %w", err)
+// writeBlockWithStats writes a block with the given statistics without
+// checking the target size
+func (w *Writer) writeBlockWithStats(ids []uint64, values []int64, stats BlockStats, config blockConfig) error {
+	block, err := w.encodeBlock(ids, values, config.encodingType)
+	if err != nil {
+		return err
 	}
-	headerWritten += 8
+	return w.writeEncodedPrefix(block, ids, values, stats, config)
+}
 
-	reserved := blockHeaderSize - headerWritten
-	if _, err := w.file.Seek(reserved, io.SeekCurrent); err != nil {
-		return fmt.Errorf("failed to skip reserved bytes: %w", err)
-	}
-	headerWritten += reserved
+// writeEncodedPrefix writes the block of the first len(ids) pairs of an encoded
+// block with the given statistics, without checking the target size
+func (w *Writer) writeEncodedPrefix(block *encodedBlock, ids []uint64, values []int64, stats BlockStats, config blockConfig) error {
+	encodingType := config.encodingType
+	count := stats.Count
 
-	if headerWritten != blockHeaderSize {
-		return fmt.Errorf("block header size mismatch: expected=%d, actual=%d",
-			blockHeaderSize, headerWritten)
+	blockStart, err := w.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("failed to get block start position: %w", err)
 	}
 
-	// Write the block layout section (16 bytes)
-	// The section layout according to spec:
-	// 1. ID section offset (from start of data section)
-	// 2. ID section size in bytes
-	// 3. Value section offset (from start of data section)
-	// 4. Value section size in bytes
-
-	// Validate section sizes
-	if idSectionSize == 0 {
-		return fmt.Errorf("ID section size is 0, which is invalid. useVarIntForIDs=%v, count=%d",
-			useVarIntForIDs, count)
+	// Sizes and counts are stored as uint32, larger blocks cannot be recorded
+	dataSize := block.idSectionSize(len(ids)) + block.valueSectionSize(len(ids))
+	if err := checkBlockSize(len(ids), w.paddedBlockSize(blockStart, dataSize)); err != nil {
+		return err
 	}
+	idSectionSize := uint32(block.idSectionSize(len(ids)))
+	valueSectionSize := uint32(block.valueSectionSize(len(ids)))
 
-	if valueSectionSize == 0 {
-		return fmt.Errorf("Value section size is 0, which is invalid. useVarIntForValues=%v, count=%d",
-			useVarIntForValues, count)
+	// Add all IDs to the global ID bitmap
+	for _, id := range ids {
+		w.globalIDs.Set(id)
 	}
 
 	// Per spec section 4.2:
 	// - ID section comes first in the data section
 	// - Value section follows the ID section
 	// The offsets are relative to the end of the block header (after the 16-byte layout section)
-	idSectionOffset := uint32(0)
-	valueSectionOffset := idSectionSize
-
-	// Create a layout buffer and fill it
-	layoutBuf := make([]byte, 16)
-	binary.LittleEndian.PutUint32(layoutBuf[0:4], idSectionOffset)
-	binary.LittleEndian.PutUint32(layoutBuf[4:8], idSectionSize)
-	binary.LittleEndian.PutUint32(layoutBuf[8:12], valueSectionOffset)
-	binary.LittleEndian.PutUint32(layoutBuf[12:16], valueSectionSize)
-
-	// Write the layout buffer to file
-	bytesWritten, err := w.file.Write(layoutBuf)
-	if err != nil {
-		return fmt.Errorf("failed to write block layout: %w", err)
+	layout := BlockLayout{
+		IDSectionOffset:    0,
+		IDSectionSize:      idSectionSize,
+		ValueSectionOffset: idSectionSize,
+		ValueSectionSize:   valueSectionSize,
 	}
-	if bytesWritten != 16 {
-		return fmt.Errorf("failed to write block layout: wrote %d bytes, expected 16", bytesWritten)
+
+	// Write the block header (64 bytes), followed by the layout section (16 bytes)
+	header := NewBlockHeader(stats.MinID, stats.MaxID, stats.MinValue, stats.MaxValue, stats.Sum, count, encodingType)
+	if err := w.writeBlockHeader(header, layout); err != nil {
+		return err
 	}
 
 	// Start of data section - this position is important for checksum calculation
@@ -208,62 +301,12 @@ func (w *Writer) writeBlockInternal(ids []uint64, values []int64) error {
 	}
 	_ = dataSectionStart // Unused for now
 
-	// Write ID array based on encoding type
-	var actualIdSectionSize int64 = 0
-
-	if useVarIntForIDs {
-		// Use variable-length encoding for IDs (using precomputed values)
-		for i := range encodedIDs {
-			// Write the precomputed varint bytes for this ID
-			written, err := w.file.Write(encodedIdBytes[i])
-			if err != nil {
-				return fmt.Errorf("failed to write varint ID: %w", err)
-			}
-			actualIdSectionSize += int64(written)
-		}
-	} else {
-		// Write fixed-length IDs
-		for _, id := range encodedIDs {
-			if err := binary.Write(w.file, binary.LittleEndian, id); err != nil {
-				return fmt.Errorf("failed to write ID: %w", err)
-			}
-			actualIdSectionSize += 8
-		}
-	}
-
-	// Verify ID section size
-	if uint32(actualIdSectionSize) != idSectionSize {
-		return fmt.Errorf("ID section size mismatch: expected=%d, actual=%d",
-			idSectionSize, actualIdSectionSize)
-	}
-
-	// Write Value array based on encoding type
-	var actualValueSectionSize int64 = 0
-
-	if useVarIntForValues {
-		// Use variable-length encoding for values (using precomputed values)
-		for i := range encodedValues {
-			// Write the precomputed varint bytes for this value
-			written, err := w.file.Write(encodedValueBytes[i])
-			if err != nil {
-				return fmt.Errorf("failed to write varint value: %w", err)
-			}
-			actualValueSectionSize += int64(written)
-		}
-	} else {
-		// Write fixed-length values
-		for _, val := range encodedValues {
-			if err := binary.Write(w.file, binary.LittleEndian, val); err != nil {
-				return fmt.Errorf("failed to write value: %w", err)
-			}
-			actualValueSectionSize += 8
-		}
+	// Write the ID section, followed by the value section
+	if _, err := w.file.Write(block.ids[:idSectionSize]); err != nil {
+		return fmt.Errorf("failed to write ID section: %w", err)
 	}
-
-	// Verify value section size
-	if uint32(actualValueSectionSize) != valueSectionSize {
-		return fmt.Errorf("value section size mismatch: expected=%d, actual=%d",
-			valueSectionSize, actualValueSectionSize)
+	if _, err := w.file.Write(block.values[:valueSectionSize]); err != nil {
+		return fmt.Errorf("failed to write value section: %w", err)
 	}
 
 	// Get end position to calculate block size
@@ -275,8 +318,8 @@ func (w *Writer) writeBlockInternal(ids []uint64, values []int64) error {
 	// Calculate actual block size
 	blockSize := uint64(blockEnd - blockStart)
 
-	// Add padding if needed to align to page boundary
-	padding := calculatePadding(blockEnd, PageSize)
+	// Add padding if needed to align the next block
+	padding := w.padding(blockEnd)
 	if padding > 0 {
 		// Create padding buffer filled with zeros
 		paddingBuf := make([]byte, padding)
@@ -300,17 +343,17 @@ func (w *Writer) writeBlockInternal(ids []uint64, values []int64) error {
 			expectedBlockSize, blockSize-uint64(padding), blockSizeDifference)
 	}
 
+	// Record position and size only once the block is complete, so that a
+	// failed write does not leave the footer bookkeeping inconsistent
+	w.blockPositions = append(w.blockPositions, uint64(blockStart))
 	w.blockSizes = append(w.blockSizes, uint32(blockSize))
 
 	// Store block statistics for footer
-	w.blockStats = append(w.blockStats, BlockStats{
-		MinID:    minID,
-		MaxID:    maxID,
-		MinValue: minValue,
-		MaxValue: maxValue,
-		Sum:      sum,
-		Count:    count,
-	})
+	stats.partition = config.partition
+	w.blockStats = append(w.blockStats, stats)
+	if w.valueOrder {
+		w.valueOrders = append(w.valueOrders, sortedValueOrder(values, w.dataType))
+	}
 
 	// Increment block count
 	w.blockCount++
@@ -320,12 +363,17 @@ func (w *Writer) writeBlockInternal(ids []uint64, values []int64) error {
 		return fmt.Errorf("failed to sync file: %w", err)
 	}
 
-	return nil
+	return w.checkpointIfDue()
 }
 
 // EstimateBlockSize calculates the exact size a block would be without writing it
 // This is useful for determining if a block would fit within a target size
 func (w *Writer) EstimateBlockSize(ids []uint64, values []int64) (uint64, error) {
+	return w.estimateBlockSize(ids, values, w.encodingType)
+}
+
+// estimateBlockSize calculates the exact size a block would be with the given encoding
+func (w *Writer) estimateBlockSize(ids []uint64, values []int64, encodingType uint32) (uint64, error) {
 	if len(ids) != len(values) {
 		return 0, fmt.Errorf("ids and values must have the same length")
 	}
@@ -334,35 +382,67 @@ func (w *Writer) EstimateBlockSize(ids []uint64, values []int64) (uint64, error)
 		return 0, fmt.Errorf("cannot estimate empty block")
 	}
 
-	// Encode IDs and values to get exact sizes
-	_, _, idSectionSize, err := w.encodeIDs(ids)
+	// The sizes of the entries are known without encoding them
+	dataSize, err := w.encodedDataSize(ids, values, encodingType)
 	if err != nil {
 		return 0, err
 	}
 
-	_, _, valueSectionSize, err := w.encodeValues(values)
+	// Padding depends on where the block would start
+	currentPos, err := w.file.Seek(0, io.SeekCurrent)
 	if err != nil {
-		return 0, err
+		return 0, fmt.Errorf("failed to get current position: %w", err)
 	}
 
-	// Calculate total block size
+	return w.paddedBlockSize(currentPos, dataSize), nil
+}
+
+// paddedBlockSize returns the size of a block with the given ID and value section
+// sizes combined, including the padding needed for alignment when the block
+// starts at blockStart
+func (w *Writer) paddedBlockSize(blockStart int64, dataSize uint64) uint64 {
 	// Block header + block layout + ID section + value section
-	totalSize := uint64(blockHeaderSize + blockLayoutSize + idSectionSize + valueSectionSize)
+	totalSize := uint64(blockHeaderSize+blockLayoutSize) + dataSize
 
-	// Add padding size if needed for page alignment
-	currentPos, err := w.file.Seek(0, io.SeekCurrent)
+	// Add padding if needed
+	padding := w.padding(blockStart + int64(totalSize))
+	return totalSize + uint64(padding)
+}
+
+// appendRawBlock appends an already encoded block, as read from another file with
+// the same encoding, without re-encoding it. The data must start with the block
+// header and must not include trailing padding. ids are the decoded IDs of the
+// block, used to maintain the global ID bitmap.
+func (w *Writer) appendRawBlock(data []byte, stats BlockStats, ids []uint64) error {
+	blockStart, err := w.file.Seek(0, io.SeekCurrent)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get current position: %w", err)
+		return fmt.Errorf("failed to get block start position: %w", err)
 	}
 
-	// Calculate where the block would end
-	blockEnd := currentPos + int64(totalSize)
+	if _, err := w.file.Write(data); err != nil {
+		return fmt.Errorf("failed to write raw block: %w", err)
+	}
 
-	// Add padding if needed
-	padding := calculatePadding(blockEnd, PageSize)
+	// Add padding to align the next block, like writeBlockInternal
+	blockEnd := blockStart + int64(len(data))
+	padding := w.padding(blockEnd)
 	if padding > 0 {
-		totalSize += uint64(padding)
+		if _, err := w.file.Write(make([]byte, padding)); err != nil {
+			return fmt.Errorf("failed to write padding bytes: %w", err)
+		}
 	}
 
-	return totalSize, nil
+	for _, id := range ids {
+		w.globalIDs.Set(id)
+	}
+
+	w.blockPositions = append(w.blockPositions, uint64(blockStart))
+	w.blockSizes = append(w.blockSizes, uint32(int64(len(data))+padding))
+	w.blockStats = append(w.blockStats, stats)
+	if w.valueOrder {
+		w.valueOrders = append(w.valueOrders, nil)
+	}
+	w.blockCount++
+
+	return w.checkpointIfDue()
 }
@@ -0,0 +1,111 @@
+package col
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeBlockMatchesEncodeData(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	ids := make([]uint64, 200)
+	values := make([]int64, 200)
+	id := uint64(1 << 40)
+	for i := range ids {
+		id += uint64(rng.Intn(1000))
+		ids[i] = id
+		values[i] = rng.Int63n(1<<20) - 1<<19
+	}
+
+	encodings := []uint32{EncodingRaw, EncodingDeltaID, EncodingDeltaValue, EncodingDeltaBoth, EncodingVarInt,
+		EncodingVarIntID, EncodingVarIntValue, EncodingVarIntBoth, EncodingDeltaDelta}
+	for _, dataType := range []uint32{DataTypeInt64, DataTypeUint64} {
+		for _, encoding := range encodings {
+			w := &Writer{dataType: dataType}
+			block, err := w.encodeBlock(ids, values, encoding)
+			require.NoError(t, err)
+
+			encodedIDs, encodedIDBytes, idSize, err := encodeIDs(ids, encoding)
+			require.NoError(t, err)
+			encodedValues, encodedValueBytes, valueSize, err := encodeValues(values, encoding, dataType)
+			require.NoError(t, err)
+
+			assert.Equal(t, appendSection(nil, encodedIDs, encodedIDBytes), block.ids, "encoding %d", encoding)
+			assert.Equal(t, appendSection(nil, encodedValues, encodedValueBytes), block.values, "encoding %d", encoding)
+			assert.Equal(t, uint64(idSize), block.idSectionSize(len(ids)))
+			assert.Equal(t, uint64(valueSize), block.valueSectionSize(len(ids)))
+
+			// Every prefix of the sections is the encoding of the prefix
+			for _, n := range []int{1, 2, 3, 57} {
+				_, _, idSize, err := encodeIDs(ids[:n], encoding)
+				require.NoError(t, err)
+				_, _, valueSize, err := encodeValues(values[:n], encoding, dataType)
+				require.NoError(t, err)
+				assert.Equal(t, uint64(idSize), block.idSectionSize(n), "encoding %d, %d pairs", encoding, n)
+				assert.Equal(t, uint64(valueSize), block.valueSectionSize(n), "encoding %d, %d pairs", encoding, n)
+			}
+		}
+	}
+}
+
+func TestWriteBlockLargestPrefix(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	ids := make([]uint64, 5000)
+	values := make([]int64, 5000)
+	for i := range ids {
+		ids[i] = uint64(i*3 + rng.Intn(3))
+		values[i] = rng.Int63n(1 << uint(rng.Intn(60)))
+	}
+
+	const target = 1024
+	var buf bytes.Buffer
+	w, err := NewWriterToBuffer(&buf, WithEncoding(EncodingVarIntBoth), WithBlockSize(target), WithPadding(PaddingNone))
+	require.NoError(t, err)
+
+	remainingIDs, remainingValues := ids, values
+	for len(remainingIDs) > 0 {
+		fullSize, err := w.estimateBlockSize(remainingIDs, remainingValues, EncodingVarIntBoth)
+		require.NoError(t, err)
+
+		err = w.WriteBlock(remainingIDs, remainingValues)
+		if err == nil {
+			assert.LessOrEqual(t, fullSize, uint64(target))
+			break
+		}
+		blockFullErr, ok := err.(*BlockFullError)
+		require.True(t, ok, "unexpected error: %v", err)
+		n := blockFullErr.ItemsWritten
+
+		// The block is the largest prefix within the target size
+		lastSize := uint64(w.blockSizes[len(w.blockSizes)-1])
+		assert.LessOrEqual(t, lastSize, uint64(target))
+		blockStart := int64(w.blockPositions[len(w.blockPositions)-1])
+		_, _, idSize, err := encodeIDs(remainingIDs[:n+1], EncodingVarIntBoth)
+		require.NoError(t, err)
+		_, _, valueSize, err := encodeValues(remainingValues[:n+1], EncodingVarIntBoth, DataTypeInt64)
+		require.NoError(t, err)
+		assert.Greater(t, w.paddedBlockSize(blockStart, uint64(idSize)+uint64(valueSize)), uint64(target))
+
+		remainingIDs, remainingValues = remainingIDs[n:], remainingValues[n:]
+	}
+	require.NoError(t, w.FinalizeAndClose())
+
+	r, err := NewReaderFromBytes(buf.Bytes())
+	require.NoError(t, err)
+	defer r.Close()
+	assert.Greater(t, r.BlockCount(), uint64(1))
+
+	var readIDs []uint64
+	var readValues []int64
+	for i := 0; i < int(r.BlockCount()); i++ {
+		blockIDs, blockValues, err := r.GetPairs(uint64(i))
+		require.NoError(t, err)
+		readIDs = append(readIDs, blockIDs...)
+		readValues = append(readValues, blockValues...)
+	}
+	assert.Equal(t, ids, readIDs)
+	assert.Equal(t, values, readValues)
+}
@@ -1,34 +1,29 @@
 package col
 
 import (
+	"encoding/binary"
 	"fmt"
 )
 
-// encodeData is a helper function to encode data based on the encoding type
-func encodeData[T any](encodingType uint32, data []T, deltaEncodeFunc func([]T) []T, encodeVarIntFunc func(T) []byte) ([]T, [][]byte, uint32, error) {
+// encodeData is a helper function to encode a data section based on its encoding
+func encodeData[T any](encoding sectionEncoding, data []T, deltaEncodeFunc func([]T) []T, encodeVarIntFunc func(T) []byte) ([]T, [][]byte, uint32, error) {
 	var encodedData []T
 	var encodedDataBytes [][]byte
 	var sectionSize uint32
 
 	// First apply delta encoding if needed
-	switch encodingType {
-	case EncodingRaw, EncodingVarInt, EncodingVarIntID:
-		// These encoding types don't use delta encoding
+	if encoding.delta {
+		encodedData = deltaEncodeFunc(data)
+	} else {
 		encodedData = make([]T, len(data))
 		copy(encodedData, data)
-	case EncodingDeltaID, EncodingDeltaValue, EncodingDeltaBoth, EncodingVarIntValue, EncodingVarIntBoth:
-		// These encoding types use delta encoding
-		encodedData = deltaEncodeFunc(data)
-	default:
-		return nil, nil, 0, fmt.Errorf("unsupported encoding type: %d", encodingType)
 	}
 
 	// Then apply varint encoding if needed
-	switch encodingType {
-	case EncodingRaw, EncodingDeltaID, EncodingDeltaValue, EncodingDeltaBoth:
+	if !encoding.varint {
 		// Fixed-width encoding
 		sectionSize = uint32(len(encodedData) * 8)
-	case EncodingVarInt, EncodingVarIntID, EncodingVarIntBoth, EncodingVarIntValue:
+	} else {
 		// Variable-width encoding
 		encodedDataBytes = make([][]byte, len(encodedData))
 		sectionSize = 0
@@ -47,3 +42,166 @@ func encodeData[T any](encodingType uint32, data []T, deltaEncodeFunc func([]T)
 
 	return encodedData, encodedDataBytes, sectionSize, nil
 }
+
+// encodedPairSize returns the number of bytes the pair at index i takes up in the
+// ID and value sections of a block holding ids and values. It matches the section
+// sizes computed by encodeIDs and encodeValues without encoding the whole block.
+func (w *Writer) encodedPairSize(ids []uint64, values []int64, i int) uint64 {
+	// Unknown encoding types are rejected when the block is written
+	idEncoding, valueEncoding, _ := sectionEncodings(w.encodingType)
+	return pairSize(ids, values, i, idEncoding, valueEncoding, w.dataType)
+}
+
+// pairSize returns the encoded size of the pair at index i with the given
+// section encodings, see encodedPairSize
+func pairSize(ids []uint64, values []int64, i int, idEncoding, valueEncoding sectionEncoding, dataType uint32) uint64 {
+	id, value := ids[i], values[i]
+
+	// Mirror the delta step of encodeData
+	if i > 0 && (idEncoding.delta || idEncoding.deltaOfDelta) {
+		id -= ids[i-1]
+	}
+	if i > 1 && idEncoding.deltaOfDelta {
+		id -= ids[i-1] - ids[i-2]
+	}
+	if i > 0 && valueEncoding.delta {
+		value -= values[i-1]
+	}
+
+	// Mirror the varint step of encodeData
+	size := uint64(uint64Size)
+	if idEncoding.varint && idEncoding.deltaOfDelta {
+		size = uint64(signedVarIntSize(int64(id)))
+	} else if idEncoding.varint {
+		size = uint64(varIntSize(id))
+	}
+	if valueEncoding.varint && !zigzagValues(valueEncoding, dataType) {
+		return size + uint64(varIntSize(uint64(value)))
+	}
+	if valueEncoding.varint {
+		return size + uint64(signedVarIntSize(value))
+	}
+	return size + uint64Size
+}
+
+// encodedDataSize returns the combined size of the ID and value sections of a
+// block holding ids and values with the given encoding type
+func (w *Writer) encodedDataSize(ids []uint64, values []int64, encodingType uint32) (uint64, error) {
+	idEncoding, valueEncoding, err := sectionEncodings(encodingType)
+	if err != nil {
+		return 0, err
+	}
+
+	var size uint64
+	for i := range ids {
+		size += pairSize(ids, values, i, idEncoding, valueEncoding, w.dataType)
+	}
+	return size, nil
+}
+
+// selectEncoding returns the encoding type for a block written with automatic
+// encoding selection. IDs with a near-constant stride, such as timestamps, are
+// delta-of-delta encoded if that makes the block smaller than the fallback
+// encoding.
+func (w *Writer) selectEncoding(ids []uint64, values []int64, fallback uint32) (uint32, error) {
+	// Delta-of-delta encoding only pays off for increasing IDs with more than
+	// one delta
+	if fallback == EncodingDeltaDelta || len(ids) < 3 || ids[1] <= ids[0] {
+		return fallback, nil
+	}
+
+	fallbackSize, err := w.encodedDataSize(ids, values, fallback)
+	if err != nil {
+		return 0, err
+	}
+	deltaDeltaSize, err := w.encodedDataSize(ids, values, EncodingDeltaDelta)
+	if err != nil {
+		return 0, err
+	}
+	if deltaDeltaSize < fallbackSize {
+		return EncodingDeltaDelta, nil
+	}
+	return fallback, nil
+}
+
+// encodedBlock holds the ID and value sections of a block, encoded once so that
+// the block or any prefix of it can be written without encoding it again. The
+// delta steps only depend on preceding entries, so the first n entries of a
+// section are the section of the first n pairs.
+type encodedBlock struct {
+	ids       []byte
+	values    []byte
+	idEnds    []uint64 // End offset of each entry in ids
+	valueEnds []uint64 // End offset of each entry in values
+}
+
+// idSectionSize returns the size of the ID section of the first n pairs
+func (b *encodedBlock) idSectionSize(n int) uint64 {
+	return b.idEnds[n-1]
+}
+
+// valueSectionSize returns the size of the value section of the first n pairs
+func (b *encodedBlock) valueSectionSize(n int) uint64 {
+	return b.valueEnds[n-1]
+}
+
+// encodeBlock encodes the sections of a block holding ids and values with the
+// given encoding type into the writer's block buffers, which are reused by the
+// next call
+func (w *Writer) encodeBlock(ids []uint64, values []int64, encodingType uint32) (*encodedBlock, error) {
+	idEncoding, valueEncoding, err := sectionEncodings(encodingType)
+	if err != nil {
+		return nil, err
+	}
+
+	block := &w.encoded
+	block.ids, block.idEnds = appendIDSection(block.ids[:0], block.idEnds[:0], ids, idEncoding)
+	block.values, block.valueEnds = appendValueSection(block.values[:0], block.valueEnds[:0], values,
+		valueEncoding, zigzagValues(valueEncoding, w.dataType))
+	return block, nil
+}
+
+// appendIDSection appends the ID section encoding of ids to buf and the end
+// offset of each entry to ends, with the same result as encodeIDs
+func appendIDSection(buf []byte, ends []uint64, ids []uint64, encoding sectionEncoding) ([]byte, []uint64) {
+	for i, id := range ids {
+		if i > 0 && (encoding.delta || encoding.deltaOfDelta) {
+			id -= ids[i-1]
+		}
+		if i > 1 && encoding.deltaOfDelta {
+			id -= ids[i-1] - ids[i-2]
+		}
+
+		switch {
+		case encoding.varint && encoding.deltaOfDelta:
+			buf = binary.AppendVarint(buf, int64(id))
+		case encoding.varint:
+			buf = binary.AppendUvarint(buf, id)
+		default:
+			buf = binary.LittleEndian.AppendUint64(buf, id)
+		}
+		ends = append(ends, uint64(len(buf)))
+	}
+	return buf, ends
+}
+
+// appendValueSection appends the value section encoding of values to buf and
+// the end offset of each entry to ends, with the same result as encodeValues
+func appendValueSection(buf []byte, ends []uint64, values []int64, encoding sectionEncoding, zigzag bool) ([]byte, []uint64) {
+	for i, value := range values {
+		if i > 0 && encoding.delta {
+			value -= values[i-1]
+		}
+
+		switch {
+		case encoding.varint && zigzag:
+			buf = binary.AppendVarint(buf, value)
+		case encoding.varint:
+			buf = binary.AppendUvarint(buf, uint64(value))
+		default:
+			buf = binary.LittleEndian.AppendUint64(buf, uint64(value))
+		}
+		ends = append(ends, uint64(len(buf)))
+	}
+	return buf, ends
+}
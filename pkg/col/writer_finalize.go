@@ -4,6 +4,9 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
+
+	"vibe-lsm/pkg/col/format"
 )
 
 // writeGlobalIDBitmap writes the global ID bitmap to the file
@@ -17,6 +20,9 @@ func (w *Writer) writeGlobalIDBitmap() (uint64, uint64, error) {
 	// Get the buffer from the bitmap
 	// The sroar bitmap is already a serialized representation
 	buf := w.globalIDs.ToBuffer()
+	if uint64(len(buf)) > math.MaxUint32 {
+		return 0, 0, fmt.Errorf("global ID bitmap of %d bytes exceeds the maximum of %d bytes", len(buf), uint64(math.MaxUint32))
+	}
 
 	// Write the size of the bitmap
 	if err := binary.Write(w.file, binary.LittleEndian, uint32(len(buf))); err != nil {
@@ -40,6 +46,194 @@ func (w *Writer) writeGlobalIDBitmap() (uint64, uint64, error) {
 	return uint64(bitmapOffset), uint64(bitmapSize), nil
 }
 
+// blockStatsSection returns the extended per-block statistics footer section
+func (w *Writer) blockStatsSection() format.FooterSection {
+	// Each entry consists of SumSquares (8 bytes), NegativeCount (4 bytes) and ZeroCount (4 bytes)
+	payload := make([]byte, len(w.blockStats)*blockStatsEntrySize)
+	for i, stats := range w.blockStats {
+		offset := i * blockStatsEntrySize
+		binary.LittleEndian.PutUint64(payload[offset:], math.Float64bits(stats.SumSquares))
+		binary.LittleEndian.PutUint32(payload[offset+8:], stats.NegativeCount)
+		binary.LittleEndian.PutUint32(payload[offset+12:], stats.ZeroCount)
+	}
+
+	return format.FooterSection{Type: FooterSectionBlockStats, Payload: payload}
+}
+
+// unsignedStatsSection returns the per-block statistics footer section of
+// unsigned columns
+func (w *Writer) unsignedStatsSection() format.FooterSection {
+	// Each entry consists of Min, Max and the low and high 64 bits of the Sum (8 bytes each)
+	payload := make([]byte, len(w.blockStats)*unsignedStatsEntrySize)
+	for i, stats := range w.blockStats {
+		offset := i * unsignedStatsEntrySize
+		binary.LittleEndian.PutUint64(payload[offset:], stats.unsigned.Min)
+		binary.LittleEndian.PutUint64(payload[offset+8:], stats.unsigned.Max)
+		binary.LittleEndian.PutUint64(payload[offset+16:], stats.unsigned.Sum)
+		binary.LittleEndian.PutUint64(payload[offset+24:], stats.unsigned.SumHigh)
+	}
+
+	return format.FooterSection{Type: FooterSectionUnsignedStats, Payload: payload}
+}
+
+// lineageSection returns the footer section listing the source files
+func (w *Writer) lineageSection() format.FooterSection {
+	// A 4-byte entry count, followed by entries of CreationTime, MinID, MaxID,
+	// Count (8 bytes each) and the length-prefixed source path
+	size := uint32Size
+	for _, entry := range w.lineage {
+		size += lineageEntryFixedSize + len(entry.Source)
+	}
+
+	payload := make([]byte, size)
+	binary.LittleEndian.PutUint32(payload[0:], uint32(len(w.lineage)))
+	offset := uint32Size
+	for _, entry := range w.lineage {
+		binary.LittleEndian.PutUint64(payload[offset:], entry.CreationTime)
+		binary.LittleEndian.PutUint64(payload[offset+8:], entry.MinID)
+		binary.LittleEndian.PutUint64(payload[offset+16:], entry.MaxID)
+		binary.LittleEndian.PutUint64(payload[offset+24:], entry.Count)
+		binary.LittleEndian.PutUint32(payload[offset+32:], uint32(len(entry.Source)))
+		offset += lineageEntryFixedSize
+		offset += copy(payload[offset:], entry.Source)
+	}
+
+	return format.FooterSection{Type: FooterSectionLineage, Payload: payload}
+}
+
+// valueOrderSection returns the footer section with the value order of each block
+func (w *Writer) valueOrderSection() format.FooterSection {
+	// Per block a 4-byte position count, followed by the positions (4 bytes
+	// each). Blocks without a value order have a count of 0.
+	size := len(w.valueOrders) * uint32Size
+	for _, order := range w.valueOrders {
+		size += len(order) * uint32Size
+	}
+
+	payload := make([]byte, size)
+	offset := 0
+	for _, order := range w.valueOrders {
+		binary.LittleEndian.PutUint32(payload[offset:], uint32(len(order)))
+		offset += uint32Size
+		for _, position := range order {
+			binary.LittleEndian.PutUint32(payload[offset:], position)
+			offset += uint32Size
+		}
+	}
+
+	return format.FooterSection{Type: FooterSectionValueOrder, Payload: payload}
+}
+
+// fileStats combines the statistics of all blocks written so far
+func (w *Writer) fileStats() FileStats {
+	return combineBlockStats(w.blockStats)
+}
+
+// combineBlockStats combines the statistics of blocks into file statistics
+func combineBlockStats(blocks []BlockStats) FileStats {
+	var stats FileStats
+	for i, block := range blocks {
+		if i == 0 || block.MinID < stats.MinID {
+			stats.MinID = block.MinID
+		}
+		if i == 0 || block.MaxID > stats.MaxID {
+			stats.MaxID = block.MaxID
+		}
+		if i == 0 || block.MinValue < stats.MinValue {
+			stats.MinValue = block.MinValue
+		}
+		if i == 0 || block.MaxValue > stats.MaxValue {
+			stats.MaxValue = block.MaxValue
+		}
+		stats.Count += uint64(block.Count)
+		stats.Sum += block.Sum
+	}
+	return stats
+}
+
+// fileStatsSection returns the file-level statistics footer section
+func (w *Writer) fileStatsSection() format.FooterSection {
+	stats := w.fileStats()
+
+	payload := make([]byte, fileStatsSize)
+	binary.LittleEndian.PutUint64(payload[0:], stats.Count)
+	binary.LittleEndian.PutUint64(payload[8:], stats.MinID)
+	binary.LittleEndian.PutUint64(payload[16:], stats.MaxID)
+	binary.LittleEndian.PutUint64(payload[24:], int64ToUint64(stats.MinValue))
+	binary.LittleEndian.PutUint64(payload[32:], int64ToUint64(stats.MaxValue))
+	binary.LittleEndian.PutUint64(payload[40:], int64ToUint64(stats.Sum))
+
+	return format.FooterSection{Type: FooterSectionFileStats, Payload: payload}
+}
+
+// footer returns the footer describing the blocks written so far
+func (w *Writer) footer() (format.Footer, error) {
+	var footer format.Footer
+
+	// Only write block info if we have any blocks
+	if w.blockCount > 0 {
+		// Check that we have positions, sizes and statistics for all blocks
+		if len(w.blockPositions) != int(w.blockCount) {
+			return footer, fmt.Errorf("block position tracking error: expected %d positions, got %d",
+				w.blockCount, len(w.blockPositions))
+		}
+		if len(w.blockSizes) != int(w.blockCount) {
+			return footer, fmt.Errorf("block size tracking error: expected %d sizes, got %d",
+				w.blockCount, len(w.blockSizes))
+		}
+		if len(w.blockStats) != int(w.blockCount) {
+			return footer, fmt.Errorf("block statistics tracking error: expected %d entries, got %d",
+				w.blockCount, len(w.blockStats))
+		}
+
+		// The block index uses the stats collected during WriteBlock
+		footer.Entries = make([]FooterEntry, w.blockCount)
+		for blockIdx, stats := range w.blockStats {
+			footer.Entries[blockIdx] = NewFooterEntry(
+				w.blockPositions[blockIdx],
+				uint32(w.blockSizes[blockIdx]),
+				stats.MinID,
+				stats.MaxID,
+				stats.MinValue,
+				stats.MaxValue,
+				stats.Sum,
+				stats.Count)
+		}
+
+		// The optional footer sections follow the block index
+		footer.Sections = append(footer.Sections, w.blockStatsSection(), w.fileStatsSection())
+		if w.dataType == DataTypeUint64 {
+			footer.Sections = append(footer.Sections, w.unsignedStatsSection())
+		}
+		if w.valueOrder {
+			if len(w.valueOrders) != int(w.blockCount) {
+				return footer, fmt.Errorf("value order tracking error: expected %d entries, got %d",
+					w.blockCount, len(w.valueOrders))
+			}
+			footer.Sections = append(footer.Sections, w.valueOrderSection())
+		}
+		if w.partitioned() {
+			footer.Sections = append(footer.Sections, w.partitionsSection())
+		}
+	}
+
+	// The lineage is kept even if all sources were empty
+	if len(w.lineage) > 0 {
+		footer.Sections = append(footer.Sections, w.lineageSection())
+	}
+
+	// The footer checksum covers all sections before it
+	if w.checksumType != ChecksumNone {
+		section, err := w.footerChecksumSection(footer)
+		if err != nil {
+			return footer, err
+		}
+		footer.Sections = append(footer.Sections, section)
+	}
+
+	return footer, nil
+}
+
 // FinalizeAndClose finalizes the file by writing the footer and closes the file
 func (w *Writer) FinalizeAndClose() error {
 	if err := w.Finalize(); err != nil {
@@ -61,32 +255,10 @@ func (w *Writer) Finalize() error {
 		return fmt.Errorf("failed to seek to start: %w", err)
 	}
 
-	// Create updated header
-	header := NewFileHeader(w.blockCount, w.blockSizeTarget, w.encodingType)
-	header.BitmapOffset = bitmapOffset
-	header.BitmapSize = bitmapSize
-
-	// Write header fields
-	headerFields := []interface{}{
-		header.Magic,
-		header.Version,
-		header.ColumnType,
-		header.BlockCount,
-		header.BlockSizeTarget,
-		header.CompressionType,
-		header.EncodingType,
-		header.CreationTime,
-		header.BitmapOffset,
-		header.BitmapSize,
-	}
-
-	// Write the fields we need to update
-	for i, field := range headerFields {
-		if err := binary.Write(w.file, binary.LittleEndian, field); err != nil {
-			return fmt.Errorf("failed to write header field %d: %w", i, err)
-		}
+	// Rewrite the header with the final block count and bitmap location
+	if err := w.writeFileHeader(bitmapOffset, bitmapSize); err != nil {
+		return fmt.Errorf("failed to update header: %w", err)
 	}
-	// Skip the rest of the header - unchanged fields
 
 	// Seek to the end to write the footer
 	if _, err := w.file.Seek(0, io.SeekEnd); err != nil {
@@ -99,8 +271,8 @@ func (w *Writer) Finalize() error {
 		return fmt.Errorf("failed to get current position: %w", err)
 	}
 
-	// Add padding to align to page boundary if necessary
-	padding := calculatePadding(currentPos, PageSize)
+	// Add padding to align the footer if necessary
+	padding := w.padding(currentPos)
 	if padding > 0 {
 		// Create padding buffer filled with zeros
 		paddingBuf := make([]byte, padding)
@@ -111,88 +283,37 @@ func (w *Writer) Finalize() error {
 		}
 	}
 
-	// Get current position - start of footer (now page-aligned)
-	footerStart, err := w.file.Seek(0, io.SeekCurrent)
+	footer, err := w.footer()
 	if err != nil {
-		return fmt.Errorf("failed to get file position: %w", err)
+		return err
 	}
-
-	// Write block index count
-	if err := binary.Write(w.file, binary.LittleEndian, uint32(w.blockCount)); err != nil {
-		return fmt.Errorf("failed to write block index count: %w", err)
+	footerBuf, err := footer.MarshalBinary()
+	if err != nil {
+		return err
 	}
 
-	// Only write block info if we have any blocks
-	if w.blockCount > 0 {
-		// Check that we have block positions for all blocks
-		if len(w.blockPositions) != int(w.blockCount) {
-			return fmt.Errorf("block position tracking error: expected %d positions, got %d",
-				w.blockCount, len(w.blockPositions))
-		}
-
-		// Process each block
-		for blockIdx := uint64(0); blockIdx < w.blockCount; blockIdx++ {
-			blockOffset := w.blockPositions[blockIdx]
-			blockSize := w.blockSizes[blockIdx]
-			stats := w.blockStats[blockIdx]
-
-			// Write block footer using the stats collected during WriteBlock
-			if err := w.writeBlockFooter(
-				blockOffset,
-				uint64(blockSize),
-				stats.MinID,
-				stats.MaxID,
-				stats.MinValue,
-				stats.MaxValue,
-				stats.Sum,
-				stats.Count); err != nil {
-				return err
-			}
-		}
+	if _, err := w.file.Write(footerBuf); err != nil {
+		return fmt.Errorf("failed to write footer: %w", err)
 	}
 
-	// Get current position - end of footer content
-	footerEnd, err := w.file.Seek(0, io.SeekCurrent)
+	// The checksum covers everything up to here, including the final header
+	headerBuf, err := w.fileHeader(bitmapOffset, bitmapSize).MarshalBinary()
 	if err != nil {
-		return fmt.Errorf("failed to get file position: %w", err)
+		return err
 	}
 
-	// Calculate footer size
-	footerSize := footerEnd - footerStart
-	footerMetaStart := footerEnd
-
-	// Write footer metadata
-	if err := binary.Write(w.file, binary.LittleEndian, uint64(footerSize)); err != nil {
-		return fmt.Errorf("failed to write footer size: %w", err)
-	}
-	if err := binary.Write(w.file, binary.LittleEndian, uint64(0)); err != nil {
-		return fmt.Errorf("failed to write checksum: %w", err)
+	// The footer metadata follows the footer, so readers can locate its start
+	meta := FooterMetadata{
+		FooterSize: uint64(len(footerBuf)),
+		Checksum:   w.fileChecksum(headerBuf),
+		Magic:      MagicNumber,
 	}
-	if err := binary.Write(w.file, binary.LittleEndian, MagicNumber); err != nil {
-		return fmt.Errorf("failed to write magic number: %w", err)
-	}
-
-	// Verify footer metadata size
-	footerMetaEnd, err := w.file.Seek(0, io.SeekCurrent)
+	metaBuf, err := meta.MarshalBinary()
 	if err != nil {
-		return fmt.Errorf("failed to get footer metadata end position: %w", err)
-	}
-
-	// The footer metadata consists of:
-	// - Footer size (8 bytes)
-	// - Checksum (8 bytes)
-	// - Magic number (8 bytes)
-	// Total: 24 bytes
-	footerMetaSize := footerMetaEnd - footerMetaStart
-	if footerMetaSize != 24 {
-		return fmt.Errorf("footer metadata size mismatch: expected=24, actual=%d", footerMetaSize)
+		return err
 	}
-
-	// Verify total footer size
-	totalFooterSize := footerMetaEnd - footerStart
-	if totalFooterSize != footerSize+24 {
-		return fmt.Errorf("total footer size mismatch: expected=%d, actual=%d",
-			footerSize+24, totalFooterSize)
+	if _, err := w.file.Write(metaBuf); err != nil {
+		return fmt.Errorf("failed to write footer metadata: %w", err)
 	}
 
 	// Final sync to ensure everything is written to disk
@@ -200,7 +321,14 @@ func (w *Writer) Finalize() error {
 		return fmt.Errorf("failed to sync file during finalization: %w", err)
 	}
 
-	return nil
+	if w.sidecar {
+		if err := w.writeSidecar(headerBuf, footer, meta); err != nil {
+			return err
+		}
+	}
+
+	// The footer supersedes the checkpoint
+	return w.removeCheckpoint()
 }
 
 // Close closes the file without finalizing it
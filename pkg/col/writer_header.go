@@ -1,67 +1,42 @@
 package col
 
 import (
-	"encoding/binary"
 	"fmt"
-	"io"
+	"time"
 )
 
-// writeHeader writes the file header to the file
+// writeHeader writes the initial file header at the current position. The
+// block count and bitmap location are filled in by Finalize, the creation time
+// is kept.
 func (w *Writer) writeHeader() error {
-	// Record start position to verify header size
-	headerStart, err := w.file.Seek(0, io.SeekCurrent)
-	if err != nil {
-		return fmt.Errorf("failed to get header start position: %w", err)
-	}
-
-	// Create the header with default values
-	header := NewFileHeader(0, w.blockSizeTarget, w.encodingType)
-
-	// Create a buffer for the header fields
-	headerFields := []interface{}{
-		header.Magic,
-		header.Version,
-		header.ColumnType,
-		header.BlockCount,
-		header.BlockSizeTarget,
-		header.CompressionType,
-		header.EncodingType,
-		header.CreationTime,
-		header.BitmapOffset,
-		header.BitmapSize,
+	if !w.creationTimeSet {
+		w.creationTime = uint64(time.Now().Unix())
 	}
+	return w.writeFileHeader(0, 0)
+}
 
-	// Write all header fields
-	for i, field := range headerFields {
-		if err := binary.Write(w.file, binary.LittleEndian, field); err != nil {
-			return fmt.Errorf("failed to write header field %d: %w", i, err)
-		}
-	}
-
-	// Calculate reserved space - sum of the sizes of the header fields we've written
-	headerFieldsSize := uint64Size + uint32Size + uint32Size + uint64Size +
-		uint32Size + uint32Size + uint32Size + uint64Size + uint64Size + uint64Size
-	reservedSize := headerSize - headerFieldsSize
-
-	// Write reserved space to fill up to 64 bytes
-	reserved := make([]byte, reservedSize)
-	if _, err := w.file.Write(reserved); err != nil {
-		return fmt.Errorf("failed to write reserved space: %w", err)
-	}
+// fileHeader returns the file header describing the current state of the writer
+func (w *Writer) fileHeader(bitmapOffset, bitmapSize uint64) FileHeader {
+	header := NewFileHeader(w.blockCount, w.blockSizeTarget, w.encodingType)
+	header.ColumnType = w.dataType
+	header.CreationTime = w.creationTime
+	header.BitmapOffset = bitmapOffset
+	header.BitmapSize = bitmapSize
+	header.ChecksumType = w.checksumType
+	return header
+}
 
-	// Verify header size
-	headerEnd, err := w.file.Seek(0, io.SeekCurrent)
+// writeFileHeader writes the complete 64-byte file header at the current
+// position. It is the only place the header is serialized, so the initial
+// header and the one rewritten by Finalize are always identical apart from the
+// block count and bitmap location.
+func (w *Writer) writeFileHeader(bitmapOffset, bitmapSize uint64) error {
+	buf, err := w.fileHeader(bitmapOffset, bitmapSize).MarshalBinary()
 	if err != nil {
-		return fmt.Errorf("failed to get header end position: %w", err)
+		return err
 	}
-
-	// Calculate actual header size
-	actualHeaderSize := headerEnd - headerStart
-
-	// Validate header size
-	if actualHeaderSize != int64(headerSize) {
-		return fmt.Errorf("header size mismatch: expected=%d, actual=%d", headerSize, actualHeaderSize)
+	if _, err := w.file.Write(buf); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
 	}
-
 	return nil
 }
@@ -1,18 +1,135 @@
 package col
 
+import "time"
+
 // WriterOption defines a function type for configuring a Writer
 type WriterOption func(*Writer)
 
-// WithEncoding sets the encoding type for the Writer
+// WithEncoding sets the encoding type for the Writer. The default is EncodingRaw,
+// or EncodingDeltaDelta for DataTypeTimestampMillis columns.
 func WithEncoding(encodingType uint32) WriterOption {
 	return func(w *Writer) {
 		w.encodingType = encodingType
+		w.encodingSet = true
+	}
+}
+
+// WithAutoEncoding enables automatic encoding selection: every block written
+// without an explicit block encoding uses EncodingDeltaDelta instead of the
+// Writer's encoding if that makes the block smaller, which is typically the case
+// for IDs with a near-constant stride such as timestamps. The choice is recorded
+// in the block header.
+func WithAutoEncoding() WriterOption {
+	return func(w *Writer) {
+		w.autoEncoding = true
+	}
+}
+
+// WithDataType sets the data type of the values, DataTypeInt64 by default. The
+// values of a DataTypeUint64 column are written with WriteBlockUint64, or as
+// their int64 bit patterns with WriteBlock.
+func WithDataType(dataType uint32) WriterOption {
+	return func(w *Writer) {
+		w.dataType = dataType
+	}
+}
+
+// WithLineage records the source files the written file is produced from in
+// the lineage footer section, see Reader.Lineage
+func WithLineage(sources ...LineageEntry) WriterOption {
+	return func(w *Writer) {
+		w.lineage = append(w.lineage, sources...)
+	}
+}
+
+// WithValueOrderIndex records for every block the positions of its pairs
+// sorted by value, so Reader.ScanByValue does not have to sort at read time.
+// Blocks appended without re-encoding, e.g. by Concat, get no index.
+func WithValueOrderIndex() WriterOption {
+	return func(w *Writer) {
+		w.valueOrder = true
 	}
 }
 
+// WithFixedTimestamp records t as the creation time in the file header
+// instead of the time the Writer is created. Times before the Unix epoch are
+// recorded as the epoch.
+func WithFixedTimestamp(t time.Time) WriterOption {
+	return func(w *Writer) {
+		w.creationTime = uint64(max(t.Unix(), 0))
+		w.creationTimeSet = true
+	}
+}
+
+// WithDeterministicOutput makes the written file depend only on the written
+// data and the options, so identical inputs produce byte-identical files, e.g.
+// for content-addressed storage or golden files. The creation time is recorded
+// as the Unix epoch; padding is always zero-filled.
+func WithDeterministicOutput() WriterOption {
+	return WithFixedTimestamp(time.Unix(0, 0))
+}
+
 // WithBlockSize sets the block size for the Writer
 func WithBlockSize(blockSize uint32) WriterOption {
 	return func(w *Writer) {
 		w.blockSizeTarget = blockSize
 	}
 }
+
+// PaddingPolicy determines the boundary that blocks and the footer are aligned to
+type PaddingPolicy struct {
+	alignment int64
+}
+
+var (
+	// PaddingNone writes blocks back to back, which minimizes the file size
+	PaddingNone = PaddingPolicy{alignment: 1}
+
+	// PaddingPageAligned aligns blocks to PageSize, which suits mmap and direct I/O
+	PaddingPageAligned = PaddingPolicy{alignment: PageSize}
+)
+
+// PaddingBoundary aligns blocks to a multiple of n bytes. Values of n below 2
+// disable padding.
+func PaddingBoundary(n int64) PaddingPolicy {
+	return PaddingPolicy{alignment: n}
+}
+
+// Alignment returns the boundary in bytes that the policy aligns blocks to
+func (p PaddingPolicy) Alignment() int64 {
+	return p.alignment
+}
+
+// WithPadding sets the padding policy for the Writer. Blocks are page-aligned
+// by default.
+func WithPadding(policy PaddingPolicy) WriterOption {
+	return func(w *Writer) {
+		w.alignment = policy.alignment
+	}
+}
+
+// blockConfig holds the settings for writing a single block
+type blockConfig struct {
+	encodingType    uint32
+	compressionType uint32
+	partition       uint64
+}
+
+// BlockOption defines a function type for configuring a single block
+type BlockOption func(*blockConfig)
+
+// WithBlockEncoding sets the encoding type for a single block, overriding the
+// encoding of the Writer
+func WithBlockEncoding(encodingType uint32) BlockOption {
+	return func(c *blockConfig) {
+		c.encodingType = encodingType
+	}
+}
+
+// WithBlockCompression sets the compression type for a single block. Only
+// CompressionNone is supported at the moment.
+func WithBlockCompression(compressionType uint32) BlockOption {
+	return func(c *blockConfig) {
+		c.compressionType = compressionType
+	}
+}
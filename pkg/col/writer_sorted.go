@@ -0,0 +1,95 @@
+package col
+
+import (
+	"fmt"
+)
+
+// DuplicatePolicy determines how a Writer validating sorted IDs handles an ID
+// that occurs more than once in a block
+type DuplicatePolicy int
+
+const (
+	// DuplicateError rejects blocks with duplicate IDs
+	DuplicateError DuplicatePolicy = iota
+	// DuplicateKeepFirst writes the first pair of each run of duplicate IDs
+	DuplicateKeepFirst
+	// DuplicateKeepLast writes the last pair of each run of duplicate IDs
+	DuplicateKeepLast
+)
+
+// UnsortedIDsError is returned by a Writer validating sorted IDs for a block
+// whose IDs are not strictly increasing. No pairs of the block are written.
+type UnsortedIDsError struct {
+	Index    int    // Position of the offending ID in the block
+	ID       uint64 // The offending ID
+	Previous uint64 // The ID before it
+}
+
+func (e *UnsortedIDsError) Error() string {
+	if e.ID == e.Previous {
+		return fmt.Sprintf("duplicate ID %d at index %d", e.ID, e.Index)
+	}
+	return fmt.Sprintf("unsorted ID %d at index %d follows %d", e.ID, e.Index, e.Previous)
+}
+
+// WithValidateSorted makes the Writer reject blocks whose IDs are not sorted
+// in increasing order or contain duplicates, with an UnsortedIDsError. The
+// block statistics and filters assume sorted IDs, so such blocks would
+// otherwise mislead readers. Validation is off by default.
+func WithValidateSorted() WriterOption {
+	return func(w *Writer) {
+		w.validateSorted = true
+	}
+}
+
+// WithDuplicatePolicy sets how duplicate IDs in a block are handled, which is
+// DuplicateError by default. Duplicates are only recognized in sorted blocks,
+// so this also enables WithValidateSorted. Removing duplicates does not modify
+// the slices passed to the Writer; the ItemsWritten of a BlockFullError count
+// the pairs passed in, including removed duplicates.
+func WithDuplicatePolicy(policy DuplicatePolicy) WriterOption {
+	return func(w *Writer) {
+		w.validateSorted = true
+		w.duplicatePolicy = policy
+	}
+}
+
+// checkSortedIDs validates that ids are sorted and applies the duplicate
+// policy. If duplicates were removed, it returns new slices and, for every
+// pair kept, the number of input pairs up to and including its run of
+// duplicates; otherwise ends is nil.
+func (w *Writer) checkSortedIDs(ids []uint64, values []int64) ([]uint64, []int64, []int, error) {
+	duplicates := 0
+	for i := 1; i < len(ids); i++ {
+		switch {
+		case ids[i] > ids[i-1]:
+		case ids[i] == ids[i-1] && w.duplicatePolicy != DuplicateError:
+			duplicates++
+		default:
+			return nil, nil, nil, &UnsortedIDsError{Index: i, ID: ids[i], Previous: ids[i-1]}
+		}
+	}
+	if duplicates == 0 {
+		return ids, values, nil, nil
+	}
+
+	keptIDs := make([]uint64, 0, len(ids)-duplicates)
+	keptValues := make([]int64, 0, len(ids)-duplicates)
+	ends := make([]int, 0, len(ids)-duplicates)
+	for start := 0; start < len(ids); {
+		end := start + 1
+		for end < len(ids) && ids[end] == ids[start] {
+			end++
+		}
+
+		kept := start
+		if w.duplicatePolicy == DuplicateKeepLast {
+			kept = end - 1
+		}
+		keptIDs = append(keptIDs, ids[kept])
+		keptValues = append(keptValues, values[kept])
+		ends = append(ends, end)
+		start = end
+	}
+	return keptIDs, keptValues, ends, nil
+}
@@ -0,0 +1,105 @@
+package col
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterValidateSorted(t *testing.T) {
+	// write writes a single block and returns the pairs read back
+	write := func(t *testing.T, ids []uint64, values []int64, options ...WriterOption) ([]uint64, []int64, error) {
+		var buf bytes.Buffer
+		writer, err := NewWriterToBuffer(&buf, options...)
+		require.NoError(t, err)
+		if err := writer.WriteBlock(ids, values); err != nil {
+			writer.Close()
+			return nil, nil, err
+		}
+		require.NoError(t, writer.FinalizeAndClose())
+
+		reader, err := NewReaderFromBytes(buf.Bytes())
+		require.NoError(t, err)
+		defer reader.Close()
+		gotIDs, gotValues := readAllPairs(t, reader)
+		return gotIDs, gotValues, nil
+	}
+
+	t.Run("Disabled by default", func(t *testing.T) {
+		ids, _, err := write(t, []uint64{3, 1, 1}, []int64{1, 2, 3})
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{3, 1, 1}, ids)
+	})
+
+	t.Run("Unsorted IDs", func(t *testing.T) {
+		_, _, err := write(t, []uint64{1, 5, 3, 7}, []int64{1, 2, 3, 4}, WithValidateSorted())
+		var unsorted *UnsortedIDsError
+		require.True(t, errors.As(err, &unsorted))
+		assert.Equal(t, UnsortedIDsError{Index: 2, ID: 3, Previous: 5}, *unsorted)
+		assert.EqualError(t, err, "unsorted ID 3 at index 2 follows 5")
+	})
+
+	t.Run("Duplicate IDs", func(t *testing.T) {
+		_, _, err := write(t, []uint64{1, 2, 2}, []int64{1, 2, 3}, WithValidateSorted())
+		var unsorted *UnsortedIDsError
+		require.True(t, errors.As(err, &unsorted))
+		assert.Equal(t, 2, unsorted.Index)
+		assert.EqualError(t, err, "duplicate ID 2 at index 2")
+	})
+
+	t.Run("Duplicate policies", func(t *testing.T) {
+		ids := []uint64{1, 2, 2, 2, 3, 4, 4}
+		values := []int64{10, 20, 21, 22, 30, 40, 41}
+
+		gotIDs, gotValues, err := write(t, ids, values, WithDuplicatePolicy(DuplicateKeepFirst))
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{1, 2, 3, 4}, gotIDs)
+		assert.Equal(t, []int64{10, 20, 30, 40}, gotValues)
+
+		gotIDs, gotValues, err = write(t, ids, values, WithDuplicatePolicy(DuplicateKeepLast))
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{1, 2, 3, 4}, gotIDs)
+		assert.Equal(t, []int64{10, 22, 30, 41}, gotValues)
+
+		// The input is left untouched
+		assert.Equal(t, []uint64{1, 2, 2, 2, 3, 4, 4}, ids)
+		assert.Equal(t, []int64{10, 20, 21, 22, 30, 40, 41}, values)
+
+		_, _, err = write(t, ids, values, WithDuplicatePolicy(DuplicateError))
+		assert.EqualError(t, err, "duplicate ID 2 at index 2")
+
+		// Unsorted IDs are rejected regardless of the policy
+		_, _, err = write(t, []uint64{2, 2, 1}, []int64{1, 2, 3}, WithDuplicatePolicy(DuplicateKeepLast))
+		assert.EqualError(t, err, "unsorted ID 1 at index 2 follows 2")
+	})
+
+	t.Run("Partial block counts input pairs", func(t *testing.T) {
+		// Room for two pairs of the raw encoding
+		blockSize := uint32(blockHeaderSize + blockLayoutSize + 2*16)
+
+		var buf bytes.Buffer
+		writer, err := NewWriterToBuffer(&buf, WithBlockSize(blockSize), WithPadding(PaddingNone),
+			WithDuplicatePolicy(DuplicateKeepLast))
+		require.NoError(t, err)
+
+		ids := []uint64{1, 1, 1, 2, 2, 3}
+		values := []int64{10, 11, 12, 20, 21, 30}
+		err = writer.WriteBlock(ids, values)
+		var blockFull *BlockFullError
+		require.True(t, errors.As(err, &blockFull))
+		assert.Equal(t, 5, blockFull.ItemsWritten)
+
+		require.NoError(t, writer.WriteBlock(ids[blockFull.ItemsWritten:], values[blockFull.ItemsWritten:]))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		reader, err := NewReaderFromBytes(buf.Bytes())
+		require.NoError(t, err)
+		defer reader.Close()
+		gotIDs, gotValues := readAllPairs(t, reader)
+		assert.Equal(t, []uint64{1, 2, 3}, gotIDs)
+		assert.Equal(t, []int64{12, 21, 30}, gotValues)
+	})
+}
@@ -0,0 +1,85 @@
+package col
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteBlockWithStats(t *testing.T) {
+	// Compact the blocks of a source file, reusing their statistics
+	var srcBuf bytes.Buffer
+	src, err := NewWriterToBuffer(&srcBuf, WithBlockSize(1024))
+	require.NoError(t, err)
+	ids := make([]uint64, 2000)
+	values := make([]int64, 2000)
+	for i := range ids {
+		ids[i] = uint64(i * 3)
+		values[i] = int64(i%17) - 8
+	}
+	require.NoError(t, writeAllBlocks(src, ids, values))
+	require.NoError(t, src.FinalizeAndClose())
+	source, err := NewReaderFromBytes(srcBuf.Bytes())
+	require.NoError(t, err)
+	require.Greater(t, source.BlockCount(), uint64(1))
+
+	var dstBuf bytes.Buffer
+	dst, err := NewWriterToBuffer(&dstBuf, WithEncoding(EncodingVarIntBoth), WithBlockSize(64))
+	require.NoError(t, err)
+	for i := 0; i < int(source.BlockCount()); i++ {
+		blockIDs, blockValues, err := source.ReadBlock(BlockID(i))
+		require.NoError(t, err)
+		stats, err := source.completeBlockStats(i)
+		require.NoError(t, err)
+
+		// Blocks are written as a whole, regardless of the target size
+		require.NoError(t, dst.WriteBlockWithStats(blockIDs, blockValues, stats))
+	}
+	require.NoError(t, dst.FinalizeAndClose())
+
+	compacted, err := NewReaderFromBytes(dstBuf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, source.BlockCount(), compacted.BlockCount())
+	assert.Equal(t, source.Aggregate(), compacted.Aggregate())
+	sourceStats, err := source.Stats()
+	require.NoError(t, err)
+	compactedStats, err := compacted.Stats()
+	require.NoError(t, err)
+	assert.Equal(t, sourceStats, compactedStats)
+
+	report, err := compacted.ValidateFooterAgainstBlocks()
+	require.NoError(t, err)
+	assert.True(t, report.OK(), "%+v", report)
+
+	t.Run("Invalid", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer, err := NewWriterToBuffer(&buf, WithValidateSorted())
+		require.NoError(t, err)
+
+		stats := BlockStats{MinID: 1, MaxID: 2, MinValue: 1, MaxValue: 2, Sum: 3, Count: 2}
+		assert.Error(t, writer.WriteBlockWithStats([]uint64{1, 2}, []int64{1, 2}, BlockStats{Count: 3}))
+		assert.Error(t, writer.WriteBlockWithStats(nil, nil, BlockStats{}))
+		assert.Error(t, writer.WriteBlockWithStats([]uint64{2, 1}, []int64{1, 2}, stats))
+		assert.NoError(t, writer.WriteBlockWithStats([]uint64{1, 2}, []int64{1, 2}, stats))
+
+		// Inconsistent ranges
+		invalid := stats
+		invalid.MinID, invalid.MaxID = 2, 1
+		assert.ErrorContains(t, writer.WriteBlockWithStats([]uint64{1, 2}, []int64{1, 2}, invalid), "minimum ID")
+		invalid = stats
+		invalid.MinValue, invalid.MaxValue = 2, 1
+		assert.ErrorContains(t, writer.WriteBlockWithStats([]uint64{1, 2}, []int64{1, 2}, invalid), "minimum value")
+		invalid = stats
+		invalid.MaxID = 5
+		assert.ErrorContains(t, writer.WriteBlockWithStats([]uint64{1, 2}, []int64{1, 2}, invalid), "do not match")
+
+		// Without sort validation, the first and last ID must be in the ID range
+		var unsortedBuf bytes.Buffer
+		unsorted, err := NewWriterToBuffer(&unsortedBuf)
+		require.NoError(t, err)
+		assert.ErrorContains(t, unsorted.WriteBlockWithStats([]uint64{1, 3}, []int64{1, 2}, stats), "outside")
+		assert.NoError(t, unsorted.WriteBlockWithStats([]uint64{2, 1}, []int64{2, 1}, stats))
+	})
+}
@@ -2,6 +2,7 @@ package col_test
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -227,3 +228,79 @@ func TestWriteBlockErrorHandling(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteBlockWithOptions(t *testing.T) {
+	// Create a temporary file for testing
+	tmpfile, err := os.CreateTemp("", "test-writer-block-options-*.col")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+	defer tmpfile.Close()
+
+	writer, err := col.NewWriter(tmpfile.Name(), col.WithEncoding(col.EncodingRaw))
+	require.NoError(t, err)
+	defer writer.Close()
+
+	// A dense block uses varint encoding, the sparse blocks keep the file default
+	denseIDs := []uint64{1, 2, 3, 4, 5}
+	denseValues := []int64{1, 1, 2, 3, 5}
+	sparseIDs := []uint64{1 << 40, 1 << 50, 1 << 60}
+	sparseValues := []int64{-1 << 40, 1 << 50, -1 << 60}
+	require.NoError(t, writer.WriteBlockWithOptions(denseIDs, denseValues, col.WithBlockEncoding(col.EncodingVarIntBoth)))
+	require.NoError(t, writer.WriteBlock(sparseIDs, sparseValues))
+	require.NoError(t, writer.WriteBlockWithOptions([]uint64{1<<60 + 1}, []int64{7}, col.WithBlockEncoding(col.EncodingDeltaBoth)))
+
+	// Invalid options don't write a block
+	assert.Error(t, writer.WriteBlockWithOptions([]uint64{1 << 61}, []int64{1}, col.WithBlockEncoding(99)))
+	assert.Error(t, writer.WriteBlockWithOptions([]uint64{1 << 61}, []int64{1}, col.WithBlockCompression(1)))
+
+	require.NoError(t, writer.FinalizeAndClose())
+
+	reader, err := col.NewReader(tmpfile.Name())
+	require.NoError(t, err)
+	defer reader.Close()
+
+	assert.Equal(t, col.EncodingRaw, reader.EncodingType())
+	assert.Equal(t, uint64(3), reader.BlockCount())
+
+	ids, values, err := reader.ReadBlock(0)
+	require.NoError(t, err)
+	assert.Equal(t, denseIDs, ids)
+	assert.Equal(t, denseValues, values)
+
+	ids, values, err = reader.ReadBlock(1)
+	require.NoError(t, err)
+	assert.Equal(t, sparseIDs, ids)
+	assert.Equal(t, sparseValues, values)
+
+	ids, values, err = reader.ReadBlock(2)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1<<60 + 1}, ids)
+	assert.Equal(t, []int64{7}, values)
+
+	fromFooter := reader.Aggregate()
+	fromBlocks := reader.AggregateWithOptions(col.AggregateOptions{SkipPreCalculated: true})
+	assert.Equal(t, col.SourceMetadata, fromFooter.Source)
+	assert.Equal(t, col.SourceFullScan, fromBlocks.Source)
+	fromBlocks.Source, fromBlocks.BlocksScanned = fromFooter.Source, fromFooter.BlocksScanned
+	assert.Equal(t, fromFooter, fromBlocks)
+}
+
+func TestNewWriterInvalidOptions(t *testing.T) {
+	dir := t.TempDir()
+
+	// Invalid options leave an existing file untouched
+	existing := filepath.Join(dir, "existing.col")
+	require.NoError(t, os.WriteFile(existing, []byte("existing data"), 0644))
+	_, err := col.NewWriter(existing, col.WithDataType(99))
+	assert.Error(t, err)
+	data, err := os.ReadFile(existing)
+	require.NoError(t, err)
+	assert.Equal(t, "existing data", string(data))
+
+	// and create no new file
+	missing := filepath.Join(dir, "missing.col")
+	_, err = col.NewWriter(missing, col.WithChecksum(99))
+	assert.Error(t, err)
+	_, err = os.Stat(missing)
+	assert.True(t, os.IsNotExist(err), "No file should be created")
+}
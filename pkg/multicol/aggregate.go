@@ -42,18 +42,43 @@ type AggregateOptions struct {
 
 	// Filter is a bitmap of allowed IDs for filtered aggregation
 	Filter *sroar.Bitmap
+
+	// Resolve determines the value of an ID stored in several files. By
+	// default the newest file wins. Duplicates within a single file are
+	// aggregated per pair, like col.Reader.AggregateWithOptions does.
+	Resolve col.ResolvePolicy
+
+	// PartitionFilter restricts the aggregation to the blocks whose partition
+	// key is listed, see col.AggregateOptions.PartitionFilter. A newer value in
+	// a block of another partition still hides the older values of its ID. It
+	// is only supported with col.ResolveLastWrite.
+	PartitionFilter []uint64
+
+	// OnError decides how blocks that cannot be read are handled, see
+	// col.AggregateOptions.OnError
+	OnError col.ErrorPolicy
 }
 
 // Aggregate aggregates data across all readers, handling updates correctly.
 // It processes readers from newest to oldest, using global ID bitmaps as deny lists
-// to exclude updated values from older files.
+// to exclude updated values from older files. Other resolve policies than
+// col.ResolveLastWrite are applied to the IDs stored in several files.
+// Blocks that cannot be read are reported as the error, see
+// col.AggregateResult.Err; with col.SkipAndReport the partial result is
+// returned along with it.
 func (mr *MultiReader) Aggregate(opts AggregateOptions) (col.AggregateResult, error) {
 	if len(mr.readers) == 0 {
 		return col.AggregateResult{}, nil
 	}
+	if opts.Resolve != col.ResolveLastWrite {
+		if opts.PartitionFilter != nil {
+			return col.AggregateResult{}, fmt.Errorf("partition filters require resolving by last write")
+		}
+		return mr.aggregateResolved(opts)
+	}
 
-	// Initialize the result with zero values
-	result := col.AggregateResult{}
+	// Results of the readers, merged once all are aggregated
+	results := make([]col.AggregateResult, 0, len(mr.readers))
 
 	// Initialize an empty deny bitmap to track processed IDs
 	denyBitmap := sroar.NewBitmap()
@@ -67,10 +92,15 @@ func (mr *MultiReader) Aggregate(opts AggregateOptions) (col.AggregateResult, er
 			SkipPreCalculated: opts.SkipPreCalculated,
 			Filter:            opts.Filter,
 			DenyFilter:        denyBitmap,
+			PartitionFilter:   opts.PartitionFilter,
+			OnError:           opts.OnError,
 		}
 
 		// Aggregate this reader with the current deny filter
 		readerResult := reader.AggregateWithOptions(readerOpts)
+		if err := readerResult.Err(); err != nil && opts.OnError == col.FailFast {
+			return col.AggregateResult{}, fmt.Errorf("failed to aggregate reader %d: %w", i, err)
+		}
 
 		// Get the global ID bitmap for this reader
 		globalIDs, err := reader.GetGlobalIDBitmap()
@@ -81,57 +111,9 @@ func (mr *MultiReader) Aggregate(opts AggregateOptions) (col.AggregateResult, er
 		// Add all IDs from this reader to the deny bitmap for older readers
 		denyBitmap = denyBitmap.Or(globalIDs)
 
-		// Merge the results
-		if result.Count == 0 {
-			// First result, just copy it
-			result = readerResult
-		} else {
-			// Merge with existing result
-			result = mergeAggregateResults(result, readerResult)
-		}
-	}
-
-	return result, nil
-}
-
-// mergeAggregateResults merges two AggregateResults into one.
-func mergeAggregateResults(a, b col.AggregateResult) col.AggregateResult {
-	// If either result is empty, return the other one
-	if a.Count == 0 {
-		return b
-	}
-	if b.Count == 0 {
-		return a
-	}
-
-	// Merge the results
-	merged := col.AggregateResult{
-		Count: a.Count + b.Count,
-		Min:   minInt64(a.Min, b.Min),
-		Max:   maxInt64(a.Max, b.Max),
-		Sum:   a.Sum + b.Sum,
+		results = append(results, readerResult)
 	}
 
-	// Calculate the average
-	if merged.Count > 0 {
-		merged.Avg = float64(merged.Sum) / float64(merged.Count)
-	}
-
-	return merged
-}
-
-// Helper function to find the minimum of two int64 values
-func minInt64(a, b int64) int64 {
-	if a < b {
-		return a
-	}
-	return b
-}
-
-// Helper function to find the maximum of two int64 values
-func maxInt64(a, b int64) int64 {
-	if a > b {
-		return a
-	}
-	return b
+	merged := col.MergeAggregates(results...)
+	return merged, merged.Err()
 }
@@ -1,10 +1,14 @@
 package multicol
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
 	"os"
 	"testing"
 
 	"vibe-lsm/pkg/col"
+	"vibe-lsm/pkg/col/format"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -114,7 +118,7 @@ func TestMultiReaderAggregate(t *testing.T) {
 	// - Total: 20 items
 
 	// Validate count
-	assert.Equal(t, 20, result.Count, "Count should be 20")
+	assert.Equal(t, uint64(20), result.Count, "Count should be 20")
 
 	// Validate min
 	assert.Equal(t, int64(10), result.Min, "Min should be 10")
@@ -158,7 +162,7 @@ func TestMultiReaderAggregate(t *testing.T) {
 	// - Total: 10 items
 
 	// Validate filtered count
-	assert.Equal(t, 10, filteredResult.Count, "Filtered count should be 10")
+	assert.Equal(t, uint64(10), filteredResult.Count, "Filtered count should be 10")
 
 	// Calculate expected filtered sum
 	expectedFilteredSum := int64(0)
@@ -181,7 +185,72 @@ func TestMultiReaderAggregateEmpty(t *testing.T) {
 	// Aggregate should return an empty result
 	result, err := multiReader.Aggregate(AggregateOptions{})
 	require.NoError(t, err)
-	assert.Equal(t, 0, result.Count, "Count should be 0 for empty MultiReader")
+	assert.Equal(t, uint64(0), result.Count, "Count should be 0 for empty MultiReader")
 	assert.Equal(t, int64(0), result.Sum, "Sum should be 0 for empty MultiReader")
 	assert.Equal(t, 0.0, result.Avg, "Average should be 0 for empty MultiReader")
 }
+
+// TestMultiReaderAggregateOptions tests that the error policy and the partition
+// filter apply to the aggregations of all readers.
+func TestMultiReaderAggregateOptions(t *testing.T) {
+	// write writes the blocks to a new in-memory file with partition keys 1, 2, ...
+	write := func(blocks ...[]uint64) []byte {
+		var buf bytes.Buffer
+		writer, err := col.NewWriterToBuffer(&buf)
+		require.NoError(t, err)
+		for i, ids := range blocks {
+			values := make([]int64, len(ids))
+			for j, id := range ids {
+				values[j] = int64(id)
+			}
+			require.NoError(t, writer.WriteBlockWithOptions(ids, values, col.WithBlockPartition(uint64(i+1))))
+		}
+		require.NoError(t, writer.FinalizeAndClose())
+		return buf.Bytes()
+	}
+	open := func(data []byte) *col.Reader {
+		reader, err := col.NewReaderFromBytes(data)
+		require.NoError(t, err)
+		t.Cleanup(func() { reader.Close() })
+		return reader
+	}
+
+	older := open(write([]uint64{1, 2}))
+	newerData := write([]uint64{3, 4}, []uint64{5, 6})
+	newer := open(newerData)
+
+	t.Run("Partition filter", func(t *testing.T) {
+		result, err := NewMultiReader([]*col.Reader{older, newer}).Aggregate(AggregateOptions{
+			PartitionFilter: []uint64{1},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(4), result.Count)
+		assert.Equal(t, int64(10), result.Sum)
+
+		_, err = NewMultiReader([]*col.Reader{older, newer}).Aggregate(AggregateOptions{
+			PartitionFilter: []uint64{1},
+			Resolve:         col.ResolveSum,
+		})
+		assert.Error(t, err)
+	})
+
+	// The second block of the newer file gets an ID section size of 0, so it
+	// cannot be decoded
+	meta, err := newer.BlockMeta(1)
+	require.NoError(t, err)
+	corrupted := append([]byte(nil), newerData...)
+	binary.LittleEndian.PutUint32(corrupted[meta.Offset+format.BlockHeaderSize+4:], 0)
+	multiReader := NewMultiReader([]*col.Reader{older, open(corrupted)})
+
+	for _, resolve := range []col.ResolvePolicy{col.ResolveLastWrite, col.ResolveSum} {
+		result, err := multiReader.Aggregate(AggregateOptions{SkipPreCalculated: true, Resolve: resolve})
+		var blockErr col.BlockError
+		require.True(t, errors.As(err, &blockErr))
+		assert.Equal(t, col.BlockID(1), blockErr.Block)
+		assert.Equal(t, uint64(4), result.Count, "The readable blocks are aggregated")
+
+		result, err = multiReader.Aggregate(AggregateOptions{SkipPreCalculated: true, Resolve: resolve, OnError: col.FailFast})
+		assert.Error(t, err)
+		assert.False(t, result.Valid())
+	}
+}
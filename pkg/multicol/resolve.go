@@ -0,0 +1,128 @@
+package multicol
+
+import (
+	"fmt"
+
+	"vibe-lsm/pkg/col"
+
+	"github.com/weaviate/sroar"
+)
+
+// Get returns the value stored for id across all readers and whether any
+// reader contains it. An ID stored in several files is resolved by policy,
+// where later readers are newer; within a file, the reader resolves it with
+// the same policy, see col.Reader.GetResolved.
+func (mr *MultiReader) Get(id uint64, policy col.ResolvePolicy) (int64, bool, error) {
+	if policy == col.ResolveLastWrite {
+		// The newest file containing the ID wins
+		for i := len(mr.readers) - 1; i >= 0; i-- {
+			value, found, err := mr.readers[i].GetResolved(id, policy)
+			if err != nil {
+				return 0, false, fmt.Errorf("failed to look up ID %d in reader %d: %w", id, i, err)
+			}
+			if found {
+				return value, true, nil
+			}
+		}
+		return 0, false, nil
+	}
+
+	var resolved int64
+	found := false
+	for i, reader := range mr.readers {
+		value, ok, err := reader.GetResolved(id, policy)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to look up ID %d in reader %d: %w", id, i, err)
+		}
+		if !ok {
+			continue
+		}
+		if !found {
+			resolved, found = value, true
+			continue
+		}
+		if resolved, err = policy.Resolve(id, resolved, value); err != nil {
+			return 0, false, err
+		}
+	}
+	return resolved, found, nil
+}
+
+// aggregateResolved aggregates all readers, resolving the IDs stored in
+// several files with opts.Resolve
+func (mr *MultiReader) aggregateResolved(opts AggregateOptions) (col.AggregateResult, error) {
+	// Find the IDs stored in more than one file
+	seen := sroar.NewBitmap()
+	duplicates := sroar.NewBitmap()
+	for i, reader := range mr.readers {
+		globalIDs, err := reader.GetGlobalIDBitmap()
+		if err != nil {
+			return col.AggregateResult{}, fmt.Errorf("failed to get global ID bitmap from reader %d: %w", i, err)
+		}
+		duplicates.Or(sroar.And(seen, globalIDs))
+		seen.Or(globalIDs)
+	}
+	if opts.Filter != nil {
+		duplicates = sroar.And(duplicates, opts.Filter)
+	}
+
+	if opts.Resolve == col.ResolveError && !duplicates.IsEmpty() {
+		return col.AggregateResult{}, fmt.Errorf("%w: %d IDs are stored in several files, the first is %d",
+			col.ErrDuplicateID, duplicates.GetCardinality(), duplicates.Minimum())
+	}
+
+	// IDs stored in a single file are aggregated as they are
+	results := make([]col.AggregateResult, 0, len(mr.readers)+1)
+	for i, reader := range mr.readers {
+		result := reader.AggregateWithOptions(col.AggregateOptions{
+			SkipPreCalculated: opts.SkipPreCalculated,
+			Filter:            opts.Filter,
+			DenyFilter:        duplicates,
+			OnError:           opts.OnError,
+		})
+		if err := result.Err(); err != nil && opts.OnError == col.FailFast {
+			return col.AggregateResult{}, fmt.Errorf("failed to aggregate reader %d: %w", i, err)
+		}
+		results = append(results, result)
+	}
+
+	// The values of the others are resolved from oldest to newest
+	resolved := make(map[uint64]int64, duplicates.GetCardinality())
+	for i, reader := range mr.readers {
+		for _, blockIdx := range reader.FilteredBlockIterator(duplicates, nil) {
+			ids, values, err := reader.ReadBlock(col.BlockID(blockIdx))
+			if err != nil {
+				return col.AggregateResult{}, fmt.Errorf("failed to read block %d of reader %d: %w", blockIdx, i, err)
+			}
+			for j, id := range ids {
+				if !duplicates.Contains(id) {
+					continue
+				}
+				value, ok := resolved[id]
+				if !ok {
+					resolved[id] = values[j]
+					continue
+				}
+				if resolved[id], err = opts.Resolve.Resolve(id, value, values[j]); err != nil {
+					return col.AggregateResult{}, err
+				}
+			}
+		}
+	}
+
+	var dup col.AggregateResult
+	for _, value := range resolved {
+		if dup.Count == 0 || value < dup.Min {
+			dup.Min = value
+		}
+		if dup.Count == 0 || value > dup.Max {
+			dup.Max = value
+		}
+		dup.Count++
+		dup.Sum += value
+	}
+	results = append(results, dup)
+
+	merged := col.MergeAggregates(results...)
+	return merged, merged.Err()
+}
@@ -0,0 +1,96 @@
+package multicol
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"vibe-lsm/pkg/col"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaviate/sroar"
+)
+
+// TestMultiReaderResolve tests the resolve policies for IDs stored in several files.
+func TestMultiReaderResolve(t *testing.T) {
+	dir := t.TempDir()
+
+	// IDs 2 and 3 are stored in both files, ID 4 only in the newer one
+	files := []struct {
+		name   string
+		ids    []uint64
+		values []int64
+	}{
+		{"old.col", []uint64{1, 2, 3}, []int64{10, 20, 30}},
+		{"new.col", []uint64{2, 3, 4}, []int64{200, 300, 400}},
+	}
+	readers := make([]*col.Reader, len(files))
+	for i, file := range files {
+		path := filepath.Join(dir, file.name)
+		writer, err := col.NewWriter(path)
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteBlock(file.ids, file.values))
+		require.NoError(t, writer.FinalizeAndClose())
+
+		readers[i], err = col.NewReader(path)
+		require.NoError(t, err)
+	}
+	mr := NewMultiReader(readers)
+	defer mr.Close()
+
+	t.Run("Get", func(t *testing.T) {
+		value, found, err := mr.Get(2, col.ResolveLastWrite)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, int64(200), value)
+
+		value, _, err = mr.Get(2, col.ResolveSum)
+		require.NoError(t, err)
+		assert.Equal(t, int64(220), value)
+
+		_, _, err = mr.Get(3, col.ResolveError)
+		assert.True(t, errors.Is(err, col.ErrDuplicateID))
+
+		value, found, err = mr.Get(1, col.ResolveError)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, int64(10), value)
+
+		_, found, err = mr.Get(5, col.ResolveSum)
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("Aggregate", func(t *testing.T) {
+		result, err := mr.Aggregate(AggregateOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(4), result.Count)
+		assert.Equal(t, int64(10+200+300+400), result.Sum)
+
+		result, err = mr.Aggregate(AggregateOptions{Resolve: col.ResolveSum})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(4), result.Count)
+		assert.Equal(t, int64(10+220+330+400), result.Sum)
+		assert.Equal(t, int64(10), result.Min)
+		assert.Equal(t, int64(400), result.Max)
+
+		_, err = mr.Aggregate(AggregateOptions{Resolve: col.ResolveError})
+		assert.True(t, errors.Is(err, col.ErrDuplicateID))
+
+		// Duplicates outside of the filter are not considered
+		filter := sroar.NewBitmap()
+		filter.Set(1)
+		filter.Set(4)
+		result, err = mr.Aggregate(AggregateOptions{Filter: filter, Resolve: col.ResolveError})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), result.Count)
+		assert.Equal(t, int64(410), result.Sum)
+
+		filter.Set(3)
+		result, err = mr.Aggregate(AggregateOptions{Filter: filter, Resolve: col.ResolveSum})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), result.Count)
+		assert.Equal(t, int64(10+330+400), result.Sum)
+	})
+}